@@ -0,0 +1,231 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OperationAnalyzeMemo is the operation name AnalyzeMemo reports to OperationMetrics.
+const OperationAnalyzeMemo = "analyze_memo"
+
+// AnalyzeMemoRequest contains parameters for analyzing a memo in one call.
+type AnalyzeMemoRequest struct {
+	// Content is the memo content to analyze.
+	Content string `json:"content"`
+
+	// ExistingTags are tags already in the system (for consistency).
+	ExistingTags []string `json:"existing_tags,omitempty"`
+
+	// MaxTags is the maximum number of tags to suggest (default 5).
+	MaxTags int `json:"max_tags,omitempty"`
+
+	// MaxTitleLength is the maximum title length in characters (default 60).
+	MaxTitleLength int `json:"max_title_length,omitempty"`
+
+	// MaxSummaryLength is the maximum summary length in characters (default 200).
+	MaxSummaryLength int `json:"max_summary_length,omitempty"`
+
+	// Language is the preferred language for the results (e.g., "en", "zh").
+	Language string `json:"language,omitempty"`
+
+	// Model overrides the provider's default model (optional).
+	Model string `json:"model,omitempty"`
+}
+
+// AnalyzeMemoResponse contains the tags, title and summary of a memo.
+type AnalyzeMemoResponse struct {
+	// Tags is the list of suggested tags.
+	Tags []string `json:"tags"`
+
+	// Title is the suggested title.
+	Title string `json:"title"`
+
+	// Summary is the brief summary.
+	Summary string `json:"summary"`
+
+	// Model is the model that generated the analysis, if reported.
+	Model string `json:"model,omitempty"`
+
+	// Usage is the token usage of the underlying completions, if reported.
+	Usage *TokenUsage `json:"usage,omitempty"`
+}
+
+// memoAnalysisSchema is the JSON schema for a memo analysis in JSON mode.
+var memoAnalysisSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"tags": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		},
+		"title":   map[string]any{"type": "string"},
+		"summary": map[string]any{"type": "string"},
+	},
+	"required":             []string{"tags", "title", "summary"},
+	"additionalProperties": false,
+}
+
+// AnalyzeMemo suggests tags, a title and a summary for a memo with a single
+// structured-output completion. Providers without JSON mode fall back to
+// separate SuggestTags, SuggestTitle and Summarize calls.
+func (s *service) AnalyzeMemo(ctx context.Context, req *AnalyzeMemoRequest) (*AnalyzeMemoResponse, error) {
+	provider := s.primaryProvider(ctx)
+	if provider == nil {
+		return nil, ErrProviderNotConfigured
+	}
+
+	if !provider.IsConfigured(ctx) {
+		return nil, ErrProviderNotConfigured
+	}
+
+	if !provider.Capabilities().JSONMode {
+		return s.analyzeMemoSeparately(ctx, req)
+	}
+
+	ctx, cancel := s.withDefaultDeadline(ctx)
+	defer cancel()
+
+	if err := s.acquire(ctx, provider); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := provider.Complete(ctx, buildAnalyzeMemoRequest(provider, req))
+	s.observe(provider, OperationAnalyzeMemo, start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze memo: %w", err)
+	}
+	s.recordUsage(resp.Model, resp.Usage)
+
+	result, err := parseMemoAnalysis(resp.Content, req)
+	if err != nil {
+		return nil, err
+	}
+	result.Model = resp.Model
+	result.Usage = resp.Usage
+	return result, nil
+}
+
+// analyzeMemoSeparately builds an analysis from separate tag, title and summary calls.
+func (s *service) analyzeMemoSeparately(ctx context.Context, req *AnalyzeMemoRequest) (*AnalyzeMemoResponse, error) {
+	tags, err := s.SuggestTags(ctx, &SuggestTagsRequest{
+		Content:      req.Content,
+		ExistingTags: req.ExistingTags,
+		MaxTags:      req.MaxTags,
+		Language:     req.Language,
+		Model:        req.Model,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	title, err := s.SuggestTitle(ctx, &SuggestTitleRequest{
+		Content:   req.Content,
+		MaxLength: req.MaxTitleLength,
+		Language:  req.Language,
+		Model:     req.Model,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.Summarize(ctx, &SummarizeRequest{
+		Content:   req.Content,
+		MaxLength: req.MaxSummaryLength,
+		Language:  req.Language,
+		Model:     req.Model,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AnalyzeMemoResponse{
+		Tags:    tags.Tags,
+		Title:   title.Title,
+		Summary: summary.Summary,
+		Model:   summary.Model,
+		Usage:   addUsage(addUsage(tags.Usage, title.Usage), summary.Usage),
+	}, nil
+}
+
+// buildAnalyzeMemoRequest builds the JSON-mode completion request for an analysis.
+func buildAnalyzeMemoRequest(provider Provider, req *AnalyzeMemoRequest) *CompletionRequest {
+	maxTags := tagLimit(&SuggestTagsRequest{MaxTags: req.MaxTags})
+	maxTitleLength := req.MaxTitleLength
+	if maxTitleLength <= 0 {
+		maxTitleLength = defaultTitleMaxLength
+	}
+	maxSummaryLength := req.MaxSummaryLength
+	if maxSummaryLength <= 0 {
+		maxSummaryLength = defaultSummaryMaxLength
+	}
+
+	systemPrompt := fmt.Sprintf(`You are a helpful assistant that organizes notes and memos.
+Analyze the content and return ONLY a JSON object with "tags", "title" and "summary" fields, nothing else.
+"tags" is an array of up to %d concise, relevant tags: lowercase, single words or hyphenated phrases (e.g., "machine-learning").
+"title" is a concise title under %d characters with no quotes or trailing punctuation.
+"summary" is a brief summary under %d characters that captures the main points.
+Example: {"tags": ["project", "meeting"], "title": "Q3 roadmap review", "summary": "The team agreed on the Q3 roadmap."}`,
+		maxTags, maxTitleLength, maxSummaryLength)
+	if req.Language != "" {
+		systemPrompt += fmt.Sprintf("\nWrite the tags, title and summary in language: %s.", req.Language)
+	}
+
+	userPrompt := fmt.Sprintf("Analyze this content:\n\n%s", req.Content)
+	if len(req.ExistingTags) > 0 {
+		userPrompt = fmt.Sprintf("Prefer these existing tags where relevant: %s\n\n%s", strings.Join(req.ExistingTags, ", "), userPrompt)
+	}
+
+	return &CompletionRequest{
+		Model: req.Model,
+		Messages: []Message{
+			{Role: RoleSystem, Content: systemPrompt},
+			{Role: RoleUser, Content: userPrompt},
+		},
+		Temperature:    0.3,
+		MaxTokens:      maxTitleLength/charsPerToken + maxSummaryLength/charsPerToken*2 + maxTags*8 + 64,
+		ResponseFormat: jsonResponseFormat(provider, req.Model, "memo_analysis", memoAnalysisSchema),
+	}
+}
+
+// parseMemoAnalysis parses a {"tags", "title", "summary"} JSON response, which
+// may be wrapped in a markdown code fence or surrounded by prose.
+func parseMemoAnalysis(content string, req *AnalyzeMemoRequest) (*AnalyzeMemoResponse, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("failed to parse memo analysis: no JSON object in response")
+	}
+
+	var structured struct {
+		Tags    json.RawMessage `json:"tags"`
+		Title   string          `json:"title"`
+		Summary string          `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &structured); err != nil {
+		return nil, fmt.Errorf("failed to parse memo analysis: %w", err)
+	}
+
+	var tags []string
+	if len(structured.Tags) > 0 {
+		tags, _, _ = decodeTagArray(string(structured.Tags))
+	}
+	if maxTags := tagLimit(&SuggestTagsRequest{MaxTags: req.MaxTags}); len(tags) > maxTags {
+		tags = tags[:maxTags]
+	}
+
+	maxTitleLength := req.MaxTitleLength
+	if maxTitleLength <= 0 {
+		maxTitleLength = defaultTitleMaxLength
+	}
+
+	return &AnalyzeMemoResponse{
+		Tags:    tags,
+		Title:   cleanTitle(structured.Title, maxTitleLength),
+		Summary: strings.TrimSpace(structured.Summary),
+	}, nil
+}
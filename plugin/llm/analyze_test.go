@@ -0,0 +1,94 @@
+package llm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeMemo_SingleCall(t *testing.T) {
+	provider := &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		capabilities: &ProviderCapabilities{JSONMode: true},
+		completeResp: &CompletionResponse{
+			Content: "```json\n" + `{"tags": ["roadmap", "planning"], "title": "\"Q3 roadmap review.\"", "summary": "The team agreed on the Q3 roadmap."}` + "\n```",
+			Model:   "gpt-4o-mini",
+			Usage:   &TokenUsage{PromptTokens: 40, CompletionTokens: 30, TotalTokens: 70},
+		},
+	}
+	svc := NewService()
+	svc.RegisterProvider(provider)
+
+	resp, err := svc.AnalyzeMemo(context.Background(), &AnalyzeMemoRequest{
+		Content:      "We reviewed and agreed on the Q3 roadmap.",
+		ExistingTags: []string{"planning"},
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeMemo() error: %v", err)
+	}
+
+	if provider.completeCalls != 1 {
+		t.Errorf("Expected one completion, got %d", provider.completeCalls)
+	}
+	if format := provider.lastCompleteReq.ResponseFormat; format == nil || format.Type != ResponseFormatJSONObject || format.Schema == nil {
+		t.Errorf("Expected a JSON object response format with a schema, got %+v", format)
+	}
+	if want := []string{"roadmap", "planning"}; !reflect.DeepEqual(resp.Tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, resp.Tags)
+	}
+	if resp.Title != "Q3 roadmap review" {
+		t.Errorf("Expected a cleaned title, got %q", resp.Title)
+	}
+	if resp.Summary != "The team agreed on the Q3 roadmap." {
+		t.Errorf("Unexpected summary %q", resp.Summary)
+	}
+	if resp.Model != "gpt-4o-mini" || resp.Usage == nil || resp.Usage.TotalTokens != 70 {
+		t.Errorf("Expected model and usage from the completion, got %q, %+v", resp.Model, resp.Usage)
+	}
+}
+
+func TestAnalyzeMemo_FallsBackWithoutJSONMode(t *testing.T) {
+	provider := &mockProvider{
+		providerType:  ProviderOllama,
+		name:          "Ollama",
+		configured:    true,
+		suggestResp:   &SuggestTagsResponse{Tags: []string{"roadmap"}, Usage: &TokenUsage{TotalTokens: 10}},
+		titleResp:     &SuggestTitleResponse{Title: "Q3 roadmap", Usage: &TokenUsage{TotalTokens: 5}},
+		summarizeResp: &SummarizeResponse{Summary: "Roadmap agreed.", Usage: &TokenUsage{TotalTokens: 20}},
+	}
+	svc := NewService()
+	svc.RegisterProvider(provider)
+
+	resp, err := svc.AnalyzeMemo(context.Background(), &AnalyzeMemoRequest{Content: "We agreed on the Q3 roadmap."})
+	if err != nil {
+		t.Fatalf("AnalyzeMemo() error: %v", err)
+	}
+
+	if provider.completeCalls != 0 {
+		t.Errorf("Expected no combined completion, got %d", provider.completeCalls)
+	}
+	if len(resp.Tags) != 1 || resp.Title != "Q3 roadmap" || resp.Summary != "Roadmap agreed." {
+		t.Errorf("Expected results of the separate calls, got %+v", resp)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 35 {
+		t.Errorf("Expected combined usage of 35 tokens, got %+v", resp.Usage)
+	}
+}
+
+func TestAnalyzeMemo_InvalidResponse(t *testing.T) {
+	provider := &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		capabilities: &ProviderCapabilities{JSONMode: true},
+		completeResp: &CompletionResponse{Content: "I cannot help with that."},
+	}
+	svc := NewService()
+	svc.RegisterProvider(provider)
+
+	if _, err := svc.AnalyzeMemo(context.Background(), &AnalyzeMemoRequest{Content: "memo"}); err == nil {
+		t.Error("Expected an error for a response without JSON")
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 
 	storepb "github.com/usememos/memos/proto/gen/store"
 )
@@ -13,8 +17,25 @@ const (
 	anthropicBaseURL      = "https://api.anthropic.com"
 	anthropicDefaultModel = "claude-3-haiku-20240307"
 	anthropicAPIVersion   = "2023-06-01"
+
+	// anthropicPromptCachingBeta enables cache_control on content blocks
+	anthropicPromptCachingBeta = "prompt-caching-2024-07-31"
+
+	// anthropicModelsPageSize is the page size requested from /v1/models (the API maximum)
+	anthropicModelsPageSize = 1000
+	// anthropicModelsMaxPages bounds pagination in case the API keeps reporting more pages
+	anthropicModelsMaxPages = 10
 )
 
+// anthropicFallbackModels is returned when the models endpoint is unavailable.
+var anthropicFallbackModels = []string{
+	"claude-3-5-sonnet-20241022",
+	"claude-3-5-haiku-20241022",
+	"claude-3-opus-20240229",
+	"claude-3-sonnet-20240229",
+	"claude-3-haiku-20240307",
+}
+
 // AnthropicProvider implements the Provider interface for Anthropic Claude.
 type AnthropicProvider struct {
 	*BaseProvider
@@ -74,20 +95,74 @@ func (p *AnthropicProvider) GetDefaultModel() string {
 	return p.defaultModel
 }
 
-// GetAvailableModels returns available models.
+// GetAvailableModels returns available models from the cache, fetching them if needed.
+// If the models endpoint fails, a static list of known models is returned.
 func (p *AnthropicProvider) GetAvailableModels(ctx context.Context) ([]string, error) {
+	return p.modelsWithFallback(ctx, false)
+}
+
+// RefreshAvailableModels refetches the model list, bypassing the cache.
+func (p *AnthropicProvider) RefreshAvailableModels(ctx context.Context) ([]string, error) {
+	return p.modelsWithFallback(ctx, true)
+}
+
+// modelsWithFallback returns the cached or live model list, falling back to the
+// static list on failure. Fallback results are not cached so the next call retries.
+func (p *AnthropicProvider) modelsWithFallback(ctx context.Context, forceRefresh bool) ([]string, error) {
 	if !p.IsConfigured(ctx) {
 		return nil, ErrProviderNotConfigured
 	}
 
-	// Anthropic doesn't have a models endpoint, return known models
-	return []string{
-		"claude-3-5-sonnet-20241022",
-		"claude-3-5-haiku-20241022",
-		"claude-3-opus-20240229",
-		"claude-3-sonnet-20240229",
-		"claude-3-haiku-20240307",
-	}, nil
+	models, err := p.CachedModels(ctx, forceRefresh, p.fetchAvailableModels)
+	if err != nil {
+		slog.Warn("Failed to list Anthropic models, using the built-in list",
+			slog.String("error", err.Error()))
+		return slices.Clone(anthropicFallbackModels), nil
+	}
+	return models, nil
+}
+
+// fetchAvailableModels queries /v1/models, following pagination.
+func (p *AnthropicProvider) fetchAvailableModels(ctx context.Context) ([]string, error) {
+	var models []string
+	afterID := ""
+	for page := 0; page < anthropicModelsMaxPages; page++ {
+		params := url.Values{}
+		params.Set("limit", strconv.Itoa(anthropicModelsPageSize))
+		if afterID != "" {
+			params.Set("after_id", afterID)
+		}
+		endpoint := fmt.Sprintf("%s/v1/models?%s", p.baseURL, params.Encode())
+
+		respBody, status, err := p.doRequest(ctx, http.MethodGet, endpoint, nil, p.headers())
+		if err != nil {
+			return nil, err
+		}
+
+		var resp anthropicModelsResponse
+		if err := p.decodeJSON(respBody, status, &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse models response: %w", err)
+		}
+
+		for _, m := range resp.Data {
+			models = append(models, m.ID)
+		}
+
+		if !resp.HasMore || resp.LastID == "" {
+			break
+		}
+		afterID = resp.LastID
+	}
+
+	if len(models) == 0 {
+		return nil, fmt.Errorf("models response contained no models")
+	}
+	return models, nil
+}
+
+// GetModelInfo returns the available models annotated with known limits.
+func (p *AnthropicProvider) GetModelInfo(ctx context.Context) ([]ModelInfo, error) {
+	return p.DefaultGetModelInfo(ctx, p)
 }
 
 // Complete performs chat completion.
@@ -95,7 +170,56 @@ func (p *AnthropicProvider) Complete(ctx context.Context, req *CompletionRequest
 	if !p.IsConfigured(ctx) {
 		return nil, ErrProviderNotConfigured
 	}
+	ctx = withRequestMetadata(ctx, req.Metadata)
+
+	ctx, cancel := withRequestTimeout(ctx, req.TimeoutSeconds)
+	defer cancel()
 
+	model := req.Model
+	if model == "" {
+		model = p.defaultModel
+	}
+	req, err := p.checkSampling(req, maxTemperatureAnthropic)
+	if err != nil {
+		return nil, err
+	}
+	req, truncated, err := truncateToContext(req, contextWindow(model))
+	if err != nil {
+		return nil, err
+	}
+
+	anthropicReq := p.buildMessagesRequest(req)
+	url := fmt.Sprintf("%s/v1/messages", p.baseURL)
+
+	respBody, status, err := p.doRequest(ctx, http.MethodPost, url, anthropicReq, p.headers())
+	if err != nil {
+		return nil, err
+	}
+
+	var resp anthropicMessagesResponse
+	if err := p.decodeJSON(respBody, status, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse completion response: %w", err)
+	}
+
+	// Extract text content from response
+	var content string
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			content += block.Text
+		}
+	}
+
+	return p.captureResponse(&CompletionResponse{
+		Content:      content,
+		Model:        resp.Model,
+		Usage:        resp.Usage.tokenUsage(),
+		FinishReason: normalizeFinishReason(resp.StopReason),
+		Truncated:    truncated,
+	}), nil
+}
+
+// buildMessagesRequest converts a CompletionRequest to an Anthropic Messages API request.
+func (p *AnthropicProvider) buildMessagesRequest(req *CompletionRequest) anthropicMessagesRequest {
 	model := req.Model
 	if model == "" {
 		model = p.defaultModel
@@ -108,10 +232,7 @@ func (p *AnthropicProvider) Complete(ctx context.Context, req *CompletionRequest
 		if m.Role == RoleSystem {
 			system = m.Content
 		} else {
-			messages = append(messages, anthropicMessage{
-				Role:    string(m.Role),
-				Content: m.Content,
-			})
+			messages = append(messages, toAnthropicMessage(m))
 		}
 	}
 
@@ -122,7 +243,15 @@ func (p *AnthropicProvider) Complete(ctx context.Context, req *CompletionRequest
 	}
 
 	if system != "" {
-		anthropicReq.System = system
+		if p.Config.EnablePromptCaching {
+			anthropicReq.System = []anthropicContentBlock{{
+				Type:         "text",
+				Text:         system,
+				CacheControl: &anthropicCacheControl{Type: "ephemeral"},
+			}}
+		} else {
+			anthropicReq.System = system
+		}
 	}
 	if req.MaxTokens > 0 {
 		anthropicReq.MaxTokens = req.MaxTokens
@@ -133,41 +262,96 @@ func (p *AnthropicProvider) Complete(ctx context.Context, req *CompletionRequest
 	if req.TopP > 0 {
 		anthropicReq.TopP = req.TopP
 	}
+	if len(req.Stop) > 0 {
+		anthropicReq.StopSequences = req.Stop
+	}
+	// Seed and frequency/presence penalties are not supported by Anthropic and are ignored
 
-	url := fmt.Sprintf("%s/v1/messages", p.baseURL)
+	return anthropicReq
+}
+
+// headers returns the authentication and version headers for Anthropic requests.
+func (p *AnthropicProvider) headers() map[string]string {
 	headers := map[string]string{
 		"x-api-key":         p.apiKey,
 		"anthropic-version": anthropicAPIVersion,
 	}
+	if p.Config.EnablePromptCaching {
+		headers["anthropic-beta"] = anthropicPromptCachingBeta
+	}
+	return headers
+}
+
+// CompleteStream performs a streamed chat completion using server-sent events.
+func (p *AnthropicProvider) CompleteStream(ctx context.Context, req *CompletionRequest) (<-chan CompletionChunk, error) {
+	if !p.IsConfigured(ctx) {
+		return nil, ErrProviderNotConfigured
+	}
+	ctx = withRequestMetadata(ctx, req.Metadata)
 
-	respBody, err := p.DoRequest(ctx, http.MethodPost, url, anthropicReq, headers)
+	req, err := p.checkSampling(req, maxTemperatureAnthropic)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp anthropicMessagesResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("failed to parse completion response: %w", err)
+	anthropicReq := p.buildMessagesRequest(req)
+	anthropicReq.Stream = true
+	url := fmt.Sprintf("%s/v1/messages", p.baseURL)
+
+	body, err := p.DoStreamRequest(ctx, http.MethodPost, url, anthropicReq, p.headers())
+	if err != nil {
+		return nil, err
 	}
 
-	// Extract text content from response
-	var content string
-	for _, block := range resp.Content {
-		if block.Type == "text" {
-			content += block.Text
+	ch := make(chan CompletionChunk)
+	go func() {
+		defer close(ch)
+		defer body.Close()
+
+		final := CompletionChunk{Done: true, Usage: &TokenUsage{}}
+		err := readSSE(body, func(event, data string) error {
+			var ev anthropicStreamEvent
+			if err := json.Unmarshal([]byte(data), &ev); err != nil {
+				return fmt.Errorf("failed to parse stream event: %w", err)
+			}
+			if event == "" {
+				event = ev.Type
+			}
+
+			switch event {
+			case "message_start":
+				final.Model = ev.Message.Model
+				final.Usage = ev.Message.Usage.tokenUsage()
+			case "content_block_delta":
+				if ev.Delta.Type == "text_delta" && ev.Delta.Text != "" {
+					if !sendChunk(ctx, ch, CompletionChunk{Content: ev.Delta.Text}) {
+						return ctx.Err()
+					}
+				}
+			case "message_delta":
+				if ev.Delta.StopReason != "" {
+					final.FinishReason = normalizeFinishReason(ev.Delta.StopReason)
+				}
+				if ev.Usage != nil {
+					final.Usage.CompletionTokens = ev.Usage.OutputTokens
+				}
+			case "message_stop":
+				return errStopStream
+			case "error":
+				return fmt.Errorf("anthropic stream error: %s", ev.Error.Message)
+			}
+			return nil
+		})
+		if err != nil {
+			sendChunk(ctx, ch, CompletionChunk{Err: err})
+			return
 		}
-	}
 
-	return &CompletionResponse{
-		Content: content,
-		Model:   resp.Model,
-		Usage: &TokenUsage{
-			PromptTokens:     resp.Usage.InputTokens,
-			CompletionTokens: resp.Usage.OutputTokens,
-			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
-		},
-		FinishReason: resp.StopReason,
-	}, nil
+		final.Usage.TotalTokens = final.Usage.PromptTokens + final.Usage.CompletionTokens
+		sendChunk(ctx, ch, final)
+	}()
+
+	return ch, nil
 }
 
 // Embed generates embeddings - Anthropic doesn't support embeddings natively.
@@ -175,6 +359,65 @@ func (p *AnthropicProvider) Embed(ctx context.Context, req *EmbeddingRequest) (*
 	return nil, fmt.Errorf("anthropic does not support embeddings")
 }
 
+// Capabilities describes the features supported by the Anthropic provider.
+func (p *AnthropicProvider) Capabilities() ProviderCapabilities {
+	// Anthropic has no embeddings API
+	return ProviderCapabilities{
+		Streaming: true,
+		Vision:    true,
+	}
+}
+
+// toAnthropicMessage converts a message, using content blocks when images are attached.
+// Images are placed before the text, as Anthropic recommends.
+func toAnthropicMessage(m Message) anthropicMessage {
+	if len(m.Images) == 0 {
+		return anthropicMessage{Role: string(m.Role), Content: m.Content}
+	}
+
+	blocks := make([]anthropicContentBlock, 0, len(m.Images)+1)
+	for _, img := range m.Images {
+		source := &anthropicImageSource{Type: "url", URL: img.URL}
+		if img.Base64 != "" {
+			source = &anthropicImageSource{Type: "base64", MediaType: img.MimeType, Data: img.Base64}
+		}
+		blocks = append(blocks, anthropicContentBlock{Type: "image", Source: source})
+	}
+	if m.Content != "" {
+		blocks = append(blocks, anthropicContentBlock{Type: "text", Text: m.Content})
+	}
+	return anthropicMessage{Role: string(m.Role), Content: blocks}
+}
+
+// CheckHealth verifies the API is reachable and accepts the key. Anthropic has
+// no free health endpoint, and GetAvailableModels falls back to a built-in
+// list, so this sends a minimal 1-token message to the default model.
+// Failures wrap ErrInvalidAPIKey or ErrProviderUnavailable.
+func (p *AnthropicProvider) CheckHealth(ctx context.Context) error {
+	if !p.IsConfigured(ctx) {
+		return ErrProviderNotConfigured
+	}
+
+	url := fmt.Sprintf("%s/v1/messages", p.baseURL)
+	req := anthropicMessagesRequest{
+		Model:     p.defaultModel,
+		Messages:  []anthropicMessage{{Role: string(RoleUser), Content: "ping"}},
+		MaxTokens: 1,
+	}
+	if _, err := p.DoRequest(ctx, http.MethodPost, url, req, p.headers()); err != nil {
+		return healthCheckError(ProviderAnthropic, err)
+	}
+	return nil
+}
+
+// ValidateConfig checks the API key is set and the base URL is well formed.
+func (p *AnthropicProvider) ValidateConfig(ctx context.Context) error {
+	if err := p.DefaultValidateConfig(ctx, p); err != nil {
+		return err
+	}
+	return validateBaseURL(p.baseURL)
+}
+
 // SuggestTags suggests tags for the given content.
 func (p *AnthropicProvider) SuggestTags(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
 	return p.DefaultSuggestTags(ctx, p, req)
@@ -185,6 +428,11 @@ func (p *AnthropicProvider) Summarize(ctx context.Context, req *SummarizeRequest
 	return p.DefaultSummarize(ctx, p, req)
 }
 
+// SuggestTitle generates a short title for the content.
+func (p *AnthropicProvider) SuggestTitle(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error) {
+	return p.DefaultSuggestTitle(ctx, p, req)
+}
+
 // ToProto converts the provider configuration to proto format.
 func (p *AnthropicProvider) ToProto() *storepb.LLMAnthropicConfig {
 	return &storepb.LLMAnthropicConfig{
@@ -196,18 +444,71 @@ func (p *AnthropicProvider) ToProto() *storepb.LLMAnthropicConfig {
 
 // Anthropic API request/response types
 
+// anthropicMessage content is a string, or a []anthropicContentBlock when images are attached.
 type anthropicMessage struct {
 	Role    string `json:"role"`
-	Content string `json:"content"`
+	Content any    `json:"content"`
+}
+
+type anthropicContentBlock struct {
+	Type         string                 `json:"type"`
+	Text         string                 `json:"text,omitempty"`
+	Source       *anthropicImageSource  `json:"source,omitempty"`
+	CacheControl *anthropicCacheControl `json:"cache_control,omitempty"`
+}
+
+// anthropicCacheControl marks the prompt up to and including a block as cacheable.
+type anthropicCacheControl struct {
+	Type string `json:"type"` // "ephemeral"
+}
+
+type anthropicImageSource struct {
+	Type      string `json:"type"` // "base64" or "url"
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
 }
 
 type anthropicMessagesRequest struct {
 	Model       string             `json:"model"`
 	Messages    []anthropicMessage `json:"messages"`
-	System      string             `json:"system,omitempty"`
+	System      any                `json:"system,omitempty"` // string, or []anthropicContentBlock with prompt caching
 	MaxTokens   int                `json:"max_tokens"`
 	Temperature float64            `json:"temperature,omitempty"`
 	TopP        float64            `json:"top_p,omitempty"`
+
+	StopSequences []string `json:"stop_sequences,omitempty"`
+	Stream        bool     `json:"stream,omitempty"`
+}
+
+// anthropicStreamEvent covers the fields used from Messages API stream events.
+type anthropicStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Model string         `json:"model"`
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type anthropicModelsResponse struct {
+	Data []struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"display_name"`
+	} `json:"data"`
+	HasMore bool   `json:"has_more"`
+	LastID  string `json:"last_id"`
 }
 
 type anthropicMessagesResponse struct {
@@ -221,8 +522,24 @@ type anthropicMessagesResponse struct {
 		Type string `json:"type"`
 		Text string `json:"text"`
 	} `json:"content"`
-	Usage struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
-	} `json:"usage"`
+	Usage anthropicUsage `json:"usage"`
+}
+
+type anthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// tokenUsage converts Anthropic usage. input_tokens excludes cached tokens,
+// which are reported separately.
+func (u anthropicUsage) tokenUsage() *TokenUsage {
+	return &TokenUsage{
+		PromptTokens:        u.InputTokens,
+		CompletionTokens:    u.OutputTokens,
+		TotalTokens:         u.InputTokens + u.OutputTokens,
+		CacheCreationTokens: u.CacheCreationInputTokens,
+		CacheReadTokens:     u.CacheReadInputTokens,
+	}
 }
@@ -0,0 +1,485 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAnthropicProviderCompleteSamplingOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		stop, ok := raw["stop_sequences"].([]any)
+		if !ok || len(stop) != 1 || stop[0] != "END" {
+			t.Errorf("Expected stop_sequences [END], got %v", raw["stop_sequences"])
+		}
+		// Unsupported options must not be sent
+		for _, key := range []string{"seed", "frequency_penalty", "presence_penalty", "stop"} {
+			if _, exists := raw[key]; exists {
+				t.Errorf("Expected %s to be omitted, got %v", key, raw[key])
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"claude-3-5-sonnet-20241022","content":[{"type":"text","text":"ok"}],"stop_reason":"stop_sequence"}`))
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProvider(&ProviderConfig{
+		Type:    ProviderAnthropic,
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+	})
+
+	seed := 42
+	resp, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages:         []Message{{Role: RoleUser, Content: "Hello"}},
+		Stop:             []string{"END"},
+		Seed:             &seed,
+		FrequencyPenalty: 0.5,
+		PresencePenalty:  0.5,
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if resp.Content != "ok" {
+		t.Errorf("Expected content ok, got %q", resp.Content)
+	}
+}
+
+func TestAnthropicProviderCompleteWithImages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Role    string                  `json:"role"`
+				Content []anthropicContentBlock `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		blocks := req.Messages[0].Content
+		if len(blocks) != 3 {
+			t.Fatalf("Expected 2 image blocks and 1 text block, got %+v", blocks)
+		}
+		if blocks[0].Type != "image" || blocks[0].Source == nil ||
+			blocks[0].Source.Type != "base64" || blocks[0].Source.MediaType != "image/png" || blocks[0].Source.Data != "aGVsbG8=" {
+			t.Errorf("Unexpected base64 image block: %+v", blocks[0])
+		}
+		if blocks[1].Type != "image" || blocks[1].Source == nil ||
+			blocks[1].Source.Type != "url" || blocks[1].Source.URL != "https://example.com/cat.jpg" {
+			t.Errorf("Unexpected URL image block: %+v", blocks[1])
+		}
+		if blocks[2].Type != "text" || blocks[2].Text != "Describe these" {
+			t.Errorf("Unexpected text block: %+v", blocks[2])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"claude-3-5-sonnet-20241022","content":[{"type":"text","text":"Two cats"}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProvider(&ProviderConfig{
+		Type:    ProviderAnthropic,
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+	})
+
+	_, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{{
+			Role:    RoleUser,
+			Content: "Describe these",
+			Images: []ImagePart{
+				{Base64: "aGVsbG8=", MimeType: "image/png"},
+				{URL: "https://example.com/cat.jpg"},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+}
+
+// writeSSE writes Anthropic-style server-sent events to w.
+func writeSSE(w http.ResponseWriter, events [][2]string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, ev := range events {
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev[0], ev[1])
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+}
+
+func TestAnthropicProviderCompleteStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req anthropicMessagesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if !req.Stream {
+			t.Error("Expected stream to be true")
+		}
+		if req.System != "Be brief." {
+			t.Errorf("Expected system prompt to be extracted, got %q", req.System)
+		}
+		if len(req.Messages) != 1 || req.Messages[0].Role != "user" {
+			t.Errorf("Expected only the user message, got %+v", req.Messages)
+		}
+
+		writeSSE(w, [][2]string{
+			{"message_start", `{"type":"message_start","message":{"model":"claude-3-5-haiku-20241022","usage":{"input_tokens":12,"output_tokens":1}}}`},
+			{"content_block_start", `{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`},
+			{"ping", `{"type":"ping"}`},
+			{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}`},
+			{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":", world"}}`},
+			{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"!"}}`},
+			{"content_block_stop", `{"type":"content_block_stop","index":0}`},
+			{"message_delta", `{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":5}}`},
+			{"message_stop", `{"type":"message_stop"}`},
+		})
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProvider(&ProviderConfig{
+		Type:    ProviderAnthropic,
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+	})
+
+	stream, err := provider.CompleteStream(context.Background(), &CompletionRequest{
+		Messages: []Message{
+			{Role: RoleSystem, Content: "Be brief."},
+			{Role: RoleUser, Content: "Say hello"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CompleteStream() error: %v", err)
+	}
+
+	var content strings.Builder
+	var final *CompletionChunk
+	for chunk := range stream {
+		if chunk.Err != nil {
+			t.Fatalf("Stream error: %v", chunk.Err)
+		}
+		if chunk.Done {
+			c := chunk
+			final = &c
+			continue
+		}
+		content.WriteString(chunk.Content)
+	}
+
+	if content.String() != "Hello, world!" {
+		t.Errorf("Expected concatenated content 'Hello, world!', got %q", content.String())
+	}
+	if final == nil {
+		t.Fatal("Expected a terminal chunk")
+	}
+	if final.FinishReason != FinishReasonStop {
+		t.Errorf("Expected finish reason stop, got %q", final.FinishReason)
+	}
+	if final.Model != "claude-3-5-haiku-20241022" {
+		t.Errorf("Expected model from message_start, got %q", final.Model)
+	}
+	if final.Usage == nil || final.Usage.PromptTokens != 12 || final.Usage.CompletionTokens != 5 || final.Usage.TotalTokens != 17 {
+		t.Errorf("Expected usage 12/5/17, got %+v", final.Usage)
+	}
+}
+
+func TestAnthropicProviderCompleteStreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeSSE(w, [][2]string{
+			{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hel"}}`},
+			{"error", `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`},
+		})
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProvider(&ProviderConfig{
+		Type:    ProviderAnthropic,
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+	})
+
+	stream, err := provider.CompleteStream(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CompleteStream() error: %v", err)
+	}
+
+	var last CompletionChunk
+	for chunk := range stream {
+		last = chunk
+	}
+	if last.Err == nil || !strings.Contains(last.Err.Error(), "Overloaded") {
+		t.Errorf("Expected terminal error chunk, got %+v", last)
+	}
+}
+
+func TestAnthropicProviderCompleteStreamHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"invalid x-api-key"}}`))
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProvider(&ProviderConfig{
+		Type:    ProviderAnthropic,
+		APIKey:  "bad-key",
+		BaseURL: server.URL,
+	})
+
+	_, err := provider.CompleteStream(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err == nil {
+		t.Fatal("Expected error for 401 response")
+	}
+}
+
+func TestAnthropicProviderGetAvailableModels(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v1/models" {
+			t.Errorf("Expected path /v1/models, got %s", r.URL.Path)
+		}
+		if got := r.Header.Get("x-api-key"); got != "test-key" {
+			t.Errorf("Expected x-api-key header, got %q", got)
+		}
+		if got := r.Header.Get("anthropic-version"); got != anthropicAPIVersion {
+			t.Errorf("Expected anthropic-version %s, got %q", anthropicAPIVersion, got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("after_id") {
+		case "":
+			w.Write([]byte(`{"data":[{"type":"model","id":"claude-sonnet-4-20250514"},{"type":"model","id":"claude-3-7-sonnet-20250219"}],"has_more":true,"last_id":"claude-3-7-sonnet-20250219"}`))
+		case "claude-3-7-sonnet-20250219":
+			w.Write([]byte(`{"data":[{"type":"model","id":"claude-3-5-haiku-20241022"}],"has_more":false,"last_id":"claude-3-5-haiku-20241022"}`))
+		default:
+			t.Errorf("Unexpected after_id %q", r.URL.Query().Get("after_id"))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProvider(&ProviderConfig{
+		Type:    ProviderAnthropic,
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+	})
+
+	models, err := provider.GetAvailableModels(context.Background())
+	if err != nil {
+		t.Fatalf("GetAvailableModels() error: %v", err)
+	}
+	expected := []string{"claude-sonnet-4-20250514", "claude-3-7-sonnet-20250219", "claude-3-5-haiku-20241022"}
+	if fmt.Sprint(models) != fmt.Sprint(expected) {
+		t.Errorf("Expected models %v, got %v", expected, models)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 page requests, got %d", requests)
+	}
+}
+
+func TestAnthropicProviderGetAvailableModelsFallback(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"type":"error","error":{"type":"not_found_error","message":"Not found"}}`))
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProvider(&ProviderConfig{
+		Type:    ProviderAnthropic,
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+	})
+
+	models, err := provider.GetAvailableModels(context.Background())
+	if err != nil {
+		t.Fatalf("Expected fallback instead of error, got %v", err)
+	}
+	if fmt.Sprint(models) != fmt.Sprint(anthropicFallbackModels) {
+		t.Errorf("Expected fallback models %v, got %v", anthropicFallbackModels, models)
+	}
+
+	// The fallback is not cached, so the next call tries the endpoint again
+	provider.GetAvailableModels(context.Background())
+	if requests != 2 {
+		t.Errorf("Expected each call to retry the endpoint, got %d requests", requests)
+	}
+
+	unconfigured := NewAnthropicProvider(&ProviderConfig{Type: ProviderAnthropic, BaseURL: server.URL})
+	if _, err := unconfigured.GetAvailableModels(context.Background()); err != ErrProviderNotConfigured {
+		t.Errorf("Expected ErrProviderNotConfigured, got %v", err)
+	}
+}
+
+func TestAnthropicProviderCompleteFinishReason(t *testing.T) {
+	tests := map[string]string{
+		"end_turn":      FinishReasonStop,
+		"stop_sequence": FinishReasonStop,
+		"max_tokens":    FinishReasonLength,
+		"tool_use":      FinishReasonToolCalls,
+		"refusal":       FinishReasonContentFilter,
+	}
+
+	for native, want := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"model":"claude-3-5-sonnet-20241022","content":[{"type":"text","text":"ok"}],"stop_reason":%q}`, native)
+		}))
+
+		provider := NewAnthropicProvider(&ProviderConfig{Type: ProviderAnthropic, APIKey: "test-key", BaseURL: server.URL})
+		resp, err := provider.Complete(context.Background(), &CompletionRequest{
+			Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+		})
+		server.Close()
+
+		if err != nil {
+			t.Fatalf("Complete() error for %s: %v", native, err)
+		}
+		if resp.FinishReason != want {
+			t.Errorf("stop_reason %q: expected %q, got %q", native, want, resp.FinishReason)
+		}
+	}
+}
+
+func TestAnthropicProviderPromptCaching(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("enabled=%v", enabled), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var raw map[string]any
+				if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+					t.Fatalf("Failed to decode request: %v", err)
+				}
+
+				beta := r.Header.Get("anthropic-beta")
+				if enabled {
+					if beta != anthropicPromptCachingBeta {
+						t.Errorf("Expected anthropic-beta %q, got %q", anthropicPromptCachingBeta, beta)
+					}
+					blocks, ok := raw["system"].([]any)
+					if !ok || len(blocks) != 1 {
+						t.Fatalf("Expected the system prompt as one content block, got %v", raw["system"])
+					}
+					block := blocks[0].(map[string]any)
+					if block["text"] != "Suggest tags." {
+						t.Errorf("Expected system text, got %v", block["text"])
+					}
+					if cc, _ := block["cache_control"].(map[string]any); cc["type"] != "ephemeral" {
+						t.Errorf("Expected cache_control ephemeral, got %v", block["cache_control"])
+					}
+				} else {
+					if beta != "" {
+						t.Errorf("Expected no anthropic-beta header, got %q", beta)
+					}
+					if raw["system"] != "Suggest tags." {
+						t.Errorf("Expected the system prompt as a string, got %v", raw["system"])
+					}
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"model":"claude-3-5-haiku-20241022","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn",` +
+					`"usage":{"input_tokens":10,"output_tokens":5,"cache_creation_input_tokens":1200,"cache_read_input_tokens":300}}`))
+			}))
+			defer server.Close()
+
+			provider := NewAnthropicProvider(&ProviderConfig{
+				Type:                ProviderAnthropic,
+				APIKey:              "test-key",
+				BaseURL:             server.URL,
+				EnablePromptCaching: enabled,
+			})
+
+			resp, err := provider.Complete(context.Background(), &CompletionRequest{
+				Messages: []Message{
+					{Role: RoleSystem, Content: "Suggest tags."},
+					{Role: RoleUser, Content: "Hello"},
+				},
+			})
+			if err != nil {
+				t.Fatalf("Complete() error: %v", err)
+			}
+			if resp.Usage.CacheCreationTokens != 1200 || resp.Usage.CacheReadTokens != 300 {
+				t.Errorf("Expected cache tokens 1200/300, got %d/%d", resp.Usage.CacheCreationTokens, resp.Usage.CacheReadTokens)
+			}
+			if resp.Usage.PromptTokens != 10 || resp.Usage.TotalTokens != 15 {
+				t.Errorf("Expected prompt 10 and total 15, got %+v", resp.Usage)
+			}
+		})
+	}
+}
+
+func TestAnthropicProviderCheckHealth(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr error
+	}{
+		{"healthy", http.StatusOK, nil},
+		{"bad key", http.StatusUnauthorized, ErrInvalidAPIKey},
+		{"unavailable", http.StatusServiceUnavailable, ErrProviderUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/v1/messages" {
+					t.Errorf("Expected POST /v1/messages, got %s %s", r.Method, r.URL.Path)
+				}
+				if got := r.Header.Get("x-api-key"); got != "sk-ant-test" {
+					t.Errorf("Expected the API key to be sent, got %q", got)
+				}
+				var req anthropicMessagesRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Fatalf("Failed to decode request: %v", err)
+				}
+				if req.MaxTokens != 1 || req.Model != "claude-3-5-haiku-latest" {
+					t.Errorf("Expected a 1-token request to the default model, got %+v", req)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"model":"claude-3-5-haiku-latest","content":[{"type":"text","text":"p"}],"stop_reason":"max_tokens"}`))
+			}))
+			defer server.Close()
+
+			provider := NewAnthropicProvider(&ProviderConfig{
+				Type:         ProviderAnthropic,
+				APIKey:       "sk-ant-test",
+				BaseURL:      server.URL,
+				DefaultModel: "claude-3-5-haiku-latest",
+				RetryPolicy:  &RetryPolicy{MaxAttempts: 1},
+			})
+
+			err := provider.CheckHealth(context.Background())
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("CheckHealth() error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestAnthropicProviderCheckHealthNotConfigured(t *testing.T) {
+	provider := NewAnthropicProvider(&ProviderConfig{Type: ProviderAnthropic})
+	if err := provider.CheckHealth(context.Background()); !errors.Is(err, ErrProviderNotConfigured) {
+		t.Errorf("Expected ErrProviderNotConfigured, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"context"
+)
+
+// BalancingStrategy determines how calls are spread across multiple instances
+// of the active provider type.
+type BalancingStrategy string
+
+const (
+	// BalancingNone always uses the first configured instance (the default).
+	BalancingNone BalancingStrategy = ""
+
+	// BalancingRoundRobin cycles through configured instances in registration order.
+	BalancingRoundRobin BalancingStrategy = "round_robin"
+
+	// BalancingWeighted distributes calls proportionally to instance weights.
+	BalancingWeighted BalancingStrategy = "weighted"
+)
+
+// providerInstance is a registered provider together with its balancing state.
+type providerInstance struct {
+	id       string
+	provider Provider
+	weight   int
+
+	// currentWeight is the running weight used by smooth weighted round-robin.
+	currentWeight int
+}
+
+// primaryLocked returns the first registered instance of a provider type.
+// Callers must hold s.mu.
+func (s *service) primaryLocked(providerType ProviderType) Provider {
+	instances := s.providers[providerType]
+	if len(instances) == 0 {
+		return nil
+	}
+	return instances[0].provider
+}
+
+// primaryProvider returns the provider set by WithProviderOverride, else the
+// primary instance of the active provider type.
+func (s *service) primaryProvider(ctx context.Context) Provider {
+	if provider := providerOverride(ctx); provider != nil {
+		return provider
+	}
+	return s.GetProvider()
+}
+
+// pickProvider selects a configured instance of the active provider type
+// according to the balancing strategy, unless WithProviderOverride set one.
+// Returns nil if none is configured.
+func (s *service) pickProvider(ctx context.Context) Provider {
+	if provider := providerOverride(ctx); provider != nil {
+		return provider
+	}
+
+	s.mu.RLock()
+	activeType := s.activeProvider
+	instances := append([]*providerInstance(nil), s.providers[activeType]...)
+	s.mu.RUnlock()
+
+	// Only consider healthy (configured) instances
+	healthy := make([]*providerInstance, 0, len(instances))
+	for _, instance := range instances {
+		if instance.provider.IsConfigured(ctx) {
+			healthy = append(healthy, instance)
+		}
+	}
+	if len(healthy) == 0 {
+		return nil
+	}
+	if len(healthy) == 1 {
+		return healthy[0].provider
+	}
+
+	switch s.balancing {
+	case BalancingRoundRobin:
+		s.mu.Lock()
+		n := s.rrCounters[activeType]
+		s.rrCounters[activeType]++
+		s.mu.Unlock()
+		return healthy[n%uint64(len(healthy))].provider
+	case BalancingWeighted:
+		return s.pickWeighted(healthy)
+	default:
+		return healthy[0].provider
+	}
+}
+
+// pickWeighted implements smooth weighted round-robin: each call adds every
+// instance's weight to its running weight, picks the largest, and subtracts the
+// total from the winner. This spreads picks evenly rather than in bursts.
+func (s *service) pickWeighted(instances []*providerInstance) Provider {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	var best *providerInstance
+	for _, instance := range instances {
+		instance.currentWeight += instance.weight
+		total += instance.weight
+		if best == nil || instance.currentWeight > best.currentWeight {
+			best = instance
+		}
+	}
+	best.currentWeight -= total
+	return best.provider
+}
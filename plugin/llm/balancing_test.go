@@ -0,0 +1,109 @@
+package llm
+
+import (
+	"context"
+	"testing"
+)
+
+// newBalancingMock returns a configured mock OpenAI provider that reports the given model.
+func newBalancingMock(model string, configured bool) *mockProvider {
+	return &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   configured,
+		completeResp: &CompletionResponse{Content: "ok", Model: model},
+	}
+}
+
+// countCompletions performs n completions and counts responses per model.
+func countCompletions(t *testing.T, svc Service, n int) map[string]int {
+	t.Helper()
+
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		resp, err := svc.Complete(context.Background(), &CompletionRequest{
+			Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+		})
+		if err != nil {
+			t.Fatalf("Complete() error: %v", err)
+		}
+		counts[resp.Model]++
+	}
+	return counts
+}
+
+func TestBalancingWeighted(t *testing.T) {
+	svc := NewService(WithBalancingStrategy(BalancingWeighted))
+	if err := svc.RegisterProviderInstance("key-a", newBalancingMock("a", true), 3); err != nil {
+		t.Fatalf("RegisterProviderInstance() error: %v", err)
+	}
+	if err := svc.RegisterProviderInstance("key-b", newBalancingMock("b", true), 1); err != nil {
+		t.Fatalf("RegisterProviderInstance() error: %v", err)
+	}
+
+	counts := countCompletions(t, svc, 8)
+	if counts["a"] != 6 || counts["b"] != 2 {
+		t.Errorf("Expected 6/2 split for weights 3:1, got %v", counts)
+	}
+}
+
+func TestBalancingRoundRobin(t *testing.T) {
+	svc := NewService(WithBalancingStrategy(BalancingRoundRobin))
+	svc.RegisterProviderInstance("key-a", newBalancingMock("a", true), 5)
+	svc.RegisterProviderInstance("key-b", newBalancingMock("b", true), 1)
+
+	counts := countCompletions(t, svc, 6)
+	if counts["a"] != 3 || counts["b"] != 3 {
+		t.Errorf("Expected even split ignoring weights, got %v", counts)
+	}
+}
+
+func TestBalancingSkipsUnconfigured(t *testing.T) {
+	svc := NewService(WithBalancingStrategy(BalancingRoundRobin))
+	svc.RegisterProviderInstance("key-a", newBalancingMock("a", true), 1)
+	svc.RegisterProviderInstance("key-b", newBalancingMock("b", false), 1)
+
+	counts := countCompletions(t, svc, 4)
+	if counts["a"] != 4 {
+		t.Errorf("Expected all calls on configured instance, got %v", counts)
+	}
+}
+
+func TestBalancingNoneUsesPrimary(t *testing.T) {
+	svc := NewService()
+	svc.RegisterProviderInstance("key-b", newBalancingMock("b", true), 1)
+	svc.RegisterProvider(newBalancingMock("default", true))
+
+	counts := countCompletions(t, svc, 3)
+	if counts["default"] != 3 {
+		t.Errorf("Expected all calls on default instance, got %v", counts)
+	}
+
+	provider := svc.GetProvider()
+	if provider == nil {
+		t.Fatal("Expected active provider")
+	}
+	if len(svc.ListProviders()) != 2 {
+		t.Errorf("Expected 2 provider statuses, got %d", len(svc.ListProviders()))
+	}
+}
+
+func TestRegisterProviderInstanceReplaces(t *testing.T) {
+	svc := NewService(WithBalancingStrategy(BalancingRoundRobin))
+	svc.RegisterProviderInstance("key-a", newBalancingMock("a", true), 1)
+	svc.RegisterProviderInstance("key-a", newBalancingMock("a2", true), 1)
+
+	statuses := svc.ListProviders()
+	if len(statuses) != 1 || statuses[0].InstanceID != "key-a" {
+		t.Fatalf("Expected single key-a instance, got %+v", statuses)
+	}
+
+	counts := countCompletions(t, svc, 2)
+	if counts["a2"] != 2 {
+		t.Errorf("Expected replaced instance to serve calls, got %v", counts)
+	}
+
+	if err := svc.RegisterProviderInstance("", newBalancingMock("x", true), 1); err == nil {
+		t.Error("Expected error for empty instance id")
+	}
+}
@@ -4,10 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"math"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // BaseProvider provides common functionality for all providers.
@@ -18,10 +27,20 @@ type BaseProvider struct {
 
 	// HTTPClient is the HTTP client for API requests.
 	HTTPClient *http.Client
+
+	modelsCache  modelsCache
+	rawResponses rawResponseBuffer
 }
 
 // NewBaseProvider creates a new base provider with the given config.
 func NewBaseProvider(config *ProviderConfig) *BaseProvider {
+	if config.HTTPClient != nil {
+		return &BaseProvider{
+			Config:     config,
+			HTTPClient: config.HTTPClient,
+		}
+	}
+
 	timeout := time.Duration(config.Timeout) * time.Second
 	if timeout == 0 {
 		timeout = 30 * time.Second
@@ -35,130 +54,497 @@ func NewBaseProvider(config *ProviderConfig) *BaseProvider {
 	}
 }
 
-// DoRequest performs an HTTP request with common handling.
-func (b *BaseProvider) DoRequest(ctx context.Context, method, url string, body interface{}, headers map[string]string) ([]byte, error) {
-	var reqBody io.Reader
-	if body != nil {
-		jsonBody, err := json.Marshal(body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		reqBody = bytes.NewBuffer(jsonBody)
+// Close releases idle HTTP connections held by the provider's client (and its
+// transport, if it supports CloseIdleConnections). The provider remains usable;
+// later requests open new connections.
+func (b *BaseProvider) Close() error {
+	b.HTTPClient.CloseIdleConnections()
+	return nil
+}
+
+// maxRetryAfter caps how long a server-provided Retry-After hint can delay a retry.
+const maxRetryAfter = 60 * time.Second
+
+// RateLimitError is returned when a provider rate limits a request.
+// It wraps ErrRateLimited and carries the server's suggested retry delay, if any.
+type RateLimitError struct {
+	// RetryAfter is the suggested delay before retrying (0 if the server gave no hint).
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s (retry after %s)", ErrRateLimited.Error(), e.RetryAfter)
 	}
+	return ErrRateLimited.Error()
+}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// Unwrap allows errors.Is(err, ErrRateLimited).
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// requestTimeoutKey marks contexts whose deadline comes from CompletionRequest.TimeoutSeconds.
+type requestTimeoutKey struct{}
+
+// withRequestTimeout derives a context bounded by a request-level timeout.
+// The deadline replaces the HTTP client's default timeout for requests made
+// with the returned context. seconds <= 0 returns ctx unchanged.
+func withRequestTimeout(ctx context.Context, seconds int) (context.Context, context.CancelFunc) {
+	if seconds <= 0 {
+		return ctx, func() {}
 	}
+	ctx = context.WithValue(ctx, requestTimeoutKey{}, true)
+	return context.WithTimeout(ctx, time.Duration(seconds)*time.Second)
+}
 
-	// Set default headers
-	req.Header.Set("Content-Type", "application/json")
+// requestMetadataKey carries CompletionRequest.Metadata to DoRequest.
+type requestMetadataKey struct{}
 
-	// Set custom headers
-	for key, value := range headers {
+// withRequestMetadata attaches per-request metadata headers to ctx.
+func withRequestMetadata(ctx context.Context, metadata map[string]string) context.Context {
+	if len(metadata) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, requestMetadataKey{}, metadata)
+}
+
+// setExtraHeaders sets ProviderConfig.ExtraHeaders and any request metadata on req.
+// It must be called before the default and provider headers are set, so those win.
+func (b *BaseProvider) setExtraHeaders(ctx context.Context, req *http.Request) {
+	for key, value := range b.Config.ExtraHeaders {
 		req.Header.Set(key, value)
 	}
+	if metadata, ok := ctx.Value(requestMetadataKey{}).(map[string]string); ok {
+		for key, value := range metadata {
+			req.Header.Set(key, value)
+		}
+	}
+}
+
+// clientFor returns the HTTP client to use for ctx. When a request-level
+// timeout is set, the client's own timeout is dropped so the context deadline governs.
+func (b *BaseProvider) clientFor(ctx context.Context) *http.Client {
+	if ctx.Value(requestTimeoutKey{}) == nil || b.HTTPClient.Timeout == 0 {
+		return b.HTTPClient
+	}
+	client := *b.HTTPClient
+	client.Timeout = 0
+	return &client
+}
+
+// DoRequest performs an HTTP request with common handling.
+func (b *BaseProvider) DoRequest(ctx context.Context, method, url string, body interface{}, headers map[string]string) ([]byte, error) {
+	respBody, _, err := b.doRequest(ctx, method, url, body, headers)
+	return respBody, err
+}
+
+// doRequest is DoRequest that also returns the status code of a successful
+// response, for decodeJSON.
+func (b *BaseProvider) doRequest(ctx context.Context, method, url string, body interface{}, headers map[string]string) ([]byte, int, error) {
+	var jsonBody []byte
+	if body != nil {
+		var err error
+		jsonBody, err = json.Marshal(body)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
 
 	// Execute request with retries
 	var lastErr error
-	maxRetries := b.Config.MaxRetries
-	if maxRetries == 0 {
-		maxRetries = 3
-	}
+	var retryAfter time.Duration
+	policy := b.retryPolicy()
 
-	for attempt := 0; attempt <= maxRetries; attempt++ {
+	for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
 		if attempt > 0 {
-			// Exponential backoff
-			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
+			// Exponential backoff, unless the server told us how long to wait
+			backoff := policy.backoff(attempt)
+			if retryAfter > 0 {
+				backoff = retryAfter
+			}
+			// Don't sleep past the deadline; the last error is more useful than a timeout
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+				return nil, 0, lastErr
+			}
 			select {
 			case <-ctx.Done():
-				return nil, ctx.Err()
+				return nil, 0, ctx.Err()
 			case <-time.After(backoff):
 			}
 		}
+		retryAfter = 0
 
-		resp, err := b.HTTPClient.Do(req)
+		// The request is rebuilt on every attempt since the body is consumed by each send
+		var reqBody io.Reader
+		if jsonBody != nil {
+			reqBody = bytes.NewReader(jsonBody)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 		if err != nil {
+			return nil, 0, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		b.setExtraHeaders(ctx, req)
+
+		// Set default headers
+		req.Header.Set("Content-Type", "application/json")
+
+		// Set custom headers
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		start := time.Now()
+		resp, err := b.clientFor(ctx).Do(req)
+		if err != nil {
+			b.logRequest(ctx, req, jsonBody, 0, nil, time.Since(start), err)
 			lastErr = fmt.Errorf("request failed: %w", err)
+			if !policy.ShouldRetry(0, err) {
+				return nil, 0, lastErr
+			}
 			continue
 		}
 
-		respBody, err := io.ReadAll(resp.Body)
+		respBody, err := b.readBody(resp.Body)
 		resp.Body.Close()
+		b.logRequest(ctx, req, jsonBody, resp.StatusCode, respBody, time.Since(start), err)
 
+		if errors.Is(err, ErrResponseTooLarge) {
+			// Retrying would only download the same oversized body again
+			return nil, 0, err
+		}
 		if err != nil {
 			lastErr = fmt.Errorf("failed to read response: %w", err)
+			if !policy.ShouldRetry(0, err) {
+				return nil, 0, lastErr
+			}
 			continue
 		}
 
 		// Handle HTTP errors
 		if resp.StatusCode >= 400 {
-			lastErr = b.handleHTTPError(resp.StatusCode, respBody)
+			lastErr = b.handleHTTPError(resp.StatusCode, resp.Header, respBody)
 
-			// Don't retry on client errors (4xx) except rate limiting
-			if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != 429 {
-				return nil, lastErr
+			// By default client errors other than 429 are not retried
+			if !policy.ShouldRetry(resp.StatusCode, lastErr) {
+				return nil, 0, lastErr
+			}
+
+			if resp.StatusCode == 429 || resp.StatusCode == 503 {
+				retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
 			}
 
 			continue
 		}
 
-		return respBody, nil
+		return respBody, resp.StatusCode, nil
+	}
+
+	return nil, 0, lastErr
+}
+
+// maxBodySnippetBytes bounds the response body quoted in decodeJSON errors.
+const maxBodySnippetBytes = 200
+
+// decodeJSON unmarshals a response body into v. On failure the error names the
+// provider and includes the HTTP status and the start of the body, so that,
+// e.g., an HTML error page from a proxy is recognizable.
+func (b *BaseProvider) decodeJSON(body []byte, status int, v any) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		snippet := strings.TrimSpace(string(body))
+		if len(snippet) > maxBodySnippetBytes {
+			snippet = strings.ToValidUTF8(snippet[:maxBodySnippetBytes], "") + "..."
+		}
+		provider := string(b.Config.Type)
+		if provider == "" {
+			provider = "provider"
+		}
+		return fmt.Errorf("%s returned invalid JSON (status %d): %w; body: %q", provider, status, err, snippet)
+	}
+	return nil
+}
+
+// defaultMaxResponseBytes is the default ProviderConfig.MaxResponseBytes.
+const defaultMaxResponseBytes = 8 << 20
+
+// readBody reads r up to the configured MaxResponseBytes. A longer body fails
+// with ErrResponseTooLarge, returning the bytes read up to the limit.
+func (b *BaseProvider) readBody(r io.Reader) ([]byte, error) {
+	limit := b.Config.MaxResponseBytes
+	if limit <= 0 {
+		limit = defaultMaxResponseBytes
 	}
 
-	return nil, lastErr
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return body, err
+	}
+	if int64(len(body)) > limit {
+		return body[:limit], fmt.Errorf("%w: exceeds %d bytes", ErrResponseTooLarge, limit)
+	}
+	return body, nil
+}
+
+// parseRetryAfter parses a Retry-After header value in either delay-seconds
+// or HTTP-date form. Returns 0 if the value is missing or invalid, and caps
+// the result at maxRetryAfter.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = date.Sub(now)
+	}
+
+	if delay < 0 {
+		return 0
+	}
+	if delay > maxRetryAfter {
+		return maxRetryAfter
+	}
+	return delay
 }
 
-// handleHTTPError converts HTTP errors to appropriate LLM errors.
-func (b *BaseProvider) handleHTTPError(statusCode int, body []byte) error {
+// ProviderError describes a failed provider API call. It wraps one of the
+// package sentinels (e.g., ErrRateLimited) when the status maps to one, so
+// errors.Is keeps working while callers can still read the upstream details.
+type ProviderError struct {
+	// Provider is the provider that returned the error.
+	Provider ProviderType
+
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Message is the provider's error message (or the raw body if it had none).
+	Message string
+
+	// Retryable indicates whether retrying the request may succeed.
+	Retryable bool
+
+	// Err is the wrapped sentinel or typed error (nil if the status has no mapping).
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ProviderError) Error() string {
+	prefix := "API error"
+	if e.Err != nil {
+		prefix = e.Err.Error()
+	}
+	if e.Message == "" {
+		return fmt.Sprintf("%s (status %d)", prefix, e.StatusCode)
+	}
+	return fmt.Sprintf("%s (status %d): %s", prefix, e.StatusCode, e.Message)
+}
+
+// Unwrap allows errors.Is against the wrapped sentinel.
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// handleHTTPError converts HTTP errors to a *ProviderError wrapping the matching LLM error.
+func (b *BaseProvider) handleHTTPError(statusCode int, header http.Header, body []byte) error {
+	provErr := &ProviderError{
+		Provider:   b.Config.Type,
+		StatusCode: statusCode,
+		Message:    extractErrorMessage(body),
+	}
+
 	switch statusCode {
 	case 401:
-		return ErrInvalidAPIKey
+		provErr.Err = ErrInvalidAPIKey
 	case 429:
-		return ErrRateLimited
+		provErr.Err = &RateLimitError{
+			RetryAfter: parseRetryAfter(header.Get("Retry-After"), time.Now()),
+		}
+		provErr.Retryable = true
 	case 503, 502, 504:
-		return ErrProviderUnavailable
+		provErr.Err = ErrProviderUnavailable
+		provErr.Retryable = true
 	default:
-		// Try to extract error message from response body
-		var errResp struct {
-			Error struct {
-				Message string `json:"message"`
-				Type    string `json:"type"`
-				Code    string `json:"code"`
-			} `json:"error"`
-			Message string `json:"message"` // Some APIs use this directly
-		}
+		provErr.Retryable = statusCode >= 500
+	}
 
-		if err := json.Unmarshal(body, &errResp); err == nil {
-			msg := errResp.Error.Message
-			if msg == "" {
-				msg = errResp.Message
-			}
-			if msg != "" {
-				return fmt.Errorf("API error (status %d): %s", statusCode, msg)
-			}
+	return provErr
+}
+
+// extractErrorMessage returns the error message from a provider error body,
+// falling back to the raw body when it isn't a recognized JSON shape.
+func extractErrorMessage(body []byte) string {
+	var errResp struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"` // Some APIs use this directly
+	}
+
+	if err := json.Unmarshal(body, &errResp); err == nil {
+		// OpenAI/Anthropic nest the message in an object; Ollama uses a plain string
+		var nested struct {
+			Message string `json:"message"`
+		}
+		var plain string
+		switch {
+		case json.Unmarshal(errResp.Error, &nested) == nil && nested.Message != "":
+			return nested.Message
+		case json.Unmarshal(errResp.Error, &plain) == nil && plain != "":
+			return plain
+		case errResp.Message != "":
+			return errResp.Message
 		}
+	}
+
+	return strings.TrimSpace(string(body))
+}
+
+// DefaultCheckHealth provides a default health check that lists the provider's
+// models, which is a lightweight request for most APIs.
+func (b *BaseProvider) DefaultCheckHealth(ctx context.Context, provider Provider) error {
+	if !provider.IsConfigured(ctx) {
+		return ErrProviderNotConfigured
+	}
+
+	if _, err := provider.GetAvailableModels(ctx); err != nil {
+		return fmt.Errorf("%s health check failed: %w", provider.GetType(), err)
+	}
+
+	return nil
+}
+
+// healthCheckError wraps a failed health probe. Auth failures already wrap
+// ErrInvalidAPIKey; transport failures and server errors are wrapped with
+// ErrProviderUnavailable so callers can tell a bad key from an unreachable API.
+func healthCheckError(providerType ProviderType, err error) error {
+	var provErr *ProviderError
+	switch {
+	case errors.Is(err, ErrInvalidAPIKey), errors.Is(err, ErrProviderUnavailable),
+		errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+	case !errors.As(err, &provErr) || provErr.StatusCode >= 500:
+		return fmt.Errorf("%s health check failed: %w: %w", providerType, ErrProviderUnavailable, err)
+	}
+	return fmt.Errorf("%s health check failed: %w", providerType, err)
+}
+
+// DefaultValidateConfig provides a default configuration check that only
+// verifies the provider reports itself as configured.
+func (b *BaseProvider) DefaultValidateConfig(ctx context.Context, provider Provider) error {
+	if !provider.IsConfigured(ctx) {
+		return ErrProviderNotConfigured
+	}
+	return nil
+}
 
-		return fmt.Errorf("API error (status %d): %s", statusCode, string(body))
+// validateBaseURL checks that raw is an absolute http(s) URL.
+func validateBaseURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid base URL %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid base URL %q: scheme must be http or https", raw)
 	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid base URL %q: missing host", raw)
+	}
+	return nil
 }
 
 // DefaultSuggestTags provides a default implementation using chat completion.
 // Providers can override this with native implementations if available.
 func (b *BaseProvider) DefaultSuggestTags(ctx context.Context, provider Provider, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
-	maxTags := req.MaxTags
-	if maxTags == 0 {
-		maxTags = 5
+	// A provider-level template takes precedence over the one on the request
+	promptTemplate := req.PromptTemplate
+	if b.Config.TagPromptTemplate != "" {
+		promptTemplate = b.Config.TagPromptTemplate
+	}
+
+	systemPrompt, userPrompt, err := buildTagPrompts(req, promptTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	completionReq := &CompletionRequest{
+		Model: req.Model,
+		Messages: []Message{
+			{Role: RoleSystem, Content: systemPrompt},
+			{Role: RoleUser, Content: userPrompt},
+		},
+		Temperature: 0.3, // Lower temperature for more consistent results
+		MaxTokens:   100,
+	}
+
+	// JSON mode requires a top-level object, so wrap the array in {"tags": [...]}
+	if provider.Capabilities().JSONMode {
+		completionReq.Messages[0].Content += "\nWrap the array in an object: {\"tags\": [...]}."
+		completionReq.ResponseFormat = jsonResponseFormat(provider, req.Model, "tag_suggestions", tagSuggestionsSchema)
+	}
+
+	resp, err := provider.Complete(ctx, completionReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag suggestions: %w", err)
+	}
+
+	tags, confidence := parseTagSuggestions(resp.Content)
+
+	// Limit to maxTags
+	maxTags := tagLimit(req)
+	if len(tags) > maxTags {
+		tags = tags[:maxTags]
+	}
+	if len(confidence) > maxTags {
+		confidence = confidence[:maxTags]
+	}
+
+	var newTags []string
+	if req.ReuseMode {
+		tags, newTags = matchExistingTags(tags, req.ExistingTags)
+	}
+
+	return &SuggestTagsResponse{
+		Tags:       tags,
+		Confidence: confidence,
+		NewTags:    newTags,
+		Model:      resp.Model,
+		Usage:      resp.Usage,
+	}, nil
+}
+
+// tagLimit returns the request's MaxTags, defaulting to 5.
+func tagLimit(req *SuggestTagsRequest) int {
+	if req.MaxTags == 0 {
+		return 5
 	}
+	return req.MaxTags
+}
+
+// buildTagPrompts renders the system and user prompts for a tag suggestion request.
+// A non-empty promptTemplate replaces the built-in user prompt.
+func buildTagPrompts(req *SuggestTagsRequest, promptTemplate string) (system, user string, err error) {
+	maxTags := tagLimit(req)
 
 	systemPrompt := `You are a helpful assistant that suggests relevant tags for notes and memos.
 Analyze the content and suggest concise, relevant tags that capture the main topics.
-Return ONLY a JSON array of tag strings, nothing else. Example: ["project", "meeting", "todo"]
+Return ONLY a JSON array of objects with "tag" and "score" fields, nothing else.
+The score is your confidence (0.0-1.0) that the tag is relevant.
+Example: [{"tag": "project", "score": 0.9}, {"tag": "meeting", "score": 0.75}]
 Tags should be lowercase, single words or hyphenated phrases (e.g., "machine-learning").`
+	if req.Language != "" {
+		systemPrompt += fmt.Sprintf("\nReturn tags in language: %s.", req.Language)
+	}
 
 	existingTagsHint := ""
 	if len(req.ExistingTags) > 0 {
 		existingTagsHint = fmt.Sprintf("\nPrefer using these existing tags when relevant: %v", req.ExistingTags)
+		if req.ReuseMode {
+			systemPrompt += "\nPick tags from the existing tags, spelled exactly as given, whenever one fits. Only create a new tag when none of them fits."
+			existingTagsHint = fmt.Sprintf("\nExisting tags to choose from first: %v", req.ExistingTags)
+		}
 	}
 
 	userPrompt := fmt.Sprintf(`Suggest up to %d tags for this content:%s
@@ -166,42 +552,269 @@ Tags should be lowercase, single words or hyphenated phrases (e.g., "machine-lea
 Content:
 %s`, maxTags, existingTagsHint, req.Content)
 
+	if promptTemplate != "" {
+		rendered, err := renderTagPrompt(promptTemplate, TagPromptData{
+			Content:      req.Content,
+			MaxTags:      maxTags,
+			ExistingTags: req.ExistingTags,
+			Language:     req.Language,
+		})
+		if err != nil {
+			return "", "", err
+		}
+		userPrompt = rendered
+	}
+
+	return systemPrompt, userPrompt, nil
+}
+
+// scoredTag is a tag suggestion with a confidence score as returned by the model.
+type scoredTag struct {
+	Tag   string  `json:"tag"`
+	Score float64 `json:"score"`
+}
+
+// tagSuggestionsSchema is the JSON schema for tag suggestions in JSON mode.
+var tagSuggestionsSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"tags": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"tag":   map[string]any{"type": "string"},
+					"score": map[string]any{"type": "number"},
+				},
+				"required":             []string{"tag", "score"},
+				"additionalProperties": false,
+			},
+		},
+	},
+	"required":             []string{"tags"},
+	"additionalProperties": false,
+}
+
+// parseTagSuggestions parses a model response into tags and confidence scores.
+// It accepts a JSON array of {"tag", "score"} objects or of strings, found
+// anywhere in the response (see parseTagArray), or free-form text.
+// Confidence is nil unless the model returned scores.
+func parseTagSuggestions(content string) ([]string, []float64) {
+	tags, confidence, complete := parseScoredTagArray(content)
+	if complete || len(tags) > 0 {
+		return tags, confidence
+	}
+
+	// Try to extract tags from non-JSON response
+	return extractTagsFromText(content), nil
+}
+
+// clampScore clamps a confidence score to the range [0, 1].
+func clampScore(score float64) float64 {
+	if score < 0 {
+		return 0
+	}
+	if score > 1 {
+		return 1
+	}
+	return score
+}
+
+// DefaultSummarize provides a default implementation using chat completion.
+// Content too long for the model's context window is summarized with map-reduce
+// chunking (see summarizeChunked).
+func (b *BaseProvider) DefaultSummarize(ctx context.Context, provider Provider, req *SummarizeRequest) (*SummarizeResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = provider.GetDefaultModel()
+	}
+	if limit := summarizeInputLimit(model); estimateTokens(req.Content) > limit {
+		return b.summarizeChunked(ctx, provider, req, limit)
+	}
+
+	return b.summarizeOnce(ctx, provider, req)
+}
+
+// structuredOutputSupporter is implemented by providers that know whether a
+// model accepts a strict json_schema response format.
+type structuredOutputSupporter interface {
+	supportsStructuredOutputs(model string) bool
+}
+
+// jsonResponseFormat returns the response format for a schema-shaped JSON reply.
+// It defaults to json_object, which every JSON-mode provider accepts, and only
+// requests json_schema when the provider reports the model supports it. The
+// schema is attached either way for providers that use it with json_object.
+func jsonResponseFormat(provider Provider, model, name string, schema map[string]any) *ResponseFormat {
+	format := &ResponseFormat{
+		Type:   ResponseFormatJSONObject,
+		Name:   name,
+		Schema: schema,
+	}
+	if s, ok := provider.(structuredOutputSupporter); ok && s.supportsStructuredOutputs(model) {
+		format.Type = ResponseFormatJSONSchema
+	}
+	return format
+}
+
+// summarizeOnce summarizes the content with a single completion.
+func (b *BaseProvider) summarizeOnce(ctx context.Context, provider Provider, req *SummarizeRequest) (*SummarizeResponse, error) {
+	systemPrompt, userPrompt := buildSummarizePrompts(req)
+
 	completionReq := &CompletionRequest{
+		Model: req.Model,
 		Messages: []Message{
 			{Role: RoleSystem, Content: systemPrompt},
 			{Role: RoleUser, Content: userPrompt},
 		},
-		Temperature: 0.3, // Lower temperature for more consistent results
-		MaxTokens:   100,
+		Temperature: 0.5,
+		MaxTokens:   b.summaryMaxTokens(req),
+	}
+	if req.ExtractKeyPoints {
+		if provider.Capabilities().JSONMode {
+			completionReq.ResponseFormat = jsonResponseFormat(provider, req.Model, "summary_with_key_points", summaryKeyPointsSchema)
+		}
 	}
 
 	resp, err := provider.Complete(ctx, completionReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tag suggestions: %w", err)
+		return nil, fmt.Errorf("failed to generate summary: %w", err)
 	}
 
-	// Parse the JSON response
-	var tags []string
-	if err := json.Unmarshal([]byte(resp.Content), &tags); err != nil {
-		// Try to extract tags from non-JSON response
-		tags = extractTagsFromText(resp.Content)
+	if req.ExtractKeyPoints {
+		summary, keyPoints := parseSummaryWithKeyPoints(resp.Content)
+		return &SummarizeResponse{
+			Summary:   summary,
+			KeyPoints: keyPoints,
+			Model:     resp.Model,
+			Usage:     resp.Usage,
+		}, nil
 	}
 
-	// Limit to maxTags
-	if len(tags) > maxTags {
-		tags = tags[:maxTags]
+	return &SummarizeResponse{
+		Summary: resp.Content,
+		Model:   resp.Model,
+		Usage:   resp.Usage,
+	}, nil
+}
+
+// Provider temperature ranges. TopP is always limited to [0, 1].
+const (
+	maxTemperatureOpenAI    = 2.0
+	maxTemperatureAnthropic = 1.0
+	maxTemperatureOllama    = 2.0
+	maxTemperatureCohere    = 1.0
+	maxTemperatureGemini    = 2.0
+)
+
+// checkSampling limits req.Temperature to [0, maxTemperature] and req.TopP to
+// [0, 1]. Out-of-range values are clamped with a debug log, or rejected with
+// ErrInvalidSampling if the provider is configured with StrictSampling. The
+// caller's request is never modified; a copy is returned when clamping.
+func (b *BaseProvider) checkSampling(req *CompletionRequest, maxTemperature float64) (*CompletionRequest, error) {
+	temperature := math.Min(math.Max(req.Temperature, 0), maxTemperature)
+	topP := math.Min(math.Max(req.TopP, 0), 1)
+	if temperature == req.Temperature && topP == req.TopP {
+		return req, nil
 	}
 
-	return &SuggestTagsResponse{
-		Tags: tags,
+	if b.Config.StrictSampling {
+		return nil, fmt.Errorf("%w: temperature %v (allowed 0-%v), top_p %v (allowed 0-1)",
+			ErrInvalidSampling, req.Temperature, maxTemperature, req.TopP)
+	}
+
+	logger := b.Config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Debug("Clamping sampling parameters",
+		slog.String("provider", string(b.Config.Type)),
+		slog.Float64("temperature", req.Temperature),
+		slog.Float64("clamped_temperature", temperature),
+		slog.Float64("top_p", req.TopP),
+		slog.Float64("clamped_top_p", topP))
+
+	out := *req
+	out.Temperature = temperature
+	out.TopP = topP
+	return &out, nil
+}
+
+// defaultTitleMaxLength is the title length used when SuggestTitleRequest.MaxLength is unset.
+const defaultTitleMaxLength = 60
+
+// DefaultSuggestTitle provides a default implementation using chat completion.
+// The model's reply is reduced to a single unquoted line of at most MaxLength characters.
+func (b *BaseProvider) DefaultSuggestTitle(ctx context.Context, provider Provider, req *SuggestTitleRequest) (*SuggestTitleResponse, error) {
+	maxLength := req.MaxLength
+	if maxLength <= 0 {
+		maxLength = defaultTitleMaxLength
+	}
+
+	systemPrompt := fmt.Sprintf(`You are a helpful assistant that writes titles for notes.
+Return a concise title under %d characters that captures the main topic.
+Return ONLY the title on a single line, with no quotes, prefix, or trailing punctuation.`, maxLength)
+	if req.Language != "" {
+		systemPrompt += fmt.Sprintf("\nWrite the title in language: %s.", req.Language)
+	}
+
+	resp, err := provider.Complete(ctx, &CompletionRequest{
+		Model: req.Model,
+		Messages: []Message{
+			{Role: RoleSystem, Content: systemPrompt},
+			{Role: RoleUser, Content: fmt.Sprintf("Write a title for this content:\n\n%s", req.Content)},
+		},
+		Temperature: 0.2,
+		MaxTokens:   maxLength/charsPerToken*2 + 16,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate title: %w", err)
+	}
+
+	return &SuggestTitleResponse{
+		Title: cleanTitle(resp.Content, maxLength),
+		Model: resp.Model,
+		Usage: resp.Usage,
 	}, nil
 }
 
-// DefaultSummarize provides a default implementation using chat completion.
-func (b *BaseProvider) DefaultSummarize(ctx context.Context, provider Provider, req *SummarizeRequest) (*SummarizeResponse, error) {
+// titleQuotes are stripped from both ends of a generated title.
+const titleQuotes = "\"'`“”‘’«»*#"
+
+// cleanTitle reduces a model reply to a title: the first non-empty line without
+// a "Title:" prefix, surrounding quotes, or a trailing period, cut to maxLength
+// characters at a word boundary where possible.
+func cleanTitle(raw string, maxLength int) string {
+	var title string
+	for _, line := range strings.Split(raw, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			title = line
+			break
+		}
+	}
+
+	if len(title) >= 6 && strings.EqualFold(title[:6], "title:") {
+		title = title[6:]
+	}
+	title = strings.Trim(strings.TrimSpace(title), titleQuotes)
+	title = strings.Join(strings.Fields(title), " ")
+	title = strings.TrimRight(title, ".")
+
+	if runes := []rune(title); len(runes) > maxLength {
+		title = string(runes[:maxLength])
+		if i := strings.LastIndex(title, " "); i > 0 {
+			title = title[:i]
+		}
+		title = strings.TrimRight(title, " ,;:-")
+	}
+	return title
+}
+
+// buildSummarizePrompts renders the system and user prompts for a summarization request.
+func buildSummarizePrompts(req *SummarizeRequest) (system, user string) {
 	maxLength := req.MaxLength
 	if maxLength == 0 {
-		maxLength = 200
+		maxLength = defaultSummaryMaxLength
 	}
 
 	style := req.Style
@@ -213,86 +826,191 @@ func (b *BaseProvider) DefaultSummarize(ctx context.Context, provider Provider,
 Create a %s summary that captures the main points.
 Keep the summary under %d characters.
 Be concise and informative.`, style, maxLength)
+	if style == "bullet" {
+		systemPrompt += "\nFormat the summary as a bulleted list with one point per line, each starting with \"- \"."
+	}
+	if req.Language != "" {
+		systemPrompt += fmt.Sprintf("\nWrite the summary in language: %s.", req.Language)
+	}
+
+	if req.ExtractKeyPoints {
+		systemPrompt += `
+Also extract the key points as short standalone sentences.
+Return ONLY a JSON object with "summary" and "key_points" fields, nothing else.
+Example: {"summary": "The team agreed on the Q3 roadmap.", "key_points": ["Launch moved to August", "Hiring two engineers"]}`
+	}
 
 	userPrompt := fmt.Sprintf("Summarize this content:\n\n%s", req.Content)
 
-	completionReq := &CompletionRequest{
-		Messages: []Message{
-			{Role: RoleSystem, Content: systemPrompt},
-			{Role: RoleUser, Content: userPrompt},
-		},
-		Temperature: 0.5,
-		MaxTokens:   300,
+	return systemPrompt, userPrompt
+}
+
+// Summary token budget parameters. A summary of n characters needs about
+// n/charsPerToken tokens; the margin absorbs tokenizer variance and list markup.
+const (
+	defaultSummaryMaxLength     = 200
+	summaryTokenMargin          = 1.5
+	summaryBulletTokenFactor    = 1.2
+	summaryMinTokens            = 64
+	summaryKeyPointsExtraTokens = 200
+)
+
+// DefaultSummaryMaxTokens returns the completion token budget for a summary of
+// at most maxLength characters in the given style.
+func DefaultSummaryMaxTokens(maxLength int, style string) int {
+	tokens := float64(maxLength) / charsPerToken * summaryTokenMargin
+	if style == "bullet" {
+		tokens *= summaryBulletTokenFactor
 	}
+	return int(tokens) + summaryMinTokens
+}
 
-	resp, err := provider.Complete(ctx, completionReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate summary: %w", err)
+// summaryMaxTokens returns the completion token budget for a summarize request,
+// using ProviderConfig.SummaryMaxTokens when set.
+func (b *BaseProvider) summaryMaxTokens(req *SummarizeRequest) int {
+	maxLength := req.MaxLength
+	if maxLength == 0 {
+		maxLength = defaultSummaryMaxLength
+	}
+	style := req.Style
+	if style == "" {
+		style = "brief"
 	}
 
-	return &SummarizeResponse{
-		Summary: resp.Content,
-	}, nil
+	budget := DefaultSummaryMaxTokens
+	if b.Config.SummaryMaxTokens != nil {
+		budget = b.Config.SummaryMaxTokens
+	}
+
+	tokens := budget(maxLength, style)
+	if req.ExtractKeyPoints {
+		tokens += summaryKeyPointsExtraTokens
+	}
+	return tokens
 }
 
-// extractTagsFromText attempts to extract tags from a non-JSON response.
-func extractTagsFromText(text string) []string {
-	// Simple extraction: split by common delimiters
-	var tags []string
-	// Remove common prefixes/suffixes
-	text = bytes.NewBufferString(text).String()
-
-	// Try to find tags in various formats
-	// This is a basic implementation; providers should return proper JSON
-	delimiters := []string{",", "\n", ";", " "}
-	for _, delim := range delimiters {
-		if len(tags) == 0 {
-			for _, part := range splitAndTrim(text, delim) {
-				if isValidTag(part) {
-					tags = append(tags, part)
-				}
+// summaryKeyPointsSchema is the JSON schema for a summary with key points in JSON mode.
+var summaryKeyPointsSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"summary": map[string]any{"type": "string"},
+		"key_points": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		},
+	},
+	"required":             []string{"summary", "key_points"},
+	"additionalProperties": false,
+}
+
+// parseSummaryWithKeyPoints parses a {"summary", "key_points"} JSON response.
+// If the model didn't return JSON, bulleted or numbered lines become key points
+// and the remaining text becomes the summary.
+func parseSummaryWithKeyPoints(content string) (string, []string) {
+	var structured struct {
+		Summary   string   `json:"summary"`
+		KeyPoints []string `json:"key_points"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &structured); err == nil && structured.Summary != "" {
+		return structured.Summary, structured.KeyPoints
+	}
+
+	var summary []string
+	var keyPoints []string
+	for _, line := range strings.Split(content, "\n") {
+		if listMarkerPattern.MatchString(line) {
+			if point := strings.TrimSpace(listMarkerPattern.ReplaceAllString(line, "")); point != "" {
+				keyPoints = append(keyPoints, point)
 			}
+			continue
+		}
+		// Skip blank lines and labels such as "Key points:"
+		if line = strings.TrimSpace(line); line != "" && !strings.HasSuffix(line, ":") {
+			summary = append(summary, line)
 		}
 	}
 
-	return tags
+	return strings.Join(summary, " "), keyPoints
 }
 
-// splitAndTrim splits a string and trims each part.
-func splitAndTrim(s string, sep string) []string {
-	var result []string
-	for i, j := 0, 0; i < len(s); i = j + len(sep) {
-		j = i
-		for j < len(s) {
-			found := true
-			for k := 0; k < len(sep) && j+k < len(s); k++ {
-				if s[j+k] != sep[k] {
-					found = false
-					break
-				}
-			}
-			if found {
-				break
-			}
-			j++
+var (
+	// hashtagPattern matches "#tag" tokens that don't follow a word character (e.g., not "C#")
+	hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&])#([\p{L}\p{N}\p{M}_-]+)`)
+
+	// listMarkerPattern matches bullet and numbered list prefixes such as "-", "*", "1." and "2)"
+	listMarkerPattern = regexp.MustCompile(`^\s*(?:[-*+•]|\d+[.)])\s+`)
+)
+
+// extractTagsFromText attempts to extract tags from a non-JSON response.
+// It recognizes, in order of preference: hashtags anywhere in the text,
+// delimited or listed tags (with "Tags:" style framing and list markers
+// removed), and finally whitespace-separated words. Every candidate must
+// pass isValidTag.
+func extractTagsFromText(text string) []string {
+	// Hashtags are the strongest signal; when present, ignore the surrounding prose
+	if matches := hashtagPattern.FindAllStringSubmatch(text, -1); len(matches) > 0 {
+		candidates := make([]string, len(matches))
+		for i, m := range matches {
+			candidates[i] = m[1]
 		}
-		if j == len(s) {
-			j = len(s)
+		if tags := filterTags(candidates); len(tags) > 0 {
+			return tags
 		}
-		part := s[i:j]
-		// Trim whitespace and common characters
-		trimmed := trimTag(part)
-		if trimmed != "" {
-			result = append(result, trimmed)
+	}
+
+	var candidates []string
+	for _, line := range strings.Split(text, "\n") {
+		line = listMarkerPattern.ReplaceAllString(line, "")
+		// Drop "key: value" framing (including the fullwidth colon); a label-only line yields nothing
+		if i := strings.IndexAny(line, ":："); i >= 0 {
+			_, size := utf8.DecodeRuneInString(line[i:])
+			line = line[i+size:]
 		}
+		for _, part := range strings.FieldsFunc(line, isTagSeparator) {
+			candidates = append(candidates, trimTag(part))
+		}
+	}
+	if tags := filterTags(candidates); len(tags) > 0 {
+		return tags
 	}
-	return result
+
+	// Last resort: space-separated tags
+	candidates = candidates[:0]
+	for _, word := range strings.Fields(text) {
+		candidates = append(candidates, trimTag(word))
+	}
+	return filterTags(candidates)
+}
+
+// isTagSeparator reports whether r separates tags in a list, including the
+// fullwidth and ideographic commas used in CJK text.
+func isTagSeparator(r rune) bool {
+	switch r {
+	case ',', ';', '，', '、', '；':
+		return true
+	}
+	return false
+}
+
+// filterTags keeps valid tags, dropping case-insensitive duplicates while preserving order.
+func filterTags(candidates []string) []string {
+	var tags []string
+	seen := make(map[string]bool, len(candidates))
+	for _, tag := range candidates {
+		key := strings.ToLower(tag)
+		if !isValidTag(tag) || seen[key] {
+			continue
+		}
+		seen[key] = true
+		tags = append(tags, tag)
+	}
+	return tags
 }
 
 // trimTag cleans up a potential tag string.
 func trimTag(s string) string {
 	// Remove leading/trailing whitespace and common characters
-	chars := " \t\n\r\"'[]{}#-"
+	chars := " \t\n\r\"'[]{}#-.*`"
 	start := 0
 	end := len(s)
 
@@ -316,21 +1034,22 @@ func containsChar(s string, c byte) bool {
 	return false
 }
 
-// isValidTag checks if a string is a valid tag.
+// isValidTag checks if a string is a valid tag. Letters and digits from any
+// script are accepted, so tags such as "日本語" or "заметки" are valid.
 func isValidTag(s string) bool {
-	if len(s) == 0 || len(s) > 50 {
+	if len(s) == 0 || utf8.RuneCountInString(s) > 50 {
 		return false
 	}
 
 	// Must contain at least one letter
 	hasLetter := false
 	for _, c := range s {
-		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		switch {
+		case unicode.IsLetter(c):
 			hasLetter = true
-		}
-		// Allow letters, numbers, hyphens, and underscores
-		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
-			(c >= '0' && c <= '9') || c == '-' || c == '_') {
+		// Allow numbers, combining marks (e.g., Devanagari vowel signs), hyphens, and underscores
+		case unicode.IsDigit(c), unicode.IsMark(c), c == '-', c == '_':
+		default:
 			return false
 		}
 	}
@@ -1,7 +1,14 @@
 package llm
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewBaseProvider(t *testing.T) {
@@ -36,6 +43,51 @@ func TestNewBaseProviderDefaultTimeout(t *testing.T) {
 	}
 }
 
+// recordingTransport records requests and returns a canned response.
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.requests = append(rt.requests, req)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(`{"ok":true}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestNewBaseProviderCustomHTTPClient(t *testing.T) {
+	transport := &recordingTransport{}
+	client := &http.Client{Transport: transport}
+
+	base := NewBaseProvider(&ProviderConfig{
+		Type:       ProviderOpenAI,
+		Timeout:    60,
+		HTTPClient: client,
+	})
+
+	if base.HTTPClient != client {
+		t.Fatal("Expected custom HTTPClient to be used")
+	}
+
+	body, err := base.DoRequest(context.Background(), http.MethodPost, "http://llm.invalid/v1/chat", map[string]string{"q": "hi"}, nil)
+	if err != nil {
+		t.Fatalf("DoRequest() error: %v", err)
+	}
+	if string(body) != `{"ok":true}` {
+		t.Errorf("Unexpected body: %s", body)
+	}
+
+	if len(transport.requests) != 1 {
+		t.Fatalf("Expected 1 request through custom transport, got %d", len(transport.requests))
+	}
+	if got := transport.requests[0].URL.String(); got != "http://llm.invalid/v1/chat" {
+		t.Errorf("Expected request URL http://llm.invalid/v1/chat, got %s", got)
+	}
+}
+
 func TestIsValidTag(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -54,6 +106,16 @@ func TestIsValidTag(t *testing.T) {
 		{"a", true},    // Single letter is valid
 		{"A", true},    // Uppercase single letter
 		{"test-tag-1", true},
+		{"日本語", true},
+		{"编程", true},
+		{"프로그래밍", true},
+		{"заметки", true},
+		{"हिन्दी", true}, // Combining vowel signs
+		{"数据-分析", true},
+		{"タグ！", false},
+		{"２０２４", false},                 // Fullwidth digits only
+		{strings.Repeat("字", 50), true}, // Length counts characters, not bytes
+		{strings.Repeat("字", 51), false},
 	}
 
 	for _, tt := range tests {
@@ -111,22 +173,72 @@ func TestContainsChar(t *testing.T) {
 
 func TestExtractTagsFromText(t *testing.T) {
 	tests := []struct {
+		name     string
 		input    string
 		expected []string
 	}{
-		{`["meeting", "project"]`, []string{}}, // JSON-like but not valid JSON parsing in this function
-		{"meeting, project, todo", []string{"meeting", "project", "todo"}},
-		{"meeting\nproject\ntodo", []string{"meeting", "project", "todo"}},
-		{"meeting; project; todo", []string{"meeting", "project", "todo"}},
-		{"", []string{}},
+		{"comma separated", "meeting, project, todo", []string{"meeting", "project", "todo"}},
+		{"newline separated", "meeting\nproject\ntodo", []string{"meeting", "project", "todo"}},
+		{"semicolon separated", "meeting; project; todo", []string{"meeting", "project", "todo"}},
+		{"JSON-like array", `["meeting", "project"]`, []string{"meeting", "project"}},
+		{"empty", "", nil},
+		{"hashtags after preamble", "Here are your tags: #meeting #project", []string{"meeting", "project"}},
+		{"hashtags in prose", "I'd suggest #golang and #api-design for this note. C# is not relevant.", []string{"golang", "api-design"}},
+		{"numbered list", "Suggested tags:\n1. meeting\n2. project\n3) planning", []string{"meeting", "project", "planning"}},
+		{"bulleted list with markdown", "Tags:\n- **meeting**\n* `project`\n• roadmap.", []string{"meeting", "project", "roadmap"}},
+		{"key value framing", "Tags: meeting, budget, q3-planning", []string{"meeting", "budget", "q3-planning"}},
+		{"list with trailing prose", "Sure!\n\n- travel\n- japan\n\nLet me know if you need more.", []string{"travel", "japan"}},
+		{"duplicates", "#Meeting #meeting #project", []string{"Meeting", "project"}},
+		{"space separated", "meeting project todo", []string{"meeting", "project", "todo"}},
+		{"numbers only", "1, 2, 3", nil},
 	}
 
 	for _, tt := range tests {
-		result := extractTagsFromText(tt.input)
-		if len(result) != len(tt.expected) {
-			// Allow some flexibility since extraction is heuristic
-			continue
-		}
+		t.Run(tt.name, func(t *testing.T) {
+			result := extractTagsFromText(tt.input)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("extractTagsFromText(%q): expected %v, got %v", tt.input, tt.expected, result)
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("extractTagsFromText(%q)[%d]: expected %q, got %q", tt.input, i, tt.expected[i], result[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExtractTagsFromText_NonLatin(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"chinese fullwidth framing", "标签：编程，学习、笔记", []string{"编程", "学习", "笔记"}},
+		{"japanese hashtags", "おすすめ: #日本語 #プログラミング", []string{"日本語", "プログラミング"}},
+		{"korean list", "태그:\n- 프로그래밍\n- 여행", []string{"프로그래밍", "여행"}},
+		{"russian key value", "Теги: заметки, путешествия", []string{"заметки", "путешествия"}},
+		{"mixed scripts", "#golang #并发", []string{"golang", "并发"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := extractTagsFromText(tt.input)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("extractTagsFromText(%q): expected %v, got %v", tt.input, tt.expected, result)
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("extractTagsFromText(%q)[%d]: expected %q, got %q", tt.input, i, tt.expected[i], result[i])
+				}
+			}
+		})
+	}
+
+	// JSON and free-form responses go through the same validator
+	tags, _ := parseTagSuggestions(`["编程", "заметки", "not valid!"]`)
+	if len(tags) != 2 || tags[0] != "编程" || tags[1] != "заметки" {
+		t.Errorf("Expected non-Latin JSON tags to be kept and invalid ones dropped, got %v", tags)
 	}
 }
 
@@ -146,8 +258,8 @@ func TestHandleHTTPError(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		err := base.handleHTTPError(tt.statusCode, tt.body)
-		if err != tt.expectedError {
+		err := base.handleHTTPError(tt.statusCode, nil, tt.body)
+		if !errors.Is(err, tt.expectedError) {
 			t.Errorf("handleHTTPError(%d): expected %v, got %v", tt.statusCode, tt.expectedError, err)
 		}
 	}
@@ -158,7 +270,7 @@ func TestHandleHTTPErrorWithJSONBody(t *testing.T) {
 
 	// Test with JSON error response
 	jsonBody := []byte(`{"error":{"message":"Invalid API key","type":"authentication_error"}}`)
-	err := base.handleHTTPError(400, jsonBody)
+	err := base.handleHTTPError(400, nil, jsonBody)
 
 	if err == nil {
 		t.Error("Expected error, got nil")
@@ -171,29 +283,757 @@ func TestHandleHTTPErrorWithJSONBody(t *testing.T) {
 	}
 }
 
-func TestSplitAndTrim(t *testing.T) {
+func TestDefaultSuggestTags_ScoredJSON(t *testing.T) {
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{
+			Content: `[{"tag": "golang", "score": 0.95}, {"tag": "testing", "score": 0.7}, {"tag": "ci", "score": 1.4}]`,
+		},
+	}
+
+	base := NewBaseProvider(&ProviderConfig{})
+	resp, err := base.DefaultSuggestTags(context.Background(), provider, &SuggestTagsRequest{
+		Content: "Writing Go tests",
+		MaxTags: 5,
+	})
+	if err != nil {
+		t.Fatalf("DefaultSuggestTags() error: %v", err)
+	}
+
+	expectedTags := []string{"golang", "testing", "ci"}
+	expectedConfidence := []float64{0.95, 0.7, 1.0} // Out-of-range scores are clamped
+	if len(resp.Tags) != len(expectedTags) || len(resp.Confidence) != len(expectedConfidence) {
+		t.Fatalf("Expected %d tags and scores, got %v / %v", len(expectedTags), resp.Tags, resp.Confidence)
+	}
+	for i := range expectedTags {
+		if resp.Tags[i] != expectedTags[i] {
+			t.Errorf("Tag %d: expected %s, got %s", i, expectedTags[i], resp.Tags[i])
+		}
+		if resp.Confidence[i] != expectedConfidence[i] {
+			t.Errorf("Confidence %d: expected %f, got %f", i, expectedConfidence[i], resp.Confidence[i])
+		}
+	}
+}
+
+func TestDefaultSuggestTags_PlainJSON(t *testing.T) {
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{
+			Content: `["meeting", "project", "notes"]`,
+		},
+	}
+
+	base := NewBaseProvider(&ProviderConfig{})
+	resp, err := base.DefaultSuggestTags(context.Background(), provider, &SuggestTagsRequest{
+		Content: "Meeting notes",
+		MaxTags: 2,
+	})
+	if err != nil {
+		t.Fatalf("DefaultSuggestTags() error: %v", err)
+	}
+
+	if len(resp.Tags) != 2 {
+		t.Errorf("Expected 2 tags (limited by MaxTags), got %v", resp.Tags)
+	}
+	if resp.Confidence != nil {
+		t.Errorf("Expected no confidence for plain tags, got %v", resp.Confidence)
+	}
+}
+
+func TestDefaultSuggestTags_NoJSONModeByDefault(t *testing.T) {
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{Content: `["meeting"]`},
+	}
+
+	base := NewBaseProvider(&ProviderConfig{})
+	if _, err := base.DefaultSuggestTags(context.Background(), provider, &SuggestTagsRequest{Content: "Meeting notes"}); err != nil {
+		t.Fatalf("DefaultSuggestTags() error: %v", err)
+	}
+
+	if provider.lastCompleteReq.ResponseFormat != nil {
+		t.Errorf("Expected no response format for provider without JSON mode, got %+v", provider.lastCompleteReq.ResponseFormat)
+	}
+}
+
+func TestDefaultSuggestTags_ProviderPromptTemplate(t *testing.T) {
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{Content: `["diagnosis"]`},
+	}
+
+	config := &ProviderConfig{TagPromptTemplate: "Medical tags for: {{.Content}}"}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	base := NewBaseProvider(config)
+	if _, err := base.DefaultSuggestTags(context.Background(), provider, &SuggestTagsRequest{
+		Content:        "Patient notes",
+		PromptTemplate: "Request tags for: {{.Content}}",
+	}); err != nil {
+		t.Fatalf("DefaultSuggestTags() error: %v", err)
+	}
+
+	// The provider-level template overrides the request template
+	if got := provider.lastCompleteReq.Messages[1].Content; got != "Medical tags for: Patient notes" {
+		t.Errorf("Expected provider template in user prompt, got %q", got)
+	}
+
+	if err := (&ProviderConfig{TagPromptTemplate: "{{.Missing}}"}).Validate(); err == nil {
+		t.Error("Expected Validate() to reject a template with unknown fields")
+	}
+}
+
+func TestParseTagSuggestions_Wrapped(t *testing.T) {
+	tags, confidence := parseTagSuggestions(`{"tags": [{"tag": "golang", "score": 0.8}, {"tag": "testing", "score": 0.6}]}`)
+
+	if len(tags) != 2 || tags[0] != "golang" || tags[1] != "testing" {
+		t.Errorf("Expected [golang testing], got %v", tags)
+	}
+	if len(confidence) != 2 || confidence[0] != 0.8 {
+		t.Errorf("Expected confidence [0.8 0.6], got %v", confidence)
+	}
+}
+
+func TestDefaultSuggestTags_Language(t *testing.T) {
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{Content: `["会议"]`},
+	}
+
+	base := NewBaseProvider(&ProviderConfig{})
+	if _, err := base.DefaultSuggestTags(context.Background(), provider, &SuggestTagsRequest{
+		Content:  "会议记录",
+		Language: "zh",
+	}); err != nil {
+		t.Fatalf("DefaultSuggestTags() error: %v", err)
+	}
+
+	systemPrompt := provider.lastCompleteReq.Messages[0].Content
+	if !strings.Contains(systemPrompt, "Return tags in language: zh") {
+		t.Errorf("Expected language directive in system prompt, got: %s", systemPrompt)
+	}
+
+	// No directive when language is empty
+	if _, err := base.DefaultSuggestTags(context.Background(), provider, &SuggestTagsRequest{
+		Content: "Meeting notes",
+	}); err != nil {
+		t.Fatalf("DefaultSuggestTags() error: %v", err)
+	}
+	if strings.Contains(provider.lastCompleteReq.Messages[0].Content, "language:") {
+		t.Error("Expected no language directive when Language is empty")
+	}
+}
+
+func TestDefaultSuggestTags_ReuseMode(t *testing.T) {
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{Content: `["Meetings", "machine_learning", "budget"]`},
+	}
+
+	base := NewBaseProvider(&ProviderConfig{})
+	resp, err := base.DefaultSuggestTags(context.Background(), provider, &SuggestTagsRequest{
+		Content:      "Budget review in the ML meeting",
+		ExistingTags: []string{"meeting", "machine-learning", "travel"},
+		ReuseMode:    true,
+	})
+	if err != nil {
+		t.Fatalf("DefaultSuggestTags() error: %v", err)
+	}
+
+	if !strings.Contains(provider.lastCompleteReq.Messages[0].Content, "Only create a new tag when none of them fits") {
+		t.Errorf("Expected the reuse directive in the system prompt, got: %s", provider.lastCompleteReq.Messages[0].Content)
+	}
+
+	// Reused tags take the corpus spelling; the rest are flagged as new
+	expectedTags := []string{"meeting", "machine-learning", "budget"}
+	if strings.Join(resp.Tags, ",") != strings.Join(expectedTags, ",") {
+		t.Errorf("Expected tags %v, got %v", expectedTags, resp.Tags)
+	}
+	if len(resp.NewTags) != 1 || resp.NewTags[0] != "budget" {
+		t.Errorf("Expected only budget to be new, got %v", resp.NewTags)
+	}
+
+	// Without ReuseMode, tags are returned as-is and none are flagged
+	resp, err = base.DefaultSuggestTags(context.Background(), provider, &SuggestTagsRequest{
+		Content:      "Budget review in the ML meeting",
+		ExistingTags: []string{"meeting"},
+	})
+	if err != nil {
+		t.Fatalf("DefaultSuggestTags() error: %v", err)
+	}
+	if resp.NewTags != nil || strings.Contains(provider.lastCompleteReq.Messages[0].Content, "Only create a new tag") {
+		t.Errorf("Expected no reuse handling without ReuseMode, got new tags %v", resp.NewTags)
+	}
+}
+
+func TestDefaultSummarize_Language(t *testing.T) {
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{Content: "摘要"},
+	}
+
+	base := NewBaseProvider(&ProviderConfig{})
+	if _, err := base.DefaultSummarize(context.Background(), provider, &SummarizeRequest{
+		Content:  "Long content",
+		Language: "zh",
+	}); err != nil {
+		t.Fatalf("DefaultSummarize() error: %v", err)
+	}
+
+	systemPrompt := provider.lastCompleteReq.Messages[0].Content
+	if !strings.Contains(systemPrompt, "Write the summary in language: zh") {
+		t.Errorf("Expected language directive in system prompt, got: %s", systemPrompt)
+	}
+}
+
+func TestDefaultSummarize_KeyPoints(t *testing.T) {
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{Content: `{"summary":"The team agreed on the roadmap.","key_points":["Launch moved to August","Hiring two engineers"]}`},
+	}
+
+	base := NewBaseProvider(&ProviderConfig{})
+	resp, err := base.DefaultSummarize(context.Background(), provider, &SummarizeRequest{
+		Content:          "Long meeting notes",
+		ExtractKeyPoints: true,
+	})
+	if err != nil {
+		t.Fatalf("DefaultSummarize() error: %v", err)
+	}
+
+	if resp.Summary != "The team agreed on the roadmap." {
+		t.Errorf("Expected parsed summary, got %q", resp.Summary)
+	}
+	if len(resp.KeyPoints) != 2 || resp.KeyPoints[0] != "Launch moved to August" || resp.KeyPoints[1] != "Hiring two engineers" {
+		t.Errorf("Expected 2 key points, got %v", resp.KeyPoints)
+	}
+	if !strings.Contains(provider.lastCompleteReq.Messages[0].Content, `"key_points"`) {
+		t.Error("Expected key point instructions in system prompt")
+	}
+}
+
+func TestDefaultSummarize_KeyPointsJSONMode(t *testing.T) {
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{Content: `{"summary":"Short.","key_points":["a","b"]}`},
+		capabilities: &ProviderCapabilities{JSONMode: true},
+	}
+
+	base := NewBaseProvider(&ProviderConfig{})
+	if _, err := base.DefaultSummarize(context.Background(), provider, &SummarizeRequest{
+		Content:          "Long content",
+		ExtractKeyPoints: true,
+	}); err != nil {
+		t.Fatalf("DefaultSummarize() error: %v", err)
+	}
+
+	format := provider.lastCompleteReq.ResponseFormat
+	if format == nil || format.Type != ResponseFormatJSONObject || format.Schema == nil {
+		t.Errorf("Expected JSON object response format with a schema, got %+v", format)
+	}
+}
+
+func TestDefaultSummarize_KeyPointsDisabled(t *testing.T) {
+	content := `{"summary":"Short.","key_points":["a"]}`
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{Content: content},
+	}
+
+	base := NewBaseProvider(&ProviderConfig{})
+	resp, err := base.DefaultSummarize(context.Background(), provider, &SummarizeRequest{Content: "Long content"})
+	if err != nil {
+		t.Fatalf("DefaultSummarize() error: %v", err)
+	}
+	if resp.Summary != content || resp.KeyPoints != nil {
+		t.Errorf("Expected raw summary without key points, got %+v", resp)
+	}
+}
+
+func TestDefaultSummarize_MaxTokensScalesWithMaxLength(t *testing.T) {
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{Content: "Summary."},
+	}
+	base := NewBaseProvider(&ProviderConfig{})
+
+	maxTokens := func(req *SummarizeRequest) int {
+		t.Helper()
+		if _, err := base.DefaultSummarize(context.Background(), provider, req); err != nil {
+			t.Fatalf("DefaultSummarize() error: %v", err)
+		}
+		return provider.lastCompleteReq.MaxTokens
+	}
+
+	short := maxTokens(&SummarizeRequest{Content: "Long content", MaxLength: 200})
+	medium := maxTokens(&SummarizeRequest{Content: "Long content", MaxLength: 1000})
+	long := maxTokens(&SummarizeRequest{Content: "Long content", MaxLength: 4000, Style: "detailed"})
+
+	if !(short < medium && medium < long) {
+		t.Errorf("Expected MaxTokens to grow with MaxLength, got %d, %d, %d", short, medium, long)
+	}
+	// 4000 characters is about 1000 tokens, which must not be clipped
+	if long <= 1000 {
+		t.Errorf("Expected a 4000-character summary to allow more than 1000 tokens, got %d", long)
+	}
+	if medium <= 300 {
+		t.Errorf("Expected a 1000-character summary not to be capped at 300 tokens, got %d", medium)
+	}
+
+	bullet := maxTokens(&SummarizeRequest{Content: "Long content", MaxLength: 1000, Style: "bullet"})
+	if bullet <= medium {
+		t.Errorf("Expected bullet style to allow extra tokens for list markup, got %d vs %d", bullet, medium)
+	}
+	if keyPoints := maxTokens(&SummarizeRequest{Content: "Long content", MaxLength: 1000, ExtractKeyPoints: true}); keyPoints <= medium {
+		t.Errorf("Expected key points to add to the budget, got %d vs %d", keyPoints, medium)
+	}
+}
+
+func TestDefaultSummarize_MaxTokensOverride(t *testing.T) {
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{Content: "Summary."},
+	}
+	base := NewBaseProvider(&ProviderConfig{
+		SummaryMaxTokens: func(maxLength int, style string) int {
+			if style != "brief" {
+				t.Errorf("Expected default style brief, got %q", style)
+			}
+			return maxLength
+		},
+	})
+
+	if _, err := base.DefaultSummarize(context.Background(), provider, &SummarizeRequest{Content: "Long content", MaxLength: 750}); err != nil {
+		t.Fatalf("DefaultSummarize() error: %v", err)
+	}
+	if got := provider.lastCompleteReq.MaxTokens; got != 750 {
+		t.Errorf("Expected overridden MaxTokens 750, got %d", got)
+	}
+}
+
+func TestDefaultSummarize_BulletStyle(t *testing.T) {
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{Content: "- one\n- two"},
+	}
+	base := NewBaseProvider(&ProviderConfig{})
+
+	if _, err := base.DefaultSummarize(context.Background(), provider, &SummarizeRequest{Content: "Long content", Style: "bullet"}); err != nil {
+		t.Fatalf("DefaultSummarize() error: %v", err)
+	}
+	if system := provider.lastCompleteReq.Messages[0].Content; !strings.Contains(system, "bulleted list") {
+		t.Errorf("Expected bullet formatting instructions, got %q", system)
+	}
+
+	if _, err := base.DefaultSummarize(context.Background(), provider, &SummarizeRequest{Content: "Long content"}); err != nil {
+		t.Fatalf("DefaultSummarize() error: %v", err)
+	}
+	if system := provider.lastCompleteReq.Messages[0].Content; strings.Contains(system, "bulleted list") {
+		t.Errorf("Expected no bullet instructions for brief style, got %q", system)
+	}
+}
+
+func TestParseSummaryWithKeyPoints(t *testing.T) {
 	tests := []struct {
-		input    string
-		sep      string
-		expected []string
+		name      string
+		input     string
+		summary   string
+		keyPoints []string
 	}{
-		{"a,b,c", ",", []string{"a", "b", "c"}},
-		{"  a  ,  b  ,  c  ", ",", []string{"a", "b", "c"}},
-		{"a", ",", []string{"a"}},
-		{"", ",", []string{}},
-		{"a;;b;;c", ";;", []string{"a", "b", "c"}},
+		{
+			name:      "json",
+			input:     `{"summary":"All good.","key_points":["a","b"]}`,
+			summary:   "All good.",
+			keyPoints: []string{"a", "b"},
+		},
+		{
+			name:      "bulleted fallback",
+			input:     "The project is on track.\n\nKey points:\n- Budget approved\n* Launch in Q3",
+			summary:   "The project is on track.",
+			keyPoints: []string{"Budget approved", "Launch in Q3"},
+		},
+		{
+			name:      "numbered fallback",
+			input:     "Weekly sync recap.\n1. Fix login bug\n2) Ship release",
+			summary:   "Weekly sync recap.",
+			keyPoints: []string{"Fix login bug", "Ship release"},
+		},
+		{
+			name:    "prose only",
+			input:   "Just a plain summary.",
+			summary: "Just a plain summary.",
+		},
 	}
 
 	for _, tt := range tests {
-		result := splitAndTrim(tt.input, tt.sep)
-		if len(result) != len(tt.expected) {
-			t.Errorf("splitAndTrim(%q, %q): expected %d items, got %d", tt.input, tt.sep, len(tt.expected), len(result))
-			continue
+		t.Run(tt.name, func(t *testing.T) {
+			summary, keyPoints := parseSummaryWithKeyPoints(tt.input)
+			if summary != tt.summary {
+				t.Errorf("Expected summary %q, got %q", tt.summary, summary)
+			}
+			if len(keyPoints) != len(tt.keyPoints) {
+				t.Fatalf("Expected key points %v, got %v", tt.keyPoints, keyPoints)
+			}
+			for i := range keyPoints {
+				if keyPoints[i] != tt.keyPoints[i] {
+					t.Errorf("Key point %d: expected %q, got %q", i, tt.keyPoints[i], keyPoints[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"2", 2 * time.Second},
+		{"  5 ", 5 * time.Second},
+		{"-1", 0},
+		{"invalid", 0},
+		{"3600", maxRetryAfter},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second},
+		{now.Add(-10 * time.Second).Format(http.TimeFormat), 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.expected {
+			t.Errorf("parseRetryAfter(%q) = %v, expected %v", tt.value, got, tt.expected)
 		}
-		for i, v := range result {
-			if v != tt.expected[i] {
-				t.Errorf("splitAndTrim(%q, %q)[%d]: expected %q, got %q", tt.input, tt.sep, i, tt.expected[i], v)
+	}
+}
+
+func TestHandleHTTPError_ProviderError(t *testing.T) {
+	base := NewBaseProvider(&ProviderConfig{Type: ProviderOpenAI})
+
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          []byte
+		expectedError error
+		message       string
+		retryable     bool
+	}{
+		{
+			name:          "unauthorized",
+			statusCode:    401,
+			body:          []byte(`{"error":{"message":"Incorrect API key provided","type":"invalid_request_error"}}`),
+			expectedError: ErrInvalidAPIKey,
+			message:       "Incorrect API key provided",
+		},
+		{
+			name:          "rate limited",
+			statusCode:    429,
+			body:          []byte(`{"message":"Too many requests"}`),
+			expectedError: ErrRateLimited,
+			message:       "Too many requests",
+			retryable:     true,
+		},
+		{
+			name:          "unavailable",
+			statusCode:    503,
+			body:          []byte("upstream overloaded"),
+			expectedError: ErrProviderUnavailable,
+			message:       "upstream overloaded",
+			retryable:     true,
+		},
+		{
+			name:       "bad request",
+			statusCode: 400,
+			body:       []byte(`{"error":{"message":"max_tokens is too large"}}`),
+			message:    "max_tokens is too large",
+		},
+		{
+			name:       "internal error",
+			statusCode: 500,
+			body:       []byte("boom"),
+			message:    "boom",
+			retryable:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := base.handleHTTPError(tt.statusCode, http.Header{}, tt.body)
+
+			if tt.expectedError != nil && !errors.Is(err, tt.expectedError) {
+				t.Errorf("Expected error to match %v, got %v", tt.expectedError, err)
+			}
+
+			var provErr *ProviderError
+			if !errors.As(err, &provErr) {
+				t.Fatalf("Expected *ProviderError, got %T", err)
 			}
+			if provErr.Provider != ProviderOpenAI {
+				t.Errorf("Expected provider openai, got %s", provErr.Provider)
+			}
+			if provErr.StatusCode != tt.statusCode {
+				t.Errorf("Expected status %d, got %d", tt.statusCode, provErr.StatusCode)
+			}
+			if provErr.Message != tt.message {
+				t.Errorf("Expected message %q, got %q", tt.message, provErr.Message)
+			}
+			if provErr.Retryable != tt.retryable {
+				t.Errorf("Expected retryable %v, got %v", tt.retryable, provErr.Retryable)
+			}
+		})
+	}
+}
+
+func TestHandleHTTPErrorRateLimitRetryAfter(t *testing.T) {
+	base := NewBaseProvider(&ProviderConfig{})
+
+	header := http.Header{}
+	header.Set("Retry-After", "7")
+	err := base.handleHTTPError(429, header, []byte("rate limited"))
+
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected error to match ErrRateLimited, got %v", err)
+	}
+
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("Expected *RateLimitError, got %T", err)
+	}
+	if rateLimitErr.RetryAfter != 7*time.Second {
+		t.Errorf("Expected RetryAfter 7s, got %v", rateLimitErr.RetryAfter)
+	}
+}
+
+func TestDoRequestRespectsRetryAfter(t *testing.T) {
+	attempts := 0
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	base := NewBaseProvider(&ProviderConfig{MaxRetries: 1})
+
+	start := time.Now()
+	if _, err := base.DoRequest(context.Background(), http.MethodPost, server.URL, map[string]string{"k": "v"}, nil); err != nil {
+		t.Fatalf("DoRequest() error: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	// The default first backoff is 1s; Retry-After: 2 must take precedence
+	if elapsed < 2*time.Second {
+		t.Errorf("Expected retry to wait at least 2s, waited %v", elapsed)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+	// The request body must be resent on retry
+	if len(bodies) != 2 || bodies[1] != bodies[0] {
+		t.Errorf("Expected identical bodies on retry, got %q", bodies)
+	}
+}
+
+func TestExtractErrorMessage(t *testing.T) {
+	tests := []struct {
+		body     string
+		expected string
+	}{
+		{`{"error":{"message":"Invalid API key","type":"authentication_error"}}`, "Invalid API key"},
+		{`{"error": "model 'llama9' not found"}`, "model 'llama9' not found"},
+		{`{"message":"Too many requests"}`, "Too many requests"},
+		{"  plain text error\n", "plain text error"},
+	}
+
+	for _, tt := range tests {
+		if got := extractErrorMessage([]byte(tt.body)); got != tt.expected {
+			t.Errorf("extractErrorMessage(%q) = %q, expected %q", tt.body, got, tt.expected)
+		}
+	}
+}
+
+func TestDefaultCheckHealth(t *testing.T) {
+	base := NewBaseProvider(&ProviderConfig{})
+
+	if err := base.DefaultCheckHealth(context.Background(), &mockProvider{configured: false}); !errors.Is(err, ErrProviderNotConfigured) {
+		t.Errorf("Expected ErrProviderNotConfigured, got %v", err)
+	}
+	if err := base.DefaultCheckHealth(context.Background(), &mockProvider{configured: true}); err != nil {
+		t.Errorf("Expected healthy provider, got %v", err)
+	}
+}
+
+func TestValidateBaseURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"https://api.openai.com/v1", false},
+		{"http://localhost:11434", false},
+		{"api.openai.com/v1", true},
+		{"ftp://example.com", true},
+		{"http://", true},
+		{"://bad", true},
+	}
+
+	for _, tt := range tests {
+		err := validateBaseURL(tt.url)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateBaseURL(%q): expected error %v, got %v", tt.url, tt.wantErr, err)
+		}
+	}
+}
+
+func TestDoRequestMaxResponseBytes(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Write([]byte(strings.Repeat("x", 2048)))
+	}))
+	defer server.Close()
+
+	base := NewBaseProvider(&ProviderConfig{MaxResponseBytes: 1024, MaxRetries: 2})
+	_, err := base.DoRequest(context.Background(), http.MethodGet, server.URL, nil, nil)
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("Expected ErrResponseTooLarge, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected an oversized response not to be retried, got %d attempts", attempts)
+	}
+
+	body, err := base.readBody(strings.NewReader(strings.Repeat("x", 2048)))
+	if !errors.Is(err, ErrResponseTooLarge) || len(body) != 1024 {
+		t.Errorf("Expected the read capped at 1024 bytes, got %d bytes, %v", len(body), err)
+	}
+
+	// A body at the limit is read in full
+	body, err = base.readBody(strings.NewReader(strings.Repeat("x", 1024)))
+	if err != nil || len(body) != 1024 {
+		t.Errorf("Expected 1024 bytes without error, got %d bytes, %v", len(body), err)
+	}
+}
+
+func TestDecodeJSONTruncatesBodySnippet(t *testing.T) {
+	base := NewBaseProvider(&ProviderConfig{Type: ProviderOllama})
+
+	var v struct{}
+	err := base.decodeJSON([]byte("<!DOCTYPE html>"+strings.Repeat("x", 500)), http.StatusOK, &v)
+	if err == nil {
+		t.Fatal("Expected an error for a non-JSON body")
+	}
+	if !strings.Contains(err.Error(), "ollama returned invalid JSON (status 200)") || !strings.Contains(err.Error(), "<!DOCTYPE html>") {
+		t.Errorf("Expected provider, status and snippet in error, got: %v", err)
+	}
+	if strings.Contains(err.Error(), strings.Repeat("x", maxBodySnippetBytes)) {
+		t.Errorf("Expected the body snippet to be truncated, got: %v", err)
+	}
+
+	if err := base.decodeJSON([]byte(`{}`), http.StatusOK, &v); err != nil {
+		t.Errorf("decodeJSON() error: %v", err)
+	}
+}
+
+func TestDoRequestExtraHeadersAndMetadata(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}],"model":"gpt-4o-mini"}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(&ProviderConfig{
+		Type:    ProviderOpenAI,
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+		ExtraHeaders: map[string]string{
+			"HTTP-Referer":  "https://memos.example.com",
+			"X-Title":       "Memos",
+			"X-Tenant-Id":   "static-tenant",
+			"Authorization": "Bearer should-not-win",
+		},
+	})
+
+	_, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+		Metadata: map[string]string{"X-Tenant-Id": "tenant-42", "X-Request-Source": "tagging"},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+
+	if got.Get("HTTP-Referer") != "https://memos.example.com" || got.Get("X-Title") != "Memos" {
+		t.Errorf("Expected static extra headers, got %v", got)
+	}
+	if got.Get("X-Tenant-Id") != "tenant-42" {
+		t.Errorf("Expected metadata to override the static header, got %q", got.Get("X-Tenant-Id"))
+	}
+	if got.Get("X-Request-Source") != "tagging" {
+		t.Errorf("Expected per-request metadata header, got %q", got.Get("X-Request-Source"))
+	}
+	if got.Get("Authorization") != "Bearer test-key" {
+		t.Errorf("Expected provider auth header to take precedence, got %q", got.Get("Authorization"))
+	}
+
+	// Metadata is scoped to the call that set it
+	if _, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	}); err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if got.Get("X-Request-Source") != "" || got.Get("X-Tenant-Id") != "static-tenant" {
+		t.Errorf("Expected metadata not to persist across calls, got %v", got)
+	}
+}
+
+func TestDefaultSuggestTitle(t *testing.T) {
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{Content: "Title: \"Weekly Planning Notes.\"\nExtra commentary", Model: "gpt-4o-mini"},
+	}
+
+	base := NewBaseProvider(&ProviderConfig{})
+	resp, err := base.DefaultSuggestTitle(context.Background(), provider, &SuggestTitleRequest{
+		Content:  "Plans for the week",
+		Language: "en",
+	})
+	if err != nil {
+		t.Fatalf("DefaultSuggestTitle() error: %v", err)
+	}
+	if resp.Title != "Weekly Planning Notes" {
+		t.Errorf("Expected title %q, got %q", "Weekly Planning Notes", resp.Title)
+	}
+	if resp.Model != "gpt-4o-mini" {
+		t.Errorf("Expected model gpt-4o-mini, got %q", resp.Model)
+	}
+
+	req := provider.lastCompleteReq
+	if req.Temperature > 0.3 || req.MaxTokens <= 0 || req.MaxTokens > 64 {
+		t.Errorf("Expected low temperature and a short token budget, got %v and %d", req.Temperature, req.MaxTokens)
+	}
+	if !strings.Contains(req.Messages[0].Content, "under 60 characters") {
+		t.Errorf("Expected the default length in the prompt, got: %s", req.Messages[0].Content)
+	}
+}
+
+func TestCleanTitle(t *testing.T) {
+	tests := []struct {
+		raw       string
+		maxLength int
+		expected  string
+	}{
+		{"Simple title", 60, "Simple title"},
+		{"\"Quoted title\"", 60, "Quoted title"},
+		{"“Curly quotes”", 60, "Curly quotes"},
+		{"'Single' ", 60, "Single"},
+		{"title: **Bold title**", 60, "Bold title"},
+		{"\n\n  Second line wins\nignored", 60, "Second line wins"},
+		{"Ends with a period.", 60, "Ends with a period"},
+		{"A rather long title that goes on and on", 20, "A rather long title"},
+		{"Supercalifragilistic", 10, "Supercalif"},
+	}
+
+	for _, tt := range tests {
+		if got := cleanTitle(tt.raw, tt.maxLength); got != tt.expected {
+			t.Errorf("cleanTitle(%q, %d) = %q, want %q", tt.raw, tt.maxLength, got, tt.expected)
+		}
+		if got := cleanTitle(tt.raw, tt.maxLength); len([]rune(got)) > tt.maxLength {
+			t.Errorf("cleanTitle(%q, %d) exceeds max length: %q", tt.raw, tt.maxLength, got)
 		}
 	}
 }
@@ -0,0 +1,424 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const (
+	cohereBaseURL        = "https://api.cohere.com"
+	cohereDefaultModel   = "command-r-08-2024"
+	cohereEmbeddingModel = "embed-english-v3.0"
+	cohereRerankModel    = "rerank-english-v3.0"
+)
+
+// CohereProvider implements the Provider and Reranker interfaces for Cohere.
+type CohereProvider struct {
+	*BaseProvider
+	apiKey         string
+	baseURL        string
+	defaultModel   string
+	embeddingModel string
+}
+
+// NewCohereProvider creates a new Cohere provider.
+func NewCohereProvider(config *ProviderConfig) *CohereProvider {
+	baseURL := cohereBaseURL
+	defaultModel := cohereDefaultModel
+	embeddingModel := cohereEmbeddingModel
+
+	if config.BaseURL != "" {
+		baseURL = config.BaseURL
+	}
+	if config.DefaultModel != "" {
+		defaultModel = config.DefaultModel
+	}
+	if config.EmbeddingModel != "" {
+		embeddingModel = config.EmbeddingModel
+	}
+
+	return &CohereProvider{
+		BaseProvider:   NewBaseProvider(config),
+		apiKey:         config.APIKey,
+		baseURL:        baseURL,
+		defaultModel:   defaultModel,
+		embeddingModel: embeddingModel,
+	}
+}
+
+// GetType returns the provider type.
+func (p *CohereProvider) GetType() ProviderType {
+	return ProviderCohere
+}
+
+// GetName returns the display name.
+func (p *CohereProvider) GetName() string {
+	return "Cohere"
+}
+
+// IsConfigured checks if the provider is properly configured.
+func (p *CohereProvider) IsConfigured(ctx context.Context) bool {
+	return p.apiKey != ""
+}
+
+// GetDefaultModel returns the default model.
+func (p *CohereProvider) GetDefaultModel() string {
+	return p.defaultModel
+}
+
+// defaultEmbeddingModel returns the model used when an EmbeddingRequest names none.
+func (p *CohereProvider) defaultEmbeddingModel() string {
+	return p.embeddingModel
+}
+
+// GetAvailableModels returns the chat models available to the API key.
+// Results are cached for ProviderConfig.ModelsCacheTTL.
+func (p *CohereProvider) GetAvailableModels(ctx context.Context) ([]string, error) {
+	return p.CachedModels(ctx, false, p.fetchAvailableModels)
+}
+
+// RefreshAvailableModels refetches the model list, bypassing the cache.
+func (p *CohereProvider) RefreshAvailableModels(ctx context.Context) ([]string, error) {
+	return p.CachedModels(ctx, true, p.fetchAvailableModels)
+}
+
+// fetchAvailableModels queries the API for the available models.
+func (p *CohereProvider) fetchAvailableModels(ctx context.Context) ([]string, error) {
+	if !p.IsConfigured(ctx) {
+		return nil, ErrProviderNotConfigured
+	}
+
+	url := fmt.Sprintf("%s/v1/models?endpoint=chat", p.baseURL)
+
+	respBody, status, err := p.doRequest(ctx, http.MethodGet, url, nil, p.headers())
+	if err != nil {
+		return nil, err
+	}
+
+	var resp cohereModelsResponse
+	if err := p.decodeJSON(respBody, status, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse models response: %w", err)
+	}
+
+	models := make([]string, 0, len(resp.Models))
+	for _, m := range resp.Models {
+		models = append(models, m.Name)
+	}
+
+	return models, nil
+}
+
+// GetModelInfo returns the available models annotated with known limits.
+func (p *CohereProvider) GetModelInfo(ctx context.Context) ([]ModelInfo, error) {
+	return p.DefaultGetModelInfo(ctx, p)
+}
+
+// Complete performs chat completion.
+func (p *CohereProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	if !p.IsConfigured(ctx) {
+		return nil, ErrProviderNotConfigured
+	}
+	ctx = withRequestMetadata(ctx, req.Metadata)
+
+	ctx, cancel := withRequestTimeout(ctx, req.TimeoutSeconds)
+	defer cancel()
+
+	if err := checkVision(p, req.Messages); err != nil {
+		return nil, err
+	}
+
+	model := req.Model
+	if model == "" {
+		model = p.defaultModel
+	}
+
+	req, err := p.checkSampling(req, maxTemperatureCohere)
+	if err != nil {
+		return nil, err
+	}
+	req, truncated, err := truncateToContext(req, contextWindow(model))
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]cohereMessage, len(req.Messages))
+	for i, m := range req.Messages {
+		messages[i] = cohereMessage{
+			Role:    string(m.Role),
+			Content: m.Content,
+		}
+	}
+
+	cohereReq := cohereChatRequest{
+		Model:            model,
+		Messages:         messages,
+		MaxTokens:        req.MaxTokens,
+		Temperature:      req.Temperature,
+		P:                req.TopP,
+		StopSequences:    req.Stop,
+		Seed:             req.Seed,
+		FrequencyPenalty: req.FrequencyPenalty,
+		PresencePenalty:  req.PresencePenalty,
+	}
+	if req.ResponseFormat != nil {
+		cohereReq.ResponseFormat = &cohereResponseFormat{
+			Type:       "json_object",
+			JSONSchema: req.ResponseFormat.Schema,
+		}
+	}
+
+	url := fmt.Sprintf("%s/v2/chat", p.baseURL)
+
+	respBody, status, err := p.doRequest(ctx, http.MethodPost, url, cohereReq, p.headers())
+	if err != nil {
+		return nil, err
+	}
+
+	var resp cohereChatResponse
+	if err := p.decodeJSON(respBody, status, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse completion response: %w", err)
+	}
+
+	var content string
+	for _, block := range resp.Message.Content {
+		if block.Type == "text" {
+			content += block.Text
+		}
+	}
+
+	tokens := resp.Usage.Tokens
+	return p.captureResponse(&CompletionResponse{
+		Content: content,
+		Model:   model, // Cohere does not echo the model
+		Usage: &TokenUsage{
+			PromptTokens:     tokens.InputTokens,
+			CompletionTokens: tokens.OutputTokens,
+			TotalTokens:      tokens.InputTokens + tokens.OutputTokens,
+		},
+		FinishReason: normalizeFinishReason(resp.FinishReason),
+		Truncated:    truncated,
+	}), nil
+}
+
+// Embed generates embeddings. Inputs are embedded as search documents.
+// Cohere v3 models have no server-side dimension reduction, so Dimensions is applied client-side.
+func (p *CohereProvider) Embed(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+	if !p.IsConfigured(ctx) {
+		return nil, ErrProviderNotConfigured
+	}
+
+	model := req.Model
+	if model == "" {
+		model = p.embeddingModel
+	}
+
+	cohereReq := cohereEmbedRequest{
+		Model:          model,
+		Texts:          req.Input,
+		InputType:      "search_document",
+		EmbeddingTypes: []string{"float"},
+	}
+
+	url := fmt.Sprintf("%s/v2/embed", p.baseURL)
+
+	respBody, status, err := p.doRequest(ctx, http.MethodPost, url, cohereReq, p.headers())
+	if err != nil {
+		return nil, err
+	}
+
+	var resp cohereEmbedResponse
+	if err := p.decodeJSON(respBody, status, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse embedding response: %w", err)
+	}
+
+	embeddings := make([][]float32, len(resp.Embeddings.Float))
+	for i, embedding := range resp.Embeddings.Float {
+		embeddings[i], err = reduceDimensions(embedding, req.Dimensions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	inputTokens := resp.Meta.BilledUnits.InputTokens
+	return &EmbeddingResponse{
+		Embeddings: embeddings,
+		Model:      model,
+		Usage: &TokenUsage{
+			PromptTokens: inputTokens,
+			TotalTokens:  inputTokens,
+		},
+	}, nil
+}
+
+// Rerank orders docs by relevance to query using Cohere's rerank endpoint.
+func (p *CohereProvider) Rerank(ctx context.Context, query string, docs []string, topN int) ([]RerankResult, error) {
+	if !p.IsConfigured(ctx) {
+		return nil, ErrProviderNotConfigured
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	cohereReq := cohereRerankRequest{
+		Model:     cohereRerankModel,
+		Query:     query,
+		Documents: docs,
+	}
+	if topN > 0 {
+		cohereReq.TopN = topN
+	}
+
+	url := fmt.Sprintf("%s/v2/rerank", p.baseURL)
+
+	respBody, status, err := p.doRequest(ctx, http.MethodPost, url, cohereReq, p.headers())
+	if err != nil {
+		return nil, err
+	}
+
+	var resp cohereRerankResponse
+	if err := p.decodeJSON(respBody, status, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse rerank response: %w", err)
+	}
+
+	results := make([]RerankResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		if r.Index < 0 || r.Index >= len(docs) {
+			return nil, fmt.Errorf("rerank result index %d out of range", r.Index)
+		}
+		results = append(results, RerankResult{
+			Index:    r.Index,
+			Document: docs[r.Index],
+			Score:    r.RelevanceScore,
+		})
+	}
+
+	return results, nil
+}
+
+// Capabilities describes the features supported by the Cohere provider.
+// Reranking is exposed through the Reranker interface.
+func (p *CohereProvider) Capabilities() ProviderCapabilities {
+	return ProviderCapabilities{
+		Embeddings: true,
+		JSONMode:   true,
+	}
+}
+
+// CheckHealth verifies the Cohere API is reachable by listing models.
+func (p *CohereProvider) CheckHealth(ctx context.Context) error {
+	return p.DefaultCheckHealth(ctx, p)
+}
+
+// ValidateConfig checks the API key is set and the base URL is well formed.
+func (p *CohereProvider) ValidateConfig(ctx context.Context) error {
+	if err := p.DefaultValidateConfig(ctx, p); err != nil {
+		return err
+	}
+	return validateBaseURL(p.baseURL)
+}
+
+// SuggestTags suggests tags for the given content.
+func (p *CohereProvider) SuggestTags(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+	return p.DefaultSuggestTags(ctx, p, req)
+}
+
+// Summarize generates a summary of the given content.
+func (p *CohereProvider) Summarize(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error) {
+	return p.DefaultSummarize(ctx, p, req)
+}
+
+// SuggestTitle generates a short title for the content.
+func (p *CohereProvider) SuggestTitle(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error) {
+	return p.DefaultSuggestTitle(ctx, p, req)
+}
+
+func (p *CohereProvider) headers() map[string]string {
+	return map[string]string{
+		"Authorization": "Bearer " + p.apiKey,
+	}
+}
+
+// Cohere API request/response types
+
+type cohereMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type cohereChatRequest struct {
+	Model         string          `json:"model"`
+	Messages      []cohereMessage `json:"messages"`
+	MaxTokens     int             `json:"max_tokens,omitempty"`
+	Temperature   float64         `json:"temperature,omitempty"`
+	P             float64         `json:"p,omitempty"`
+	StopSequences []string        `json:"stop_sequences,omitempty"`
+	Seed          *int            `json:"seed,omitempty"`
+
+	FrequencyPenalty float64               `json:"frequency_penalty,omitempty"`
+	PresencePenalty  float64               `json:"presence_penalty,omitempty"`
+	ResponseFormat   *cohereResponseFormat `json:"response_format,omitempty"`
+}
+
+type cohereResponseFormat struct {
+	Type       string         `json:"type"`
+	JSONSchema map[string]any `json:"json_schema,omitempty"`
+}
+
+type cohereChatResponse struct {
+	ID           string `json:"id"`
+	FinishReason string `json:"finish_reason"`
+	Message      struct {
+		Role    string `json:"role"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	} `json:"message"`
+	Usage struct {
+		Tokens struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"tokens"`
+	} `json:"usage"`
+}
+
+type cohereEmbedRequest struct {
+	Model          string   `json:"model"`
+	Texts          []string `json:"texts"`
+	InputType      string   `json:"input_type"`
+	EmbeddingTypes []string `json:"embedding_types"`
+}
+
+type cohereEmbedResponse struct {
+	ID         string `json:"id"`
+	Embeddings struct {
+		Float [][]float32 `json:"float"`
+	} `json:"embeddings"`
+	Meta struct {
+		BilledUnits struct {
+			InputTokens int `json:"input_tokens"`
+		} `json:"billed_units"`
+	} `json:"meta"`
+}
+
+type cohereRerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n,omitempty"`
+}
+
+type cohereRerankResponse struct {
+	ID      string `json:"id"`
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+type cohereModelsResponse struct {
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
@@ -0,0 +1,220 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newCohereTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("Expected bearer auth, got %q", r.Header.Get("Authorization"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/v2/chat":
+			var req cohereChatRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("Failed to decode request: %v", err)
+			}
+			if req.Model != cohereDefaultModel {
+				t.Errorf("Expected model %s, got %s", cohereDefaultModel, req.Model)
+			}
+			if req.P != 0.9 || len(req.StopSequences) != 1 {
+				t.Errorf("Expected p and stop_sequences to be mapped, got %+v", req)
+			}
+			w.Write([]byte(`{"id":"c1","finish_reason":"COMPLETE","message":{"role":"assistant","content":[{"type":"text","text":"Hello!"}]},"usage":{"tokens":{"input_tokens":5,"output_tokens":2}}}`))
+		case "/v2/embed":
+			var req cohereEmbedRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("Failed to decode request: %v", err)
+			}
+			if req.Model != cohereEmbeddingModel {
+				t.Errorf("Expected model %s, got %s", cohereEmbeddingModel, req.Model)
+			}
+			if req.InputType != "search_document" || len(req.EmbeddingTypes) != 1 || req.EmbeddingTypes[0] != "float" {
+				t.Errorf("Unexpected embed options: %+v", req)
+			}
+			if len(req.Texts) != 2 {
+				t.Errorf("Expected 2 texts, got %v", req.Texts)
+			}
+			w.Write([]byte(`{"id":"e1","embeddings":{"float":[[0.1,0.2,0.3],[0.4,0.5,0.6]]},"meta":{"billed_units":{"input_tokens":4}}}`))
+		case "/v2/rerank":
+			var req cohereRerankRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("Failed to decode request: %v", err)
+			}
+			if req.Query != "meeting" || req.TopN != 2 || len(req.Documents) != 3 {
+				t.Errorf("Unexpected rerank request: %+v", req)
+			}
+			w.Write([]byte(`{"id":"r1","results":[{"index":2,"relevance_score":0.97},{"index":0,"relevance_score":0.41}]}`))
+		case "/v1/models":
+			w.Write([]byte(`{"models":[{"name":"command-r-08-2024"},{"name":"command-r-plus-08-2024"}]}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestCohereProviderComplete(t *testing.T) {
+	server := newCohereTestServer(t)
+	defer server.Close()
+
+	provider := NewCohereProvider(&ProviderConfig{APIKey: "test-key", BaseURL: server.URL})
+
+	resp, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Hi"}},
+		TopP:     0.9,
+		Stop:     []string{"END"},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+
+	if resp.Content != "Hello!" {
+		t.Errorf("Expected content Hello!, got %q", resp.Content)
+	}
+	if resp.Usage.TotalTokens != 7 {
+		t.Errorf("Expected 7 total tokens, got %d", resp.Usage.TotalTokens)
+	}
+	if resp.FinishReason != FinishReasonStop {
+		t.Errorf("Expected finish reason stop, got %s", resp.FinishReason)
+	}
+}
+
+func TestCohereProviderEmbed(t *testing.T) {
+	server := newCohereTestServer(t)
+	defer server.Close()
+
+	provider := NewCohereProvider(&ProviderConfig{APIKey: "test-key", BaseURL: server.URL})
+
+	resp, err := provider.Embed(context.Background(), &EmbeddingRequest{
+		Input: []string{"first memo", "second memo"},
+	})
+	if err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+
+	if len(resp.Embeddings) != 2 || len(resp.Embeddings[0]) != 3 {
+		t.Fatalf("Expected 2 embeddings of length 3, got %v", resp.Embeddings)
+	}
+	if resp.Embeddings[1][2] != 0.6 {
+		t.Errorf("Expected last value 0.6, got %v", resp.Embeddings[1][2])
+	}
+	if resp.Usage.PromptTokens != 4 {
+		t.Errorf("Expected 4 prompt tokens, got %d", resp.Usage.PromptTokens)
+	}
+}
+
+func TestCohereProviderRerank(t *testing.T) {
+	server := newCohereTestServer(t)
+	defer server.Close()
+
+	provider := NewCohereProvider(&ProviderConfig{APIKey: "test-key", BaseURL: server.URL})
+	docs := []string{"lunch plans", "grocery list", "meeting notes"}
+
+	results, err := provider.Rerank(context.Background(), "meeting", docs, 2)
+	if err != nil {
+		t.Fatalf("Rerank() error: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %v", results)
+	}
+	if results[0].Index != 2 || results[0].Document != "meeting notes" || results[0].Score != 0.97 {
+		t.Errorf("Unexpected top result: %+v", results[0])
+	}
+	if results[1].Index != 0 || results[1].Document != "lunch plans" {
+		t.Errorf("Unexpected second result: %+v", results[1])
+	}
+}
+
+func TestCohereProviderGetAvailableModels(t *testing.T) {
+	server := newCohereTestServer(t)
+	defer server.Close()
+
+	provider := NewCohereProvider(&ProviderConfig{APIKey: "test-key", BaseURL: server.URL})
+
+	models, err := provider.GetAvailableModels(context.Background())
+	if err != nil {
+		t.Fatalf("GetAvailableModels() error: %v", err)
+	}
+	if len(models) != 2 || models[0] != "command-r-08-2024" {
+		t.Errorf("Unexpected models: %v", models)
+	}
+}
+
+func TestCohereProviderNotConfigured(t *testing.T) {
+	provider := NewCohereProvider(&ProviderConfig{})
+
+	if provider.IsConfigured(context.Background()) {
+		t.Error("Expected provider without API key to be unconfigured")
+	}
+	if _, err := provider.Rerank(context.Background(), "q", []string{"doc"}, 0); !errors.Is(err, ErrProviderNotConfigured) {
+		t.Errorf("Expected ErrProviderNotConfigured, got %v", err)
+	}
+}
+
+func TestServiceRerank(t *testing.T) {
+	server := newCohereTestServer(t)
+	defer server.Close()
+
+	svc := NewService()
+	svc.RegisterProvider(NewCohereProvider(&ProviderConfig{APIKey: "test-key", BaseURL: server.URL}))
+
+	results, err := svc.Rerank(context.Background(), "meeting", []string{"lunch plans", "grocery list", "meeting notes"}, 2)
+	if err != nil {
+		t.Fatalf("Rerank() error: %v", err)
+	}
+	if len(results) != 2 || results[0].Document != "meeting notes" {
+		t.Errorf("Unexpected results: %v", results)
+	}
+}
+
+func TestServiceRerankUnsupported(t *testing.T) {
+	svc := NewService()
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOpenAI, name: "OpenAI", configured: true})
+
+	_, err := svc.Rerank(context.Background(), "meeting", []string{"doc"}, 1)
+	if !errors.Is(err, ErrCapabilityNotSupported) {
+		t.Errorf("Expected ErrCapabilityNotSupported, got %v", err)
+	}
+}
+
+func TestCohereProviderCompleteFinishReason(t *testing.T) {
+	tests := map[string]string{
+		"COMPLETE":      FinishReasonStop,
+		"STOP_SEQUENCE": FinishReasonStop,
+		"MAX_TOKENS":    FinishReasonLength,
+		"TOOL_CALL":     FinishReasonToolCalls,
+		"ERROR_TOXIC":   FinishReasonContentFilter,
+	}
+
+	for native, want := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"id":"c1","finish_reason":%q,"message":{"role":"assistant","content":[{"type":"text","text":"ok"}]},"usage":{"tokens":{"input_tokens":1,"output_tokens":1}}}`, native)
+		}))
+
+		provider := NewCohereProvider(&ProviderConfig{APIKey: "test-key", BaseURL: server.URL})
+		resp, err := provider.Complete(context.Background(), &CompletionRequest{
+			Messages: []Message{{Role: RoleUser, Content: "Hi"}},
+		})
+		server.Close()
+
+		if err != nil {
+			t.Fatalf("Complete() error for %s: %v", native, err)
+		}
+		if resp.FinishReason != want {
+			t.Errorf("finish_reason %q: expected %q, got %q", native, want, resp.FinishReason)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sync"
 
 	storepb "github.com/usememos/memos/proto/gen/store"
 )
@@ -12,12 +14,18 @@ import (
 // It bridges the gap between proto-based storage and the runtime service.
 type ConfigManager struct {
 	service Service
+
+	fallbackOrder   []ProviderType
+	fallbackOrderMu sync.RWMutex
+
+	warmupOnLoad bool
 }
 
 // NewConfigManager creates a new configuration manager.
 func NewConfigManager(service Service) *ConfigManager {
 	return &ConfigManager{
-		service: service,
+		service:       service,
+		fallbackOrder: defaultFallbackOrder,
 	}
 }
 
@@ -37,6 +45,20 @@ func (m *ConfigManager) LoadFromProto(ctx context.Context, setting *storepb.Inst
 		}
 	}
 
+	if config := setting.GetOpenaiCompatibleConfig(); config != nil {
+		provider := NewOpenAICompatibleProviderFromProto(config)
+		if err := m.service.RegisterProvider(provider); err != nil {
+			slog.Warn("Failed to register OpenAI-compatible provider", slog.Any("error", err))
+		}
+	}
+
+	if config := setting.GetGroqConfig(); config != nil {
+		provider := NewGroqProviderFromProto(config)
+		if err := m.service.RegisterProvider(provider); err != nil {
+			slog.Warn("Failed to register Groq provider", slog.Any("error", err))
+		}
+	}
+
 	if config := setting.GetOllamaConfig(); config != nil {
 		provider := NewOllamaProviderFromProto(config)
 		if err := m.service.RegisterProvider(provider); err != nil {
@@ -51,6 +73,13 @@ func (m *ConfigManager) LoadFromProto(ctx context.Context, setting *storepb.Inst
 		}
 	}
 
+	if config := setting.GetGeminiConfig(); config != nil {
+		provider := NewGeminiProviderFromProto(config)
+		if err := m.service.RegisterProvider(provider); err != nil {
+			slog.Warn("Failed to register Gemini provider", slog.Any("error", err))
+		}
+	}
+
 	// Set the active provider if specified
 	if setting.Provider != storepb.InstanceLLMSetting_LLM_PROVIDER_UNSPECIFIED {
 		providerType := protoProviderToType(setting.Provider)
@@ -67,9 +96,21 @@ func (m *ConfigManager) LoadFromProto(ctx context.Context, setting *storepb.Inst
 		}
 	}
 
+	if m.warmupOnLoad && m.service.GetProvider() != nil {
+		if err := m.service.Warmup(ctx); err != nil {
+			slog.Warn("Failed to warm up LLM provider", slog.Any("error", err))
+		}
+	}
+
 	return nil
 }
 
+// SetWarmupOnLoad controls whether LoadFromProto warms up the active provider
+// (see Service.Warmup) after loading. Warmup failures are logged, not returned.
+func (m *ConfigManager) SetWarmupOnLoad(enabled bool) {
+	m.warmupOnLoad = enabled
+}
+
 // ToProto converts the current service state to proto configuration.
 // This should be called when saving settings.
 func (m *ConfigManager) ToProto() *storepb.InstanceLLMSetting {
@@ -85,6 +126,18 @@ func (m *ConfigManager) ToProto() *storepb.InstanceLLMSetting {
 					setting.OpenaiConfig = openai.ToProto()
 				}
 			}
+		case ProviderOpenAICompatible:
+			if provider, err := m.service.GetProviderByType(ProviderOpenAICompatible); err == nil {
+				if compatible, ok := provider.(*OpenAIProvider); ok {
+					setting.OpenaiCompatibleConfig = compatible.ToProto()
+				}
+			}
+		case ProviderGroq:
+			if provider, err := m.service.GetProviderByType(ProviderGroq); err == nil {
+				if groq, ok := provider.(*OpenAIProvider); ok {
+					setting.GroqConfig = groq.ToProto()
+				}
+			}
 		case ProviderOllama:
 			if provider, err := m.service.GetProviderByType(ProviderOllama); err == nil {
 				if ollama, ok := provider.(*OllamaProvider); ok {
@@ -97,6 +150,12 @@ func (m *ConfigManager) ToProto() *storepb.InstanceLLMSetting {
 					setting.AnthropicConfig = anthropic.ToProto()
 				}
 			}
+		case ProviderGemini:
+			if provider, err := m.service.GetProviderByType(ProviderGemini); err == nil {
+				if gemini, ok := provider.(*GeminiProvider); ok {
+					setting.GeminiConfig = gemini.ToProto()
+				}
+			}
 		}
 
 		// Set the active provider
@@ -128,17 +187,88 @@ func (m *ConfigManager) SetActiveProviderWithFallback(ctx context.Context, provi
 	return m.tryFallbackProvider(ctx)
 }
 
+// defaultFallbackOrder is the default priority order for fallback: Ollama (local), OpenAI, Anthropic, Gemini, OpenAI-compatible, Cohere, Groq.
+var defaultFallbackOrder = []ProviderType{ProviderOllama, ProviderOpenAI, ProviderAnthropic, ProviderGemini, ProviderOpenAICompatible, ProviderCohere, ProviderGroq}
+
+// SetFallbackOrder sets the priority order used when the requested provider is
+// unavailable. Providers not in the list are never selected as a fallback.
+// Returns an error for an empty list, unknown provider types or duplicates.
+func (m *ConfigManager) SetFallbackOrder(order []ProviderType) error {
+	if len(order) == 0 {
+		return fmt.Errorf("fallback order must not be empty")
+	}
+
+	seen := make(map[ProviderType]bool, len(order))
+	for _, providerType := range order {
+		if !isKnownProviderType(providerType) {
+			return fmt.Errorf("unknown provider type in fallback order: %q", providerType)
+		}
+		if seen[providerType] {
+			return fmt.Errorf("duplicate provider type in fallback order: %q", providerType)
+		}
+		seen[providerType] = true
+	}
+
+	if orderer, ok := m.service.(fallbackOrderer); ok {
+		orderer.setFallbackOrder(slices.Clone(order))
+		return nil
+	}
+
+	m.fallbackOrderMu.Lock()
+	defer m.fallbackOrderMu.Unlock()
+	m.fallbackOrder = slices.Clone(order)
+	return nil
+}
+
+// fallbackOrderer is implemented by services that keep their own fallback order,
+// so that deregistering the active provider and tryFallbackProvider agree.
+type fallbackOrderer interface {
+	getFallbackOrder() []ProviderType
+	setFallbackOrder(order []ProviderType)
+}
+
+// getFallbackOrder returns the service's fallback order if it keeps one, else the manager's.
+func (m *ConfigManager) getFallbackOrder() []ProviderType {
+	if orderer, ok := m.service.(fallbackOrderer); ok {
+		return orderer.getFallbackOrder()
+	}
+
+	m.fallbackOrderMu.RLock()
+	defer m.fallbackOrderMu.RUnlock()
+	return m.fallbackOrder
+}
+
+// isKnownProviderType reports whether providerType is one of the Provider* constants.
+func isKnownProviderType(providerType ProviderType) bool {
+	switch providerType {
+	case ProviderOpenAI, ProviderAnthropic, ProviderGemini, ProviderOllama,
+		ProviderOpenAICompatible, ProviderCohere, ProviderGroq, ProviderMock:
+		return true
+	}
+	return false
+}
+
+// fallbackSetter is implemented by services that can attribute an active
+// provider change to a fallback rather than an explicit selection.
+type fallbackSetter interface {
+	setActiveProvider(providerType ProviderType, reason string) error
+}
+
 // tryFallbackProvider attempts to select an available provider as fallback.
 func (m *ConfigManager) tryFallbackProvider(ctx context.Context) error {
 	providers := m.service.ListProviders()
 
-	// Priority order for fallback: Ollama (local), OpenAI, Anthropic, Gemini
-	fallbackOrder := []ProviderType{ProviderOllama, ProviderOpenAI, ProviderAnthropic, ProviderGemini}
+	setActive := m.service.SetActiveProvider
+	if setter, ok := m.service.(fallbackSetter); ok {
+		setActive = func(providerType ProviderType) error {
+			return setter.setActiveProvider(providerType, ProviderChangeFallback)
+		}
+	}
 
-	for _, providerType := range fallbackOrder {
+	for _, providerType := range m.getFallbackOrder() {
 		for _, status := range providers {
 			if status.Type == providerType && status.Configured {
-				if err := m.service.SetActiveProvider(providerType); err == nil {
+				if err := setActive(providerType); err == nil {
 					slog.Info("Fallback provider selected", slog.String("provider", string(providerType)))
 					return nil
 				}
@@ -165,6 +295,10 @@ func protoProviderToType(provider storepb.InstanceLLMSetting_LLMProvider) Provid
 		return ProviderGemini
 	case storepb.InstanceLLMSetting_OLLAMA:
 		return ProviderOllama
+	case storepb.InstanceLLMSetting_OPENAI_COMPATIBLE:
+		return ProviderOpenAICompatible
+	case storepb.InstanceLLMSetting_GROQ:
+		return ProviderGroq
 	default:
 		return ""
 	}
@@ -181,6 +315,10 @@ func typeToProtoProvider(providerType ProviderType) storepb.InstanceLLMSetting_L
 		return storepb.InstanceLLMSetting_GEMINI
 	case ProviderOllama:
 		return storepb.InstanceLLMSetting_OLLAMA
+	case ProviderOpenAICompatible:
+		return storepb.InstanceLLMSetting_OPENAI_COMPATIBLE
+	case ProviderGroq:
+		return storepb.InstanceLLMSetting_GROQ
 	default:
 		return storepb.InstanceLLMSetting_LLM_PROVIDER_UNSPECIFIED
 	}
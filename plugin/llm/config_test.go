@@ -101,6 +101,36 @@ func TestConfigManager_LoadFromProto_Ollama(t *testing.T) {
 	}
 }
 
+func TestConfigManager_LoadFromProto_Gemini(t *testing.T) {
+	service := NewService()
+	manager := NewConfigManager(service)
+
+	setting := &storepb.InstanceLLMSetting{
+		Provider: storepb.InstanceLLMSetting_GEMINI,
+		GeminiConfig: &storepb.LLMGeminiConfig{
+			ApiKey:       "AIzaSy-test-key-1234567890",
+			DefaultModel: "gemini-1.5-pro",
+		},
+	}
+
+	if err := manager.LoadFromProto(context.Background(), setting); err != nil {
+		t.Errorf("LoadFromProto failed: %v", err)
+	}
+
+	provider := service.GetProvider()
+	if provider == nil || provider.GetType() != ProviderGemini {
+		t.Fatalf("Expected Gemini to be the active provider, got %v", provider)
+	}
+
+	saved := manager.ToProto()
+	if saved.GeminiConfig.GetApiKey() != "AIzaSy-test-key-1234567890" || saved.GeminiConfig.GetDefaultModel() != "gemini-1.5-pro" {
+		t.Errorf("Expected the Gemini config to round-trip, got %+v", saved.GeminiConfig)
+	}
+	if saved.Provider != storepb.InstanceLLMSetting_GEMINI {
+		t.Errorf("Expected GEMINI as the saved provider, got %v", saved.Provider)
+	}
+}
+
 func TestConfigManager_LoadFromProto_MultipleProviders(t *testing.T) {
 	service := NewService()
 	manager := NewConfigManager(service)
@@ -307,6 +337,84 @@ func TestConfigManager_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestConfigManager_RoundTrip_OpenAICompatible(t *testing.T) {
+	service := NewService()
+	manager := NewConfigManager(service)
+
+	setting := &storepb.InstanceLLMSetting{
+		Provider: storepb.InstanceLLMSetting_OPENAI_COMPATIBLE,
+		OpenaiCompatibleConfig: &storepb.LLMOpenAIConfig{
+			BaseUrl:      "http://localhost:8000/v1",
+			DefaultModel: "meta-llama/Llama-3.1-8B-Instruct",
+		},
+	}
+
+	if err := manager.LoadFromProto(context.Background(), setting); err != nil {
+		t.Fatalf("LoadFromProto failed: %v", err)
+	}
+
+	provider := service.GetProvider()
+	if provider == nil || provider.GetType() != ProviderOpenAICompatible {
+		t.Fatalf("Expected OpenAI-compatible provider to be active, got %v", provider)
+	}
+
+	result := manager.ToProto()
+	if result.Provider != storepb.InstanceLLMSetting_OPENAI_COMPATIBLE {
+		t.Errorf("Expected OPENAI_COMPATIBLE provider, got %v", result.Provider)
+	}
+	if result.OpenaiCompatibleConfig == nil {
+		t.Fatal("Expected OpenaiCompatibleConfig to be set")
+	}
+	if result.OpenaiCompatibleConfig.BaseUrl != "http://localhost:8000/v1" {
+		t.Errorf("Expected base URL to round trip, got %s", result.OpenaiCompatibleConfig.BaseUrl)
+	}
+	if result.OpenaiConfig != nil {
+		t.Error("Expected OpenaiConfig to stay unset")
+	}
+}
+
+func TestConfigManager_RoundTrip_Groq(t *testing.T) {
+	service := NewService()
+	manager := NewConfigManager(service)
+
+	setting := &storepb.InstanceLLMSetting{
+		Provider: storepb.InstanceLLMSetting_GROQ,
+		GroqConfig: &storepb.LLMOpenAIConfig{
+			ApiKey:       "gsk_test1234567890abcdefghij",
+			DefaultModel: "llama-3.3-70b-versatile",
+		},
+	}
+
+	if err := manager.LoadFromProto(context.Background(), setting); err != nil {
+		t.Fatalf("LoadFromProto failed: %v", err)
+	}
+
+	provider := service.GetProvider()
+	if provider == nil || provider.GetType() != ProviderGroq {
+		t.Fatalf("Expected Groq provider to be active, got %v", provider)
+	}
+
+	result := manager.ToProto()
+	if result.Provider != storepb.InstanceLLMSetting_GROQ {
+		t.Errorf("Expected GROQ provider, got %v", result.Provider)
+	}
+	if result.GroqConfig == nil {
+		t.Fatal("Expected GroqConfig to be set")
+	}
+	if result.GroqConfig.ApiKey != "gsk_test1234567890abcdefghij" {
+		t.Errorf("Expected API key to round trip, got %s", result.GroqConfig.ApiKey)
+	}
+	if result.GroqConfig.BaseUrl != groqBaseURL {
+		t.Errorf("Expected base URL %s, got %s", groqBaseURL, result.GroqConfig.BaseUrl)
+	}
+	if result.GroqConfig.DefaultModel != "llama-3.3-70b-versatile" {
+		t.Errorf("Expected default model to round trip, got %s", result.GroqConfig.DefaultModel)
+	}
+	if result.OpenaiConfig != nil {
+		t.Error("Expected OpenaiConfig to stay unset")
+	}
+}
+
 func TestProtoProviderToType(t *testing.T) {
 	tests := []struct {
 		proto    storepb.InstanceLLMSetting_LLMProvider
@@ -316,6 +424,8 @@ func TestProtoProviderToType(t *testing.T) {
 		{storepb.InstanceLLMSetting_ANTHROPIC, ProviderAnthropic},
 		{storepb.InstanceLLMSetting_GEMINI, ProviderGemini},
 		{storepb.InstanceLLMSetting_OLLAMA, ProviderOllama},
+		{storepb.InstanceLLMSetting_OPENAI_COMPATIBLE, ProviderOpenAICompatible},
+		{storepb.InstanceLLMSetting_GROQ, ProviderGroq},
 		{storepb.InstanceLLMSetting_LLM_PROVIDER_UNSPECIFIED, ""},
 	}
 
@@ -336,6 +446,8 @@ func TestTypeToProtoProvider(t *testing.T) {
 		{ProviderAnthropic, storepb.InstanceLLMSetting_ANTHROPIC},
 		{ProviderGemini, storepb.InstanceLLMSetting_GEMINI},
 		{ProviderOllama, storepb.InstanceLLMSetting_OLLAMA},
+		{ProviderOpenAICompatible, storepb.InstanceLLMSetting_OPENAI_COMPATIBLE},
+		{ProviderGroq, storepb.InstanceLLMSetting_GROQ},
 		{"unknown", storepb.InstanceLLMSetting_LLM_PROVIDER_UNSPECIFIED},
 	}
 
@@ -415,3 +527,103 @@ func TestConfigManager_TryFallbackProvider_Priority(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigManager_SetFallbackOrder(t *testing.T) {
+	service := NewService()
+	manager := NewConfigManager(service)
+
+	_ = service.RegisterProvider(NewOllamaProvider(&ProviderConfig{
+		Type:       ProviderOllama,
+		OllamaHost: "http://localhost:11434",
+	}))
+	_ = service.RegisterProvider(NewOpenAIProvider(&ProviderConfig{
+		Type:   ProviderOpenAI,
+		APIKey: "test-key",
+	}))
+
+	// Prefer OpenAI over local Ollama
+	if err := manager.SetFallbackOrder([]ProviderType{ProviderOpenAI, ProviderOllama}); err != nil {
+		t.Fatalf("SetFallbackOrder failed: %v", err)
+	}
+
+	// Anthropic is not registered, so the fallback order decides
+	if err := manager.SetActiveProviderWithFallback(context.Background(), ProviderAnthropic); err != nil {
+		t.Fatalf("SetActiveProviderWithFallback failed: %v", err)
+	}
+
+	if active := service.GetProvider(); active == nil || active.GetType() != ProviderOpenAI {
+		t.Errorf("Expected OpenAI to be selected by the custom fallback order, got %v", active)
+	}
+}
+
+func TestConfigManager_SetFallbackOrder_Deregister(t *testing.T) {
+	service := NewService()
+	manager := NewConfigManager(service)
+
+	_ = service.RegisterProvider(NewOllamaProvider(&ProviderConfig{
+		Type:       ProviderOllama,
+		OllamaHost: "http://localhost:11434",
+	}))
+	_ = service.RegisterProvider(NewOpenAIProvider(&ProviderConfig{
+		Type:   ProviderOpenAI,
+		APIKey: "test-key",
+	}))
+	_ = service.RegisterProvider(NewAnthropicProvider(&ProviderConfig{
+		Type:   ProviderAnthropic,
+		APIKey: "test-key",
+	}))
+	if err := service.SetActiveProvider(ProviderAnthropic); err != nil {
+		t.Fatalf("SetActiveProvider failed: %v", err)
+	}
+
+	// The default order would pick Ollama
+	if err := manager.SetFallbackOrder([]ProviderType{ProviderOpenAI, ProviderOllama}); err != nil {
+		t.Fatalf("SetFallbackOrder failed: %v", err)
+	}
+	if err := service.DeregisterProvider(ProviderAnthropic); err != nil {
+		t.Fatalf("DeregisterProvider failed: %v", err)
+	}
+
+	if active := service.GetProvider(); active == nil || active.GetType() != ProviderOpenAI {
+		t.Errorf("Expected deregistration to follow the custom fallback order, got %v", active)
+	}
+}
+
+func TestConfigManager_SetFallbackOrder_Excludes(t *testing.T) {
+	service := NewService()
+	manager := NewConfigManager(service)
+
+	_ = service.RegisterProvider(NewOllamaProvider(&ProviderConfig{
+		Type:       ProviderOllama,
+		OllamaHost: "http://localhost:11434",
+	}))
+
+	// Providers left out of the order are never used as a fallback
+	if err := manager.SetFallbackOrder([]ProviderType{ProviderOpenAI}); err != nil {
+		t.Fatalf("SetFallbackOrder failed: %v", err)
+	}
+	if err := manager.SetActiveProviderWithFallback(context.Background(), ProviderAnthropic); err == nil {
+		t.Error("Expected no fallback when the only configured provider is excluded")
+	}
+}
+
+func TestConfigManager_SetFallbackOrder_Invalid(t *testing.T) {
+	manager := NewConfigManager(NewService())
+
+	tests := []struct {
+		name  string
+		order []ProviderType
+	}{
+		{"empty", nil},
+		{"unknown", []ProviderType{ProviderOpenAI, "bogus"}},
+		{"duplicate", []ProviderType{ProviderOpenAI, ProviderOllama, ProviderOpenAI}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := manager.SetFallbackOrder(tt.order); err == nil {
+				t.Errorf("Expected SetFallbackOrder(%v) to fail", tt.order)
+			}
+		})
+	}
+}
@@ -3,13 +3,16 @@ package llm
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/pbkdf2"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 )
 
 var (
@@ -20,39 +23,153 @@ var (
 	ErrKeyTooShort = errors.New("encryption key too short")
 )
 
+// Ciphertext format versions. Legacy ciphertext has no version byte and is
+// encrypted with a SHA-256-derived key: nonce || ciphertext || tag.
+const (
+	// cryptoVersionPBKDF2 marks ciphertext encrypted with a PBKDF2-derived key:
+	// version || iterations (uint32, big-endian) || salt || nonce || ciphertext || tag.
+	cryptoVersionPBKDF2 byte = 0x01
+
+	// pbkdf2SaltSize is the size of the random PBKDF2 salt in bytes.
+	pbkdf2SaltSize = 16
+
+	// DefaultPBKDF2Iterations is the recommended PBKDF2-HMAC-SHA256 iteration count.
+	DefaultPBKDF2Iterations = 600000
+
+	// maxPBKDF2Iterations bounds the iteration count accepted from stored ciphertext.
+	maxPBKDF2Iterations = 10000000
+
+	// maxDerivedKeys bounds the derived key cache.
+	maxDerivedKeys = 64
+)
+
 // KeyCrypto provides AES-256-GCM encryption for API keys.
 // GCM (Galois/Counter Mode) provides both confidentiality and authenticity.
 type KeyCrypto struct {
+	// key is the legacy SHA-256-derived key, used when PBKDF2 is not enabled
+	// and to decrypt legacy ciphertext.
 	key []byte
+
+	masterKey  string
+	iterations int    // PBKDF2 iterations; 0 means legacy SHA-256 derivation
+	salt       []byte // per-instance PBKDF2 salt used for encryption
+
+	// derived caches PBKDF2 keys by salt and iteration count, since derivation is slow.
+	derivedMu sync.Mutex
+	derived   map[string][]byte
+}
+
+// KeyCryptoOption configures a KeyCrypto.
+type KeyCryptoOption func(*KeyCrypto)
+
+// WithPBKDF2 derives the encryption key with PBKDF2-HMAC-SHA256 using the given
+// iteration count and a random per-instance salt stored with each ciphertext.
+// Values below 1 use DefaultPBKDF2Iterations.
+func WithPBKDF2(iterations int) KeyCryptoOption {
+	return func(kc *KeyCrypto) {
+		if iterations < 1 {
+			iterations = DefaultPBKDF2Iterations
+		}
+		kc.iterations = iterations
+	}
 }
 
 // NewKeyCrypto creates a new KeyCrypto instance with the given key.
-// The key is hashed with SHA-256 to ensure it's exactly 32 bytes for AES-256.
-func NewKeyCrypto(masterKey string) (*KeyCrypto, error) {
+// By default the key is hashed with SHA-256 to ensure it's exactly 32 bytes for
+// AES-256; use WithPBKDF2 for a salted, slow derivation. Ciphertext in either
+// format can always be decrypted.
+func NewKeyCrypto(masterKey string, opts ...KeyCryptoOption) (*KeyCrypto, error) {
 	if len(masterKey) < 16 {
 		return nil, ErrKeyTooShort
 	}
 
 	// Hash the master key to get exactly 32 bytes for AES-256
 	hash := sha256.Sum256([]byte(masterKey))
-	return &KeyCrypto{key: hash[:]}, nil
+	kc := &KeyCrypto{
+		key:       hash[:],
+		masterKey: masterKey,
+		derived:   make(map[string][]byte),
+	}
+
+	for _, opt := range opts {
+		opt(kc)
+	}
+
+	if kc.iterations > 0 {
+		kc.salt = make([]byte, pbkdf2SaltSize)
+		if _, err := io.ReadFull(rand.Reader, kc.salt); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+		// Derive once up front so the first Encrypt isn't slow
+		if _, err := kc.deriveKey(kc.salt, kc.iterations); err != nil {
+			return nil, err
+		}
+	}
+
+	return kc, nil
+}
+
+// deriveKey returns the PBKDF2 key for the given salt and iteration count.
+func (kc *KeyCrypto) deriveKey(salt []byte, iterations int) ([]byte, error) {
+	cacheKey := fmt.Sprintf("%d:%x", iterations, salt)
+
+	kc.derivedMu.Lock()
+	defer kc.derivedMu.Unlock()
+
+	if key, ok := kc.derived[cacheKey]; ok {
+		return key, nil
+	}
+
+	key, err := pbkdf2.Key(sha256.New, kc.masterKey, salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	if len(kc.derived) < maxDerivedKeys {
+		kc.derived[cacheKey] = key
+	}
+	return key, nil
+}
+
+// newGCM creates an AES-256-GCM AEAD for the given key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
 }
 
 // Encrypt encrypts plaintext using AES-256-GCM.
-// Returns base64-encoded ciphertext (nonce || ciphertext || tag).
+// Returns base64-encoded ciphertext (nonce || ciphertext || tag), prefixed with
+// the version, iteration count, and salt when PBKDF2 is enabled.
 func (kc *KeyCrypto) Encrypt(plaintext string) (string, error) {
 	if plaintext == "" {
 		return "", nil
 	}
 
-	block, err := aes.NewCipher(kc.key)
-	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
+	key := kc.key
+	var prefix []byte
+	if kc.iterations > 0 {
+		derived, err := kc.deriveKey(kc.salt, kc.iterations)
+		if err != nil {
+			return "", err
+		}
+		key = derived
+
+		prefix = make([]byte, 0, 5+len(kc.salt))
+		prefix = append(prefix, cryptoVersionPBKDF2)
+		prefix = binary.BigEndian.AppendUint32(prefix, uint32(kc.iterations))
+		prefix = append(prefix, kc.salt...)
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	gcm, err := newGCM(key)
 	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
+		return "", err
 	}
 
 	// Create a unique nonce for this encryption
@@ -61,14 +178,15 @@ func (kc *KeyCrypto) Encrypt(plaintext string) (string, error) {
 		return "", fmt.Errorf("failed to generate nonce: %w", err)
 	}
 
-	// Encrypt and prepend nonce
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	// Encrypt and prepend nonce (and the PBKDF2 header, if any)
+	ciphertext := gcm.Seal(append(prefix, nonce...), nonce, []byte(plaintext), nil)
 
 	// Encode as base64 for safe storage
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
 // Decrypt decrypts base64-encoded ciphertext using AES-256-GCM.
+// Both legacy (SHA-256) and PBKDF2 ciphertext formats are accepted.
 func (kc *KeyCrypto) Decrypt(ciphertext string) (string, error) {
 	if ciphertext == "" {
 		return "", nil
@@ -79,14 +197,48 @@ func (kc *KeyCrypto) Decrypt(ciphertext string) (string, error) {
 		return "", fmt.Errorf("failed to decode ciphertext: %w", err)
 	}
 
-	block, err := aes.NewCipher(kc.key)
+	// A legacy nonce may start with the version byte by chance, so fall back to
+	// the legacy format if the versioned one doesn't authenticate.
+	if len(data) > 0 && data[0] == cryptoVersionPBKDF2 {
+		plaintext, err := kc.decryptPBKDF2(data)
+		if err == nil {
+			return plaintext, nil
+		}
+		if legacy, legacyErr := kc.open(kc.key, data); legacyErr == nil {
+			return legacy, nil
+		}
+		return "", err
+	}
+
+	return kc.open(kc.key, data)
+}
+
+// decryptPBKDF2 decrypts the versioned PBKDF2 format.
+func (kc *KeyCrypto) decryptPBKDF2(data []byte) (string, error) {
+	headerSize := 5 + pbkdf2SaltSize
+	if len(data) < headerSize {
+		return "", ErrInvalidCiphertext
+	}
+
+	iterations := int(binary.BigEndian.Uint32(data[1:5]))
+	if iterations < 1 || iterations > maxPBKDF2Iterations {
+		return "", ErrInvalidCiphertext
+	}
+	salt := data[5:headerSize]
+
+	key, err := kc.deriveKey(salt, iterations)
 	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
+		return "", err
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	return kc.open(key, data[headerSize:])
+}
+
+// open decrypts nonce || ciphertext || tag with the given key.
+func (kc *KeyCrypto) open(key, data []byte) (string, error) {
+	gcm, err := newGCM(key)
 	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
+		return "", err
 	}
 
 	nonceSize := gcm.NonceSize()
@@ -106,21 +258,57 @@ func (kc *KeyCrypto) Decrypt(ciphertext string) (string, error) {
 // MaskAPIKey masks an API key for display, showing only the last 4 characters.
 // Returns "****XXXX" format where XXXX is the last 4 chars.
 func MaskAPIKey(apiKey string) string {
+	return MaskAPIKeyWithOptions(apiKey, MaskOptions{RevealSuffix: 4})
+}
+
+// collapsedMask is the fixed-width middle used when MaskOptions.Collapse is set.
+const collapsedMask = "****"
+
+// MaskOptions controls how MaskAPIKeyWithOptions masks a key.
+type MaskOptions struct {
+	// RevealPrefix is the number of leading characters to show.
+	RevealPrefix int
+
+	// RevealSuffix is the number of trailing characters to show.
+	RevealSuffix int
+
+	// Collapse replaces the masked middle with a fixed-width "****" so the
+	// key length is not leaked.
+	Collapse bool
+}
+
+// MaskAPIKeyWithOptions masks an API key for display, revealing the configured
+// number of leading and trailing characters. Keys too short to reveal anything
+// without exposing most of the key are fully masked.
+func MaskAPIKeyWithOptions(apiKey string, opts MaskOptions) string {
 	if apiKey == "" {
 		return ""
 	}
 
-	if len(apiKey) <= 4 {
+	prefix := max(opts.RevealPrefix, 0)
+	suffix := max(opts.RevealSuffix, 0)
+	if prefix+suffix >= len(apiKey) {
+		if opts.Collapse {
+			return collapsedMask
+		}
 		return strings.Repeat("*", len(apiKey))
 	}
 
-	lastFour := apiKey[len(apiKey)-4:]
-	return strings.Repeat("*", len(apiKey)-4) + lastFour
+	middle := collapsedMask
+	if !opts.Collapse {
+		middle = strings.Repeat("*", len(apiKey)-prefix-suffix)
+	}
+	return apiKey[:prefix] + middle + apiKey[len(apiKey)-suffix:]
 }
 
 // ValidateAPIKeyFormat performs basic format validation for API keys.
 // Different providers have different key formats.
 func ValidateAPIKeyFormat(providerType ProviderType, apiKey string) error {
+	// OpenAI-compatible servers accept arbitrary keys, or none at all
+	if providerType == ProviderOpenAICompatible {
+		return nil
+	}
+
 	if apiKey == "" {
 		return errors.New("API key cannot be empty")
 	}
@@ -134,6 +322,14 @@ func ValidateAPIKeyFormat(providerType ProviderType, apiKey string) error {
 		if len(apiKey) < 20 {
 			return errors.New("OpenAI API key appears too short")
 		}
+	case ProviderGroq:
+		// Groq keys start with "gsk_"
+		if !strings.HasPrefix(apiKey, "gsk_") {
+			return errors.New("Groq API key should start with 'gsk_'")
+		}
+		if len(apiKey) < 20 {
+			return errors.New("Groq API key appears too short")
+		}
 	case ProviderAnthropic:
 		// Anthropic keys typically start with "sk-ant-"
 		if !strings.HasPrefix(apiKey, "sk-ant-") {
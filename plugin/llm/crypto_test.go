@@ -1,6 +1,9 @@
 package llm
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
 	"strings"
 	"testing"
 )
@@ -172,6 +175,91 @@ func TestKeyCrypto_DecryptWithWrongKey(t *testing.T) {
 	}
 }
 
+func TestKeyCrypto_PBKDF2RoundTrip(t *testing.T) {
+	kc, err := NewKeyCrypto("test-master-key-for-encryption", WithPBKDF2(1000))
+	if err != nil {
+		t.Fatalf("NewKeyCrypto() error = %v", err)
+	}
+
+	ciphertext, err := kc.Encrypt("sk-test-api-key")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	data, _ := base64.StdEncoding.DecodeString(ciphertext)
+	if data[0] != cryptoVersionPBKDF2 {
+		t.Errorf("Expected version byte %#x, got %#x", cryptoVersionPBKDF2, data[0])
+	}
+	if !bytes.Equal(data[5:5+pbkdf2SaltSize], kc.salt) {
+		t.Error("Expected instance salt to be stored with the ciphertext")
+	}
+
+	decrypted, err := kc.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if decrypted != "sk-test-api-key" {
+		t.Errorf("Decrypt() = %v, want sk-test-api-key", decrypted)
+	}
+
+	// Another instance with the same master key has a different salt but can still decrypt
+	other, _ := NewKeyCrypto("test-master-key-for-encryption", WithPBKDF2(1000))
+	if bytes.Equal(other.salt, kc.salt) {
+		t.Error("Expected per-instance salts to differ")
+	}
+	if decrypted, err := other.Decrypt(ciphertext); err != nil || decrypted != "sk-test-api-key" {
+		t.Errorf("Decrypt() with other instance = %v, %v", decrypted, err)
+	}
+}
+
+func TestKeyCrypto_LegacyCompatibility(t *testing.T) {
+	legacy, _ := NewKeyCrypto("test-master-key-for-encryption")
+	upgraded, _ := NewKeyCrypto("test-master-key-for-encryption", WithPBKDF2(1000))
+
+	// Legacy SHA-256 ciphertext still decrypts after enabling PBKDF2
+	for i := 0; i < 50; i++ {
+		ciphertext, err := legacy.Encrypt("sk-legacy-key")
+		if err != nil {
+			t.Fatalf("Encrypt() error = %v", err)
+		}
+		decrypted, err := upgraded.Decrypt(ciphertext)
+		if err != nil || decrypted != "sk-legacy-key" {
+			t.Fatalf("Decrypt() of legacy ciphertext = %v, %v", decrypted, err)
+		}
+	}
+
+	// PBKDF2 ciphertext decrypts without the option, since the header is self-describing
+	ciphertext, _ := upgraded.Encrypt("sk-new-key")
+	decrypted, err := legacy.Decrypt(ciphertext)
+	if err != nil || decrypted != "sk-new-key" {
+		t.Errorf("Decrypt() of PBKDF2 ciphertext = %v, %v", decrypted, err)
+	}
+}
+
+func TestKeyCrypto_PBKDF2WrongSalt(t *testing.T) {
+	kc, _ := NewKeyCrypto("test-master-key-for-encryption", WithPBKDF2(1000))
+
+	ciphertext, _ := kc.Encrypt("sk-test-api-key")
+	data, _ := base64.StdEncoding.DecodeString(ciphertext)
+	data[5] ^= 0xFF // Corrupt the first salt byte
+
+	if _, err := kc.Decrypt(base64.StdEncoding.EncodeToString(data)); err == nil {
+		t.Error("Decrypt() should fail with a wrong salt")
+	}
+}
+
+func TestKeyCrypto_PBKDF2RejectsExcessiveIterations(t *testing.T) {
+	kc, _ := NewKeyCrypto("test-master-key-for-encryption", WithPBKDF2(1000))
+
+	ciphertext, _ := kc.Encrypt("sk-test-api-key")
+	data, _ := base64.StdEncoding.DecodeString(ciphertext)
+	binary.BigEndian.PutUint32(data[1:5], maxPBKDF2Iterations+1)
+
+	if _, err := kc.Decrypt(base64.StdEncoding.EncodeToString(data)); err == nil {
+		t.Error("Decrypt() should reject an excessive iteration count")
+	}
+}
+
 func TestKeyCrypto_DecryptInvalidCiphertext(t *testing.T) {
 	kc, _ := NewKeyCrypto("test-master-key-for-encryption")
 
@@ -263,6 +351,61 @@ func TestMaskAPIKey(t *testing.T) {
 	}
 }
 
+func TestMaskAPIKeyWithOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		apiKey   string
+		opts     MaskOptions
+		expected string
+	}{
+		{
+			name:     "first 3 and last 4",
+			apiKey:   "sk-test1234567890abcdef",
+			opts:     MaskOptions{RevealPrefix: 3, RevealSuffix: 4},
+			expected: "sk-****************cdef",
+		},
+		{
+			name:     "collapsed middle",
+			apiKey:   "sk-ant-REDACTED",
+			opts:     MaskOptions{RevealPrefix: 3, RevealSuffix: 4, Collapse: true},
+			expected: "sk-****mnop",
+		},
+		{
+			name:     "collapsed suffix only",
+			apiKey:   "sk-test1234567890abcdef",
+			opts:     MaskOptions{RevealSuffix: 4, Collapse: true},
+			expected: "****cdef",
+		},
+		{
+			name:     "key shorter than revealed parts",
+			apiKey:   "sk-1234",
+			opts:     MaskOptions{RevealPrefix: 3, RevealSuffix: 4},
+			expected: "*******",
+		},
+		{
+			name:     "short key collapsed hides length",
+			apiKey:   "sk-1234",
+			opts:     MaskOptions{RevealPrefix: 3, RevealSuffix: 4, Collapse: true},
+			expected: "****",
+		},
+		{
+			name:     "empty key",
+			apiKey:   "",
+			opts:     MaskOptions{RevealPrefix: 3, RevealSuffix: 4, Collapse: true},
+			expected: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := MaskAPIKeyWithOptions(tc.apiKey, tc.opts)
+			if result != tc.expected {
+				t.Errorf("MaskAPIKeyWithOptions(%q, %+v) = %q, want %q", tc.apiKey, tc.opts, result, tc.expected)
+			}
+		})
+	}
+}
+
 func TestValidateAPIKeyFormat(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -293,6 +436,42 @@ func TestValidateAPIKeyFormat(t *testing.T) {
 			errContains:  "too short",
 		},
 
+		// OpenAI-compatible tests
+		{
+			name:         "OpenAI-compatible arbitrary key",
+			providerType: ProviderOpenAICompatible,
+			apiKey:       "local",
+			wantErr:      false,
+		},
+		{
+			name:         "OpenAI-compatible without key",
+			providerType: ProviderOpenAICompatible,
+			apiKey:       "",
+			wantErr:      false,
+		},
+
+		// Groq tests
+		{
+			name:         "valid Groq key",
+			providerType: ProviderGroq,
+			apiKey:       "gsk_1234567890abcdefghijklmnop",
+			wantErr:      false,
+		},
+		{
+			name:         "Groq key with OpenAI prefix",
+			providerType: ProviderGroq,
+			apiKey:       "sk-1234567890abcdefghijklmnop",
+			wantErr:      true,
+			errContains:  "should start with 'gsk_'",
+		},
+		{
+			name:         "Groq key too short",
+			providerType: ProviderGroq,
+			apiKey:       "gsk_short",
+			wantErr:      true,
+			errContains:  "too short",
+		},
+
 		// Anthropic tests
 		{
 			name:         "valid Anthropic key",
@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"fmt"
+	"math"
+)
+
+// reduceDimensions truncates an embedding to dims dimensions and L2
+// re-normalizes it, for providers without server-side dimension reduction.
+// This matches how Matryoshka-trained models (e.g., nomic-embed-text) are
+// meant to be shortened. dims <= 0 returns the vector unchanged.
+func reduceDimensions(vec []float32, dims int) ([]float32, error) {
+	if dims <= 0 || dims == len(vec) {
+		return vec, nil
+	}
+	if dims > len(vec) {
+		return nil, fmt.Errorf("requested %d dimensions exceeds native embedding size %d", dims, len(vec))
+	}
+
+	return l2Normalize(vec[:dims]), nil
+}
+
+// DimensionAdapter fits an embedding to target dimensions. It must not modify vec.
+type DimensionAdapter func(vec []float32, target int) []float32
+
+// PadOrTruncate is the default DimensionAdapter: it zero-fills vectors shorter
+// than target and truncates longer ones.
+func PadOrTruncate(vec []float32, target int) []float32 {
+	out := make([]float32, target)
+	copy(out, vec)
+	return out
+}
+
+// adaptEmbeddings returns a copy of resp with every vector fitted to target
+// dimensions by adapter. Vectors already of that length are kept as-is, and a
+// target <= 0 returns resp unchanged.
+func adaptEmbeddings(resp *EmbeddingResponse, target int, adapter DimensionAdapter) *EmbeddingResponse {
+	if target <= 0 {
+		return resp
+	}
+
+	out := *resp
+	out.Embeddings = make([][]float32, len(resp.Embeddings))
+	for i, vec := range resp.Embeddings {
+		if len(vec) == target {
+			out.Embeddings[i] = vec
+			continue
+		}
+		out.Embeddings[i] = adapter(vec, target)
+	}
+	return &out
+}
+
+// finishEmbeddings returns a copy of resp with Dimension set and, if normalize
+// is true, every vector scaled to unit length. The provider's vectors are not modified.
+func finishEmbeddings(resp *EmbeddingResponse, normalize bool) *EmbeddingResponse {
+	out := *resp
+	if len(out.Embeddings) > 0 {
+		out.Dimension = len(out.Embeddings[0])
+	}
+	if normalize {
+		out.Embeddings = make([][]float32, len(resp.Embeddings))
+		for i, vec := range resp.Embeddings {
+			out.Embeddings[i] = l2Normalize(vec)
+		}
+	}
+	return &out
+}
+
+// l2Normalize returns a copy of vec scaled to unit length.
+// A zero vector is returned unchanged.
+func l2Normalize(vec []float32) []float32 {
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+
+	out := make([]float32, len(vec))
+	if sum == 0 {
+		copy(out, vec)
+		return out
+	}
+
+	norm := math.Sqrt(sum)
+	for i, v := range vec {
+		out[i] = float32(float64(v) / norm)
+	}
+	return out
+}
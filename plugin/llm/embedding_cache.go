@@ -0,0 +1,248 @@
+package llm
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EmbedCacheStats reports the state of the service embedding cache.
+type EmbedCacheStats struct {
+	// Hits is the number of inputs served from the cache.
+	Hits int64 `json:"hits"`
+
+	// Misses is the number of inputs not found in the cache (including expired entries).
+	Misses int64 `json:"misses"`
+
+	// Evictions is the number of entries dropped to stay within the size limit.
+	Evictions int64 `json:"evictions"`
+
+	// Size is the current number of cached vectors.
+	Size int `json:"size"`
+}
+
+// WithEmbeddingCache enables an in-memory cache of embedding vectors keyed by
+// a hash of the provider, model, dimensions and exact input text. Entries
+// expire after ttl (0 means never) and the least recently used entries are
+// evicted beyond maxSize. A non-positive maxSize leaves the cache disabled.
+func WithEmbeddingCache(ttl time.Duration, maxSize int) ServiceOption {
+	return func(s *service) {
+		if maxSize <= 0 {
+			s.embedCache = nil
+			return
+		}
+		s.embedCache = newEmbeddingCache(ttl, maxSize)
+	}
+}
+
+// cachedEmbedding is a single cached vector.
+type cachedEmbedding struct {
+	key       string
+	vector    []float32
+	model     string
+	createdAt time.Time
+}
+
+// embeddingCache is an LRU cache of embedding vectors (most recently used at the front).
+type embeddingCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxSize int
+	entries map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+func newEmbeddingCache(ttl time.Duration, maxSize int) *embeddingCache {
+	return &embeddingCache{
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// embeddingCacheKey hashes everything that determines the resulting vector.
+func embeddingCacheKey(providerType ProviderType, model string, dimensions int, text string) string {
+	h := sha256.New()
+	h.Write([]byte(providerType))
+	h.Write([]byte{0})
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	var dims [8]byte
+	binary.BigEndian.PutUint64(dims[:], uint64(dimensions))
+	h.Write(dims[:])
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// get returns a copy of the cached vector and the model that produced it.
+func (c *embeddingCache) get(key string) ([]float32, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, "", false
+	}
+
+	entry := elem.Value.(*cachedEmbedding)
+	if c.ttl > 0 && c.now().Sub(entry.createdAt) > c.ttl {
+		c.remove(elem)
+		c.misses++
+		return nil, "", false
+	}
+
+	c.order.MoveToFront(elem)
+	c.hits++
+
+	vector := make([]float32, len(entry.vector))
+	copy(vector, entry.vector)
+	return vector, entry.model, true
+}
+
+// put stores a copy of vector under key, evicting the least recently used entries if full.
+func (c *embeddingCache) put(key string, vector []float32, model string) {
+	stored := make([]float32, len(vector))
+	copy(stored, vector)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cachedEmbedding{
+		key:       key,
+		vector:    stored,
+		model:     model,
+		createdAt: c.now(),
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	for len(c.entries) >= c.maxSize && c.order.Len() > 0 {
+		c.remove(c.order.Back())
+		c.evictions++
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+}
+
+// remove drops an entry from both the LRU list and the index. Callers hold mu.
+func (c *embeddingCache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*cachedEmbedding).key)
+}
+
+func (c *embeddingCache) stats() EmbedCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return EmbedCacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Size:      len(c.entries),
+	}
+}
+
+// EmbedCacheStats returns the embedding cache counters (zero value if the cache is disabled).
+func (s *service) EmbedCacheStats() EmbedCacheStats {
+	if s.embedCache == nil {
+		return EmbedCacheStats{}
+	}
+	return s.embedCache.stats()
+}
+
+// embeddingModeler is implemented by providers with a default embedding model
+// separate from their default chat model.
+type embeddingModeler interface {
+	defaultEmbeddingModel() string
+}
+
+// embeddingModel returns the model the provider embeds with when the request
+// names none, falling back to the default model.
+func embeddingModel(provider Provider) string {
+	if m, ok := provider.(embeddingModeler); ok {
+		return m.defaultEmbeddingModel()
+	}
+	return provider.GetDefaultModel()
+}
+
+// embedCached serves req from the embedding cache where possible and sends
+// only the missing inputs to the provider. Fresh vectors are cached under the
+// requested model, or the provider's embedding model if none is requested, so
+// that later identical requests hit and a changed embedding model misses.
+func (s *service) embedCached(ctx context.Context, provider Provider, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = embeddingModel(provider)
+	}
+
+	keys := make([]string, len(req.Input))
+	vectors := make([][]float32, len(req.Input))
+	var missing []int
+	var cachedModel string
+	for i, text := range req.Input {
+		keys[i] = embeddingCacheKey(provider.GetType(), model, req.Dimensions, text)
+		if vector, m, ok := s.embedCache.get(keys[i]); ok {
+			vectors[i] = vector
+			cachedModel = m
+			continue
+		}
+		missing = append(missing, i)
+	}
+
+	if len(missing) == 0 {
+		return &EmbeddingResponse{Embeddings: vectors, Model: cachedModel}, nil
+	}
+
+	if err := s.acquire(ctx, provider); err != nil {
+		return nil, err
+	}
+
+	partial := *req
+	partial.Input = make([]string, len(missing))
+	for j, i := range missing {
+		partial.Input[j] = req.Input[i]
+	}
+
+	start := time.Now()
+	resp, err := provider.Embed(ctx, &partial)
+	s.observe(provider, OperationEmbed, start, err)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, nil
+	}
+	s.recordUsage(resp.Model, resp.Usage)
+
+	if len(resp.Embeddings) != len(missing) {
+		return nil, fmt.Errorf("%s embeddings: expected %d vectors, got %d", provider.GetName(), len(missing), len(resp.Embeddings))
+	}
+
+	for j, i := range missing {
+		vectors[i] = resp.Embeddings[j]
+		s.embedCache.put(keys[i], resp.Embeddings[j], resp.Model)
+	}
+
+	return &EmbeddingResponse{
+		Embeddings: vectors,
+		Model:      resp.Model,
+		Usage:      resp.Usage,
+	}, nil
+}
@@ -0,0 +1,185 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newEmbedCacheTestService(t *testing.T, ttl time.Duration, maxSize int) (*service, *mockProvider) {
+	t.Helper()
+
+	svc := NewService(WithEmbeddingCache(ttl, maxSize)).(*service)
+	provider := &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		defaultModel: "text-embedding-3-small",
+		embedResp: &EmbeddingResponse{
+			Embeddings: [][]float32{{0.1, 0.2, 0.3}},
+			Model:      "text-embedding-3-small",
+		},
+	}
+	if err := svc.RegisterProvider(provider); err != nil {
+		t.Fatalf("RegisterProvider() error: %v", err)
+	}
+	return svc, provider
+}
+
+func TestEmbeddingCacheHit(t *testing.T) {
+	svc, provider := newEmbedCacheTestService(t, time.Minute, 10)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		resp, err := svc.Embed(ctx, &EmbeddingRequest{Input: []string{"hello"}})
+		if err != nil {
+			t.Fatalf("Embed() error: %v", err)
+		}
+		if len(resp.Embeddings) != 1 || len(resp.Embeddings[0]) != 3 {
+			t.Fatalf("Embed() = %+v, want one 3-dim vector", resp.Embeddings)
+		}
+		if resp.Model != "text-embedding-3-small" {
+			t.Errorf("Model = %q, want text-embedding-3-small", resp.Model)
+		}
+	}
+
+	if provider.embedCalls != 1 {
+		t.Errorf("provider Embed calls = %d, want 1", provider.embedCalls)
+	}
+	stats := svc.EmbedCacheStats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Size != 1 {
+		t.Errorf("EmbedCacheStats() = %+v, want 1 hit, 1 miss, size 1", stats)
+	}
+}
+
+func TestEmbeddingCacheMisses(t *testing.T) {
+	svc, provider := newEmbedCacheTestService(t, time.Minute, 10)
+	ctx := context.Background()
+
+	requests := []*EmbeddingRequest{
+		{Input: []string{"hello"}},
+		{Input: []string{"world"}},
+		{Input: []string{"hello"}, Model: "text-embedding-3-large"},
+		{Input: []string{"hello"}, Dimensions: 256},
+	}
+	for _, req := range requests {
+		if _, err := svc.Embed(ctx, req); err != nil {
+			t.Fatalf("Embed(%+v) error: %v", req, err)
+		}
+	}
+
+	if provider.embedCalls != len(requests) {
+		t.Errorf("provider Embed calls = %d, want %d", provider.embedCalls, len(requests))
+	}
+	if stats := svc.EmbedCacheStats(); stats.Hits != 0 || stats.Misses != int64(len(requests)) {
+		t.Errorf("EmbedCacheStats() = %+v, want 0 hits and %d misses", stats, len(requests))
+	}
+}
+
+func TestEmbeddingCachePartialHit(t *testing.T) {
+	svc, provider := newEmbedCacheTestService(t, time.Minute, 10)
+	ctx := context.Background()
+
+	if _, err := svc.Embed(ctx, &EmbeddingRequest{Input: []string{"hello"}}); err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+
+	resp, err := svc.Embed(ctx, &EmbeddingRequest{Input: []string{"hello", "world"}})
+	if err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+	if len(resp.Embeddings) != 2 {
+		t.Fatalf("Embeddings = %d, want 2", len(resp.Embeddings))
+	}
+	if got := provider.lastEmbedReq.Input; len(got) != 1 || got[0] != "world" {
+		t.Errorf("provider Input = %v, want only the uncached input", got)
+	}
+}
+
+func TestEmbeddingCacheExpiryAndEviction(t *testing.T) {
+	svc, provider := newEmbedCacheTestService(t, time.Minute, 1)
+	ctx := context.Background()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc.embedCache.now = func() time.Time { return now }
+
+	embed := func(text string) {
+		t.Helper()
+		if _, err := svc.Embed(ctx, &EmbeddingRequest{Input: []string{text}}); err != nil {
+			t.Fatalf("Embed(%q) error: %v", text, err)
+		}
+	}
+
+	embed("hello")
+	now = now.Add(2 * time.Minute)
+	embed("hello")
+	if provider.embedCalls != 2 {
+		t.Errorf("provider Embed calls after expiry = %d, want 2", provider.embedCalls)
+	}
+
+	embed("world")
+	embed("hello")
+	if provider.embedCalls != 4 {
+		t.Errorf("provider Embed calls after eviction = %d, want 4", provider.embedCalls)
+	}
+	if stats := svc.EmbedCacheStats(); stats.Evictions != 2 || stats.Size != 1 {
+		t.Errorf("EmbedCacheStats() = %+v, want 2 evictions and size 1", stats)
+	}
+}
+
+func TestEmbeddingCacheMissesOnEmbeddingModelChange(t *testing.T) {
+	var models []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIEmbeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		models = append(models, req.Model)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data":[{"index":0,"embedding":[0.1,0.2]}],"model":%q}`, req.Model)
+	}))
+	defer server.Close()
+
+	svc := NewService(WithEmbeddingCache(time.Minute, 10))
+	ctx := context.Background()
+
+	// Only the embedding model changes; the chat model stays the same
+	for _, embeddingModel := range []string{"text-embedding-3-small", "text-embedding-3-large"} {
+		provider := NewOpenAIProvider(&ProviderConfig{
+			Type:           ProviderOpenAI,
+			APIKey:         "test-key",
+			BaseURL:        server.URL,
+			EmbeddingModel: embeddingModel,
+		})
+		if err := svc.RegisterProvider(provider); err != nil {
+			t.Fatalf("RegisterProvider() error: %v", err)
+		}
+
+		resp, err := svc.Embed(ctx, &EmbeddingRequest{Input: []string{"hello"}})
+		if err != nil {
+			t.Fatalf("Embed() error: %v", err)
+		}
+		if resp.Model != embeddingModel {
+			t.Errorf("Model = %q, want %q", resp.Model, embeddingModel)
+		}
+	}
+
+	if len(models) != 2 || models[1] != "text-embedding-3-large" {
+		t.Errorf("provider embedding models = %v, want a second call with text-embedding-3-large", models)
+	}
+	if stats := svc.EmbedCacheStats(); stats.Hits != 0 || stats.Misses != 2 {
+		t.Errorf("EmbedCacheStats() = %+v, want 0 hits and 2 misses", stats)
+	}
+}
+
+func TestEmbeddingCacheDisabled(t *testing.T) {
+	svc := NewService()
+	if stats := svc.EmbedCacheStats(); stats != (EmbedCacheStats{}) {
+		t.Errorf("EmbedCacheStats() = %+v, want zero value", stats)
+	}
+}
@@ -0,0 +1,394 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	storepb "github.com/usememos/memos/proto/gen/store"
+)
+
+const (
+	geminiBaseURL      = "https://generativelanguage.googleapis.com/v1beta"
+	geminiDefaultModel = "gemini-1.5-flash"
+
+	// geminiModelsPageSize is the page size requested from /models (the API maximum)
+	geminiModelsPageSize = 1000
+)
+
+// GeminiProvider implements the Provider interface for the Google AI Gemini API.
+type GeminiProvider struct {
+	*BaseProvider
+	apiKey       string
+	baseURL      string
+	defaultModel string
+}
+
+// NewGeminiProvider creates a new Gemini provider.
+func NewGeminiProvider(config *ProviderConfig) *GeminiProvider {
+	baseURL := geminiBaseURL
+	defaultModel := geminiDefaultModel
+
+	if config.BaseURL != "" {
+		baseURL = config.BaseURL
+	}
+	if config.DefaultModel != "" {
+		defaultModel = config.DefaultModel
+	}
+
+	return &GeminiProvider{
+		BaseProvider: NewBaseProvider(config),
+		apiKey:       config.APIKey,
+		baseURL:      baseURL,
+		defaultModel: defaultModel,
+	}
+}
+
+// NewGeminiProviderFromProto creates a new Gemini provider from proto config.
+func NewGeminiProviderFromProto(pbConfig *storepb.LLMGeminiConfig) *GeminiProvider {
+	config := &ProviderConfig{
+		Type:         ProviderGemini,
+		APIKey:       pbConfig.GetApiKey(),
+		DefaultModel: pbConfig.GetDefaultModel(),
+	}
+	return NewGeminiProvider(config)
+}
+
+// GetType returns the provider type.
+func (p *GeminiProvider) GetType() ProviderType {
+	return ProviderGemini
+}
+
+// GetName returns the display name.
+func (p *GeminiProvider) GetName() string {
+	return "Gemini"
+}
+
+// IsConfigured checks if the provider is properly configured.
+func (p *GeminiProvider) IsConfigured(ctx context.Context) bool {
+	return p.apiKey != ""
+}
+
+// GetDefaultModel returns the default model.
+func (p *GeminiProvider) GetDefaultModel() string {
+	return p.defaultModel
+}
+
+// GetAvailableModels returns the models that support generateContent.
+// Results are cached for ProviderConfig.ModelsCacheTTL.
+func (p *GeminiProvider) GetAvailableModels(ctx context.Context) ([]string, error) {
+	return p.CachedModels(ctx, false, p.fetchAvailableModels)
+}
+
+// RefreshAvailableModels refetches the model list, bypassing the cache.
+func (p *GeminiProvider) RefreshAvailableModels(ctx context.Context) ([]string, error) {
+	return p.CachedModels(ctx, true, p.fetchAvailableModels)
+}
+
+// fetchAvailableModels queries the API for the available models.
+func (p *GeminiProvider) fetchAvailableModels(ctx context.Context) ([]string, error) {
+	if !p.IsConfigured(ctx) {
+		return nil, ErrProviderNotConfigured
+	}
+
+	url := fmt.Sprintf("%s/models?pageSize=%d", p.baseURL, geminiModelsPageSize)
+
+	respBody, status, err := p.doRequest(ctx, http.MethodGet, url, nil, p.headers())
+	if err != nil {
+		return nil, geminiAuthError(err)
+	}
+
+	var resp geminiModelsResponse
+	if err := p.decodeJSON(respBody, status, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse models response: %w", err)
+	}
+
+	models := make([]string, 0, len(resp.Models))
+	for _, m := range resp.Models {
+		for _, method := range m.SupportedGenerationMethods {
+			if method == "generateContent" {
+				models = append(models, strings.TrimPrefix(m.Name, "models/"))
+				break
+			}
+		}
+	}
+
+	return models, nil
+}
+
+// GetModelInfo returns the available models annotated with known limits.
+func (p *GeminiProvider) GetModelInfo(ctx context.Context) ([]ModelInfo, error) {
+	return p.DefaultGetModelInfo(ctx, p)
+}
+
+// Complete performs chat completion with generateContent.
+// A ResponseFormat is sent as generationConfig.responseSchema with an
+// application/json response MIME type.
+func (p *GeminiProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	if !p.IsConfigured(ctx) {
+		return nil, ErrProviderNotConfigured
+	}
+	ctx = withRequestMetadata(ctx, req.Metadata)
+
+	ctx, cancel := withRequestTimeout(ctx, req.TimeoutSeconds)
+	defer cancel()
+
+	if err := checkVision(p, req.Messages); err != nil {
+		return nil, err
+	}
+
+	model := req.Model
+	if model == "" {
+		model = p.defaultModel
+	}
+
+	req, err := p.checkSampling(req, maxTemperatureGemini)
+	if err != nil {
+		return nil, err
+	}
+	req, truncated, err := truncateToContext(req, contextWindow(model))
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/models/%s:generateContent", p.baseURL, url.PathEscape(model))
+
+	respBody, status, err := p.doRequest(ctx, http.MethodPost, endpoint, buildGeminiRequest(req), p.headers())
+	if err != nil {
+		return nil, geminiAuthError(err)
+	}
+
+	var resp geminiGenerateResponse
+	if err := p.decodeJSON(respBody, status, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse completion response: %w", err)
+	}
+
+	if len(resp.Candidates) == 0 {
+		return nil, fmt.Errorf("no completion candidates returned")
+	}
+
+	candidate := resp.Candidates[0]
+	var content string
+	for _, part := range candidate.Content.Parts {
+		content += part.Text
+	}
+
+	responseModel := resp.ModelVersion
+	if responseModel == "" {
+		responseModel = model
+	}
+
+	usage := resp.UsageMetadata
+	return p.captureResponse(&CompletionResponse{
+		Content: content,
+		Model:   responseModel,
+		Usage: &TokenUsage{
+			PromptTokens:     usage.PromptTokenCount,
+			CompletionTokens: usage.CandidatesTokenCount,
+			TotalTokens:      usage.TotalTokenCount,
+		},
+		FinishReason: normalizeFinishReason(candidate.FinishReason),
+		Truncated:    truncated,
+	}), nil
+}
+
+// buildGeminiRequest converts a CompletionRequest to a generateContent request.
+// System messages become the system instruction; assistant messages use the
+// "model" role.
+func buildGeminiRequest(req *CompletionRequest) geminiGenerateRequest {
+	var geminiReq geminiGenerateRequest
+	for _, m := range req.Messages {
+		switch m.Role {
+		case RoleSystem:
+			geminiReq.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: m.Content}}}
+		case RoleAssistant:
+			geminiReq.Contents = append(geminiReq.Contents, geminiContent{Role: "model", Parts: []geminiPart{{Text: m.Content}}})
+		default:
+			geminiReq.Contents = append(geminiReq.Contents, geminiContent{Role: "user", Parts: []geminiPart{{Text: m.Content}}})
+		}
+	}
+
+	config := &geminiGenerationConfig{
+		Temperature:      req.Temperature,
+		TopP:             req.TopP,
+		MaxOutputTokens:  req.MaxTokens,
+		StopSequences:    req.Stop,
+		Seed:             req.Seed,
+		FrequencyPenalty: req.FrequencyPenalty,
+		PresencePenalty:  req.PresencePenalty,
+	}
+	if req.ResponseFormat != nil {
+		config.ResponseMimeType = "application/json"
+		if req.ResponseFormat.Schema != nil {
+			config.ResponseSchema = toGeminiSchema(req.ResponseFormat.Schema)
+		}
+	}
+	geminiReq.GenerationConfig = config
+
+	return geminiReq
+}
+
+// toGeminiSchema converts a JSON schema to Gemini's OpenAPI schema subset:
+// types are upper-case and additionalProperties is not supported.
+func toGeminiSchema(schema map[string]any) map[string]any {
+	converted := make(map[string]any, len(schema))
+	for key, value := range schema {
+		switch key {
+		case "additionalProperties":
+			continue
+		case "type":
+			if t, ok := value.(string); ok {
+				value = strings.ToUpper(t)
+			}
+		case "items":
+			if items, ok := value.(map[string]any); ok {
+				value = toGeminiSchema(items)
+			}
+		case "properties":
+			if properties, ok := value.(map[string]any); ok {
+				convertedProperties := make(map[string]any, len(properties))
+				for name, property := range properties {
+					if propertySchema, ok := property.(map[string]any); ok {
+						property = toGeminiSchema(propertySchema)
+					}
+					convertedProperties[name] = property
+				}
+				value = convertedProperties
+			}
+		}
+		converted[key] = value
+	}
+	return converted
+}
+
+// geminiAuthError maps Gemini's invalid-key responses to ErrInvalidAPIKey.
+// Gemini rejects a bad key with 400 API_KEY_INVALID or 403 rather than 401.
+func geminiAuthError(err error) error {
+	var provErr *ProviderError
+	if !errors.As(err, &provErr) || provErr.Err != nil {
+		return err
+	}
+	if provErr.StatusCode == http.StatusForbidden ||
+		(provErr.StatusCode == http.StatusBadRequest && strings.Contains(provErr.Message, "API key")) {
+		provErr.Err = ErrInvalidAPIKey
+	}
+	return err
+}
+
+// Embed returns ErrCapabilityNotSupported; Gemini embeddings are not implemented.
+func (p *GeminiProvider) Embed(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+	return nil, fmt.Errorf("%w: gemini embeddings are not implemented", ErrCapabilityNotSupported)
+}
+
+// Capabilities describes the features supported by the Gemini provider.
+// Response schemas are honored through generationConfig.responseSchema.
+func (p *GeminiProvider) Capabilities() ProviderCapabilities {
+	return ProviderCapabilities{
+		JSONMode: true,
+	}
+}
+
+// CheckHealth verifies the API is reachable and accepts the key by listing
+// models. Failures wrap ErrInvalidAPIKey or ErrProviderUnavailable.
+func (p *GeminiProvider) CheckHealth(ctx context.Context) error {
+	if !p.IsConfigured(ctx) {
+		return ErrProviderNotConfigured
+	}
+
+	if _, err := p.RefreshAvailableModels(ctx); err != nil {
+		return healthCheckError(p.GetType(), err)
+	}
+	return nil
+}
+
+// ValidateConfig checks the API key is set and the base URL is well formed.
+func (p *GeminiProvider) ValidateConfig(ctx context.Context) error {
+	if err := p.DefaultValidateConfig(ctx, p); err != nil {
+		return err
+	}
+	return validateBaseURL(p.baseURL)
+}
+
+// SuggestTags suggests tags for the given content. JSON mode constrains the
+// reply with a response schema, so it parses without the free-text fallback.
+func (p *GeminiProvider) SuggestTags(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+	return p.DefaultSuggestTags(ctx, p, req)
+}
+
+// Summarize generates a summary of the given content. Key points are requested
+// with a response schema.
+func (p *GeminiProvider) Summarize(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error) {
+	return p.DefaultSummarize(ctx, p, req)
+}
+
+// SuggestTitle generates a short title for the content.
+func (p *GeminiProvider) SuggestTitle(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error) {
+	return p.DefaultSuggestTitle(ctx, p, req)
+}
+
+// ToProto converts the provider config to proto format.
+func (p *GeminiProvider) ToProto() *storepb.LLMGeminiConfig {
+	return &storepb.LLMGeminiConfig{
+		ApiKey:       p.apiKey,
+		DefaultModel: p.defaultModel,
+	}
+}
+
+func (p *GeminiProvider) headers() map[string]string {
+	return map[string]string{
+		"x-goog-api-key": p.apiKey,
+	}
+}
+
+// Gemini API request/response types
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature     float64  `json:"temperature,omitempty"`
+	TopP            float64  `json:"topP,omitempty"`
+	MaxOutputTokens int      `json:"maxOutputTokens,omitempty"`
+	StopSequences   []string `json:"stopSequences,omitempty"`
+	Seed            *int     `json:"seed,omitempty"`
+
+	FrequencyPenalty float64        `json:"frequencyPenalty,omitempty"`
+	PresencePenalty  float64        `json:"presencePenalty,omitempty"`
+	ResponseMimeType string         `json:"responseMimeType,omitempty"`
+	ResponseSchema   map[string]any `json:"responseSchema,omitempty"`
+}
+
+type geminiGenerateRequest struct {
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent         `json:"contents"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiGenerateResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
+	ModelVersion string `json:"modelVersion"`
+}
+
+type geminiModelsResponse struct {
+	Models []struct {
+		Name                       string   `json:"name"`
+		SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+	} `json:"models"`
+}
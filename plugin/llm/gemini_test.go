@@ -0,0 +1,227 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	storepb "github.com/usememos/memos/proto/gen/store"
+)
+
+const testGeminiKey = "AIzaSy-test-key-1234567890"
+
+// newGeminiTestServer returns a server answering generateContent with content
+// and recording the decoded request in got.
+func newGeminiTestServer(t *testing.T, content string, got *geminiGenerateRequest) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-goog-api-key") != testGeminiKey {
+			t.Errorf("Expected the API key header, got %q", r.Header.Get("x-goog-api-key"))
+		}
+		if r.URL.Path != "/models/gemini-1.5-flash:generateContent" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		encoded, _ := json.Marshal(content)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":` + string(encoded) + `}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":8,"totalTokenCount":20},"modelVersion":"gemini-1.5-flash-002"}`))
+	}))
+}
+
+func newTestGeminiProvider(baseURL string) *GeminiProvider {
+	return NewGeminiProvider(&ProviderConfig{
+		Type:        ProviderGemini,
+		APIKey:      testGeminiKey,
+		BaseURL:     baseURL,
+		RetryPolicy: &RetryPolicy{MaxAttempts: 1},
+	})
+}
+
+func TestGeminiProviderComplete(t *testing.T) {
+	var got geminiGenerateRequest
+	server := newGeminiTestServer(t, "Hello!", &got)
+	defer server.Close()
+
+	resp, err := newTestGeminiProvider(server.URL).Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{
+			{Role: RoleSystem, Content: "Be brief."},
+			{Role: RoleUser, Content: "Hi"},
+			{Role: RoleAssistant, Content: "Hello"},
+			{Role: RoleUser, Content: "Again"},
+		},
+		MaxTokens: 50,
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+
+	if got.SystemInstruction == nil || got.SystemInstruction.Parts[0].Text != "Be brief." {
+		t.Errorf("Expected the system prompt as systemInstruction, got %+v", got.SystemInstruction)
+	}
+	roles := make([]string, len(got.Contents))
+	for i, c := range got.Contents {
+		roles[i] = c.Role
+	}
+	if want := []string{"user", "model", "user"}; !reflect.DeepEqual(roles, want) {
+		t.Errorf("Expected roles %v, got %v", want, roles)
+	}
+	if got.GenerationConfig == nil || got.GenerationConfig.MaxOutputTokens != 50 || got.GenerationConfig.ResponseSchema != nil {
+		t.Errorf("Unexpected generationConfig %+v", got.GenerationConfig)
+	}
+
+	if resp.Content != "Hello!" || resp.Model != "gemini-1.5-flash-002" {
+		t.Errorf("Unexpected response %+v", resp)
+	}
+	if resp.FinishReason != FinishReasonStop {
+		t.Errorf("Expected finish reason %s, got %s", FinishReasonStop, resp.FinishReason)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens != 12 || resp.Usage.CompletionTokens != 8 || resp.Usage.TotalTokens != 20 {
+		t.Errorf("Unexpected usage %+v", resp.Usage)
+	}
+}
+
+func TestGeminiProviderSuggestTagsResponseSchema(t *testing.T) {
+	var got geminiGenerateRequest
+	server := newGeminiTestServer(t, `{"tags":[{"tag":"meeting","score":0.9},{"tag":"project-alpha","score":0.7}]}`, &got)
+	defer server.Close()
+
+	resp, err := newTestGeminiProvider(server.URL).SuggestTags(context.Background(), &SuggestTagsRequest{
+		Content: "Meeting notes for project Alpha",
+	})
+	if err != nil {
+		t.Fatalf("SuggestTags() error: %v", err)
+	}
+
+	config := got.GenerationConfig
+	if config == nil || config.ResponseMimeType != "application/json" {
+		t.Fatalf("Expected an application/json response MIME type, got %+v", config)
+	}
+	if config.ResponseSchema["type"] != "OBJECT" {
+		t.Errorf("Expected an OBJECT response schema, got %v", config.ResponseSchema)
+	}
+	if _, ok := config.ResponseSchema["additionalProperties"]; ok {
+		t.Error("Expected additionalProperties to be dropped from the response schema")
+	}
+	tags, _ := config.ResponseSchema["properties"].(map[string]any)["tags"].(map[string]any)
+	if tags["type"] != "ARRAY" {
+		t.Errorf("Expected tags to be an ARRAY, got %v", tags)
+	}
+
+	// Scores only come from the schema-constrained JSON, never from the text fallback
+	if want := []string{"meeting", "project-alpha"}; !reflect.DeepEqual(resp.Tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, resp.Tags)
+	}
+	if want := []float64{0.9, 0.7}; !reflect.DeepEqual(resp.Confidence, want) {
+		t.Errorf("Expected confidence %v, got %v", want, resp.Confidence)
+	}
+}
+
+func TestGeminiProviderSummarizeKeyPointsResponseSchema(t *testing.T) {
+	var got geminiGenerateRequest
+	server := newGeminiTestServer(t, `{"summary":"The team agreed on the roadmap.","key_points":["Roadmap approved","Launch in Q3"]}`, &got)
+	defer server.Close()
+
+	resp, err := newTestGeminiProvider(server.URL).Summarize(context.Background(), &SummarizeRequest{
+		Content:          "Long meeting notes about the roadmap",
+		ExtractKeyPoints: true,
+	})
+	if err != nil {
+		t.Fatalf("Summarize() error: %v", err)
+	}
+
+	config := got.GenerationConfig
+	if config == nil || config.ResponseMimeType != "application/json" || config.ResponseSchema == nil {
+		t.Fatalf("Expected a JSON response schema, got %+v", config)
+	}
+	required, _ := config.ResponseSchema["required"].([]any)
+	if len(required) != 2 {
+		t.Errorf("Expected summary and key_points to be required, got %v", config.ResponseSchema["required"])
+	}
+
+	if resp.Summary != "The team agreed on the roadmap." {
+		t.Errorf("Unexpected summary %q", resp.Summary)
+	}
+	if want := []string{"Roadmap approved", "Launch in Q3"}; !reflect.DeepEqual(resp.KeyPoints, want) {
+		t.Errorf("Expected key points %v, got %v", want, resp.KeyPoints)
+	}
+}
+
+func TestGeminiProviderGetAvailableModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" {
+			t.Errorf("Expected path /models, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"models":[{"name":"models/gemini-1.5-flash","supportedGenerationMethods":["generateContent","countTokens"]},{"name":"models/text-embedding-004","supportedGenerationMethods":["embedContent"]}]}`))
+	}))
+	defer server.Close()
+
+	models, err := newTestGeminiProvider(server.URL).GetAvailableModels(context.Background())
+	if err != nil {
+		t.Fatalf("GetAvailableModels() error: %v", err)
+	}
+	if want := []string{"gemini-1.5-flash"}; !reflect.DeepEqual(models, want) {
+		t.Errorf("Expected only generateContent models %v, got %v", want, models)
+	}
+}
+
+func TestGeminiProviderCheckHealthStatuses(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{"healthy", http.StatusOK, `{"models":[]}`, nil},
+		{"unauthorized", http.StatusUnauthorized, `{"error":{"message":"Unauthorized"}}`, ErrInvalidAPIKey},
+		{"invalid key", http.StatusBadRequest, `{"error":{"message":"API key not valid. Please pass a valid API key."}}`, ErrInvalidAPIKey},
+		{"unavailable", http.StatusServiceUnavailable, `{"error":{"message":"overloaded"}}`, ErrProviderUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != "/models" {
+					t.Errorf("Expected GET /models, got %s %s", r.Method, r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			err := newTestGeminiProvider(server.URL).CheckHealth(context.Background())
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("CheckHealth() error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGeminiProviderDefaults(t *testing.T) {
+	provider := NewGeminiProvider(&ProviderConfig{Type: ProviderGemini})
+
+	if provider.baseURL != geminiBaseURL || provider.GetDefaultModel() != geminiDefaultModel {
+		t.Errorf("Unexpected defaults: %s, %s", provider.baseURL, provider.GetDefaultModel())
+	}
+	if provider.IsConfigured(context.Background()) {
+		t.Error("Expected provider without a key to be unconfigured")
+	}
+	if _, err := provider.Embed(context.Background(), &EmbeddingRequest{Input: []string{"hello"}}); !errors.Is(err, ErrCapabilityNotSupported) {
+		t.Errorf("Expected ErrCapabilityNotSupported, got %v", err)
+	}
+
+	fromProto := NewGeminiProviderFromProto(&storepb.LLMGeminiConfig{ApiKey: testGeminiKey, DefaultModel: "gemini-1.5-pro"})
+	if fromProto.GetType() != ProviderGemini || fromProto.GetDefaultModel() != "gemini-1.5-pro" || !fromProto.IsConfigured(context.Background()) {
+		t.Errorf("Unexpected provider from proto: %+v", fromProto.ToProto())
+	}
+}
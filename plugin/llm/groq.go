@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"strings"
+
+	storepb "github.com/usememos/memos/proto/gen/store"
+)
+
+const (
+	groqBaseURL      = "https://api.groq.com/openai/v1"
+	groqDefaultModel = "llama-3.1-8b-instant"
+)
+
+// NewGroqProvider creates a provider for Groq's OpenAI-compatible API.
+// It reuses the OpenAI request and response handling with Groq's base URL and
+// default model. Groq keys start with "gsk_" rather than "sk-".
+func NewGroqProvider(config *ProviderConfig) *OpenAIProvider {
+	p := NewOpenAIProvider(config)
+	if config.BaseURL == "" {
+		p.baseURL = groqBaseURL
+	}
+	if config.DefaultModel == "" {
+		p.defaultModel = groqDefaultModel
+	}
+	// Groq has no embeddings API
+	p.embeddingModel = ""
+	p.groq = true
+	return p
+}
+
+// NewGroqProviderFromProto creates a Groq provider from proto config.
+func NewGroqProviderFromProto(pbConfig *storepb.LLMOpenAIConfig) *OpenAIProvider {
+	config := &ProviderConfig{
+		Type:         ProviderGroq,
+		APIKey:       pbConfig.GetApiKey(),
+		BaseURL:      pbConfig.GetBaseUrl(),
+		DefaultModel: pbConfig.GetDefaultModel(),
+	}
+	return NewGroqProvider(config)
+}
+
+// isGroqChatModel checks if a Groq model ID is a chat model.
+// Groq also serves speech-to-text, text-to-speech and moderation models.
+func isGroqChatModel(id string) bool {
+	for _, exclude := range []string{"whisper", "tts", "guard"} {
+		if strings.Contains(id, exclude) {
+			return false
+		}
+	}
+	return true
+}
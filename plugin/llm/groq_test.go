@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+const testGroqKey = "gsk_test1234567890abcdefghij"
+
+func TestGroqProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer "+testGroqKey {
+			t.Errorf("Expected Groq key in Authorization header, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/models":
+			w.Write([]byte(`{"data":[{"id":"llama-3.1-8b-instant"},{"id":"whisper-large-v3"},{"id":"llama-guard-3-8b"},{"id":"playai-tts"},{"id":"mixtral-8x7b-32768"}]}`))
+		case "/chat/completions":
+			var req openAIChatRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("Failed to decode request: %v", err)
+			}
+			if req.Model != groqDefaultModel {
+				t.Errorf("Expected model %s, got %s", groqDefaultModel, req.Model)
+			}
+			w.Write([]byte(`{"model":"llama-3.1-8b-instant","choices":[{"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewGroqProvider(&ProviderConfig{
+		Type:    ProviderGroq,
+		APIKey:  testGroqKey,
+		BaseURL: server.URL,
+	})
+
+	if provider.GetType() != ProviderGroq {
+		t.Errorf("Expected type %s, got %s", ProviderGroq, provider.GetType())
+	}
+	if provider.GetName() != "Groq" {
+		t.Errorf("Expected name Groq, got %s", provider.GetName())
+	}
+	if !provider.IsConfigured(context.Background()) {
+		t.Fatal("Expected provider with a key to be configured")
+	}
+
+	models, err := provider.GetAvailableModels(context.Background())
+	if err != nil {
+		t.Fatalf("GetAvailableModels() error: %v", err)
+	}
+	if !slices.Equal(models, []string{"llama-3.1-8b-instant", "mixtral-8x7b-32768"}) {
+		t.Errorf("Expected only Groq chat models, got %v", models)
+	}
+
+	resp, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if resp.Content != "Hi" {
+		t.Errorf("Expected content Hi, got %q", resp.Content)
+	}
+}
+
+func TestGroqProviderDefaults(t *testing.T) {
+	provider := NewGroqProvider(&ProviderConfig{Type: ProviderGroq})
+
+	if provider.baseURL != groqBaseURL {
+		t.Errorf("Expected base URL %s, got %s", groqBaseURL, provider.baseURL)
+	}
+	if provider.GetDefaultModel() != groqDefaultModel {
+		t.Errorf("Expected default model %s, got %s", groqDefaultModel, provider.GetDefaultModel())
+	}
+	if provider.IsConfigured(context.Background()) {
+		t.Error("Expected provider without a key to be unconfigured")
+	}
+	if provider.Capabilities().Embeddings {
+		t.Error("Expected Groq not to advertise embeddings")
+	}
+
+	provider = NewGroqProvider(&ProviderConfig{Type: ProviderGroq, APIKey: testGroqKey})
+	_, err := provider.Embed(context.Background(), &EmbeddingRequest{Input: []string{"hello"}})
+	if !errors.Is(err, ErrCapabilityNotSupported) {
+		t.Errorf("Expected ErrCapabilityNotSupported, got %v", err)
+	}
+}
+
+func TestIsGroqChatModel(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"llama-3.3-70b-versatile", true},
+		{"gemma2-9b-it", true},
+		{"whisper-large-v3-turbo", false},
+		{"distil-whisper-large-v3-en", false},
+		{"playai-tts", false},
+		{"meta-llama/llama-guard-4-12b", false},
+	}
+
+	for _, tt := range tests {
+		if got := isGroqChatModel(tt.id); got != tt.want {
+			t.Errorf("isGroqChatModel(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
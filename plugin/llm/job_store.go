@@ -0,0 +1,272 @@
+package llm
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrJobNotFound indicates the requested job does not exist in the job store.
+var ErrJobNotFound = errors.New("tag job not found")
+
+// JobStore persists async tag jobs so they can survive a restart.
+type JobStore interface {
+	// SaveJob inserts or replaces a job.
+	SaveJob(ctx context.Context, job *TagJob) error
+
+	// GetJob retrieves a job by ID. Returns ErrJobNotFound if it does not exist.
+	GetJob(ctx context.Context, id string) (*TagJob, error)
+
+	// ListJobs returns all stored jobs.
+	ListJobs(ctx context.Context) ([]*TagJob, error)
+
+	// DeleteJob removes a job by ID. Deleting a missing job is not an error.
+	DeleteJob(ctx context.Context, id string) error
+}
+
+// InMemoryJobStore is an in-memory implementation of JobStore.
+// Jobs are lost when the process exits; use SQLJobStore for persistence.
+type InMemoryJobStore struct {
+	jobs map[string]*TagJob
+	mu   sync.RWMutex
+}
+
+// NewInMemoryJobStore creates a new in-memory job store.
+func NewInMemoryJobStore() *InMemoryJobStore {
+	return &InMemoryJobStore{
+		jobs: make(map[string]*TagJob),
+	}
+}
+
+// SaveJob inserts or replaces a job.
+func (s *InMemoryJobStore) SaveJob(ctx context.Context, job *TagJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Store a copy so later mutations by the caller are not visible without another save
+	snapshot := *job
+	s.jobs[job.ID] = &snapshot
+	return nil
+}
+
+// GetJob retrieves a job by ID.
+func (s *InMemoryJobStore) GetJob(ctx context.Context, id string) (*TagJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, exists := s.jobs[id]
+	if !exists {
+		return nil, ErrJobNotFound
+	}
+
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// ListJobs returns all stored jobs.
+func (s *InMemoryJobStore) ListJobs(ctx context.Context) ([]*TagJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*TagJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		snapshot := *job
+		result = append(result, &snapshot)
+	}
+	return result, nil
+}
+
+// DeleteJob removes a job by ID.
+func (s *InMemoryJobStore) DeleteJob(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.jobs, id)
+	return nil
+}
+
+// SQLJobStore is a database-backed implementation of JobStore.
+// It supports the same drivers as the main store: "sqlite", "mysql", and "postgres".
+type SQLJobStore struct {
+	db     *sql.DB
+	driver string
+}
+
+// NewSQLJobStore creates a SQL job store over an already migrated database.
+// The llm_tag_job table is created by the store migrations.
+func NewSQLJobStore(db *sql.DB, driver string) (*SQLJobStore, error) {
+	switch driver {
+	case "sqlite", "mysql", "postgres":
+	default:
+		return nil, fmt.Errorf("unsupported job store driver: %s", driver)
+	}
+
+	return &SQLJobStore{db: db, driver: driver}, nil
+}
+
+// SaveJob inserts or replaces a job.
+func (s *SQLJobStore) SaveJob(ctx context.Context, job *TagJob) error {
+	existingTags, err := json.Marshal(job.ExistingTags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal existing tags: %w", err)
+	}
+
+	result := ""
+	if job.Result != nil {
+		bytes, err := json.Marshal(job.Result)
+		if err != nil {
+			return fmt.Errorf("failed to marshal job result: %w", err)
+		}
+		result = string(bytes)
+	}
+
+	errMsg := ""
+	if job.Error != nil {
+		errMsg = job.Error.Error()
+	}
+
+	var completedTs *int64
+	if job.CompletedAt != nil {
+		ts := job.CompletedAt.Unix()
+		completedTs = &ts
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Delete and re-insert to get upsert semantics portable across all drivers
+	if _, err := tx.ExecContext(ctx, s.rebind("DELETE FROM llm_tag_job WHERE id = ?"), job.ID); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+
+	stmt := "INSERT INTO llm_tag_job (id, memo_id, user_id, content, existing_tags, status, result, error, attempts, created_ts, completed_ts) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	if _, err := tx.ExecContext(ctx, s.rebind(stmt),
+		job.ID, job.MemoID, job.UserID, job.Content, string(existingTags),
+		string(job.Status), result, errMsg, job.Attempts, job.CreatedAt.Unix(), completedTs,
+	); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetJob retrieves a job by ID.
+func (s *SQLJobStore) GetJob(ctx context.Context, id string) (*TagJob, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind(sqlJobSelect+" WHERE id = ?"), id)
+	job, err := scanTagJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrJobNotFound
+	}
+	return job, err
+}
+
+// ListJobs returns all stored jobs.
+func (s *SQLJobStore) ListJobs(ctx context.Context) ([]*TagJob, error) {
+	rows, err := s.db.QueryContext(ctx, sqlJobSelect+" ORDER BY created_ts ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*TagJob
+	for rows.Next() {
+		job, err := scanTagJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// DeleteJob removes a job by ID.
+func (s *SQLJobStore) DeleteJob(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, s.rebind("DELETE FROM llm_tag_job WHERE id = ?"), id); err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+	return nil
+}
+
+// rebind converts "?" placeholders to the driver's placeholder style.
+func (s *SQLJobStore) rebind(query string) string {
+	if s.driver != "postgres" {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+const sqlJobSelect = "SELECT id, memo_id, user_id, content, existing_tags, status, result, error, attempts, created_ts, completed_ts FROM llm_tag_job"
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTagJob scans a single job row.
+func scanTagJob(row rowScanner) (*TagJob, error) {
+	var (
+		job          TagJob
+		existingTags string
+		status       string
+		result       string
+		errMsg       string
+		createdTs    int64
+		completedTs  sql.NullInt64
+	)
+
+	if err := row.Scan(&job.ID, &job.MemoID, &job.UserID, &job.Content, &existingTags,
+		&status, &result, &errMsg, &job.Attempts, &createdTs, &completedTs); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(existingTags), &job.ExistingTags); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal existing tags: %w", err)
+	}
+	if result != "" {
+		job.Result = &SuggestTagsResponse{}
+		if err := json.Unmarshal([]byte(result), job.Result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal job result: %w", err)
+		}
+	}
+	if errMsg != "" {
+		job.Error = errors.New(errMsg)
+	}
+
+	job.Status = TagJobStatus(status)
+	job.CreatedAt = time.Unix(createdTs, 0)
+	if completedTs.Valid {
+		completedAt := time.Unix(completedTs.Int64, 0)
+		job.CompletedAt = &completedAt
+	}
+
+	return &job, nil
+}
+
+// Ensure both implementations satisfy JobStore.
+var (
+	_ JobStore = (*InMemoryJobStore)(nil)
+	_ JobStore = (*SQLJobStore)(nil)
+)
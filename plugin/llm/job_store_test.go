@@ -0,0 +1,236 @@
+package llm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	// Register the sqlite driver for SQLJobStore tests.
+	_ "modernc.org/sqlite"
+)
+
+func newTestSQLJobStore(t *testing.T) *SQLJobStore {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open sqlite: %v", err)
+	}
+	// Each connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	// Apply the real sqlite schema so the test tracks the migrations
+	schema, err := os.ReadFile("../../store/migration/sqlite/LATEST.sql")
+	if err != nil {
+		t.Fatalf("Failed to read sqlite schema: %v", err)
+	}
+	if _, err := db.Exec(string(schema)); err != nil {
+		t.Fatalf("Failed to apply sqlite schema: %v", err)
+	}
+
+	store, err := NewSQLJobStore(db, "sqlite")
+	if err != nil {
+		t.Fatalf("NewSQLJobStore failed: %v", err)
+	}
+	return store
+}
+
+func testJobStore(t *testing.T, store JobStore) {
+	ctx := context.Background()
+	now := time.Now()
+
+	job := &TagJob{
+		ID:           "job-1",
+		MemoID:       100,
+		UserID:       1,
+		Content:      "Job store content",
+		ExistingTags: []string{"go"},
+		Status:       TagJobStatusCompleted,
+		Result:       &SuggestTagsResponse{Tags: []string{"golang", "testing"}},
+		CreatedAt:    now,
+		CompletedAt:  &now,
+	}
+	if err := store.SaveJob(ctx, job); err != nil {
+		t.Fatalf("SaveJob failed: %v", err)
+	}
+
+	got, err := store.GetJob(ctx, "job-1")
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	if got.MemoID != 100 || got.UserID != 1 || got.Content != job.Content {
+		t.Errorf("Unexpected job fields: %+v", got)
+	}
+	if got.Status != TagJobStatusCompleted {
+		t.Errorf("Expected status completed, got %s", got.Status)
+	}
+	if got.Result == nil || len(got.Result.Tags) != 2 {
+		t.Errorf("Expected 2 result tags, got %+v", got.Result)
+	}
+	if got.CompletedAt == nil {
+		t.Error("Expected CompletedAt to be set")
+	}
+
+	// Saving again replaces the job
+	job.Status = TagJobStatusFailed
+	job.Error = errors.New("boom")
+	if err := store.SaveJob(ctx, job); err != nil {
+		t.Fatalf("SaveJob (replace) failed: %v", err)
+	}
+	got, _ = store.GetJob(ctx, "job-1")
+	if got.Status != TagJobStatusFailed || got.Error == nil || got.Error.Error() != "boom" {
+		t.Errorf("Expected failed job with error, got %+v", got)
+	}
+
+	jobs, err := store.ListJobs(ctx)
+	if err != nil {
+		t.Fatalf("ListJobs failed: %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Errorf("Expected 1 job, got %d", len(jobs))
+	}
+
+	if err := store.DeleteJob(ctx, "job-1"); err != nil {
+		t.Fatalf("DeleteJob failed: %v", err)
+	}
+	if _, err := store.GetJob(ctx, "job-1"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound after delete, got %v", err)
+	}
+}
+
+func TestInMemoryJobStore(t *testing.T) {
+	testJobStore(t, NewInMemoryJobStore())
+}
+
+func TestSQLJobStore(t *testing.T) {
+	testJobStore(t, newTestSQLJobStore(t))
+}
+
+func TestSQLJobStore_PersistsAttempts(t *testing.T) {
+	ctx := context.Background()
+	store := newTestSQLJobStore(t)
+
+	job := &TagJob{
+		ID:        "retry-job",
+		MemoID:    5,
+		UserID:    1,
+		Content:   "Retried content",
+		Status:    TagJobStatusPending,
+		Attempts:  2,
+		CreatedAt: time.Now(),
+	}
+	if err := store.SaveJob(ctx, job); err != nil {
+		t.Fatalf("SaveJob failed: %v", err)
+	}
+
+	got, err := store.GetJob(ctx, "retry-job")
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	if got.Attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got.Attempts)
+	}
+
+	jobs, err := store.ListJobs(ctx)
+	if err != nil {
+		t.Fatalf("ListJobs failed: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].Attempts != 2 {
+		t.Errorf("Expected listed job with 2 attempts, got %+v", jobs)
+	}
+}
+
+func TestNewSQLJobStore_UnsupportedDriver(t *testing.T) {
+	if _, err := NewSQLJobStore(nil, "oracle"); err == nil {
+		t.Error("Expected error for unsupported driver")
+	}
+}
+
+func TestSQLJobStore_Rebind(t *testing.T) {
+	store := &SQLJobStore{driver: "postgres"}
+	got := store.rebind("SELECT * FROM t WHERE a = ? AND b = ?")
+	if got != "SELECT * FROM t WHERE a = $1 AND b = $2" {
+		t.Errorf("Unexpected rebind result: %s", got)
+	}
+}
+
+func TestTagService_JobsSurviveRestart(t *testing.T) {
+	store := newTestSQLJobStore(t)
+	config := &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    10,
+		JobStore:          store,
+	}
+
+	ts := NewTagService(&mockLLMService{}, config)
+	job, err := ts.SuggestTagsAsync(1, 100, "Restart test content", nil)
+	if err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	ts.Stop()
+
+	// Simulate a restart with a new service over the same store
+	restarted := NewTagService(&mockLLMService{}, config)
+	defer restarted.Stop()
+
+	got, exists := restarted.GetJob(job.ID)
+	if !exists {
+		t.Fatal("Job should survive restart")
+	}
+	if got.Status != TagJobStatusCompleted {
+		t.Errorf("Expected completed status, got %s", got.Status)
+	}
+	if got.Result == nil || len(got.Result.Tags) != 3 {
+		t.Errorf("Expected 3 result tags, got %+v", got.Result)
+	}
+}
+
+func TestTagService_ResumesPendingJobs(t *testing.T) {
+	store := NewInMemoryJobStore()
+	store.SaveJob(context.Background(), &TagJob{
+		ID:        "pending-job",
+		MemoID:    7,
+		UserID:    1,
+		Content:   "Pending before restart",
+		Status:    TagJobStatusPending,
+		CreatedAt: time.Now(),
+	})
+
+	mock := &mockLLMService{}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    10,
+		JobStore:          store,
+	})
+	defer ts.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	got, exists := ts.GetJob("pending-job")
+	if !exists {
+		t.Fatal("Pending job should exist")
+	}
+	if got.Status != TagJobStatusCompleted {
+		t.Errorf("Expected resumed job to complete, got %s", got.Status)
+	}
+	if mock.GetCallCount() != 1 {
+		t.Errorf("Expected 1 LLM call for resumed job, got %d", mock.GetCallCount())
+	}
+}
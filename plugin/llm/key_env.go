@@ -0,0 +1,133 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// DefaultMasterKeyEnv is the environment variable read by NewKeyCryptoFromEnv
+// and NewKeyStorageFromEnv when no variable name is given.
+const DefaultMasterKeyEnv = "MEMOS_LLM_MASTER_KEY"
+
+// minMasterKeyEntropyBits is the minimum estimated entropy of a master key.
+// A random 32-character key (e.g., from `openssl rand -base64 24`) is well above it.
+const minMasterKeyEntropyBits = 64
+
+// minSingleClassMasterKeyLength is the minimum length of a master key drawn from
+// a single character class (e.g., only lowercase letters). Shorter keys must mix
+// at least two of lowercase, uppercase, digits and symbols.
+const minSingleClassMasterKeyLength = 32
+
+var (
+	// ErrMasterKeyNotSet indicates the master key environment variable is missing or empty.
+	ErrMasterKeyNotSet = errors.New("master key not set")
+
+	// ErrMasterKeyWeak indicates the master key is too short or too predictable.
+	ErrMasterKeyWeak = errors.New("master key too weak")
+)
+
+// NewKeyCryptoFromEnv creates a KeyCrypto from the master key in the environment
+// variable envVar (DefaultMasterKeyEnv if empty). Errors name the variable and
+// wrap ErrMasterKeyNotSet or ErrMasterKeyWeak.
+func NewKeyCryptoFromEnv(envVar string, opts ...KeyCryptoOption) (*KeyCrypto, error) {
+	masterKey, err := masterKeyFromEnv(envVar)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyCrypto(masterKey, opts...)
+}
+
+// NewKeyStorageFromEnv creates an in-memory key storage encrypted with the master
+// key in the environment variable envVar (DefaultMasterKeyEnv if empty).
+func NewKeyStorageFromEnv(envVar string, opts ...KeyCryptoOption) (*InMemoryKeyStorage, error) {
+	masterKey, err := masterKeyFromEnv(envVar)
+	if err != nil {
+		return nil, err
+	}
+	return NewInMemoryKeyStorage(masterKey, opts...)
+}
+
+// masterKeyFromEnv reads and validates the master key from envVar.
+func masterKeyFromEnv(envVar string) (string, error) {
+	if envVar == "" {
+		envVar = DefaultMasterKeyEnv
+	}
+
+	masterKey := strings.TrimSpace(os.Getenv(envVar))
+	if masterKey == "" {
+		return "", fmt.Errorf("%s: %w", envVar, ErrMasterKeyNotSet)
+	}
+	if len(masterKey) < 16 {
+		return "", fmt.Errorf("%s: %w: need at least 16 characters, got %d", envVar, ErrMasterKeyWeak, len(masterKey))
+	}
+	if len(masterKey) < minSingleClassMasterKeyLength && characterClasses(masterKey) < 2 {
+		return "", fmt.Errorf("%s: %w: mix letters, digits or symbols, or use at least %d characters", envVar, ErrMasterKeyWeak, minSingleClassMasterKeyLength)
+	}
+	if bits := estimateEntropyBits(masterKey); bits < minMasterKeyEntropyBits {
+		return "", fmt.Errorf("%s: %w: estimated %.0f bits of entropy, need %d", envVar, ErrMasterKeyWeak, bits, minMasterKeyEntropyBits)
+	}
+	return masterKey, nil
+}
+
+// estimateEntropyBits estimates the entropy of s from its character
+// distribution (Shannon entropy per character times length). Repeated or
+// low-variety keys such as "aaaa..." or "passwordpassword" score low.
+// Characters that continue a sequential or repeated run (the third "c" of
+// "abc", "cba" or "ccc" onwards) are predictable and don't count towards the
+// length, so keys such as "abcdefghijklmnop" score low too.
+func estimateEntropyBits(s string) float64 {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	for _, r := range runes {
+		counts[r]++
+	}
+
+	var perChar float64
+	for _, c := range counts {
+		p := float64(c) / float64(len(runes))
+		perChar -= p * math.Log2(p)
+	}
+
+	effective := len(runes)
+	for i := 2; i < len(runes); i++ {
+		step := runes[i] - runes[i-1]
+		if step >= -1 && step <= 1 && step == runes[i-1]-runes[i-2] {
+			effective--
+		}
+	}
+	return perChar * float64(effective)
+}
+
+// characterClasses counts how many of lowercase letters, uppercase letters,
+// digits and other characters appear in s.
+func characterClasses(s string) int {
+	var lower, upper, digit, other bool
+	for _, r := range s {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, other} {
+		if present {
+			classes++
+		}
+	}
+	return classes
+}
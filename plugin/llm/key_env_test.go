@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testEnvMasterKey = "d00d183b61bec27e63e2bbd288188e98"
+
+func TestNewKeyStorageFromEnv(t *testing.T) {
+	t.Setenv("TEST_LLM_MASTER_KEY", testEnvMasterKey)
+
+	storage, err := NewKeyStorageFromEnv("TEST_LLM_MASTER_KEY")
+	if err != nil {
+		t.Fatalf("NewKeyStorageFromEnv() error: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := storage.StoreKey(ctx, 1, ProviderOpenAI, "sk-test-key-123456789"); err != nil {
+		t.Fatalf("StoreKey() error: %v", err)
+	}
+	got, err := storage.GetKey(ctx, 1, ProviderOpenAI)
+	if err != nil || got != "sk-test-key-123456789" {
+		t.Errorf("GetKey() = %q, %v", got, err)
+	}
+}
+
+func TestNewKeyCryptoFromEnv_DefaultVar(t *testing.T) {
+	t.Setenv(DefaultMasterKeyEnv, testEnvMasterKey)
+
+	kc, err := NewKeyCryptoFromEnv("")
+	if err != nil {
+		t.Fatalf("NewKeyCryptoFromEnv() error: %v", err)
+	}
+	ciphertext, err := kc.Encrypt("secret")
+	if err != nil {
+		t.Fatalf("Encrypt() error: %v", err)
+	}
+	if plaintext, err := kc.Decrypt(ciphertext); err != nil || plaintext != "secret" {
+		t.Errorf("Decrypt() = %q, %v", plaintext, err)
+	}
+}
+
+func TestNewKeyCryptoFromEnv_Missing(t *testing.T) {
+	t.Setenv("TEST_LLM_MASTER_KEY", "")
+
+	_, err := NewKeyCryptoFromEnv("TEST_LLM_MASTER_KEY")
+	if !errors.Is(err, ErrMasterKeyNotSet) {
+		t.Fatalf("Expected ErrMasterKeyNotSet, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "TEST_LLM_MASTER_KEY") {
+		t.Errorf("Expected the error to name the variable, got %v", err)
+	}
+}
+
+func TestNewKeyStorageFromEnv_Weak(t *testing.T) {
+	for _, key := range []string{
+		"short",
+		"aaaaaaaaaaaaaaaaaaaa",
+		"passwordpassword",
+		"abcdefghijklmnop",                 // Sequential run
+		"0123456789abcdef",                 // Sequential runs in two classes
+		"zyxwvutsrqponmlkjihgfedcba012345", // Descending run
+		"kdhwqpzmxnrtvbyu",                 // Single character class
+	} {
+		t.Setenv("TEST_LLM_MASTER_KEY", key)
+
+		_, err := NewKeyStorageFromEnv("TEST_LLM_MASTER_KEY")
+		if !errors.Is(err, ErrMasterKeyWeak) {
+			t.Errorf("Key %q: expected ErrMasterKeyWeak, got %v", key, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), "TEST_LLM_MASTER_KEY") {
+			t.Errorf("Expected the error to name the variable, got %v", err)
+		}
+	}
+}
+
+func TestEstimateEntropyBits_SequentialRuns(t *testing.T) {
+	if bits := estimateEntropyBits("abcdefghijklmnop"); bits >= minMasterKeyEntropyBits {
+		t.Errorf("Expected a sequential key to score below %d bits, got %.0f", minMasterKeyEntropyBits, bits)
+	}
+	if bits := estimateEntropyBits(testEnvMasterKey); bits < minMasterKeyEntropyBits {
+		t.Errorf("Expected a random key to score at least %d bits, got %.0f", minMasterKeyEntropyBits, bits)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
 	"sync"
 	"time"
 )
@@ -15,6 +16,9 @@ var (
 
 	// ErrKeyAlreadyExists indicates an API key already exists for this provider.
 	ErrKeyAlreadyExists = errors.New("API key already exists for this provider")
+
+	// ErrKeyExpired indicates the API key has passed its expiry time.
+	ErrKeyExpired = errors.New("API key expired")
 )
 
 // StoredAPIKey represents an encrypted API key stored in the system.
@@ -42,6 +46,29 @@ type StoredAPIKey struct {
 
 	// UserID is the ID of the user who owns this key (0 for instance-level keys).
 	UserID int32 `json:"user_id"`
+
+	// UseCount is the number of times the key has been marked used.
+	UseCount int64 `json:"use_count"`
+
+	// LastUsedFromProvider names the provider that last used the key (see WithKeyUsageProvider).
+	LastUsedFromProvider string `json:"last_used_from_provider,omitempty"`
+
+	// ExpiresAt is when the key stops being usable (nil means it never expires).
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// isExpired reports whether the key has expired as of now.
+func (k *StoredAPIKey) isExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// keyUsageProviderKey is the context key for WithKeyUsageProvider.
+type keyUsageProviderKey struct{}
+
+// WithKeyUsageProvider returns a context that makes MarkKeyUsed record the given
+// provider name (e.g., an instance ID) as LastUsedFromProvider.
+func WithKeyUsageProvider(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, keyUsageProviderKey{}, name)
 }
 
 // KeyStorageService manages API key storage with encryption.
@@ -49,26 +76,49 @@ type KeyStorageService interface {
 	// StoreKey stores an API key with encryption.
 	StoreKey(ctx context.Context, userID int32, providerType ProviderType, apiKey string) (*StoredAPIKey, error)
 
-	// GetKey retrieves and decrypts an API key.
+	// StoreKeyWithExpiry stores an API key that becomes unusable at expiresAt.
+	StoreKeyWithExpiry(ctx context.Context, userID int32, providerType ProviderType, apiKey string, expiresAt time.Time) (*StoredAPIKey, error)
+
+	// GetKey retrieves and decrypts an API key. Expired keys return ErrKeyExpired.
 	GetKey(ctx context.Context, userID int32, providerType ProviderType) (string, error)
 
 	// GetStoredKey retrieves the stored key metadata (without decrypting).
 	GetStoredKey(ctx context.Context, userID int32, providerType ProviderType) (*StoredAPIKey, error)
 
-	// UpdateKey updates an existing API key.
+	// UpdateKey updates an existing API key. The new key never expires.
 	UpdateKey(ctx context.Context, userID int32, providerType ProviderType, apiKey string) (*StoredAPIKey, error)
 
+	// UpsertKey stores the key if absent or updates it if present.
+	// created reports whether a new key was stored. The key never expires.
+	UpsertKey(ctx context.Context, userID int32, providerType ProviderType, apiKey string) (stored *StoredAPIKey, created bool, err error)
+
 	// DeleteKey removes an API key.
 	DeleteKey(ctx context.Context, userID int32, providerType ProviderType) error
 
-	// ListKeys returns all stored keys for a user (without decrypting).
+	// DeleteAllKeys removes every key for a user and returns how many were deleted.
+	DeleteAllKeys(ctx context.Context, userID int32) (int, error)
+
+	// ListKeys returns all stored keys for a user (without decrypting), ordered by provider type.
 	ListKeys(ctx context.Context, userID int32) ([]*StoredAPIKey, error)
 
-	// HasKey checks if a key exists for a provider.
+	// ListKeysByProvider returns the user's stored keys (without decrypting) keyed
+	// by provider type. Providers without a key are omitted.
+	ListKeysByProvider(ctx context.Context, userID int32) (map[ProviderType]*StoredAPIKey, error)
+
+	// CountKeys returns the number of keys stored for a user, including expired ones.
+	CountKeys(ctx context.Context, userID int32) (int, error)
+
+	// HasKey checks if a usable (unexpired) key exists for a provider.
 	HasKey(ctx context.Context, userID int32, providerType ProviderType) bool
 
-	// MarkKeyUsed updates the LastUsedAt timestamp.
+	// MarkKeyUsed updates the LastUsedAt timestamp and increments UseCount.
 	MarkKeyUsed(ctx context.Context, userID int32, providerType ProviderType) error
+
+	// GetKeyUsageStats returns how often and when a key was last used.
+	GetKeyUsageStats(ctx context.Context, userID int32, providerType ProviderType) (count int64, lastUsed *time.Time, err error)
+
+	// PurgeExpiredKeys deletes all expired keys and returns how many were removed.
+	PurgeExpiredKeys(ctx context.Context) int
 }
 
 // InMemoryKeyStorage is an in-memory implementation of KeyStorageService.
@@ -80,8 +130,9 @@ type InMemoryKeyStorage struct {
 }
 
 // NewInMemoryKeyStorage creates a new in-memory key storage service.
-func NewInMemoryKeyStorage(masterKey string) (*InMemoryKeyStorage, error) {
-	crypto, err := NewKeyCrypto(masterKey)
+// Crypto options (e.g., WithPBKDF2) are passed through to NewKeyCrypto.
+func NewInMemoryKeyStorage(masterKey string, opts ...KeyCryptoOption) (*InMemoryKeyStorage, error) {
+	crypto, err := NewKeyCrypto(masterKey, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create crypto: %w", err)
 	}
@@ -99,6 +150,16 @@ func storageKey(userID int32, providerType ProviderType) string {
 
 // StoreKey stores an API key with encryption.
 func (s *InMemoryKeyStorage) StoreKey(ctx context.Context, userID int32, providerType ProviderType, apiKey string) (*StoredAPIKey, error) {
+	return s.storeKey(userID, providerType, apiKey, nil)
+}
+
+// StoreKeyWithExpiry stores an API key that becomes unusable at expiresAt.
+func (s *InMemoryKeyStorage) StoreKeyWithExpiry(ctx context.Context, userID int32, providerType ProviderType, apiKey string, expiresAt time.Time) (*StoredAPIKey, error) {
+	return s.storeKey(userID, providerType, apiKey, &expiresAt)
+}
+
+// storeKey encrypts and stores a new key with an optional expiry.
+func (s *InMemoryKeyStorage) storeKey(userID int32, providerType ProviderType, apiKey string, expiresAt *time.Time) (*StoredAPIKey, error) {
 	// Validate the API key format
 	if err := ValidateAPIKeyFormat(providerType, apiKey); err != nil {
 		return nil, fmt.Errorf("invalid API key: %w", err)
@@ -127,6 +188,7 @@ func (s *InMemoryKeyStorage) StoreKey(ctx context.Context, userID int32, provide
 		CreatedAt:    now,
 		UpdatedAt:    now,
 		UserID:       userID,
+		ExpiresAt:    expiresAt,
 	}
 
 	s.keys[key] = stored
@@ -149,6 +211,9 @@ func (s *InMemoryKeyStorage) GetKey(ctx context.Context, userID int32, providerT
 	if !exists {
 		return "", ErrKeyNotFound
 	}
+	if stored.isExpired(time.Now()) {
+		return "", ErrKeyExpired
+	}
 
 	// Decrypt the key
 	apiKey, err := s.crypto.Decrypt(stored.EncryptedKey)
@@ -176,6 +241,7 @@ func (s *InMemoryKeyStorage) GetStoredKey(ctx context.Context, userID int32, pro
 }
 
 // UpdateKey updates an existing API key.
+// Any expiry belonged to the old key material, so it is cleared.
 func (s *InMemoryKeyStorage) UpdateKey(ctx context.Context, userID int32, providerType ProviderType, apiKey string) (*StoredAPIKey, error) {
 	// Validate the API key format
 	if err := ValidateAPIKeyFormat(providerType, apiKey); err != nil {
@@ -202,6 +268,7 @@ func (s *InMemoryKeyStorage) UpdateKey(ctx context.Context, userID int32, provid
 	stored.EncryptedKey = encryptedKey
 	stored.MaskedKey = MaskAPIKey(apiKey)
 	stored.UpdatedAt = time.Now()
+	stored.ExpiresAt = nil
 
 	slog.Info("API key updated",
 		slog.Int("user_id", int(userID)),
@@ -213,6 +280,55 @@ func (s *InMemoryKeyStorage) UpdateKey(ctx context.Context, userID int32, provid
 	return &copy, nil
 }
 
+// UpsertKey stores the key if absent or updates it if present, preserving the original CreatedAt.
+// Updating clears any expiry, so a new key saved over an expired one is usable.
+func (s *InMemoryKeyStorage) UpsertKey(ctx context.Context, userID int32, providerType ProviderType, apiKey string) (*StoredAPIKey, bool, error) {
+	// Validate the API key format
+	if err := ValidateAPIKeyFormat(providerType, apiKey); err != nil {
+		return nil, false, fmt.Errorf("invalid API key: %w", err)
+	}
+
+	encryptedKey, err := s.crypto.Encrypt(apiKey)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encrypt key: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := storageKey(userID, providerType)
+	now := time.Now()
+	stored, exists := s.keys[key]
+	if exists {
+		stored.ID = GenerateKeyID(apiKey)
+		stored.EncryptedKey = encryptedKey
+		stored.MaskedKey = MaskAPIKey(apiKey)
+		stored.UpdatedAt = now
+		stored.ExpiresAt = nil
+	} else {
+		stored = &StoredAPIKey{
+			ID:           GenerateKeyID(apiKey),
+			ProviderType: providerType,
+			EncryptedKey: encryptedKey,
+			MaskedKey:    MaskAPIKey(apiKey),
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			UserID:       userID,
+		}
+		s.keys[key] = stored
+	}
+
+	slog.Info("API key upserted",
+		slog.Int("user_id", int(userID)),
+		slog.String("provider", string(providerType)),
+		slog.String("key_id", stored.ID),
+		slog.Bool("created", !exists))
+
+	// Return a copy
+	copy := *stored
+	return &copy, !exists, nil
+}
+
 // DeleteKey removes an API key.
 func (s *InMemoryKeyStorage) DeleteKey(ctx context.Context, userID int32, providerType ProviderType) error {
 	s.mu.Lock()
@@ -232,7 +348,45 @@ func (s *InMemoryKeyStorage) DeleteKey(ctx context.Context, userID int32, provid
 	return nil
 }
 
-// ListKeys returns all stored keys for a user (without decrypting).
+// DeleteAllKeys removes every key for a user across all providers, e.g. when the
+// user is deleted. Returns the number of keys removed.
+func (s *InMemoryKeyStorage) DeleteAllKeys(ctx context.Context, userID int32) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := 0
+	for key, stored := range s.keys {
+		if stored.UserID == userID {
+			delete(s.keys, key)
+			deleted++
+		}
+	}
+
+	if deleted > 0 {
+		slog.Info("API keys deleted for user",
+			slog.Int("user_id", int(userID)),
+			slog.Int("count", deleted))
+	}
+
+	return deleted, nil
+}
+
+// CountKeys returns the number of keys stored for a user, including expired ones.
+func (s *InMemoryKeyStorage) CountKeys(ctx context.Context, userID int32) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, stored := range s.keys {
+		if stored.UserID == userID {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// ListKeys returns all stored keys for a user (without decrypting), ordered by provider type.
 func (s *InMemoryKeyStorage) ListKeys(ctx context.Context, userID int32) ([]*StoredAPIKey, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -248,20 +402,41 @@ func (s *InMemoryKeyStorage) ListKeys(ctx context.Context, userID int32) ([]*Sto
 		}
 	}
 
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ProviderType < result[j].ProviderType
+	})
+	return result, nil
+}
+
+// ListKeysByProvider returns the user's stored keys (without decrypting) keyed
+// by provider type, e.g. to show every configured provider's masked key on a
+// settings page. Providers without a key are omitted; expired keys are included
+// so they can be shown as such.
+func (s *InMemoryKeyStorage) ListKeysByProvider(ctx context.Context, userID int32) (map[ProviderType]*StoredAPIKey, error) {
+	keys, err := s.ListKeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[ProviderType]*StoredAPIKey, len(keys))
+	for _, key := range keys {
+		result[key.ProviderType] = key
+	}
 	return result, nil
 }
 
-// HasKey checks if a key exists for a provider.
+// HasKey checks if a usable (unexpired) key exists for a provider.
 func (s *InMemoryKeyStorage) HasKey(ctx context.Context, userID int32, providerType ProviderType) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	key := storageKey(userID, providerType)
-	_, exists := s.keys[key]
-	return exists
+	stored, exists := s.keys[key]
+	return exists && !stored.isExpired(time.Now())
 }
 
-// MarkKeyUsed updates the LastUsedAt timestamp.
+// MarkKeyUsed updates the LastUsedAt timestamp and increments UseCount.
+// If ctx carries a provider name from WithKeyUsageProvider, it is recorded as LastUsedFromProvider.
 func (s *InMemoryKeyStorage) MarkKeyUsed(ctx context.Context, userID int32, providerType ProviderType) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -274,9 +449,56 @@ func (s *InMemoryKeyStorage) MarkKeyUsed(ctx context.Context, userID int32, prov
 
 	now := time.Now()
 	stored.LastUsedAt = &now
+	stored.UseCount++
+	if name, ok := ctx.Value(keyUsageProviderKey{}).(string); ok && name != "" {
+		stored.LastUsedFromProvider = name
+	}
 
 	return nil
 }
 
+// GetKeyUsageStats returns how often and when a key was last used.
+// lastUsed is nil if the key has never been used.
+func (s *InMemoryKeyStorage) GetKeyUsageStats(ctx context.Context, userID int32, providerType ProviderType) (int64, *time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key := storageKey(userID, providerType)
+	stored, exists := s.keys[key]
+	if !exists {
+		return 0, nil, ErrKeyNotFound
+	}
+
+	var lastUsed *time.Time
+	if stored.LastUsedAt != nil {
+		t := *stored.LastUsedAt
+		lastUsed = &t
+	}
+
+	return stored.UseCount, lastUsed, nil
+}
+
+// PurgeExpiredKeys deletes all expired keys and returns how many were removed.
+// It is safe to call from a background goroutine.
+func (s *InMemoryKeyStorage) PurgeExpiredKeys(ctx context.Context) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	purged := 0
+	for key, stored := range s.keys {
+		if stored.isExpired(now) {
+			delete(s.keys, key)
+			purged++
+		}
+	}
+
+	if purged > 0 {
+		slog.Info("expired API keys purged", slog.Int("count", purged))
+	}
+
+	return purged
+}
+
 // Ensure InMemoryKeyStorage implements KeyStorageService.
 var _ KeyStorageService = (*InMemoryKeyStorage)(nil)
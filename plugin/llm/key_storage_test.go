@@ -2,7 +2,9 @@ package llm
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewInMemoryKeyStorage(t *testing.T) {
@@ -273,6 +275,82 @@ func TestKeyStorage_UpdateKey_InvalidKey(t *testing.T) {
 	}
 }
 
+func TestKeyStorage_UpsertKey_Create(t *testing.T) {
+	storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
+	ctx := context.Background()
+
+	apiKey := "sk-upsert-key-123456789012345678901234567890"
+
+	stored, created, err := storage.UpsertKey(ctx, 1, ProviderOpenAI, apiKey)
+	if err != nil {
+		t.Fatalf("UpsertKey() error: %v", err)
+	}
+	if !created {
+		t.Error("UpsertKey() should report a new key as created")
+	}
+	if stored.MaskedKey != MaskAPIKey(apiKey) {
+		t.Errorf("UpsertKey() masked key = %v, want %v", stored.MaskedKey, MaskAPIKey(apiKey))
+	}
+
+	retrieved, err := storage.GetKey(ctx, 1, ProviderOpenAI)
+	if err != nil {
+		t.Fatalf("GetKey() error: %v", err)
+	}
+	if retrieved != apiKey {
+		t.Errorf("GetKey() after upsert = %v, want %v", retrieved, apiKey)
+	}
+}
+
+func TestKeyStorage_UpsertKey_Update(t *testing.T) {
+	storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
+	ctx := context.Background()
+
+	originalKey := "sk-original-key-123456789012345678901234567"
+	updatedKey := "sk-updated-key-9876543210987654321098765432"
+
+	original, _, err := storage.UpsertKey(ctx, 1, ProviderOpenAI, originalKey)
+	if err != nil {
+		t.Fatalf("UpsertKey() failed: %v", err)
+	}
+
+	// Ensure the clock advances between writes
+	time.Sleep(10 * time.Millisecond)
+
+	updated, created, err := storage.UpsertKey(ctx, 1, ProviderOpenAI, updatedKey)
+	if err != nil {
+		t.Fatalf("UpsertKey() error: %v", err)
+	}
+	if created {
+		t.Error("UpsertKey() should not report an existing key as created")
+	}
+	if !updated.CreatedAt.Equal(original.CreatedAt) {
+		t.Errorf("UpsertKey() CreatedAt = %v, want %v", updated.CreatedAt, original.CreatedAt)
+	}
+	if !updated.UpdatedAt.After(original.UpdatedAt) {
+		t.Errorf("UpsertKey() UpdatedAt %v should be after %v", updated.UpdatedAt, original.UpdatedAt)
+	}
+
+	retrieved, err := storage.GetKey(ctx, 1, ProviderOpenAI)
+	if err != nil {
+		t.Fatalf("GetKey() error: %v", err)
+	}
+	if retrieved != updatedKey {
+		t.Errorf("GetKey() after upsert = %v, want %v", retrieved, updatedKey)
+	}
+}
+
+func TestKeyStorage_UpsertKey_InvalidKey(t *testing.T) {
+	storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
+	ctx := context.Background()
+
+	if _, _, err := storage.UpsertKey(ctx, 1, ProviderOpenAI, "invalid-key"); err == nil {
+		t.Error("UpsertKey() should fail with invalid key format")
+	}
+	if storage.HasKey(ctx, 1, ProviderOpenAI) {
+		t.Error("UpsertKey() should not store an invalid key")
+	}
+}
+
 func TestKeyStorage_DeleteKey(t *testing.T) {
 	storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
 	ctx := context.Background()
@@ -419,6 +497,83 @@ func TestKeyStorage_MarkKeyUsed_NotFound(t *testing.T) {
 	}
 }
 
+func TestKeyStorage_MarkKeyUsed_IncrementsCount(t *testing.T) {
+	storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
+	ctx := context.Background()
+
+	storage.StoreKey(ctx, 1, ProviderOpenAI, "sk-test-key-123456789012345678901234567890")
+
+	for i := 0; i < 3; i++ {
+		if err := storage.MarkKeyUsed(ctx, 1, ProviderOpenAI); err != nil {
+			t.Fatalf("MarkKeyUsed() error: %v", err)
+		}
+	}
+
+	count, lastUsed, err := storage.GetKeyUsageStats(ctx, 1, ProviderOpenAI)
+	if err != nil {
+		t.Fatalf("GetKeyUsageStats() error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("count = %d, want 3", count)
+	}
+	if lastUsed == nil {
+		t.Error("lastUsed should be set after MarkKeyUsed")
+	}
+
+	// ListKeys should expose the count
+	keys, _ := storage.ListKeys(ctx, 1)
+	if len(keys) != 1 || keys[0].UseCount != 3 {
+		t.Errorf("ListKeys() UseCount = %v, want 3", keys)
+	}
+}
+
+func TestKeyStorage_MarkKeyUsed_Concurrent(t *testing.T) {
+	storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
+	ctx := context.Background()
+
+	storage.StoreKey(ctx, 1, ProviderOpenAI, "sk-test-key-123456789012345678901234567890")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			storage.MarkKeyUsed(ctx, 1, ProviderOpenAI)
+		}()
+	}
+	wg.Wait()
+
+	count, _, _ := storage.GetKeyUsageStats(ctx, 1, ProviderOpenAI)
+	if count != 50 {
+		t.Errorf("count = %d, want 50", count)
+	}
+}
+
+func TestKeyStorage_MarkKeyUsed_RecordsProvider(t *testing.T) {
+	storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
+	ctx := context.Background()
+
+	storage.StoreKey(ctx, 1, ProviderOpenAI, "sk-test-key-123456789012345678901234567890")
+
+	if err := storage.MarkKeyUsed(WithKeyUsageProvider(ctx, "openai-eu"), 1, ProviderOpenAI); err != nil {
+		t.Fatalf("MarkKeyUsed() error: %v", err)
+	}
+
+	stored, _ := storage.GetStoredKey(ctx, 1, ProviderOpenAI)
+	if stored.LastUsedFromProvider != "openai-eu" {
+		t.Errorf("LastUsedFromProvider = %q, want %q", stored.LastUsedFromProvider, "openai-eu")
+	}
+}
+
+func TestKeyStorage_GetKeyUsageStats_NotFound(t *testing.T) {
+	storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
+
+	_, _, err := storage.GetKeyUsageStats(context.Background(), 1, ProviderOpenAI)
+	if err != ErrKeyNotFound {
+		t.Errorf("GetKeyUsageStats() error = %v, want ErrKeyNotFound", err)
+	}
+}
+
 func TestKeyStorage_MultipleUsers(t *testing.T) {
 	storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
 	ctx := context.Background()
@@ -473,3 +628,199 @@ func TestKeyStorage_InstanceLevelKey(t *testing.T) {
 		t.Errorf("Instance key mismatch: got %v, want %v", retrieved, instanceKey)
 	}
 }
+
+func TestKeyStorage_ExpiredKey(t *testing.T) {
+	storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Hour)
+	stored, err := storage.StoreKeyWithExpiry(ctx, 1, ProviderOpenAI, "sk-test-key-123456789012345678901234567890", past)
+	if err != nil {
+		t.Fatalf("StoreKeyWithExpiry() error: %v", err)
+	}
+	if stored.ExpiresAt == nil || !stored.ExpiresAt.Equal(past) {
+		t.Errorf("ExpiresAt = %v, want %v", stored.ExpiresAt, past)
+	}
+
+	if _, err := storage.GetKey(ctx, 1, ProviderOpenAI); err != ErrKeyExpired {
+		t.Errorf("GetKey() error = %v, want ErrKeyExpired", err)
+	}
+	if storage.HasKey(ctx, 1, ProviderOpenAI) {
+		t.Error("HasKey() should be false for an expired key")
+	}
+}
+
+func TestKeyStorage_UnexpiredKey(t *testing.T) {
+	storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
+	ctx := context.Background()
+
+	apiKey := "sk-test-key-123456789012345678901234567890"
+	storage.StoreKeyWithExpiry(ctx, 1, ProviderOpenAI, apiKey, time.Now().Add(time.Hour))
+
+	got, err := storage.GetKey(ctx, 1, ProviderOpenAI)
+	if err != nil {
+		t.Fatalf("GetKey() error: %v", err)
+	}
+	if got != apiKey {
+		t.Errorf("GetKey() = %q, want %q", got, apiKey)
+	}
+	if !storage.HasKey(ctx, 1, ProviderOpenAI) {
+		t.Error("HasKey() should be true before expiry")
+	}
+}
+
+func TestKeyStorage_ReplaceExpiredKey(t *testing.T) {
+	ctx := context.Background()
+	oldKey := "sk-test-key-123456789012345678901234567890"
+	newKey := "sk-test-key-abcdefghijabcdefghijabcdefghij"
+
+	tests := []struct {
+		name    string
+		replace func(storage *InMemoryKeyStorage) (*StoredAPIKey, error)
+	}{
+		{
+			name: "upsert",
+			replace: func(storage *InMemoryKeyStorage) (*StoredAPIKey, error) {
+				stored, _, err := storage.UpsertKey(ctx, 1, ProviderOpenAI, newKey)
+				return stored, err
+			},
+		},
+		{
+			name: "update",
+			replace: func(storage *InMemoryKeyStorage) (*StoredAPIKey, error) {
+				return storage.UpdateKey(ctx, 1, ProviderOpenAI, newKey)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
+			if _, err := storage.StoreKeyWithExpiry(ctx, 1, ProviderOpenAI, oldKey, time.Now().Add(-time.Hour)); err != nil {
+				t.Fatalf("StoreKeyWithExpiry() error: %v", err)
+			}
+
+			stored, err := tt.replace(storage)
+			if err != nil {
+				t.Fatalf("replace error: %v", err)
+			}
+			if stored.ExpiresAt != nil {
+				t.Errorf("ExpiresAt = %v, want nil after replacing the key", stored.ExpiresAt)
+			}
+
+			got, err := storage.GetKey(ctx, 1, ProviderOpenAI)
+			if err != nil {
+				t.Fatalf("GetKey() error: %v", err)
+			}
+			if got != newKey {
+				t.Errorf("GetKey() = %q, want %q", got, newKey)
+			}
+			if n := storage.PurgeExpiredKeys(ctx); n != 0 {
+				t.Errorf("PurgeExpiredKeys() = %d, want 0 for the replaced key", n)
+			}
+		})
+	}
+}
+
+func TestKeyStorage_PurgeExpiredKeys(t *testing.T) {
+	storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
+	ctx := context.Background()
+
+	storage.StoreKeyWithExpiry(ctx, 1, ProviderOpenAI, "sk-test-key-123456789012345678901234567890", time.Now().Add(-time.Minute))
+	storage.StoreKeyWithExpiry(ctx, 2, ProviderOpenAI, "sk-test-key-123456789012345678901234567890", time.Now().Add(time.Hour))
+	storage.StoreKey(ctx, 1, ProviderAnthropic, "sk-ant-REDACTED")
+
+	if n := storage.PurgeExpiredKeys(ctx); n != 1 {
+		t.Errorf("PurgeExpiredKeys() = %d, want 1", n)
+	}
+	if _, err := storage.GetStoredKey(ctx, 1, ProviderOpenAI); err != ErrKeyNotFound {
+		t.Errorf("GetStoredKey() error = %v, want ErrKeyNotFound after purge", err)
+	}
+	if !storage.HasKey(ctx, 2, ProviderOpenAI) || !storage.HasKey(ctx, 1, ProviderAnthropic) {
+		t.Error("unexpired keys should survive the purge")
+	}
+	if n := storage.PurgeExpiredKeys(ctx); n != 0 {
+		t.Errorf("second PurgeExpiredKeys() = %d, want 0", n)
+	}
+}
+
+func TestKeyStorage_DeleteAllKeys_MultipleProviders(t *testing.T) {
+	storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
+	ctx := context.Background()
+
+	storage.StoreKey(ctx, 1, ProviderOpenAI, "sk-test-key-123456789012345678901234567890")
+	storage.StoreKey(ctx, 1, ProviderAnthropic, "sk-ant-REDACTED")
+	storage.StoreKeyWithExpiry(ctx, 1, ProviderCohere, "cohere-test-key-1234567890123456789012", time.Now().Add(-time.Minute))
+	storage.StoreKey(ctx, 11, ProviderOpenAI, "sk-other-key-12345678901234567890123456")
+
+	if n, err := storage.CountKeys(ctx, 1); err != nil || n != 3 {
+		t.Fatalf("CountKeys() = %d, %v; want 3 including the expired key", n, err)
+	}
+
+	n, err := storage.DeleteAllKeys(ctx, 1)
+	if err != nil {
+		t.Fatalf("DeleteAllKeys() error: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("DeleteAllKeys() = %d, want 3", n)
+	}
+	if n, _ := storage.CountKeys(ctx, 1); n != 0 {
+		t.Errorf("CountKeys() after delete = %d, want 0", n)
+	}
+	if keys, _ := storage.ListKeys(ctx, 1); len(keys) != 0 {
+		t.Errorf("ListKeys() after delete = %d keys, want 0", len(keys))
+	}
+
+	// Other users' keys are untouched
+	if n, _ := storage.CountKeys(ctx, 11); n != 1 || !storage.HasKey(ctx, 11, ProviderOpenAI) {
+		t.Error("User 11 key should survive deleting user 1's keys")
+	}
+}
+
+func TestKeyStorage_DeleteAllKeys_NoKeys(t *testing.T) {
+	storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
+	ctx := context.Background()
+
+	if n, err := storage.CountKeys(ctx, 42); err != nil || n != 0 {
+		t.Errorf("CountKeys() = %d, %v; want 0, nil", n, err)
+	}
+	if n, err := storage.DeleteAllKeys(ctx, 42); err != nil || n != 0 {
+		t.Errorf("DeleteAllKeys() = %d, %v; want 0, nil", n, err)
+	}
+}
+
+func TestKeyStorage_ListKeysByProvider(t *testing.T) {
+	storage, _ := NewInMemoryKeyStorage("test-master-key-12345")
+	ctx := context.Background()
+
+	_, _ = storage.StoreKey(ctx, 1, ProviderOpenAI, "sk-openai-key-1234567890123456789012345")
+	_, _ = storage.StoreKey(ctx, 1, ProviderAnthropic, "sk-ant-REDACTED")
+	_, _ = storage.StoreKey(ctx, 2, ProviderCohere, "cohere-key-1234567890")
+
+	keys, err := storage.ListKeysByProvider(ctx, 1)
+	if err != nil {
+		t.Fatalf("ListKeysByProvider() error: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("ListKeysByProvider() returned %d keys, want 2", len(keys))
+	}
+	for _, providerType := range []ProviderType{ProviderOpenAI, ProviderAnthropic} {
+		key, ok := keys[providerType]
+		if !ok {
+			t.Errorf("Expected a key for %s", providerType)
+			continue
+		}
+		if key.ProviderType != providerType || key.MaskedKey == "" {
+			t.Errorf("Unexpected key for %s: %+v", providerType, key)
+		}
+	}
+	if _, ok := keys[ProviderCohere]; ok {
+		t.Error("Expected no entry for a provider without a key")
+	}
+
+	// ListKeys is ordered by provider type
+	list, _ := storage.ListKeys(ctx, 1)
+	if len(list) != 2 || list[0].ProviderType != ProviderAnthropic || list[1].ProviderType != ProviderOpenAI {
+		t.Errorf("Expected keys ordered by provider type, got %v and %v", list[0].ProviderType, list[1].ProviderType)
+	}
+}
@@ -0,0 +1,200 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// CompleteFunc performs a chat completion.
+type CompleteFunc func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error)
+
+// EmbedFunc generates embeddings.
+type EmbedFunc func(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error)
+
+// SuggestTagsFunc suggests tags for content.
+type SuggestTagsFunc func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error)
+
+// SummarizeFunc summarizes content.
+type SummarizeFunc func(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error)
+
+// SuggestTitleFunc suggests a title for content.
+type SuggestTitleFunc func(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error)
+
+// Operations bundles the core Service operations that middleware can wrap.
+type Operations struct {
+	Complete     CompleteFunc
+	Embed        EmbedFunc
+	SuggestTags  SuggestTagsFunc
+	Summarize    SummarizeFunc
+	SuggestTitle SuggestTitleFunc
+}
+
+// Middleware wraps the core Service operations, e.g. for logging, metrics,
+// retries, or caching. It receives the next operations in the chain and returns
+// the wrapped ones; operations it doesn't care about can be passed through as-is.
+type Middleware func(next Operations) Operations
+
+// WithMiddleware adds middleware around the service's core operations. The first
+// middleware is the outermost: it runs first on the way in and last on the way out.
+// Repeated options append to the chain.
+func WithMiddleware(mw ...Middleware) ServiceOption {
+	return func(s *service) {
+		s.middleware = append(s.middleware, mw...)
+	}
+}
+
+// chainMiddleware wraps core with mw so that mw[0] is the outermost layer.
+// Operations a middleware leaves nil pass through to the next layer.
+func chainMiddleware(core Operations, mw []Middleware) Operations {
+	ops := core
+	for i := len(mw) - 1; i >= 0; i-- {
+		ops = passThrough(mw[i](ops), ops)
+	}
+	return ops
+}
+
+// passThrough fills the nil operations of wrapped from next.
+func passThrough(wrapped, next Operations) Operations {
+	if wrapped.Complete == nil {
+		wrapped.Complete = next.Complete
+	}
+	if wrapped.Embed == nil {
+		wrapped.Embed = next.Embed
+	}
+	if wrapped.SuggestTags == nil {
+		wrapped.SuggestTags = next.SuggestTags
+	}
+	if wrapped.Summarize == nil {
+		wrapped.Summarize = next.Summarize
+	}
+	if wrapped.SuggestTitle == nil {
+		wrapped.SuggestTitle = next.SuggestTitle
+	}
+	return wrapped
+}
+
+// Operation names reported by TimingMiddleware.
+const (
+	OperationComplete     = "complete"
+	OperationEmbed        = "embed"
+	OperationSuggestTags  = "suggest_tags"
+	OperationSummarize    = "summarize"
+	OperationSuggestTitle = "suggest_title"
+)
+
+// TimingMiddleware reports the latency and outcome of every operation to record,
+// e.g. to feed a metrics histogram. op is one of the Operation* constants.
+func TimingMiddleware(record func(op string, latency time.Duration, err error)) Middleware {
+	return func(next Operations) Operations {
+		return Operations{
+			Complete: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+				start := time.Now()
+				resp, err := next.Complete(ctx, req)
+				record(OperationComplete, time.Since(start), err)
+				return resp, err
+			},
+			Embed: func(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+				start := time.Now()
+				resp, err := next.Embed(ctx, req)
+				record(OperationEmbed, time.Since(start), err)
+				return resp, err
+			},
+			SuggestTags: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+				start := time.Now()
+				resp, err := next.SuggestTags(ctx, req)
+				record(OperationSuggestTags, time.Since(start), err)
+				return resp, err
+			},
+			Summarize: func(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error) {
+				start := time.Now()
+				resp, err := next.Summarize(ctx, req)
+				record(OperationSummarize, time.Since(start), err)
+				return resp, err
+			},
+			SuggestTitle: func(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error) {
+				start := time.Now()
+				resp, err := next.SuggestTitle(ctx, req)
+				record(OperationSuggestTitle, time.Since(start), err)
+				return resp, err
+			},
+		}
+	}
+}
+
+// RetryMiddleware retries operations that fail with a transient error (rate
+// limiting, provider unavailability, or a network timeout), backing off according
+// to policy. Zero-valued policy fields use the DoRequest defaults, with 3 attempts.
+// If policy.ShouldRetry is set it replaces the transient check and is called with
+// a status code of 0.
+//
+// Providers already retry individual HTTP requests, so this is most useful for
+// errors raised above the HTTP layer, such as the service's own rate limiter.
+func RetryMiddleware(policy RetryPolicy) Middleware {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	shouldRetry := policy.ShouldRetry
+	if shouldRetry == nil {
+		shouldRetry = func(_ int, err error) bool { return isTransientError(err) }
+	}
+	policy = policy.withDefaults()
+
+	return func(next Operations) Operations {
+		return Operations{
+			Complete: func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+				return withRetry(ctx, policy, shouldRetry, func() (*CompletionResponse, error) { return next.Complete(ctx, req) })
+			},
+			Embed: func(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+				return withRetry(ctx, policy, shouldRetry, func() (*EmbeddingResponse, error) { return next.Embed(ctx, req) })
+			},
+			SuggestTags: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+				return withRetry(ctx, policy, shouldRetry, func() (*SuggestTagsResponse, error) { return next.SuggestTags(ctx, req) })
+			},
+			Summarize: func(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error) {
+				return withRetry(ctx, policy, shouldRetry, func() (*SummarizeResponse, error) { return next.Summarize(ctx, req) })
+			},
+			SuggestTitle: func(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error) {
+				return withRetry(ctx, policy, shouldRetry, func() (*SuggestTitleResponse, error) { return next.SuggestTitle(ctx, req) })
+			},
+		}
+	}
+}
+
+// withRetry calls fn until it succeeds, shouldRetry rejects the error, attempts
+// run out, or ctx is done.
+func withRetry[T any](ctx context.Context, policy RetryPolicy, shouldRetry func(int, error) bool, fn func() (T, error)) (T, error) {
+	var resp T
+	var err error
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		resp, err = fn()
+		if err == nil || attempt == policy.MaxAttempts || ctx.Err() != nil || !shouldRetry(0, err) {
+			return resp, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return resp, err
+		case <-time.After(policy.backoff(attempt)):
+		}
+	}
+	return resp, err
+}
+
+// isTransientError reports whether err is likely to succeed on retry. Provider
+// errors are classified by their Retryable flag.
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var provErr *ProviderError
+	if errors.As(err, &provErr) {
+		return provErr.Retryable
+	}
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrProviderUnavailable) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
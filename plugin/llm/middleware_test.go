@@ -0,0 +1,195 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// tracingMiddleware appends "<name>:before" and "<name>:after" around Complete.
+func tracingMiddleware(name string, trace *[]string) Middleware {
+	return func(next Operations) Operations {
+		wrapped := next
+		wrapped.Complete = func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			*trace = append(*trace, name+":before")
+			resp, err := next.Complete(ctx, req)
+			*trace = append(*trace, name+":after")
+			return resp, err
+		}
+		return wrapped
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var trace []string
+	provider := &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		completeResp: &CompletionResponse{Content: "ok"},
+	}
+	svc := NewService(
+		WithMiddleware(tracingMiddleware("outer", &trace)),
+		WithMiddleware(tracingMiddleware("inner", &trace)),
+	)
+	svc.RegisterProvider(provider)
+
+	resp, err := svc.Complete(context.Background(), &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "Hi"}}})
+	if err != nil || resp.Content != "ok" {
+		t.Fatalf("Complete() = %v, %v", resp, err)
+	}
+	if provider.completeCalls != 1 {
+		t.Errorf("Expected 1 provider call, got %d", provider.completeCalls)
+	}
+
+	expected := []string{"outer:before", "inner:before", "inner:after", "outer:after"}
+	if fmt.Sprint(trace) != fmt.Sprint(expected) {
+		t.Errorf("Expected order %v, got %v", expected, trace)
+	}
+
+	// Operations a middleware doesn't wrap pass straight through
+	trace = nil
+	provider.summarizeResp = &SummarizeResponse{Summary: "short"}
+	if _, err := svc.Summarize(context.Background(), &SummarizeRequest{Content: "text"}); err != nil {
+		t.Fatalf("Summarize() error: %v", err)
+	}
+	if len(trace) != 0 {
+		t.Errorf("Expected Summarize to bypass Complete middleware, got %v", trace)
+	}
+}
+
+func TestTimingMiddleware(t *testing.T) {
+	type record struct {
+		op  string
+		err error
+	}
+	var records []record
+	provider := &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		completeResp: &CompletionResponse{Content: "ok"},
+		embedErr:     ErrModelNotFound,
+	}
+	svc := NewService(WithMiddleware(TimingMiddleware(func(op string, latency time.Duration, err error) {
+		if latency < 0 {
+			t.Errorf("Negative latency for %s", op)
+		}
+		records = append(records, record{op, err})
+	})))
+	svc.RegisterProvider(provider)
+
+	svc.Complete(context.Background(), &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "Hi"}}})
+	svc.Embed(context.Background(), &EmbeddingRequest{Input: []string{"Hi"}})
+
+	if len(records) != 2 || records[0].op != OperationComplete || records[0].err != nil {
+		t.Fatalf("Unexpected records %+v", records)
+	}
+	if records[1].op != OperationEmbed || !errors.Is(records[1].err, ErrModelNotFound) {
+		t.Errorf("Expected embed failure to be recorded, got %+v", records[1])
+	}
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	provider := &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		completeErr:  ErrProviderUnavailable,
+	}
+	svc := NewService(WithMiddleware(RetryMiddleware(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+	})))
+	svc.RegisterProvider(provider)
+
+	req := &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "Hi"}}}
+	if _, err := svc.Complete(context.Background(), req); !errors.Is(err, ErrProviderUnavailable) {
+		t.Fatalf("Expected ErrProviderUnavailable after retries, got %v", err)
+	}
+	if provider.completeCalls != 3 {
+		t.Errorf("Expected 3 attempts for a transient error, got %d", provider.completeCalls)
+	}
+
+	// Permanent errors are not retried
+	provider.completeCalls = 0
+	provider.completeErr = ErrInvalidAPIKey
+	if _, err := svc.Complete(context.Background(), req); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("Expected ErrInvalidAPIKey, got %v", err)
+	}
+	if provider.completeCalls != 1 {
+		t.Errorf("Expected 1 attempt for a permanent error, got %d", provider.completeCalls)
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limited", ErrRateLimited, true},
+		{"unavailable", fmt.Errorf("wrapped: %w", ErrProviderUnavailable), true},
+		{"invalid key", ErrInvalidAPIKey, false},
+		{"canceled", context.Canceled, false},
+		{"retryable provider error", &ProviderError{StatusCode: 503, Retryable: true}, true},
+		{"permanent provider error", &ProviderError{StatusCode: 400}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientError(tt.err); got != tt.want {
+				t.Errorf("isTransientError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryMiddlewareWithProviderRateLimit(t *testing.T) {
+	provider := &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		completeResp: &CompletionResponse{Content: "ok"},
+	}
+	// 1200/min refills a token every 50ms; retries wait it out
+	svc := NewService(
+		WithProviderRateLimit(ProviderOpenAI, ProviderRateLimit{RequestsPerMinute: 1200, Burst: 1}),
+		WithMiddleware(RetryMiddleware(RetryPolicy{
+			MaxAttempts: 5,
+			BaseDelay:   30 * time.Millisecond,
+			Jitter:      JitterNone,
+		})),
+	)
+	svc.RegisterProvider(provider)
+
+	req := &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "Hi"}}}
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Complete(context.Background(), req); err != nil {
+			t.Fatalf("Complete() #%d error: %v", i+1, err)
+		}
+	}
+}
+
+func TestMiddlewarePassesThroughNilOperations(t *testing.T) {
+	provider := &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		titleResp:    &SuggestTitleResponse{Title: "Title"},
+	}
+
+	// A middleware that only sets Complete must not break the other operations
+	completeOnly := func(next Operations) Operations {
+		return Operations{Complete: next.Complete}
+	}
+	svc := NewService(WithMiddleware(completeOnly))
+	svc.RegisterProvider(provider)
+
+	resp, err := svc.SuggestTitle(context.Background(), &SuggestTitleRequest{Content: "content"})
+	if err != nil || resp.Title != "Title" {
+		t.Errorf("SuggestTitle() = %+v, %v", resp, err)
+	}
+}
@@ -0,0 +1,277 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	mockEchoModel               = "echo"
+	mockEchoEmbeddingDimensions = 64
+)
+
+// mockEchoStopwords are common words skipped when suggesting tags.
+var mockEchoStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true,
+	"you": true, "all": true, "can": true, "was": true, "this": true, "that": true,
+	"with": true, "have": true, "from": true, "they": true, "will": true, "what": true,
+	"when": true, "your": true, "about": true, "there": true, "their": true, "which": true,
+	"would": true, "should": true, "could": true, "into": true, "than": true, "then": true,
+	"them": true, "these": true, "those": true, "been": true, "were": true, "has": true,
+	"had": true, "its": true, "our": true, "out": true, "also": true, "just": true,
+}
+
+// MockEchoProvider is a deterministic provider that never touches the network.
+// It is intended for CI, demos, and offline deployments: completions echo the
+// last user message, embeddings are derived from a hash of the input, and tags
+// are the content's most frequent words.
+type MockEchoProvider struct {
+	*BaseProvider
+	defaultModel string
+}
+
+// NewMockEchoProvider creates a new echo provider.
+func NewMockEchoProvider(config *ProviderConfig) *MockEchoProvider {
+	defaultModel := mockEchoModel
+	if config.DefaultModel != "" {
+		defaultModel = config.DefaultModel
+	}
+
+	return &MockEchoProvider{
+		BaseProvider: NewBaseProvider(config),
+		defaultModel: defaultModel,
+	}
+}
+
+// GetType returns the provider type.
+func (p *MockEchoProvider) GetType() ProviderType {
+	return ProviderMock
+}
+
+// GetName returns the display name.
+func (p *MockEchoProvider) GetName() string {
+	return "Mock (Echo)"
+}
+
+// IsConfigured always reports true; the provider needs no credentials.
+func (p *MockEchoProvider) IsConfigured(ctx context.Context) bool {
+	return true
+}
+
+// GetDefaultModel returns the default model.
+func (p *MockEchoProvider) GetDefaultModel() string {
+	return p.defaultModel
+}
+
+// GetAvailableModels returns the single echo model.
+func (p *MockEchoProvider) GetAvailableModels(ctx context.Context) ([]string, error) {
+	return []string{p.defaultModel}, nil
+}
+
+// GetModelInfo returns the available models annotated with known limits.
+func (p *MockEchoProvider) GetModelInfo(ctx context.Context) ([]ModelInfo, error) {
+	return p.DefaultGetModelInfo(ctx, p)
+}
+
+// Complete returns the content of the last user message.
+func (p *MockEchoProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var content string
+	promptWords := 0
+	for _, msg := range req.Messages {
+		promptWords += len(strings.Fields(msg.Content))
+		if msg.Role == RoleUser {
+			content = msg.Content
+		}
+	}
+	completionWords := len(strings.Fields(content))
+
+	return &CompletionResponse{
+		Content: content,
+		Model:   p.model(req.Model),
+		Usage: &TokenUsage{
+			PromptTokens:     promptWords,
+			CompletionTokens: completionWords,
+			TotalTokens:      promptWords + completionWords,
+		},
+		FinishReason: "stop",
+	}, nil
+}
+
+// Embed returns unit-length vectors derived from a SHA-256 hash of each input.
+// Identical inputs always produce identical embeddings.
+func (p *MockEchoProvider) Embed(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	dims := req.Dimensions
+	if dims <= 0 {
+		dims = mockEchoEmbeddingDimensions
+	}
+
+	embeddings := make([][]float32, len(req.Input))
+	tokens := 0
+	for i, input := range req.Input {
+		embeddings[i] = hashEmbedding(input, dims)
+		tokens += len(strings.Fields(input))
+	}
+
+	return &EmbeddingResponse{
+		Embeddings: embeddings,
+		Model:      p.model(req.Model),
+		Usage: &TokenUsage{
+			PromptTokens: tokens,
+			TotalTokens:  tokens,
+		},
+	}, nil
+}
+
+// SuggestTags returns the most frequent non-stopword words in the content,
+// with confidence relative to the most frequent one. Ties are broken alphabetically.
+func (p *MockEchoProvider) SuggestTags(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(req.Content), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+	for _, word := range words {
+		word = strings.Trim(word, "-")
+		if len([]rune(word)) < 3 || mockEchoStopwords[word] || !isValidTag(word) {
+			continue
+		}
+		counts[word]++
+	}
+
+	candidates := make([]string, 0, len(counts))
+	for word := range counts {
+		candidates = append(candidates, word)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if counts[candidates[i]] != counts[candidates[j]] {
+			return counts[candidates[i]] > counts[candidates[j]]
+		}
+		return candidates[i] < candidates[j]
+	})
+
+	maxTags := tagLimit(req)
+	if len(candidates) > maxTags {
+		candidates = candidates[:maxTags]
+	}
+
+	confidence := make([]float64, len(candidates))
+	for i, tag := range candidates {
+		confidence[i] = float64(counts[tag]) / float64(counts[candidates[0]])
+	}
+
+	return &SuggestTagsResponse{
+		Tags:       candidates,
+		Confidence: confidence,
+	}, nil
+}
+
+// Summarize returns the content truncated to MaxLength characters at a word boundary.
+func (p *MockEchoProvider) Summarize(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	maxLength := req.MaxLength
+	if maxLength == 0 {
+		maxLength = 200
+	}
+
+	summary := strings.Join(strings.Fields(req.Content), " ")
+	if runes := []rune(summary); len(runes) > maxLength {
+		summary = string(runes[:maxLength])
+		if i := strings.LastIndex(summary, " "); i > 0 {
+			summary = summary[:i]
+		}
+	}
+
+	return &SummarizeResponse{
+		Summary: summary,
+	}, nil
+}
+
+// SuggestTitle returns the first line of the content, cleaned and truncated to MaxLength characters.
+func (p *MockEchoProvider) SuggestTitle(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	maxLength := req.MaxLength
+	if maxLength <= 0 {
+		maxLength = defaultTitleMaxLength
+	}
+
+	return &SuggestTitleResponse{
+		Title: cleanTitle(req.Content, maxLength),
+	}, nil
+}
+
+// Capabilities returns the features supported by the echo provider.
+func (p *MockEchoProvider) Capabilities() ProviderCapabilities {
+	return ProviderCapabilities{
+		Embeddings: true,
+	}
+}
+
+// CheckHealth always succeeds.
+func (p *MockEchoProvider) CheckHealth(ctx context.Context) error {
+	return nil
+}
+
+// ValidateConfig always succeeds; the provider has nothing to configure.
+func (p *MockEchoProvider) ValidateConfig(ctx context.Context) error {
+	return nil
+}
+
+// model returns the requested model, or the default if none was requested.
+func (p *MockEchoProvider) model(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	return p.defaultModel
+}
+
+// hashEmbedding expands a SHA-256 hash of input into a unit vector of dims components.
+func hashEmbedding(input string, dims int) []float32 {
+	vec := make([]float32, dims)
+	var sumSquares float64
+	var block [sha256.Size]byte
+	for i := 0; i < dims; i++ {
+		// Each block of the hash provides 8 components
+		if i%8 == 0 {
+			h := sha256.New()
+			h.Write([]byte(input))
+			binary.Write(h, binary.BigEndian, uint32(i/8))
+			copy(block[:], h.Sum(nil))
+		}
+		raw := binary.BigEndian.Uint32(block[(i%8)*4:])
+		v := float64(raw)/math.MaxUint32*2 - 1
+		vec[i] = float32(v)
+		sumSquares += v * v
+	}
+
+	if norm := math.Sqrt(sumSquares); norm > 0 {
+		for i := range vec {
+			vec[i] = float32(float64(vec[i]) / norm)
+		}
+	}
+	return vec
+}
+
+// Ensure MockEchoProvider implements Provider.
+var _ Provider = (*MockEchoProvider)(nil)
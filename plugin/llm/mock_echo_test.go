@@ -0,0 +1,130 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestMockEchoProviderComplete(t *testing.T) {
+	provider := NewMockEchoProvider(DefaultConfig(ProviderMock))
+
+	resp, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{
+			{Role: RoleSystem, Content: "Be brief."},
+			{Role: RoleUser, Content: "first"},
+			{Role: RoleAssistant, Content: "ok"},
+			{Role: RoleUser, Content: "echo me back"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if resp.Content != "echo me back" {
+		t.Errorf("Expected last user message, got %q", resp.Content)
+	}
+	if resp.Model != "echo" {
+		t.Errorf("Expected model echo, got %q", resp.Model)
+	}
+	if resp.Usage == nil || resp.Usage.CompletionTokens != 3 {
+		t.Errorf("Expected 3 completion tokens, got %+v", resp.Usage)
+	}
+}
+
+func TestMockEchoProviderEmbedDeterministic(t *testing.T) {
+	provider := NewMockEchoProvider(DefaultConfig(ProviderMock))
+	ctx := context.Background()
+
+	first, err := provider.Embed(ctx, &EmbeddingRequest{Input: []string{"hello world", "goodbye"}})
+	if err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+	second, _ := provider.Embed(ctx, &EmbeddingRequest{Input: []string{"hello world"}})
+
+	if len(first.Embeddings[0]) != mockEchoEmbeddingDimensions {
+		t.Fatalf("Expected %d dimensions, got %d", mockEchoEmbeddingDimensions, len(first.Embeddings[0]))
+	}
+	if fmt.Sprint(first.Embeddings[0]) != fmt.Sprint(second.Embeddings[0]) {
+		t.Error("Expected the same input to produce the same embedding")
+	}
+	if fmt.Sprint(first.Embeddings[0]) == fmt.Sprint(first.Embeddings[1]) {
+		t.Error("Expected different inputs to produce different embeddings")
+	}
+
+	var sumSquares float64
+	for _, v := range first.Embeddings[0] {
+		sumSquares += float64(v) * float64(v)
+	}
+	if math.Abs(sumSquares-1) > 1e-4 {
+		t.Errorf("Expected a unit vector, got squared norm %f", sumSquares)
+	}
+
+	custom, _ := provider.Embed(ctx, &EmbeddingRequest{Input: []string{"hello world"}, Dimensions: 10})
+	if len(custom.Embeddings[0]) != 10 {
+		t.Errorf("Expected 10 dimensions, got %d", len(custom.Embeddings[0]))
+	}
+}
+
+func TestMockEchoProviderSuggestTagsDeterministic(t *testing.T) {
+	provider := NewMockEchoProvider(DefaultConfig(ProviderMock))
+	content := "Golang testing notes. Testing golang code with table tests; the golang toolchain has testing built in."
+
+	req := &SuggestTagsRequest{Content: content, MaxTags: 3}
+	first, err := provider.SuggestTags(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SuggestTags() error: %v", err)
+	}
+
+	expected := []string{"golang", "testing", "built"}
+	if fmt.Sprint(first.Tags) != fmt.Sprint(expected) {
+		t.Errorf("Expected tags %v, got %v", expected, first.Tags)
+	}
+	if first.Confidence[0] != 1 {
+		t.Errorf("Expected top tag confidence 1, got %v", first.Confidence)
+	}
+
+	for i := 0; i < 5; i++ {
+		again, _ := provider.SuggestTags(context.Background(), req)
+		if fmt.Sprint(again.Tags) != fmt.Sprint(first.Tags) {
+			t.Fatalf("Expected deterministic tags, got %v then %v", first.Tags, again.Tags)
+		}
+	}
+}
+
+func TestMockEchoProviderSummarize(t *testing.T) {
+	provider := NewMockEchoProvider(DefaultConfig(ProviderMock))
+
+	resp, err := provider.Summarize(context.Background(), &SummarizeRequest{
+		Content:   "The quick brown fox jumps over the lazy dog",
+		MaxLength: 20,
+	})
+	if err != nil {
+		t.Fatalf("Summarize() error: %v", err)
+	}
+	if resp.Summary != "The quick brown fox" {
+		t.Errorf("Expected truncation at a word boundary, got %q", resp.Summary)
+	}
+}
+
+func TestMockEchoProviderRegistration(t *testing.T) {
+	config := DefaultConfig(ProviderMock)
+	if config.DefaultModel != "echo" {
+		t.Errorf("Expected default model echo, got %q", config.DefaultModel)
+	}
+
+	svc := NewService()
+	if err := svc.RegisterProvider(NewMockEchoProvider(config)); err != nil {
+		t.Fatalf("RegisterProvider() error: %v", err)
+	}
+	if provider := svc.GetProvider(); provider == nil || provider.GetType() != ProviderMock {
+		t.Fatalf("Expected the mock provider to be auto-selected, got %v", provider)
+	}
+
+	resp, err := svc.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "ping"}},
+	})
+	if err != nil || resp.Content != "ping" {
+		t.Errorf("Expected echoed completion through the service, got %v, %v", resp, err)
+	}
+}
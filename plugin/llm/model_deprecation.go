@@ -0,0 +1,101 @@
+package llm
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// ModelDeprecation describes a model its provider has deprecated or retired.
+type ModelDeprecation struct {
+	// Replacement is the recommended model to switch to (may be empty).
+	Replacement string `json:"replacement,omitempty"`
+
+	// Retired indicates the provider no longer serves the model; otherwise
+	// it is deprecated and scheduled for removal.
+	Retired bool `json:"retired,omitempty"`
+}
+
+var (
+	modelDeprecationsMu sync.RWMutex
+
+	// modelDeprecations maps an exact model name to its deprecation. Seeded with
+	// published deprecations; extend with RegisterModelDeprecation.
+	modelDeprecations = map[string]ModelDeprecation{
+		// OpenAI
+		"gpt-3.5-turbo-0301":   {Replacement: "gpt-4o-mini", Retired: true},
+		"gpt-3.5-turbo-0613":   {Replacement: "gpt-4o-mini", Retired: true},
+		"gpt-3.5-turbo-16k":    {Replacement: "gpt-4o-mini", Retired: true},
+		"gpt-4-0314":           {Replacement: "gpt-4o", Retired: true},
+		"gpt-4-32k":            {Replacement: "gpt-4o", Retired: true},
+		"gpt-4-vision-preview": {Replacement: "gpt-4o", Retired: true},
+		"text-davinci-003":     {Replacement: "gpt-4o-mini", Retired: true},
+
+		// Anthropic
+		"claude-instant-1.2":       {Replacement: "claude-3-5-haiku-latest", Retired: true},
+		"claude-2.0":               {Replacement: "claude-sonnet-4-0", Retired: true},
+		"claude-2.1":               {Replacement: "claude-sonnet-4-0", Retired: true},
+		"claude-3-sonnet-20240229": {Replacement: "claude-sonnet-4-0", Retired: true},
+		"claude-3-opus-20240229":   {Replacement: "claude-opus-4-0"},
+
+		// Gemini
+		"gemini-1.0-pro": {Replacement: "gemini-1.5-flash", Retired: true},
+
+		// Cohere
+		"command-light": {Replacement: "command-r-08-2024"},
+	}
+)
+
+// RegisterModelDeprecation marks a model as deprecated, overriding any existing entry.
+func RegisterModelDeprecation(model string, deprecation ModelDeprecation) {
+	modelDeprecationsMu.Lock()
+	defer modelDeprecationsMu.Unlock()
+
+	modelDeprecations[model] = deprecation
+}
+
+// LookupModelDeprecation returns the deprecation registered for model, if any.
+// Only exact model names match, so a current snapshot of a model family isn't
+// flagged because an older one was deprecated.
+func LookupModelDeprecation(model string) (ModelDeprecation, bool) {
+	modelDeprecationsMu.RLock()
+	defer modelDeprecationsMu.RUnlock()
+
+	deprecation, ok := modelDeprecations[model]
+	return deprecation, ok
+}
+
+// deprecationWarning returns a warning for model if it is registered as
+// deprecated, or "" otherwise.
+func deprecationWarning(model string) string {
+	deprecation, ok := LookupModelDeprecation(model)
+	if !ok {
+		return ""
+	}
+
+	status := "deprecated"
+	if deprecation.Retired {
+		status = "retired"
+	}
+	if deprecation.Replacement == "" {
+		return fmt.Sprintf("model %s is %s", model, status)
+	}
+	return fmt.Sprintf("model %s is %s; switch to %s", model, status, deprecation.Replacement)
+}
+
+// warnDeprecatedModel returns the deprecation warning for model, logging it
+// the first time the service sees the model.
+func (s *service) warnDeprecatedModel(provider Provider, model string) string {
+	warning := deprecationWarning(model)
+	if warning == "" {
+		return ""
+	}
+
+	if _, logged := s.deprecationsLogged.LoadOrStore(model, true); !logged {
+		slog.Warn("LLM model deprecated",
+			slog.String("provider", string(provider.GetType())),
+			slog.String("model", model),
+			slog.String("warning", warning))
+	}
+	return warning
+}
@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCompleteWarnsOnDeprecatedModel(t *testing.T) {
+	RegisterModelDeprecation("test-old-model", ModelDeprecation{Replacement: "test-new-model"})
+
+	tests := []struct {
+		name         string
+		requested    string
+		defaultModel string
+		wantWarning  string
+	}{
+		{"requested deprecated model", "test-old-model", "gpt-4o-mini", "model test-old-model is deprecated; switch to test-new-model"},
+		{"default deprecated model", "", "test-old-model", "model test-old-model is deprecated; switch to test-new-model"},
+		{"retired model", "gpt-3.5-turbo-0301", "gpt-4o-mini", "model gpt-3.5-turbo-0301 is retired; switch to gpt-4o-mini"},
+		{"current model", "gpt-4o-mini", "gpt-4o-mini", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewService()
+			svc.RegisterProvider(&mockProvider{
+				providerType: ProviderOpenAI,
+				name:         "OpenAI",
+				configured:   true,
+				defaultModel: tt.defaultModel,
+				completeResp: &CompletionResponse{Content: "Hi"},
+			})
+
+			resp, err := svc.Complete(context.Background(), &CompletionRequest{
+				Model:    tt.requested,
+				Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+			})
+			if err != nil {
+				t.Fatalf("Complete() error: %v", err)
+			}
+
+			if tt.wantWarning == "" {
+				if len(resp.Warnings) != 0 {
+					t.Errorf("Expected no warnings, got %v", resp.Warnings)
+				}
+				return
+			}
+			if len(resp.Warnings) != 1 || resp.Warnings[0] != tt.wantWarning {
+				t.Errorf("Expected warning %q, got %v", tt.wantWarning, resp.Warnings)
+			}
+		})
+	}
+}
+
+func TestLookupModelDeprecation_ExactMatch(t *testing.T) {
+	if _, ok := LookupModelDeprecation("gpt-3.5-turbo-0301"); !ok {
+		t.Error("Expected gpt-3.5-turbo-0301 to be deprecated")
+	}
+	// A deprecated snapshot doesn't flag the rest of the family
+	if _, ok := LookupModelDeprecation("gpt-3.5-turbo-0125"); ok {
+		t.Error("Expected gpt-3.5-turbo-0125 not to be deprecated")
+	}
+}
@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// ModelInfo describes a model's limits and capabilities.
+type ModelInfo struct {
+	// ID is the model identifier as accepted by the provider.
+	ID string `json:"id"`
+
+	// ContextWindow is the maximum number of input tokens (0 if unknown).
+	ContextWindow int `json:"context_window,omitempty"`
+
+	// MaxOutputTokens is the maximum number of tokens the model can generate (0 if unknown).
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"`
+
+	// SupportsEmbeddings indicates the model produces embeddings rather than completions.
+	SupportsEmbeddings bool `json:"supports_embeddings"`
+}
+
+var (
+	modelInfoMu sync.RWMutex
+
+	// modelInfoRegistry maps a model name (or model name prefix) to its metadata.
+	// Seeded with published limits; override with RegisterModelInfo.
+	modelInfoRegistry = map[string]ModelInfo{
+		// OpenAI
+		"gpt-4o-mini":            {ContextWindow: 128000, MaxOutputTokens: 16384},
+		"gpt-4o":                 {ContextWindow: 128000, MaxOutputTokens: 16384},
+		"gpt-4-turbo":            {ContextWindow: 128000, MaxOutputTokens: 4096},
+		"gpt-4":                  {ContextWindow: 8192, MaxOutputTokens: 8192},
+		"gpt-3.5-turbo":          {ContextWindow: 16385, MaxOutputTokens: 4096},
+		"o1-mini":                {ContextWindow: 128000, MaxOutputTokens: 65536},
+		"o1":                     {ContextWindow: 200000, MaxOutputTokens: 100000},
+		"text-embedding-3-small": {ContextWindow: 8191, SupportsEmbeddings: true},
+		"text-embedding-3-large": {ContextWindow: 8191, SupportsEmbeddings: true},
+		"text-embedding-ada-002": {ContextWindow: 8191, SupportsEmbeddings: true},
+
+		// Anthropic
+		"claude-opus-4":     {ContextWindow: 200000, MaxOutputTokens: 32000},
+		"claude-sonnet-4":   {ContextWindow: 200000, MaxOutputTokens: 64000},
+		"claude-3-7-sonnet": {ContextWindow: 200000, MaxOutputTokens: 64000},
+		"claude-3-5-sonnet": {ContextWindow: 200000, MaxOutputTokens: 8192},
+		"claude-3-5-haiku":  {ContextWindow: 200000, MaxOutputTokens: 8192},
+		"claude-3-opus":     {ContextWindow: 200000, MaxOutputTokens: 4096},
+		"claude-3-sonnet":   {ContextWindow: 200000, MaxOutputTokens: 4096},
+		"claude-3-haiku":    {ContextWindow: 200000, MaxOutputTokens: 4096},
+
+		// Cohere
+		"command-r-plus":          {ContextWindow: 128000, MaxOutputTokens: 4096},
+		"command-r":               {ContextWindow: 128000, MaxOutputTokens: 4096},
+		"command-light":           {ContextWindow: 4096, MaxOutputTokens: 4096},
+		"command":                 {ContextWindow: 4096, MaxOutputTokens: 4096},
+		"embed-english-v3.0":      {ContextWindow: 512, SupportsEmbeddings: true},
+		"embed-multilingual-v3.0": {ContextWindow: 512, SupportsEmbeddings: true},
+
+		// Groq
+		"llama-3.1-8b-instant":    {ContextWindow: 131072, MaxOutputTokens: 131072},
+		"llama-3.3-70b-versatile": {ContextWindow: 131072, MaxOutputTokens: 32768},
+
+		// Ollama (default context windows of the published models)
+		"llama3.2":          {ContextWindow: 131072},
+		"llama3.1":          {ContextWindow: 131072},
+		"llama3":            {ContextWindow: 8192},
+		"mistral":           {ContextWindow: 32768},
+		"qwen2.5":           {ContextWindow: 32768},
+		"nomic-embed-text":  {ContextWindow: 8192, SupportsEmbeddings: true},
+		"mxbai-embed-large": {ContextWindow: 512, SupportsEmbeddings: true},
+	}
+)
+
+// RegisterModelInfo sets the metadata for a model, overriding any existing entry.
+// The model name also matches versioned variants (e.g., "gpt-4o" matches "gpt-4o-2024-08-06").
+func RegisterModelInfo(model string, info ModelInfo) {
+	modelInfoMu.Lock()
+	defer modelInfoMu.Unlock()
+
+	info.ID = ""
+	modelInfoRegistry[model] = info
+}
+
+// LookupModelInfo returns the registered metadata for a model, with ID set to model.
+// An exact match wins; otherwise the longest registered prefix is used.
+func LookupModelInfo(model string) (ModelInfo, bool) {
+	modelInfoMu.RLock()
+	defer modelInfoMu.RUnlock()
+
+	info, ok := modelInfoRegistry[model]
+	if !ok {
+		var best string
+		for name := range modelInfoRegistry {
+			if strings.HasPrefix(model, name) && len(name) > len(best) {
+				best = name
+			}
+		}
+		if best == "" {
+			return ModelInfo{ID: model}, false
+		}
+		info = modelInfoRegistry[best]
+	}
+
+	info.ID = model
+	return info, true
+}
+
+// DefaultGetModelInfo lists the provider's available models and annotates them
+// from the model registry. Unknown models are returned with only ID set.
+func (b *BaseProvider) DefaultGetModelInfo(ctx context.Context, provider Provider) ([]ModelInfo, error) {
+	models, err := provider.GetAvailableModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]ModelInfo, 0, len(models))
+	for _, model := range models {
+		info, _ := LookupModelInfo(model)
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
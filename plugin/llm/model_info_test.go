@@ -0,0 +1,96 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLookupModelInfo(t *testing.T) {
+	tests := []struct {
+		model              string
+		contextWindow      int
+		maxOutputTokens    int
+		supportsEmbeddings bool
+	}{
+		{"gpt-4o", 128000, 16384, false},
+		{"gpt-4o-2024-08-06", 128000, 16384, false},
+		{"gpt-4o-mini", 128000, 16384, false},
+		{"gpt-4", 8192, 8192, false},
+		{"claude-3-5-sonnet-20241022", 200000, 8192, false},
+		{"claude-3-haiku-20240307", 200000, 4096, false},
+		{"command-r-plus", 128000, 4096, false},
+		{"text-embedding-3-small", 8191, 0, true},
+		{"nomic-embed-text:latest", 8192, 0, true},
+		{"llama3.2:3b", 131072, 0, false},
+	}
+
+	for _, tt := range tests {
+		info, ok := LookupModelInfo(tt.model)
+		if !ok {
+			t.Errorf("%s: expected registered model info", tt.model)
+			continue
+		}
+		if info.ID != tt.model {
+			t.Errorf("%s: expected ID to be the queried model, got %q", tt.model, info.ID)
+		}
+		if info.ContextWindow != tt.contextWindow || info.MaxOutputTokens != tt.maxOutputTokens || info.SupportsEmbeddings != tt.supportsEmbeddings {
+			t.Errorf("%s: unexpected info %+v", tt.model, info)
+		}
+	}
+
+	info, ok := LookupModelInfo("my-custom-model")
+	if ok || info.ID != "my-custom-model" || info.ContextWindow != 0 {
+		t.Errorf("Expected unknown model to have only ID set, got %+v (ok=%v)", info, ok)
+	}
+}
+
+func TestRegisterModelInfo(t *testing.T) {
+	RegisterModelInfo("acme-large", ModelInfo{ContextWindow: 32000, MaxOutputTokens: 2048})
+	defer func() {
+		modelInfoMu.Lock()
+		delete(modelInfoRegistry, "acme-large")
+		modelInfoMu.Unlock()
+	}()
+
+	info, ok := LookupModelInfo("acme-large-v2")
+	if !ok || info.ContextWindow != 32000 || info.MaxOutputTokens != 2048 {
+		t.Errorf("Expected registered info for acme-large-v2, got %+v (ok=%v)", info, ok)
+	}
+}
+
+func TestProviderGetModelInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"models":[{"name":"llama3.2:latest"},{"name":"nomic-embed-text:latest"},{"name":"homebrew:7b"}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{Type: ProviderOllama, OllamaHost: server.URL})
+	infos, err := provider.GetModelInfo(context.Background())
+	if err != nil {
+		t.Fatalf("GetModelInfo() error: %v", err)
+	}
+	if len(infos) != 3 {
+		t.Fatalf("Expected 3 models, got %+v", infos)
+	}
+
+	if infos[0].ID != "llama3.2:latest" || infos[0].ContextWindow != 131072 {
+		t.Errorf("Unexpected info for llama3.2: %+v", infos[0])
+	}
+	if !infos[1].SupportsEmbeddings {
+		t.Errorf("Expected nomic-embed-text to support embeddings, got %+v", infos[1])
+	}
+	if infos[2].ID != "homebrew:7b" || infos[2].ContextWindow != 0 {
+		t.Errorf("Expected unknown model to have only ID set, got %+v", infos[2])
+	}
+
+	// GetAvailableModels lists the same ids
+	models, _ := provider.GetAvailableModels(context.Background())
+	for i, info := range infos {
+		if models[i] != info.ID {
+			t.Errorf("Expected model ids to match, got %v and %+v", models, infos)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultModelsCacheTTL is how long a provider's model list is reused before refetching.
+const defaultModelsCacheTTL = 60 * time.Second
+
+// ModelRefresher is implemented by providers that cache their model list.
+type ModelRefresher interface {
+	// RefreshAvailableModels bypasses the cache, refetches the model list, and caches the result.
+	RefreshAvailableModels(ctx context.Context) ([]string, error)
+}
+
+// modelsCache holds a provider's cached model list. Concurrent fetches share
+// a single upstream request.
+type modelsCache struct {
+	mu        sync.Mutex
+	models    []string
+	fetchedAt time.Time
+	group     singleflight.Group
+}
+
+// CachedModels returns the provider's model list from the cache, calling fetch
+// when the cache is empty, expired, or forceRefresh is set. Callers that arrive
+// while a fetch is in flight wait for and share its result. Errors are not cached.
+func (b *BaseProvider) CachedModels(ctx context.Context, forceRefresh bool, fetch func(ctx context.Context) ([]string, error)) ([]string, error) {
+	ttl := b.Config.ModelsCacheTTL
+	if ttl == 0 {
+		ttl = defaultModelsCacheTTL
+	}
+
+	cache := &b.modelsCache
+	if !forceRefresh && ttl > 0 {
+		cache.mu.Lock()
+		if cache.models != nil && time.Since(cache.fetchedAt) < ttl {
+			models := slices.Clone(cache.models)
+			cache.mu.Unlock()
+			return models, nil
+		}
+		cache.mu.Unlock()
+	}
+
+	// The shared fetch must outlive any single caller's cancellation
+	ch := cache.group.DoChan(string(b.Config.Type), func() (any, error) {
+		models, err := fetch(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		cache.mu.Lock()
+		cache.models = models
+		cache.fetchedAt = time.Now()
+		cache.mu.Unlock()
+		return models, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return slices.Clone(res.Val.([]string)), nil
+	}
+}
@@ -0,0 +1,129 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newModelsTestServer(t *testing.T, delay time.Duration) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"models":[{"name":"llama3.2"},{"name":"mistral"}]}`))
+	}))
+	return server, &requests
+}
+
+func TestGetAvailableModelsCached(t *testing.T) {
+	server, requests := newModelsTestServer(t, 0)
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{Type: ProviderOllama, OllamaHost: server.URL})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		models, err := provider.GetAvailableModels(ctx)
+		if err != nil {
+			t.Fatalf("GetAvailableModels() error: %v", err)
+		}
+		if len(models) != 2 {
+			t.Fatalf("Expected 2 models, got %v", models)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected 1 upstream request, got %d", got)
+	}
+
+	// Mutating the result must not affect the cache
+	models, _ := provider.GetAvailableModels(ctx)
+	models[0] = "changed"
+	if models, _ := provider.GetAvailableModels(ctx); models[0] != "llama3.2" {
+		t.Errorf("Expected cached models to be unaffected by caller mutation, got %v", models)
+	}
+
+	if _, err := provider.RefreshAvailableModels(ctx); err != nil {
+		t.Fatalf("RefreshAvailableModels() error: %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected refresh to bypass the cache, got %d requests", got)
+	}
+}
+
+func TestGetAvailableModelsConcurrentSingleFlight(t *testing.T) {
+	server, requests := newModelsTestServer(t, 100*time.Millisecond)
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{Type: ProviderOllama, OllamaHost: server.URL})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := provider.GetAvailableModels(context.Background())
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("GetAvailableModels() error: %v", err)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected concurrent calls to share 1 upstream request, got %d", got)
+	}
+}
+
+func TestGetAvailableModelsCacheExpires(t *testing.T) {
+	server, requests := newModelsTestServer(t, 0)
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{
+		Type:           ProviderOllama,
+		OllamaHost:     server.URL,
+		ModelsCacheTTL: 50 * time.Millisecond,
+	})
+	ctx := context.Background()
+
+	provider.GetAvailableModels(ctx)
+	provider.GetAvailableModels(ctx)
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("Expected 1 request within the TTL, got %d", got)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	provider.GetAvailableModels(ctx)
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected a new request after the TTL, got %d", got)
+	}
+}
+
+func TestGetAvailableModelsCacheDisabled(t *testing.T) {
+	server, requests := newModelsTestServer(t, 0)
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{
+		Type:           ProviderOllama,
+		OllamaHost:     server.URL,
+		ModelsCacheTTL: -1,
+	})
+
+	provider.GetAvailableModels(context.Background())
+	provider.GetAvailableModels(context.Background())
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected every call to hit upstream with caching disabled, got %d", got)
+	}
+}
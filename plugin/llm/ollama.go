@@ -3,8 +3,11 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
 
 	storepb "github.com/usememos/memos/proto/gen/store"
 )
@@ -21,6 +24,9 @@ type OllamaProvider struct {
 	host           string
 	defaultModel   string
 	embeddingModel string
+	numCtx         int
+	keepAlive      string
+	batchEmbed     bool
 }
 
 // NewOllamaProvider creates a new Ollama provider.
@@ -44,6 +50,9 @@ func NewOllamaProvider(config *ProviderConfig) *OllamaProvider {
 		host:           host,
 		defaultModel:   defaultModel,
 		embeddingModel: embeddingModel,
+		numCtx:         config.OllamaNumCtx,
+		keepAlive:      config.OllamaKeepAlive,
+		batchEmbed:     config.OllamaBatchEmbed,
 	}
 }
 
@@ -79,21 +88,37 @@ func (p *OllamaProvider) GetDefaultModel() string {
 	return p.defaultModel
 }
 
+// defaultEmbeddingModel returns the model used when an EmbeddingRequest names none.
+func (p *OllamaProvider) defaultEmbeddingModel() string {
+	return p.embeddingModel
+}
+
 // GetAvailableModels returns available models from the Ollama server.
+// Results are cached for ProviderConfig.ModelsCacheTTL.
 func (p *OllamaProvider) GetAvailableModels(ctx context.Context) ([]string, error) {
+	return p.CachedModels(ctx, false, p.fetchAvailableModels)
+}
+
+// RefreshAvailableModels refetches the model list, bypassing the cache.
+func (p *OllamaProvider) RefreshAvailableModels(ctx context.Context) ([]string, error) {
+	return p.CachedModels(ctx, true, p.fetchAvailableModels)
+}
+
+// fetchAvailableModels queries the API for the available models.
+func (p *OllamaProvider) fetchAvailableModels(ctx context.Context) ([]string, error) {
 	if !p.IsConfigured(ctx) {
 		return nil, ErrProviderNotConfigured
 	}
 
 	url := fmt.Sprintf("%s/api/tags", p.host)
 
-	respBody, err := p.DoRequest(ctx, http.MethodGet, url, nil, nil)
+	respBody, status, err := p.doRequest(ctx, http.MethodGet, url, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	var resp ollamaModelsResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := p.decodeJSON(respBody, status, &resp); err != nil {
 		return nil, fmt.Errorf("failed to parse models response: %w", err)
 	}
 
@@ -105,12 +130,80 @@ func (p *OllamaProvider) GetAvailableModels(ctx context.Context) ([]string, erro
 	return models, nil
 }
 
+// GetModelInfo returns the available models annotated with known limits.
+func (p *OllamaProvider) GetModelInfo(ctx context.Context) ([]ModelInfo, error) {
+	return p.DefaultGetModelInfo(ctx, p)
+}
+
 // Complete performs chat completion using Ollama's API.
 func (p *OllamaProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
 	if !p.IsConfigured(ctx) {
 		return nil, ErrProviderNotConfigured
 	}
+	ctx = withRequestMetadata(ctx, req.Metadata)
+
+	ctx, cancel := withRequestTimeout(ctx, req.TimeoutSeconds)
+	defer cancel()
+
+	req, err := p.checkSampling(req, maxTemperatureOllama)
+	if err != nil {
+		return nil, err
+	}
+	req, truncated, err := truncateToContext(req, p.contextWindow(req))
+	if err != nil {
+		return nil, err
+	}
+
+	ollamaReq, err := p.buildChatRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/api/chat", p.host)
+
+	respBody, status, err := p.doRequest(ctx, http.MethodPost, url, ollamaReq, nil)
+	if err != nil {
+		return nil, ollamaError(err)
+	}
+
+	var resp ollamaChatResponse
+	if err := p.decodeJSON(respBody, status, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse completion response: %w", err)
+	}
+
+	// Some models stop immediately (e.g., on a stop token) and reply with nothing
+	if strings.TrimSpace(resp.Message.Content) == "" {
+		return nil, fmt.Errorf("%w (model %s, done_reason %q)", ErrEmptyResponse, resp.Model, resp.DoneReason)
+	}
+
+	return p.captureResponse(&CompletionResponse{
+		Content: resp.Message.Content,
+		Model:   resp.Model,
+		Usage: &TokenUsage{
+			PromptTokens:     resp.PromptEvalCount,
+			CompletionTokens: resp.EvalCount,
+			TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
+		},
+		FinishReason: normalizeFinishReason(resp.DoneReason),
+		Truncated:    truncated,
+	}), nil
+}
+
+// contextWindow returns the context size Ollama will use for req: the
+// configured num_ctx if set, otherwise the model's known window.
+func (p *OllamaProvider) contextWindow(req *CompletionRequest) int {
+	if p.numCtx > 0 {
+		return p.numCtx
+	}
+	model := req.Model
+	if model == "" {
+		model = p.defaultModel
+	}
+	return contextWindow(model)
+}
 
+// buildChatRequest converts a CompletionRequest to an Ollama chat request.
+func (p *OllamaProvider) buildChatRequest(req *CompletionRequest) (ollamaChatRequest, error) {
 	model := req.Model
 	if model == "" {
 		model = p.defaultModel
@@ -123,46 +216,122 @@ func (p *OllamaProvider) Complete(ctx context.Context, req *CompletionRequest) (
 			Role:    string(m.Role),
 			Content: m.Content,
 		}
+		for _, img := range m.Images {
+			// Ollama can't fetch remote images
+			if img.Base64 == "" {
+				return ollamaChatRequest{}, fmt.Errorf("%w: ollama accepts only base64 images", ErrCapabilityNotSupported)
+			}
+			messages[i].Images = append(messages[i].Images, img.Base64)
+		}
 	}
 
 	ollamaReq := ollamaChatRequest{
-		Model:    model,
-		Messages: messages,
-		Stream:   false, // We don't support streaming yet
+		Model:     model,
+		Messages:  messages,
+		Stream:    false,
+		KeepAlive: p.keepAlive,
 	}
 
 	// Add options if specified
-	if req.Temperature > 0 || req.TopP > 0 {
-		ollamaReq.Options = &ollamaOptions{}
+	if req.Temperature > 0 || req.TopP > 0 || len(req.Stop) > 0 || req.Seed != nil || req.MaxTokens > 0 || p.numCtx > 0 {
+		ollamaReq.Options = &ollamaOptions{
+			NumPredict: req.MaxTokens,
+			NumCtx:     p.numCtx,
+		}
 		if req.Temperature > 0 {
 			ollamaReq.Options.Temperature = req.Temperature
 		}
 		if req.TopP > 0 {
 			ollamaReq.Options.TopP = req.TopP
 		}
+		if len(req.Stop) > 0 {
+			ollamaReq.Options.Stop = req.Stop
+		}
+		ollamaReq.Options.Seed = req.Seed
 	}
 
-	url := fmt.Sprintf("%s/api/chat", p.host)
+	return ollamaReq, nil
+}
 
-	respBody, err := p.DoRequest(ctx, http.MethodPost, url, ollamaReq, nil)
+// CompleteStream performs a chat completion and streams the response.
+// Ollama streams newline-delimited JSON; the final object (done:true) carries usage.
+func (p *OllamaProvider) CompleteStream(ctx context.Context, req *CompletionRequest) (<-chan CompletionChunk, error) {
+	if !p.IsConfigured(ctx) {
+		return nil, ErrProviderNotConfigured
+	}
+	ctx = withRequestMetadata(ctx, req.Metadata)
+
+	req, err := p.checkSampling(req, maxTemperatureOllama)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp ollamaChatResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("failed to parse completion response: %w", err)
+	ollamaReq, err := p.buildChatRequest(req)
+	if err != nil {
+		return nil, err
 	}
+	ollamaReq.Stream = true
+	url := fmt.Sprintf("%s/api/chat", p.host)
 
-	return &CompletionResponse{
-		Content: resp.Message.Content,
-		Model:   resp.Model,
-		Usage: &TokenUsage{
-			PromptTokens:     resp.PromptEvalCount,
-			CompletionTokens: resp.EvalCount,
-			TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
-		},
-	}, nil
+	body, err := p.DoStreamRequest(ctx, http.MethodPost, url, ollamaReq, nil)
+	if err != nil {
+		return nil, ollamaError(err)
+	}
+
+	ch := make(chan CompletionChunk)
+	go func() {
+		defer close(ch)
+		defer body.Close()
+
+		// Unblock a pending read as soon as the caller cancels
+		stop := context.AfterFunc(ctx, func() { body.Close() })
+		defer stop()
+
+		var final CompletionChunk
+		err := readNDJSON(body, func(line []byte) error {
+			var resp ollamaChatResponse
+			if err := json.Unmarshal(line, &resp); err != nil {
+				return fmt.Errorf("failed to parse stream chunk: %w", err)
+			}
+			if resp.Error != "" {
+				return fmt.Errorf("ollama stream error: %s", resp.Error)
+			}
+
+			if resp.Message.Content != "" {
+				if !sendChunk(ctx, ch, CompletionChunk{Content: resp.Message.Content}) {
+					return ctx.Err()
+				}
+			}
+			if resp.Done {
+				final = CompletionChunk{
+					Done:         true,
+					Model:        resp.Model,
+					FinishReason: normalizeFinishReason(resp.DoneReason),
+					Usage: &TokenUsage{
+						PromptTokens:     resp.PromptEvalCount,
+						CompletionTokens: resp.EvalCount,
+						TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
+					},
+				}
+				return errStopStream
+			}
+			return nil
+		})
+		if err == nil && !final.Done {
+			err = ctx.Err()
+			if err == nil {
+				err = errors.New("ollama stream ended before completion")
+			}
+		}
+		if err != nil {
+			sendChunk(ctx, ch, CompletionChunk{Err: err})
+			return
+		}
+
+		sendChunk(ctx, ch, final)
+	}()
+
+	return ch, nil
 }
 
 // Embed generates embeddings using Ollama's API.
@@ -176,32 +345,33 @@ func (p *OllamaProvider) Embed(ctx context.Context, req *EmbeddingRequest) (*Emb
 		model = p.embeddingModel
 	}
 
-	// Ollama embedding API takes one input at a time, so we need to make multiple requests
-	embeddings := make([][]float32, len(req.Input))
-	var totalTokens int
-
-	for i, input := range req.Input {
-		ollamaReq := ollamaEmbedRequest{
-			Model: model,
-			Input: input,
+	var (
+		embeddings  [][]float32
+		totalTokens int
+		err         error
+	)
+	batched := false
+	if p.batchEmbed && len(req.Input) > 1 {
+		embeddings, totalTokens, batched, err = p.embedBatch(ctx, model, req.Input)
+		if err != nil {
+			return nil, err
 		}
-
-		url := fmt.Sprintf("%s/api/embed", p.host)
-
-		respBody, err := p.DoRequest(ctx, http.MethodPost, url, ollamaReq, nil)
+	}
+	if !batched {
+		embeddings, totalTokens, err = p.embedEach(ctx, model, req.Input)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		var resp ollamaEmbedResponse
-		if err := json.Unmarshal(respBody, &resp); err != nil {
-			return nil, fmt.Errorf("failed to parse embedding response: %w", err)
+	// Ollama has no server-side dimension reduction, so truncate client-side
+	for i, embedding := range embeddings {
+		if embedding == nil {
+			continue
 		}
-
-		if len(resp.Embeddings) > 0 {
-			embeddings[i] = resp.Embeddings[0]
+		if embeddings[i], err = reduceDimensions(embedding, req.Dimensions); err != nil {
+			return nil, err
 		}
-		totalTokens += resp.PromptEvalCount
 	}
 
 	return &EmbeddingResponse{
@@ -214,6 +384,70 @@ func (p *OllamaProvider) Embed(ctx context.Context, req *EmbeddingRequest) (*Emb
 	}, nil
 }
 
+// embedBatch embeds all inputs in a single /api/embed request. It reports
+// ok=false when the server returned a different number of embeddings than
+// inputs, which older servers do when they only embed the first input.
+func (p *OllamaProvider) embedBatch(ctx context.Context, model string, inputs []string) (embeddings [][]float32, tokens int, ok bool, err error) {
+	resp, err := p.postEmbed(ctx, ollamaEmbedRequest{Model: model, Input: inputs})
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	if len(resp.Embeddings) != len(inputs) {
+		slog.Warn("Ollama batch embedding returned an unexpected count, embedding inputs one at a time",
+			slog.Int("inputs", len(inputs)),
+			slog.Int("embeddings", len(resp.Embeddings)))
+		return nil, 0, false, nil
+	}
+	return resp.Embeddings, resp.PromptEvalCount, true, nil
+}
+
+// embedEach embeds inputs with one /api/embed request per input.
+func (p *OllamaProvider) embedEach(ctx context.Context, model string, inputs []string) ([][]float32, int, error) {
+	embeddings := make([][]float32, len(inputs))
+	var totalTokens int
+
+	for i, input := range inputs {
+		resp, err := p.postEmbed(ctx, ollamaEmbedRequest{Model: model, Input: input})
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if len(resp.Embeddings) > 0 {
+			embeddings[i] = resp.Embeddings[0]
+		}
+		totalTokens += resp.PromptEvalCount
+	}
+
+	return embeddings, totalTokens, nil
+}
+
+// postEmbed sends a request to /api/embed and parses the response.
+func (p *OllamaProvider) postEmbed(ctx context.Context, ollamaReq ollamaEmbedRequest) (*ollamaEmbedResponse, error) {
+	url := fmt.Sprintf("%s/api/embed", p.host)
+
+	respBody, status, err := p.doRequest(ctx, http.MethodPost, url, ollamaReq, nil)
+	if err != nil {
+		return nil, ollamaError(err)
+	}
+
+	var resp ollamaEmbedResponse
+	if err := p.decodeJSON(respBody, status, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse embedding response: %w", err)
+	}
+	return &resp, nil
+}
+
+// Capabilities describes the features supported by the Ollama provider.
+// Vision requires a multimodal model (e.g., llava); text-only models ignore images.
+func (p *OllamaProvider) Capabilities() ProviderCapabilities {
+	return ProviderCapabilities{
+		Embeddings: true,
+		Streaming:  true,
+		Vision:     true,
+	}
+}
+
 // SuggestTags suggests tags for the given content.
 func (p *OllamaProvider) SuggestTags(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
 	return p.DefaultSuggestTags(ctx, p, req)
@@ -224,6 +458,11 @@ func (p *OllamaProvider) Summarize(ctx context.Context, req *SummarizeRequest) (
 	return p.DefaultSummarize(ctx, p, req)
 }
 
+// SuggestTitle generates a short title for the content.
+func (p *OllamaProvider) SuggestTitle(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error) {
+	return p.DefaultSuggestTitle(ctx, p, req)
+}
+
 // ToProto converts the provider configuration to proto format.
 func (p *OllamaProvider) ToProto() *storepb.LLMOllamaConfig {
 	return &storepb.LLMOllamaConfig{
@@ -250,17 +489,105 @@ func (p *OllamaProvider) CheckHealth(ctx context.Context) error {
 	return nil
 }
 
+// Warmup loads the default model into memory: /api/generate with no prompt
+// loads the model without generating anything.
+func (p *OllamaProvider) Warmup(ctx context.Context) error {
+	if !p.IsConfigured(ctx) {
+		return ErrProviderNotConfigured
+	}
+
+	url := fmt.Sprintf("%s/api/generate", p.host)
+
+	_, err := p.DoRequest(ctx, http.MethodPost, url, ollamaGenerateRequest{Model: p.defaultModel, KeepAlive: p.keepAlive}, nil)
+	if err != nil {
+		return ollamaError(err)
+	}
+	return nil
+}
+
+// ValidateConfig checks the host is set and is a well-formed URL.
+func (p *OllamaProvider) ValidateConfig(ctx context.Context) error {
+	if err := p.DefaultValidateConfig(ctx, p); err != nil {
+		return err
+	}
+	return validateBaseURL(p.host)
+}
+
+// ollamaError maps Ollama's 404 "model 'x' not found, try pulling it" response
+// to ErrModelNotFound so callers can offer to pull the model.
+func ollamaError(err error) error {
+	var provErr *ProviderError
+	if errors.As(err, &provErr) && provErr.StatusCode == http.StatusNotFound &&
+		strings.Contains(strings.ToLower(provErr.Message), "not found") {
+		provErr.Err = ErrModelNotFound
+	}
+	return err
+}
+
+// PullModel downloads a model to the Ollama server via /api/pull, reading
+// progress updates until the pull completes. Pulls can take minutes, so only
+// ctx bounds the call.
+func (p *OllamaProvider) PullModel(ctx context.Context, model string) error {
+	if !p.IsConfigured(ctx) {
+		return ErrProviderNotConfigured
+	}
+
+	url := fmt.Sprintf("%s/api/pull", p.host)
+
+	body, err := p.DoStreamRequest(ctx, http.MethodPost, url, ollamaPullRequest{Model: model, Stream: true}, nil)
+	if err != nil {
+		return ollamaError(err)
+	}
+	defer body.Close()
+
+	succeeded := false
+	err = readNDJSON(body, func(line []byte) error {
+		var progress ollamaPullProgress
+		if err := json.Unmarshal(line, &progress); err != nil {
+			return fmt.Errorf("failed to parse pull progress: %w", err)
+		}
+		if progress.Error != "" {
+			return fmt.Errorf("ollama pull failed: %s", progress.Error)
+		}
+
+		slog.Debug("Ollama pull progress",
+			slog.String("model", model),
+			slog.String("status", progress.Status),
+			slog.Int64("completed", progress.Completed),
+			slog.Int64("total", progress.Total))
+
+		if progress.Status == "success" {
+			succeeded = true
+			return errStopStream
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !succeeded {
+		return fmt.Errorf("ollama pull of %s ended without success", model)
+	}
+
+	slog.Info("Ollama model pulled", slog.String("model", model))
+	return nil
+}
+
 // Ollama API request/response types
 
 type ollamaMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Images  []string `json:"images,omitempty"` // Base64-encoded
 }
 
 type ollamaOptions struct {
-	Temperature float64 `json:"temperature,omitempty"`
-	TopP        float64 `json:"top_p,omitempty"`
-	NumPredict  int     `json:"num_predict,omitempty"`
+	Temperature float64  `json:"temperature,omitempty"`
+	TopP        float64  `json:"top_p,omitempty"`
+	NumPredict  int      `json:"num_predict,omitempty"`
+	NumCtx      int      `json:"num_ctx,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
 }
 
 type ollamaChatRequest struct {
@@ -268,6 +595,9 @@ type ollamaChatRequest struct {
 	Messages []ollamaMessage `json:"messages"`
 	Stream   bool            `json:"stream"`
 	Options  *ollamaOptions  `json:"options,omitempty"`
+
+	// KeepAlive is a duration string (e.g. "5m") or "-1" to keep the model loaded
+	KeepAlive string `json:"keep_alive,omitempty"`
 }
 
 type ollamaChatResponse struct {
@@ -282,11 +612,17 @@ type ollamaChatResponse struct {
 	LoadDuration    int64  `json:"load_duration,omitempty"`
 	PromptEvalCount int    `json:"prompt_eval_count,omitempty"`
 	EvalCount       int    `json:"eval_count,omitempty"`
+	Error           string `json:"error,omitempty"` // Set on mid-stream failures
+}
+
+type ollamaGenerateRequest struct {
+	Model     string `json:"model"`
+	KeepAlive string `json:"keep_alive,omitempty"`
 }
 
 type ollamaEmbedRequest struct {
 	Model string `json:"model"`
-	Input string `json:"input"`
+	Input any    `json:"input"` // string, or []string for a batch
 }
 
 type ollamaEmbedResponse struct {
@@ -313,3 +649,16 @@ type ollamaModelsResponse struct {
 		} `json:"details"`
 	} `json:"models"`
 }
+
+type ollamaPullRequest struct {
+	Model  string `json:"model"`
+	Stream bool   `json:"stream"`
+}
+
+type ollamaPullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
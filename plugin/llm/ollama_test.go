@@ -3,9 +3,13 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	storepb "github.com/usememos/memos/proto/gen/store"
 )
@@ -182,6 +186,38 @@ func TestOllamaProviderComplete(t *testing.T) {
 	}
 }
 
+func TestOllamaProviderCompleteEmptyResponse(t *testing.T) {
+	for _, content := range []string{"", "  \n\t"} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"model":       "llama3.2",
+				"message":     map[string]string{"role": "assistant", "content": content},
+				"done":        true,
+				"done_reason": "stop",
+			})
+		}))
+
+		provider := NewOllamaProvider(&ProviderConfig{
+			Type:       ProviderOllama,
+			OllamaHost: server.URL,
+		})
+
+		_, err := provider.Complete(context.Background(), &CompletionRequest{
+			Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+		})
+		server.Close()
+
+		if !errors.Is(err, ErrEmptyResponse) {
+			t.Errorf("Expected ErrEmptyResponse for content %q, got %v", content, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), "stop") {
+			t.Errorf("Expected the done reason in the error, got %v", err)
+		}
+	}
+}
+
 func TestOllamaProviderCompleteNotConfigured(t *testing.T) {
 	provider := NewOllamaProvider(&ProviderConfig{
 		Type: ProviderOllama,
@@ -256,6 +292,165 @@ func TestOllamaProviderCompleteWithOptions(t *testing.T) {
 	}
 }
 
+func TestOllamaProviderCompleteWithStopAndSeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		options, ok := raw["options"].(map[string]any)
+		if !ok {
+			t.Fatalf("Expected options object, got %v", raw["options"])
+		}
+		stop, ok := options["stop"].([]any)
+		if !ok || len(stop) != 1 || stop[0] != "\n\n" {
+			t.Errorf("Expected options.stop [\"\\n\\n\"], got %v", options["stop"])
+		}
+		if options["seed"] != float64(42) {
+			t.Errorf("Expected options.seed 42, got %v", options["seed"])
+		}
+		if _, exists := options["frequency_penalty"]; exists {
+			t.Error("Expected frequency_penalty to be omitted for Ollama")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"llama3.2","message":{"role":"assistant","content":"Response"},"done":true}`))
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{
+		Type:       ProviderOllama,
+		OllamaHost: server.URL,
+	})
+
+	seed := 42
+	_, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages:         []Message{{Role: RoleUser, Content: "Hello"}},
+		Stop:             []string{"\n\n"},
+		Seed:             &seed,
+		FrequencyPenalty: 0.5,
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+}
+
+func TestOllamaProviderCompleteWithNumPredictAndKeepAlive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		if raw["keep_alive"] != "10m" {
+			t.Errorf("Expected keep_alive '10m', got %v", raw["keep_alive"])
+		}
+		options, ok := raw["options"].(map[string]any)
+		if !ok {
+			t.Fatalf("Expected options object, got %v", raw["options"])
+		}
+		if options["num_predict"] != float64(256) {
+			t.Errorf("Expected options.num_predict 256, got %v", options["num_predict"])
+		}
+		if options["num_ctx"] != float64(8192) {
+			t.Errorf("Expected options.num_ctx 8192, got %v", options["num_ctx"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"llama3.2","message":{"role":"assistant","content":"Response"},"done":true}`))
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{
+		Type:            ProviderOllama,
+		OllamaHost:      server.URL,
+		OllamaNumCtx:    8192,
+		OllamaKeepAlive: "10m",
+	})
+
+	_, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages:  []Message{{Role: RoleUser, Content: "Hello"}},
+		MaxTokens: 256,
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+}
+
+func TestOllamaProviderCompleteOmitsUnsetOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		if _, exists := raw["keep_alive"]; exists {
+			t.Error("Expected keep_alive to be omitted when not configured")
+		}
+		if _, exists := raw["options"]; exists {
+			t.Errorf("Expected options to be omitted, got %v", raw["options"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"llama3.2","message":{"role":"assistant","content":"Response"},"done":true}`))
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{
+		Type:       ProviderOllama,
+		OllamaHost: server.URL,
+	})
+
+	_, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+}
+
+func TestOllamaProviderCompleteWithImages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		images := req.Messages[0].Images
+		if len(images) != 1 || images[0] != "aGVsbG8=" {
+			t.Errorf("Expected images [aGVsbG8=], got %v", images)
+		}
+		if req.Messages[0].Content != "Describe this" {
+			t.Errorf("Expected content 'Describe this', got %q", req.Messages[0].Content)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"llava","message":{"role":"assistant","content":"A cat"},"done":true}`))
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{
+		Type:       ProviderOllama,
+		OllamaHost: server.URL,
+	})
+
+	_, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Describe this", Images: []ImagePart{{Base64: "aGVsbG8=", MimeType: "image/png"}}}},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+
+	// Ollama can't fetch remote images
+	_, err = provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Describe this", Images: []ImagePart{{URL: "https://example.com/cat.jpg"}}}},
+	})
+	if !errors.Is(err, ErrCapabilityNotSupported) {
+		t.Errorf("Expected ErrCapabilityNotSupported for URL image, got %v", err)
+	}
+}
+
 func TestOllamaProviderEmbed(t *testing.T) {
 	callCount := 0
 
@@ -315,6 +510,144 @@ func TestOllamaProviderEmbed(t *testing.T) {
 	}
 }
 
+func TestOllamaProviderEmbedBatch(t *testing.T) {
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+
+		var raw map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		input, ok := raw["input"].([]any)
+		if !ok || len(input) != 2 || input[0] != "Hello world" || input[1] != "Another text" {
+			t.Errorf("Expected batched input array, got %v", raw["input"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ollamaEmbedResponse{
+			Model:           "nomic-embed-text",
+			Embeddings:      [][]float32{{0.1, 0.2}, {0.3, 0.4}},
+			PromptEvalCount: 8,
+		})
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{
+		Type:             ProviderOllama,
+		OllamaHost:       server.URL,
+		OllamaBatchEmbed: true,
+	})
+
+	resp, err := provider.Embed(context.Background(), &EmbeddingRequest{
+		Input: []string{"Hello world", "Another text"},
+	})
+	if err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+
+	if callCount != 1 {
+		t.Errorf("Expected 1 API call, got %d", callCount)
+	}
+	if len(resp.Embeddings) != 2 || resp.Embeddings[1][0] != 0.3 {
+		t.Errorf("Expected embeddings in input order, got %v", resp.Embeddings)
+	}
+	if resp.Usage.TotalTokens != 8 {
+		t.Errorf("Expected 8 total tokens, got %d", resp.Usage.TotalTokens)
+	}
+}
+
+func TestOllamaProviderEmbedBatchFallback(t *testing.T) {
+	var inputs []any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		inputs = append(inputs, raw["input"])
+
+		// Older servers embed only one input per request
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ollamaEmbedResponse{
+			Model:           "nomic-embed-text",
+			Embeddings:      [][]float32{{0.1, 0.2}},
+			PromptEvalCount: 5,
+		})
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{
+		Type:             ProviderOllama,
+		OllamaHost:       server.URL,
+		OllamaBatchEmbed: true,
+	})
+
+	resp, err := provider.Embed(context.Background(), &EmbeddingRequest{
+		Input: []string{"Hello world", "Another text"},
+	})
+	if err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+
+	// One batch attempt, then one request per input
+	if len(inputs) != 3 {
+		t.Fatalf("Expected 3 API calls, got %d", len(inputs))
+	}
+	if _, ok := inputs[0].([]any); !ok {
+		t.Errorf("Expected the first request to be batched, got %v", inputs[0])
+	}
+	if inputs[1] != "Hello world" || inputs[2] != "Another text" {
+		t.Errorf("Expected per-input fallback requests, got %v", inputs[1:])
+	}
+	if len(resp.Embeddings) != 2 {
+		t.Fatalf("Expected 2 embeddings, got %d", len(resp.Embeddings))
+	}
+	if resp.Usage.TotalTokens != 10 {
+		t.Errorf("Expected 10 total tokens from the fallback requests, got %d", resp.Usage.TotalTokens)
+	}
+}
+
+func TestOllamaProviderEmbedDimensions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ollamaEmbedResponse{
+			Model:      "nomic-embed-text",
+			Embeddings: [][]float32{{3, 4, 12}},
+		})
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{
+		Type:       ProviderOllama,
+		OllamaHost: server.URL,
+	})
+
+	resp, err := provider.Embed(context.Background(), &EmbeddingRequest{
+		Input:      []string{"Hello world"},
+		Dimensions: 2,
+	})
+	if err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+
+	// [3, 4] truncated and normalized to unit length is [0.6, 0.8]
+	got := resp.Embeddings[0]
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 dimensions, got %d", len(got))
+	}
+	if math.Abs(float64(got[0])-0.6) > 1e-6 || math.Abs(float64(got[1])-0.8) > 1e-6 {
+		t.Errorf("Expected [0.6 0.8], got %v", got)
+	}
+
+	// Asking for more than the native size is an error
+	if _, err := provider.Embed(context.Background(), &EmbeddingRequest{
+		Input:      []string{"Hello world"},
+		Dimensions: 4,
+	}); err == nil {
+		t.Error("Expected error when Dimensions exceeds native size")
+	}
+}
+
 func TestOllamaProviderEmbedNotConfigured(t *testing.T) {
 	provider := NewOllamaProvider(&ProviderConfig{
 		Type: ProviderOllama,
@@ -521,8 +854,8 @@ func TestOllamaProviderHTTPErrors(t *testing.T) {
 		{
 			name:           "Model not found",
 			statusCode:     404,
-			expectedError:  nil, // Generic error
-			serverResponse: `{"error": "model not found"}`,
+			expectedError:  ErrModelNotFound,
+			serverResponse: `{"error": "model 'llama9' not found, try pulling it first"}`,
 		},
 	}
 
@@ -550,9 +883,265 @@ func TestOllamaProviderHTTPErrors(t *testing.T) {
 				t.Error("Expected error, got nil")
 			}
 
-			if tt.expectedError != nil && err != tt.expectedError {
+			if tt.expectedError != nil && !errors.Is(err, tt.expectedError) {
 				t.Errorf("Expected error %v, got %v", tt.expectedError, err)
 			}
 		})
 	}
 }
+
+func TestOllamaProviderEmbedModelNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"model 'mxbai-embed-large' not found, try pulling it first"}`))
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{
+		Type:       ProviderOllama,
+		OllamaHost: server.URL,
+	})
+
+	_, err := provider.Embed(context.Background(), &EmbeddingRequest{Input: []string{"Hello"}, Model: "mxbai-embed-large"})
+	if !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("Expected ErrModelNotFound, got %v", err)
+	}
+
+	var provErr *ProviderError
+	if !errors.As(err, &provErr) || !strings.Contains(provErr.Message, "mxbai-embed-large") {
+		t.Errorf("Expected upstream message to be preserved, got %v", err)
+	}
+}
+
+func TestOllamaProviderPullModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/pull" {
+			t.Errorf("Expected path /api/pull, got %s", r.URL.Path)
+		}
+
+		var req ollamaPullRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req.Model != "llama3.2" || !req.Stream {
+			t.Errorf("Unexpected pull request: %+v", req)
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, line := range []string{
+			`{"status":"pulling manifest"}`,
+			`{"status":"downloading","digest":"sha256:abc","total":100,"completed":50}`,
+			`{"status":"downloading","digest":"sha256:abc","total":100,"completed":100}`,
+			`{"status":"verifying sha256 digest"}`,
+			`{"status":"success"}`,
+		} {
+			w.Write([]byte(line + "\n"))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{
+		Type:       ProviderOllama,
+		OllamaHost: server.URL,
+	})
+
+	if err := provider.PullModel(context.Background(), "llama3.2"); err != nil {
+		t.Fatalf("PullModel() error: %v", err)
+	}
+}
+
+func TestOllamaProviderPullModelError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"pulling manifest"}` + "\n"))
+		w.Write([]byte(`{"error":"pull model manifest: file does not exist"}` + "\n"))
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{
+		Type:       ProviderOllama,
+		OllamaHost: server.URL,
+	})
+
+	err := provider.PullModel(context.Background(), "no-such-model")
+	if err == nil || !strings.Contains(err.Error(), "file does not exist") {
+		t.Errorf("Expected pull error, got %v", err)
+	}
+}
+
+func writeNDJSON(w http.ResponseWriter, lines []string) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	for _, line := range lines {
+		w.Write([]byte(line + "\n"))
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+}
+
+func TestOllamaProviderCompleteStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if !req.Stream {
+			t.Error("Expected stream to be true")
+		}
+
+		writeNDJSON(w, []string{
+			`{"model":"llama3.2","message":{"role":"assistant","content":"Hel"},"done":false}`,
+			`{"model":"llama3.2","message":{"role":"assistant","content":"lo"},"done":false}`,
+			`{"model":"llama3.2","message":{"role":"assistant","content":"!"},"done":false}`,
+			`{"model":"llama3.2","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":5,"eval_count":3}`,
+		})
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{Type: ProviderOllama, OllamaHost: server.URL})
+
+	stream, err := provider.CompleteStream(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Say hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CompleteStream() error: %v", err)
+	}
+
+	var content strings.Builder
+	var final *CompletionChunk
+	for chunk := range stream {
+		if chunk.Err != nil {
+			t.Fatalf("Stream error: %v", chunk.Err)
+		}
+		if chunk.Done {
+			c := chunk
+			final = &c
+			continue
+		}
+		content.WriteString(chunk.Content)
+	}
+
+	if content.String() != "Hello!" {
+		t.Errorf("Expected concatenated content 'Hello!', got %q", content.String())
+	}
+	if final == nil {
+		t.Fatal("Expected a terminal chunk")
+	}
+	if final.FinishReason != "stop" {
+		t.Errorf("Expected finish reason stop, got %q", final.FinishReason)
+	}
+	if final.Model != "llama3.2" {
+		t.Errorf("Expected model llama3.2, got %q", final.Model)
+	}
+	if final.Usage == nil || final.Usage.PromptTokens != 5 || final.Usage.CompletionTokens != 3 || final.Usage.TotalTokens != 8 {
+		t.Errorf("Expected usage 5/3/8, got %+v", final.Usage)
+	}
+}
+
+func TestOllamaProviderCompleteStreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeNDJSON(w, []string{
+			`{"model":"llama3.2","message":{"role":"assistant","content":"Hel"},"done":false}`,
+			`{"error":"model runner has unexpectedly stopped"}`,
+		})
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(&ProviderConfig{Type: ProviderOllama, OllamaHost: server.URL})
+
+	stream, err := provider.CompleteStream(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Say hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CompleteStream() error: %v", err)
+	}
+
+	var streamErr error
+	for chunk := range stream {
+		if chunk.Err != nil {
+			streamErr = chunk.Err
+		}
+	}
+	if streamErr == nil || !strings.Contains(streamErr.Error(), "unexpectedly stopped") {
+		t.Errorf("Expected stream error from server, got %v", streamErr)
+	}
+}
+
+func TestOllamaProviderCompleteStreamCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeNDJSON(w, []string{
+			`{"model":"llama3.2","message":{"role":"assistant","content":"Hel"},"done":false}`,
+		})
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	provider := NewOllamaProvider(&ProviderConfig{Type: ProviderOllama, OllamaHost: server.URL})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := provider.CompleteStream(ctx, &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Say hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CompleteStream() error: %v", err)
+	}
+
+	first := <-stream
+	if first.Content != "Hel" {
+		t.Fatalf("Expected first chunk 'Hel', got %+v", first)
+	}
+	cancel()
+
+	// The stream must close even though the server never finishes the response
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case chunk, ok := <-stream:
+			if !ok {
+				return
+			}
+			if chunk.Done {
+				t.Fatalf("Expected no terminal chunk after cancel, got %+v", chunk)
+			}
+		case <-timeout:
+			t.Fatal("Stream did not close after context cancellation")
+		}
+	}
+}
+
+func TestOllamaProviderCompleteFinishReason(t *testing.T) {
+	tests := map[string]string{
+		"stop":   FinishReasonStop,
+		"length": FinishReasonLength,
+	}
+
+	for native, want := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"model":       "llama3.2",
+				"message":     map[string]string{"role": "assistant", "content": "ok"},
+				"done":        true,
+				"done_reason": native,
+			})
+		}))
+
+		provider := NewOllamaProvider(&ProviderConfig{Type: ProviderOllama, OllamaHost: server.URL})
+		resp, err := provider.Complete(context.Background(), &CompletionRequest{
+			Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+		})
+		server.Close()
+
+		if err != nil {
+			t.Fatalf("Complete() error for %s: %v", native, err)
+		}
+		if resp.FinishReason != want {
+			t.Errorf("done_reason %q: expected %q, got %q", native, want, resp.FinishReason)
+		}
+	}
+}
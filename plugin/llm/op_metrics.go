@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// OpMetric summarizes the provider calls made for one provider and operation.
+type OpMetric struct {
+	// Provider is the provider type that served the calls.
+	Provider ProviderType `json:"provider"`
+
+	// Op is one of the Operation* constants.
+	Op string `json:"op"`
+
+	// Count is the number of calls, including failed ones.
+	Count int64 `json:"count"`
+
+	// Errors is the number of calls that returned an error.
+	Errors int64 `json:"errors"`
+
+	// P50 and P95 are latency percentiles, reported as the upper bound of the
+	// histogram bucket they fall in (so they are approximate).
+	P50 time.Duration `json:"p50"`
+	P95 time.Duration `json:"p95"`
+}
+
+// latencyBuckets are the upper bounds of the latency histogram buckets. Calls
+// slower than the last bound land in an overflow bucket reported as that bound.
+var latencyBuckets = []time.Duration{
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	60 * time.Second,
+}
+
+// opKey identifies a provider and operation.
+type opKey struct {
+	provider ProviderType
+	op       string
+}
+
+// opHistogram counts calls per latency bucket.
+type opHistogram struct {
+	count   int64
+	errors  int64
+	buckets []int64 // one per latencyBuckets entry, plus overflow
+}
+
+// quantile returns the upper bound of the bucket holding the q-th call.
+func (h *opHistogram) quantile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := int64(q*float64(h.count) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+
+	var seen int64
+	for i, n := range h.buckets {
+		seen += n
+		if seen >= rank {
+			if i >= len(latencyBuckets) {
+				return latencyBuckets[len(latencyBuckets)-1]
+			}
+			return latencyBuckets[i]
+		}
+	}
+	return latencyBuckets[len(latencyBuckets)-1]
+}
+
+// opMetrics records provider call latencies per provider and operation.
+type opMetrics struct {
+	mu   sync.Mutex
+	hist map[opKey]*opHistogram
+}
+
+func (m *opMetrics) record(provider ProviderType, op string, latency time.Duration, err error) {
+	bucket := sort.Search(len(latencyBuckets), func(i int) bool { return latency <= latencyBuckets[i] })
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.hist == nil {
+		m.hist = make(map[opKey]*opHistogram)
+	}
+	key := opKey{provider: provider, op: op}
+	h, ok := m.hist[key]
+	if !ok {
+		h = &opHistogram{buckets: make([]int64, len(latencyBuckets)+1)}
+		m.hist[key] = h
+	}
+
+	h.count++
+	if err != nil {
+		h.errors++
+	}
+	h.buckets[bucket]++
+}
+
+// snapshot returns the metrics sorted by provider, then operation.
+func (m *opMetrics) snapshot() []OpMetric {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]OpMetric, 0, len(m.hist))
+	for key, h := range m.hist {
+		result = append(result, OpMetric{
+			Provider: key.provider,
+			Op:       key.op,
+			Count:    h.count,
+			Errors:   h.errors,
+			P50:      h.quantile(0.50),
+			P95:      h.quantile(0.95),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Provider != result[j].Provider {
+			return result[i].Provider < result[j].Provider
+		}
+		return result[i].Op < result[j].Op
+	})
+	return result
+}
+
+// OperationMetrics returns call counts, errors and latency percentiles per
+// provider and operation, for calls made through Complete, Embed, SuggestTags,
+// Summarize and SuggestTitle. Calls served from the embedding cache or rejected
+// before reaching a provider are not counted.
+func (s *service) OperationMetrics() []OpMetric {
+	return s.opMetrics.snapshot()
+}
+
+// observe records one provider call started at start.
+func (s *service) observe(provider Provider, op string, start time.Time, err error) {
+	s.opMetrics.record(provider.GetType(), op, time.Since(start), err)
+}
@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOpMetricsPercentiles(t *testing.T) {
+	var m opMetrics
+	for i := 0; i < 90; i++ {
+		m.record(ProviderOllama, OperationComplete, 40*time.Millisecond, nil)
+	}
+	for i := 0; i < 10; i++ {
+		m.record(ProviderOllama, OperationComplete, 8*time.Second, errors.New("slow"))
+	}
+	m.record(ProviderOpenAI, OperationComplete, 900*time.Millisecond, nil)
+	m.record(ProviderOpenAI, OperationEmbed, 2*time.Minute, nil)
+
+	metrics := m.snapshot()
+	if len(metrics) != 3 {
+		t.Fatalf("Expected 3 metrics, got %d", len(metrics))
+	}
+
+	ollama := metrics[0]
+	if ollama.Provider != ProviderOllama || ollama.Op != OperationComplete {
+		t.Fatalf("Expected ollama complete first, got %+v", ollama)
+	}
+	if ollama.Count != 100 || ollama.Errors != 10 {
+		t.Errorf("Expected 100 calls and 10 errors, got %d and %d", ollama.Count, ollama.Errors)
+	}
+	if ollama.P50 != 50*time.Millisecond {
+		t.Errorf("Expected P50 in the 50ms bucket, got %v", ollama.P50)
+	}
+	if ollama.P95 != 10*time.Second {
+		t.Errorf("Expected P95 in the 10s bucket, got %v", ollama.P95)
+	}
+
+	if got := metrics[1]; got.Provider != ProviderOpenAI || got.Op != OperationComplete || got.P50 != time.Second {
+		t.Errorf("Unexpected openai complete metric: %+v", got)
+	}
+	// Calls beyond the last bucket report the last bound
+	if got := metrics[2]; got.Op != OperationEmbed || got.P95 != 60*time.Second {
+		t.Errorf("Unexpected openai embed metric: %+v", got)
+	}
+}
+
+func TestServiceOperationMetrics(t *testing.T) {
+	svc := NewService()
+	provider := &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		completeResp: &CompletionResponse{Content: "ok"},
+	}
+	svc.RegisterProvider(provider)
+
+	ctx := context.Background()
+	req := &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "Hi"}}}
+	for i := 0; i < 3; i++ {
+		if _, err := svc.Complete(ctx, req); err != nil {
+			t.Fatalf("Complete() error: %v", err)
+		}
+	}
+	provider.completeErr = errors.New("boom")
+	if _, err := svc.Complete(ctx, req); err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	metrics := svc.OperationMetrics()
+	if len(metrics) != 1 {
+		t.Fatalf("Expected 1 metric, got %+v", metrics)
+	}
+	m := metrics[0]
+	if m.Provider != ProviderOpenAI || m.Op != OperationComplete || m.Count != 4 || m.Errors != 1 {
+		t.Errorf("Unexpected metric: %+v", m)
+	}
+	if m.P95 < m.P50 {
+		t.Errorf("Expected P95 >= P50, got %v < %v", m.P95, m.P50)
+	}
+}
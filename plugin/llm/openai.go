@@ -2,9 +2,10 @@ package llm
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 
 	storepb "github.com/usememos/memos/proto/gen/store"
 )
@@ -13,6 +14,13 @@ const (
 	openAIBaseURL        = "https://api.openai.com/v1"
 	openAIDefaultModel   = "gpt-4o-mini"
 	openAIEmbeddingModel = "text-embedding-3-small"
+
+	// azureOpenAIAPIVersion is the default Azure OpenAI API version.
+	azureOpenAIAPIVersion = "2024-06-01"
+
+	// azureJSONModeAPIVersion is the first Azure API version with structured
+	// outputs; JSON mode is only advertised for Azure from this version on.
+	azureJSONModeAPIVersion = "2024-08-01"
 )
 
 // OpenAIProvider implements the Provider interface for OpenAI.
@@ -22,6 +30,20 @@ type OpenAIProvider struct {
 	baseURL        string
 	defaultModel   string
 	embeddingModel string
+
+	// Azure OpenAI settings; azureDeployment enables Azure mode when set.
+	azureDeployment string
+	azureAPIVersion string
+
+	// organization and project scope usage in shared OpenAI accounts
+	organization string
+	project      string
+
+	// compatible marks a generic OpenAI-compatible endpoint (see NewOpenAICompatibleProvider).
+	compatible bool
+
+	// groq marks Groq's OpenAI-compatible API (see NewGroqProvider).
+	groq bool
 }
 
 // NewOpenAIProvider creates a new OpenAI provider.
@@ -40,39 +62,94 @@ func NewOpenAIProvider(config *ProviderConfig) *OpenAIProvider {
 		embeddingModel = config.EmbeddingModel
 	}
 
+	azureAPIVersion := ""
+	if config.AzureDeployment != "" {
+		azureAPIVersion = azureOpenAIAPIVersion
+		if config.AzureAPIVersion != "" {
+			azureAPIVersion = config.AzureAPIVersion
+		}
+	}
+
 	return &OpenAIProvider{
-		BaseProvider:   NewBaseProvider(config),
-		apiKey:         config.APIKey,
-		baseURL:        baseURL,
-		defaultModel:   defaultModel,
-		embeddingModel: embeddingModel,
+		BaseProvider:    NewBaseProvider(config),
+		apiKey:          config.APIKey,
+		baseURL:         baseURL,
+		defaultModel:    defaultModel,
+		embeddingModel:  embeddingModel,
+		azureDeployment: config.AzureDeployment,
+		azureAPIVersion: azureAPIVersion,
+		organization:    config.OpenAIOrganization,
+		project:         config.OpenAIProject,
 	}
 }
 
 // NewOpenAIProviderFromProto creates a new OpenAI provider from proto config.
 func NewOpenAIProviderFromProto(pbConfig *storepb.LLMOpenAIConfig) *OpenAIProvider {
 	config := &ProviderConfig{
-		Type:           ProviderOpenAI,
+		Type:            ProviderOpenAI,
+		APIKey:          pbConfig.GetApiKey(),
+		BaseURL:         pbConfig.GetBaseUrl(),
+		DefaultModel:    pbConfig.GetDefaultModel(),
+		EmbeddingModel:  pbConfig.GetEmbeddingModel(),
+		AzureDeployment: pbConfig.GetAzureDeployment(),
+		AzureAPIVersion: pbConfig.GetAzureApiVersion(),
+
+		OpenAIOrganization: pbConfig.GetOrganization(),
+		OpenAIProject:      pbConfig.GetProject(),
+	}
+	return NewOpenAIProvider(config)
+}
+
+// NewOpenAICompatibleProvider creates a provider for a server that speaks the
+// OpenAI chat/embeddings protocol. Unlike NewOpenAIProvider, the API key is
+// optional, the base URL is required, and model listing is not filtered.
+func NewOpenAICompatibleProvider(config *ProviderConfig) *OpenAIProvider {
+	p := NewOpenAIProvider(config)
+	p.baseURL = config.BaseURL
+	p.compatible = true
+	return p
+}
+
+// NewOpenAICompatibleProviderFromProto creates an OpenAI-compatible provider from proto config.
+func NewOpenAICompatibleProviderFromProto(pbConfig *storepb.LLMOpenAIConfig) *OpenAIProvider {
+	config := &ProviderConfig{
+		Type:           ProviderOpenAICompatible,
 		APIKey:         pbConfig.GetApiKey(),
 		BaseURL:        pbConfig.GetBaseUrl(),
 		DefaultModel:   pbConfig.GetDefaultModel(),
 		EmbeddingModel: pbConfig.GetEmbeddingModel(),
 	}
-	return NewOpenAIProvider(config)
+	return NewOpenAICompatibleProvider(config)
 }
 
 // GetType returns the provider type.
 func (p *OpenAIProvider) GetType() ProviderType {
+	if p.compatible {
+		return ProviderOpenAICompatible
+	}
+	if p.groq {
+		return ProviderGroq
+	}
 	return ProviderOpenAI
 }
 
 // GetName returns the display name.
 func (p *OpenAIProvider) GetName() string {
+	if p.compatible {
+		return "OpenAI-Compatible"
+	}
+	if p.groq {
+		return "Groq"
+	}
 	return "OpenAI"
 }
 
 // IsConfigured checks if the provider is properly configured.
+// OpenAI-compatible endpoints only need a base URL, since many don't require a key.
 func (p *OpenAIProvider) IsConfigured(ctx context.Context) bool {
+	if p.compatible {
+		return p.baseURL != ""
+	}
 	return p.apiKey != ""
 }
 
@@ -81,32 +158,58 @@ func (p *OpenAIProvider) GetDefaultModel() string {
 	return p.defaultModel
 }
 
+// defaultEmbeddingModel returns the model used when an EmbeddingRequest names none.
+func (p *OpenAIProvider) defaultEmbeddingModel() string {
+	return p.embeddingModel
+}
+
 // GetAvailableModels returns available models.
+// Results are cached for ProviderConfig.ModelsCacheTTL.
 func (p *OpenAIProvider) GetAvailableModels(ctx context.Context) ([]string, error) {
+	return p.CachedModels(ctx, false, p.fetchAvailableModels)
+}
+
+// RefreshAvailableModels refetches the model list, bypassing the cache.
+func (p *OpenAIProvider) RefreshAvailableModels(ctx context.Context) ([]string, error) {
+	return p.CachedModels(ctx, true, p.fetchAvailableModels)
+}
+
+// fetchAvailableModels queries the API for the available models.
+func (p *OpenAIProvider) fetchAvailableModels(ctx context.Context) ([]string, error) {
 	if !p.IsConfigured(ctx) {
 		return nil, ErrProviderNotConfigured
 	}
 
-	url := fmt.Sprintf("%s/models", p.baseURL)
-	headers := map[string]string{
-		"Authorization": fmt.Sprintf("Bearer %s", p.apiKey),
+	// Azure deployments pin a single model and don't expose the /models list
+	if p.isAzure() {
+		return []string{p.azureDeployment}, nil
 	}
 
-	respBody, err := p.DoRequest(ctx, http.MethodGet, url, nil, headers)
+	url := fmt.Sprintf("%s/models", p.baseURL)
+	headers := p.authHeaders()
+
+	respBody, status, err := p.doRequest(ctx, http.MethodGet, url, nil, headers)
 	if err != nil {
 		return nil, err
 	}
 
 	var resp openAIModelsResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := p.decodeJSON(respBody, status, &resp); err != nil {
 		return nil, fmt.Errorf("failed to parse models response: %w", err)
 	}
 
-	// Filter to only chat models
+	// Filter to only chat models; OpenAI-compatible servers use arbitrary model IDs
 	var models []string
 	for _, m := range resp.Data {
-		// Include GPT models and o1 models
-		if isOpenAIChatModel(m.ID) {
+		switch {
+		case p.compatible:
+			models = append(models, m.ID)
+		case p.groq:
+			if isGroqChatModel(m.ID) {
+				models = append(models, m.ID)
+			}
+		case isOpenAIChatModel(m.ID):
+			// Include GPT models and o1 models
 			models = append(models, m.ID)
 		}
 	}
@@ -114,24 +217,44 @@ func (p *OpenAIProvider) GetAvailableModels(ctx context.Context) ([]string, erro
 	return models, nil
 }
 
+// GetModelInfo returns the available models annotated with known limits.
+func (p *OpenAIProvider) GetModelInfo(ctx context.Context) ([]ModelInfo, error) {
+	return p.DefaultGetModelInfo(ctx, p)
+}
+
 // Complete performs chat completion.
 func (p *OpenAIProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
 	if !p.IsConfigured(ctx) {
 		return nil, ErrProviderNotConfigured
 	}
+	ctx = withRequestMetadata(ctx, req.Metadata)
+
+	ctx, cancel := withRequestTimeout(ctx, req.TimeoutSeconds)
+	defer cancel()
 
 	model := req.Model
 	if model == "" {
 		model = p.defaultModel
 	}
 
+	if err := checkVision(p, req.Messages); err != nil {
+		return nil, err
+	}
+
+	req, err := p.checkSampling(req, maxTemperatureOpenAI)
+	if err != nil {
+		return nil, err
+	}
+
+	req, truncated, err := truncateToContext(req, contextWindow(model))
+	if err != nil {
+		return nil, err
+	}
+
 	// Build OpenAI request
 	messages := make([]openAIMessage, len(req.Messages))
 	for i, m := range req.Messages {
-		messages[i] = openAIMessage{
-			Role:    string(m.Role),
-			Content: m.Content,
-		}
+		messages[i] = toOpenAIMessage(m)
 	}
 
 	openAIReq := openAIChatRequest{
@@ -148,19 +271,24 @@ func (p *OpenAIProvider) Complete(ctx context.Context, req *CompletionRequest) (
 	if req.TopP > 0 {
 		openAIReq.TopP = req.TopP
 	}
-
-	url := fmt.Sprintf("%s/chat/completions", p.baseURL)
-	headers := map[string]string{
-		"Authorization": fmt.Sprintf("Bearer %s", p.apiKey),
+	if len(req.Stop) > 0 {
+		openAIReq.Stop = req.Stop
 	}
+	openAIReq.Seed = req.Seed
+	openAIReq.FrequencyPenalty = req.FrequencyPenalty
+	openAIReq.PresencePenalty = req.PresencePenalty
+	openAIReq.ResponseFormat = toOpenAIResponseFormat(req.ResponseFormat)
+
+	url := p.endpoint("chat/completions")
+	headers := p.authHeaders()
 
-	respBody, err := p.DoRequest(ctx, http.MethodPost, url, openAIReq, headers)
+	respBody, status, err := p.doRequest(ctx, http.MethodPost, url, openAIReq, headers)
 	if err != nil {
 		return nil, err
 	}
 
 	var resp openAIChatResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := p.decodeJSON(respBody, status, &resp); err != nil {
 		return nil, fmt.Errorf("failed to parse completion response: %w", err)
 	}
 
@@ -168,7 +296,7 @@ func (p *OpenAIProvider) Complete(ctx context.Context, req *CompletionRequest) (
 		return nil, fmt.Errorf("no completion choices returned")
 	}
 
-	return &CompletionResponse{
+	return p.captureResponse(&CompletionResponse{
 		Content: resp.Choices[0].Message.Content,
 		Model:   resp.Model,
 		Usage: &TokenUsage{
@@ -176,7 +304,9 @@ func (p *OpenAIProvider) Complete(ctx context.Context, req *CompletionRequest) (
 			CompletionTokens: resp.Usage.CompletionTokens,
 			TotalTokens:      resp.Usage.TotalTokens,
 		},
-	}, nil
+		FinishReason: normalizeFinishReason(resp.Choices[0].FinishReason),
+		Truncated:    truncated,
+	}), nil
 }
 
 // Embed generates embeddings for the given input.
@@ -184,6 +314,9 @@ func (p *OpenAIProvider) Embed(ctx context.Context, req *EmbeddingRequest) (*Emb
 	if !p.IsConfigured(ctx) {
 		return nil, ErrProviderNotConfigured
 	}
+	if p.groq {
+		return nil, fmt.Errorf("%w: groq does not support embeddings", ErrCapabilityNotSupported)
+	}
 
 	model := req.Model
 	if model == "" {
@@ -191,22 +324,21 @@ func (p *OpenAIProvider) Embed(ctx context.Context, req *EmbeddingRequest) (*Emb
 	}
 
 	openAIReq := openAIEmbeddingRequest{
-		Model: model,
-		Input: req.Input,
+		Model:      model,
+		Input:      req.Input,
+		Dimensions: req.Dimensions, // Only text-embedding-3 and later support this
 	}
 
-	url := fmt.Sprintf("%s/embeddings", p.baseURL)
-	headers := map[string]string{
-		"Authorization": fmt.Sprintf("Bearer %s", p.apiKey),
-	}
+	url := p.endpoint("embeddings")
+	headers := p.authHeaders()
 
-	respBody, err := p.DoRequest(ctx, http.MethodPost, url, openAIReq, headers)
+	respBody, status, err := p.doRequest(ctx, http.MethodPost, url, openAIReq, headers)
 	if err != nil {
 		return nil, err
 	}
 
 	var resp openAIEmbeddingResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := p.decodeJSON(respBody, status, &resp); err != nil {
 		return nil, fmt.Errorf("failed to parse embedding response: %w", err)
 	}
 
@@ -225,6 +357,33 @@ func (p *OpenAIProvider) Embed(ctx context.Context, req *EmbeddingRequest) (*Emb
 	}, nil
 }
 
+// CheckHealth verifies the API is reachable and accepts the key with an
+// uncached GET /models. Failures wrap ErrInvalidAPIKey or ErrProviderUnavailable.
+func (p *OpenAIProvider) CheckHealth(ctx context.Context) error {
+	if !p.IsConfigured(ctx) {
+		return ErrProviderNotConfigured
+	}
+
+	// Azure deployments don't expose the /models list
+	if p.isAzure() {
+		return p.DefaultCheckHealth(ctx, p)
+	}
+
+	url := fmt.Sprintf("%s/models", p.baseURL)
+	if _, err := p.DoRequest(ctx, http.MethodGet, url, nil, p.authHeaders()); err != nil {
+		return healthCheckError(p.GetType(), err)
+	}
+	return nil
+}
+
+// ValidateConfig checks the provider is configured and the base URL is well formed.
+func (p *OpenAIProvider) ValidateConfig(ctx context.Context) error {
+	if err := p.DefaultValidateConfig(ctx, p); err != nil {
+		return err
+	}
+	return validateBaseURL(p.baseURL)
+}
+
 // SuggestTags suggests tags for the given content.
 func (p *OpenAIProvider) SuggestTags(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
 	return p.DefaultSuggestTags(ctx, p, req)
@@ -235,16 +394,136 @@ func (p *OpenAIProvider) Summarize(ctx context.Context, req *SummarizeRequest) (
 	return p.DefaultSummarize(ctx, p, req)
 }
 
+// SuggestTitle generates a short title for the content.
+func (p *OpenAIProvider) SuggestTitle(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error) {
+	return p.DefaultSuggestTitle(ctx, p, req)
+}
+
 // ToProto converts the provider configuration to proto format.
 func (p *OpenAIProvider) ToProto() *storepb.LLMOpenAIConfig {
 	return &storepb.LLMOpenAIConfig{
-		ApiKey:         p.apiKey,
-		BaseUrl:        p.baseURL,
-		DefaultModel:   p.defaultModel,
-		EmbeddingModel: p.embeddingModel,
+		ApiKey:          p.apiKey,
+		BaseUrl:         p.baseURL,
+		DefaultModel:    p.defaultModel,
+		EmbeddingModel:  p.embeddingModel,
+		AzureDeployment: p.azureDeployment,
+		AzureApiVersion: p.azureAPIVersion,
+		Organization:    p.organization,
+		Project:         p.project,
+	}
+}
+
+// Capabilities describes the features supported by the OpenAI provider.
+// OpenAI-compatible servers vary in their response_format and image support, so
+// JSON mode and vision are not advertised for them. Groq has no embeddings API.
+// Azure only advertises JSON mode from azureJSONModeAPIVersion on.
+func (p *OpenAIProvider) Capabilities() ProviderCapabilities {
+	if p.compatible {
+		return ProviderCapabilities{
+			Embeddings: true,
+		}
+	}
+	if p.groq {
+		return ProviderCapabilities{}
+	}
+	return ProviderCapabilities{
+		Embeddings: true,
+		JSONMode:   !p.isAzure() || p.azureAPIVersion >= azureJSONModeAPIVersion,
+		Vision:     true,
 	}
 }
 
+// supportsStructuredOutputs reports whether model accepts a strict json_schema
+// response format. Azure deployment names don't identify the underlying model,
+// and older OpenAI models (gpt-4, gpt-4-turbo, gpt-3.5-turbo, o1-mini) reject
+// json_schema, so only known structured-output models qualify.
+func (p *OpenAIProvider) supportsStructuredOutputs(model string) bool {
+	if p.compatible || p.groq || p.isAzure() {
+		return false
+	}
+	if model == "" {
+		model = p.defaultModel
+	}
+	for _, name := range []string{"gpt-4o-2024-05-13", "o1-mini", "o1-preview"} {
+		if isModelVariant(model, name) {
+			return false
+		}
+	}
+	for _, name := range []string{"gpt-4o", "gpt-4.1", "gpt-5", "o1", "o3", "o4-mini"} {
+		if isModelVariant(model, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isModelVariant reports whether model is name or a "-"-suffixed variant of it
+// (e.g., "gpt-4o-2024-08-06" for "gpt-4o", but not "gpt-4.1" for "gpt-4").
+func isModelVariant(model, name string) bool {
+	return model == name || strings.HasPrefix(model, name+"-")
+}
+
+// toOpenAIResponseFormat converts a ResponseFormat to OpenAI's response_format payload.
+func toOpenAIResponseFormat(format *ResponseFormat) *openAIResponseFormat {
+	if format == nil {
+		return nil
+	}
+	if format.Type != ResponseFormatJSONSchema {
+		return &openAIResponseFormat{Type: format.Type}
+	}
+
+	name := format.Name
+	if name == "" {
+		name = "response"
+	}
+	return &openAIResponseFormat{
+		Type: ResponseFormatJSONSchema,
+		JSONSchema: &openAIJSONSchema{
+			Name:   name,
+			Schema: format.Schema,
+			Strict: true,
+		},
+	}
+}
+
+// isAzure reports whether the provider targets an Azure OpenAI deployment.
+func (p *OpenAIProvider) isAzure() bool {
+	return p.azureDeployment != ""
+}
+
+// endpoint builds the URL for the given API path.
+// Azure deployments use /openai/deployments/{deployment}/{path}?api-version=...
+func (p *OpenAIProvider) endpoint(path string) string {
+	if p.isAzure() {
+		return fmt.Sprintf("%s/openai/deployments/%s/%s?api-version=%s",
+			p.baseURL, url.PathEscape(p.azureDeployment), path, url.QueryEscape(p.azureAPIVersion))
+	}
+	return fmt.Sprintf("%s/%s", p.baseURL, path)
+}
+
+// authHeaders returns the authentication headers for the configured API flavor,
+// plus the organization and project headers when set.
+func (p *OpenAIProvider) authHeaders() map[string]string {
+	if p.isAzure() {
+		return map[string]string{"api-key": p.apiKey}
+	}
+
+	headers := make(map[string]string)
+	if p.apiKey != "" {
+		headers["Authorization"] = fmt.Sprintf("Bearer %s", p.apiKey)
+	}
+	if p.organization != "" {
+		headers["OpenAI-Organization"] = p.organization
+	}
+	if p.project != "" {
+		headers["OpenAI-Project"] = p.project
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
 // isOpenAIChatModel checks if a model ID is a chat model.
 func isOpenAIChatModel(id string) bool {
 	prefixes := []string{"gpt-4", "gpt-3.5", "o1", "chatgpt"}
@@ -256,11 +535,43 @@ func isOpenAIChatModel(id string) bool {
 	return false
 }
 
+// toOpenAIMessage converts a message, using a content array when images are attached.
+// Base64 images are sent as data URLs.
+func toOpenAIMessage(m Message) openAIMessage {
+	if len(m.Images) == 0 {
+		return openAIMessage{Role: string(m.Role), Content: m.Content}
+	}
+
+	parts := make([]openAIContentPart, 0, len(m.Images)+1)
+	if m.Content != "" {
+		parts = append(parts, openAIContentPart{Type: "text", Text: m.Content})
+	}
+	for _, img := range m.Images {
+		url := img.URL
+		if img.Base64 != "" {
+			url = fmt.Sprintf("data:%s;base64,%s", img.MimeType, img.Base64)
+		}
+		parts = append(parts, openAIContentPart{Type: "image_url", ImageURL: &openAIImageURL{URL: url}})
+	}
+	return openAIMessage{Role: string(m.Role), Content: parts}
+}
+
 // OpenAI API request/response types
 
+// openAIMessage content is a string, or a []openAIContentPart when images are attached.
 type openAIMessage struct {
 	Role    string `json:"role"`
-	Content string `json:"content"`
+	Content any    `json:"content"`
+}
+
+type openAIContentPart struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+type openAIImageURL struct {
+	URL string `json:"url"`
 }
 
 type openAIChatRequest struct {
@@ -269,6 +580,23 @@ type openAIChatRequest struct {
 	MaxTokens   int             `json:"max_tokens,omitempty"`
 	Temperature float64         `json:"temperature,omitempty"`
 	TopP        float64         `json:"top_p,omitempty"`
+	Stop        []string        `json:"stop,omitempty"`
+	Seed        *int            `json:"seed,omitempty"`
+
+	FrequencyPenalty float64               `json:"frequency_penalty,omitempty"`
+	PresencePenalty  float64               `json:"presence_penalty,omitempty"`
+	ResponseFormat   *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIJSONSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema,omitempty"`
+	Strict bool           `json:"strict"`
 }
 
 type openAIChatResponse struct {
@@ -292,8 +620,9 @@ type openAIChatResponse struct {
 }
 
 type openAIEmbeddingRequest struct {
-	Model string   `json:"model"`
-	Input []string `json:"input"`
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions,omitempty"`
 }
 
 type openAIEmbeddingResponse struct {
@@ -3,9 +3,13 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	storepb "github.com/usememos/memos/proto/gen/store"
 )
@@ -200,6 +204,90 @@ func TestOpenAIProviderComplete(t *testing.T) {
 	}
 }
 
+func TestOpenAIProviderCompleteSamplingOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		req  func() *CompletionRequest
+		want map[string]any
+		omit []string
+	}{
+		{
+			name: "all set",
+			req: func() *CompletionRequest {
+				seed := 7
+				return &CompletionRequest{
+					Stop:             []string{"END"},
+					Seed:             &seed,
+					FrequencyPenalty: 0.5,
+					PresencePenalty:  -0.25,
+				}
+			},
+			want: map[string]any{
+				"seed":              float64(7),
+				"frequency_penalty": 0.5,
+				"presence_penalty":  -0.25,
+			},
+		},
+		{
+			name: "zero seed is sent",
+			req: func() *CompletionRequest {
+				seed := 0
+				return &CompletionRequest{Seed: &seed}
+			},
+			want: map[string]any{"seed": float64(0)},
+			omit: []string{"stop", "frequency_penalty", "presence_penalty"},
+		},
+		{
+			name: "unset",
+			req:  func() *CompletionRequest { return &CompletionRequest{} },
+			omit: []string{"stop", "seed", "frequency_penalty", "presence_penalty"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var raw map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+					t.Fatalf("Failed to decode request: %v", err)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"model":"gpt-4o-mini","choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+			}))
+			defer server.Close()
+
+			provider := NewOpenAIProvider(&ProviderConfig{
+				Type:    ProviderOpenAI,
+				APIKey:  "test-key",
+				BaseURL: server.URL,
+			})
+
+			req := tt.req()
+			req.Messages = []Message{{Role: RoleUser, Content: "Hello"}}
+			if _, err := provider.Complete(context.Background(), req); err != nil {
+				t.Fatalf("Complete() error: %v", err)
+			}
+
+			for key, want := range tt.want {
+				if raw[key] != want {
+					t.Errorf("Expected %s=%v, got %v", key, want, raw[key])
+				}
+			}
+			for _, key := range tt.omit {
+				if _, exists := raw[key]; exists {
+					t.Errorf("Expected %s to be omitted, got %v", key, raw[key])
+				}
+			}
+			if len(req.Stop) > 0 {
+				stop, ok := raw["stop"].([]any)
+				if !ok || len(stop) != 1 || stop[0] != "END" {
+					t.Errorf("Expected stop [END], got %v", raw["stop"])
+				}
+			}
+		})
+	}
+}
+
 func TestOpenAIProviderCompleteNotConfigured(t *testing.T) {
 	provider := NewOpenAIProvider(&ProviderConfig{Type: ProviderOpenAI})
 
@@ -286,6 +374,39 @@ func TestOpenAIProviderEmbed(t *testing.T) {
 	}
 }
 
+func TestOpenAIProviderEmbedDimensions(t *testing.T) {
+	var gotDimensions int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIEmbeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		gotDimensions = req.Dimensions
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1,0.2]}],"model":"text-embedding-3-small"}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(&ProviderConfig{
+		Type:    ProviderOpenAI,
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+	})
+
+	if _, err := provider.Embed(context.Background(), &EmbeddingRequest{
+		Input:      []string{"Hello world"},
+		Model:      "text-embedding-3-small",
+		Dimensions: 256,
+	}); err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+
+	if gotDimensions != 256 {
+		t.Errorf("Expected dimensions 256 in request, got %d", gotDimensions)
+	}
+}
+
 func TestOpenAIProviderEmbedNotConfigured(t *testing.T) {
 	provider := NewOpenAIProvider(&ProviderConfig{Type: ProviderOpenAI})
 
@@ -453,6 +574,176 @@ func TestOpenAIProviderSuggestTags(t *testing.T) {
 	}
 }
 
+func TestOpenAIProviderSuggestTagsJSONMode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		if req.ResponseFormat == nil {
+			t.Fatal("Expected response_format to be set")
+		}
+		if req.ResponseFormat.Type != ResponseFormatJSONSchema {
+			t.Errorf("Expected response_format type %s, got %s", ResponseFormatJSONSchema, req.ResponseFormat.Type)
+		}
+		if req.ResponseFormat.JSONSchema == nil || req.ResponseFormat.JSONSchema.Name != "tag_suggestions" {
+			t.Errorf("Expected tag_suggestions schema, got %+v", req.ResponseFormat.JSONSchema)
+		} else if !req.ResponseFormat.JSONSchema.Strict {
+			t.Error("Expected strict schema")
+		}
+
+		// Schema-constrained response: a top-level object wrapping the tags
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"gpt-4o-mini","choices":[{"message":{"role":"assistant","content":"{\"tags\":[{\"tag\":\"meeting\",\"score\":0.9},{\"tag\":\"alpha\",\"score\":0.7}]}"}}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(&ProviderConfig{
+		Type:    ProviderOpenAI,
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+	})
+
+	resp, err := provider.SuggestTags(context.Background(), &SuggestTagsRequest{
+		Content: "Meeting notes for project Alpha",
+	})
+	if err != nil {
+		t.Fatalf("SuggestTags() error: %v", err)
+	}
+
+	if len(resp.Tags) != 2 || resp.Tags[0] != "meeting" || resp.Tags[1] != "alpha" {
+		t.Errorf("Expected [meeting alpha], got %v", resp.Tags)
+	}
+	if len(resp.Confidence) != 2 || resp.Confidence[0] != 0.9 {
+		t.Errorf("Expected confidence [0.9 0.7], got %v", resp.Confidence)
+	}
+}
+
+func TestOpenAIProviderSuggestTagsJSONObjectFallback(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ProviderConfig
+	}{
+		{
+			name:   "gpt-4-turbo",
+			config: ProviderConfig{DefaultModel: "gpt-4-turbo"},
+		},
+		{
+			name:   "azure",
+			config: ProviderConfig{AzureDeployment: "my-gpt4o", AzureAPIVersion: "2024-08-01-preview"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var raw map[string]any
+				if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+					t.Fatalf("Failed to decode request: %v", err)
+				}
+
+				format, ok := raw["response_format"].(map[string]any)
+				if !ok || format["type"] != ResponseFormatJSONObject {
+					t.Errorf("Expected response_format {type: json_object}, got %v", raw["response_format"])
+				}
+				if _, exists := format["json_schema"]; exists {
+					t.Error("Expected no json_schema for a model without structured outputs")
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"model":"gpt-4-turbo","choices":[{"message":{"role":"assistant","content":"{\"tags\":[{\"tag\":\"meeting\",\"score\":0.9}]}"}}]}`))
+			}))
+			defer server.Close()
+
+			config := tt.config
+			config.Type = ProviderOpenAI
+			config.APIKey = "test-key"
+			config.BaseURL = server.URL
+			provider := NewOpenAIProvider(&config)
+
+			resp, err := provider.SuggestTags(context.Background(), &SuggestTagsRequest{
+				Content: "Meeting notes for project Alpha",
+			})
+			if err != nil {
+				t.Fatalf("SuggestTags() error: %v", err)
+			}
+			if len(resp.Tags) != 1 || resp.Tags[0] != "meeting" {
+				t.Errorf("Expected [meeting], got %v", resp.Tags)
+			}
+		})
+	}
+}
+
+func TestOpenAIProviderSupportsStructuredOutputs(t *testing.T) {
+	provider := NewOpenAIProvider(&ProviderConfig{Type: ProviderOpenAI})
+
+	tests := []struct {
+		model string
+		want  bool
+	}{
+		{"", true},
+		{"gpt-4o-2024-08-06", true},
+		{"gpt-4.1-mini", true},
+		{"o1", true},
+		{"gpt-4o-2024-05-13", false},
+		{"gpt-4-turbo", false},
+		{"gpt-4", false},
+		{"gpt-3.5-turbo", false},
+		{"o1-mini", false},
+	}
+
+	for _, tt := range tests {
+		if got := provider.supportsStructuredOutputs(tt.model); got != tt.want {
+			t.Errorf("supportsStructuredOutputs(%q) = %v, want %v", tt.model, got, tt.want)
+		}
+	}
+
+	azure := NewOpenAIProvider(&ProviderConfig{
+		Type:            ProviderOpenAI,
+		AzureDeployment: "my-gpt4o",
+		AzureAPIVersion: "2024-08-01-preview",
+	})
+	if azure.supportsStructuredOutputs("gpt-4o") {
+		t.Error("Expected Azure deployments not to use json_schema")
+	}
+}
+
+func TestOpenAIProviderCompleteJSONObjectFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		format, ok := raw["response_format"].(map[string]any)
+		if !ok || format["type"] != "json_object" {
+			t.Errorf("Expected response_format {type: json_object}, got %v", raw["response_format"])
+		}
+		if _, exists := format["json_schema"]; exists {
+			t.Error("Expected no json_schema for json_object mode")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"gpt-4o-mini","choices":[{"message":{"role":"assistant","content":"{}"}}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(&ProviderConfig{
+		Type:    ProviderOpenAI,
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+	})
+
+	_, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages:       []Message{{Role: RoleUser, Content: "Hello"}},
+		ResponseFormat: &ResponseFormat{Type: ResponseFormatJSONObject},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+}
+
 func TestOpenAIProviderSummarize(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/chat/completions" {
@@ -547,10 +838,525 @@ func TestOpenAIProviderHTTPErrors(t *testing.T) {
 		}
 
 		_, err := provider.Complete(context.Background(), req)
-		if err != tt.expectedError {
+		if !errors.Is(err, tt.expectedError) {
 			t.Errorf("Status %d: expected %v, got %v", tt.statusCode, tt.expectedError, err)
 		}
 
 		server.Close()
 	}
 }
+
+func TestOpenAIProviderInvalidJSONResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><body>502 Bad Gateway from proxy</body></html>"))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(&ProviderConfig{
+		Type:    ProviderOpenAI,
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+	})
+
+	_, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err == nil {
+		t.Fatal("Expected an error for an HTML response")
+	}
+	for _, want := range []string{"openai", "status 200", "502 Bad Gateway from proxy"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got: %v", want, err)
+		}
+	}
+
+	_, err = provider.Embed(context.Background(), &EmbeddingRequest{Input: []string{"a"}})
+	if err == nil || !strings.Contains(err.Error(), "<html>") {
+		t.Errorf("Expected embed error to include the body snippet, got: %v", err)
+	}
+}
+
+func TestOpenAIProviderAzureComplete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/my-gpt4o/chat/completions" {
+			t.Errorf("Expected Azure deployment path, got %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("api-version"); got != "2024-02-01" {
+			t.Errorf("Expected api-version 2024-02-01, got %q", got)
+		}
+		if got := r.Header.Get("api-key"); got != "azure-key" {
+			t.Errorf("Expected api-key header azure-key, got %q", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("Expected no Authorization header, got %q", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"gpt-4o","choices":[{"message":{"role":"assistant","content":"Hi from Azure"}}],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(&ProviderConfig{
+		Type:            ProviderOpenAI,
+		APIKey:          "azure-key",
+		BaseURL:         server.URL,
+		AzureDeployment: "my-gpt4o",
+		AzureAPIVersion: "2024-02-01",
+	})
+
+	resp, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if resp.Content != "Hi from Azure" {
+		t.Errorf("Expected content 'Hi from Azure', got %q", resp.Content)
+	}
+}
+
+func TestOpenAIProviderAzureEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/my-embed/embeddings" {
+			t.Errorf("Expected Azure embeddings path, got %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("api-version"); got != azureOpenAIAPIVersion {
+			t.Errorf("Expected default api-version %s, got %q", azureOpenAIAPIVersion, got)
+		}
+		if got := r.Header.Get("api-key"); got != "azure-key" {
+			t.Errorf("Expected api-key header azure-key, got %q", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[{"embedding":[0.1,0.2]}],"model":"text-embedding-3-small"}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(&ProviderConfig{
+		Type:            ProviderOpenAI,
+		APIKey:          "azure-key",
+		BaseURL:         server.URL,
+		AzureDeployment: "my-embed",
+	})
+
+	resp, err := provider.Embed(context.Background(), &EmbeddingRequest{Input: []string{"Hello"}})
+	if err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+	if len(resp.Embeddings) != 1 || len(resp.Embeddings[0]) != 2 {
+		t.Errorf("Unexpected embeddings: %v", resp.Embeddings)
+	}
+}
+
+func TestOpenAIProviderAzureGetAvailableModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request to %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(&ProviderConfig{
+		Type:            ProviderOpenAI,
+		APIKey:          "azure-key",
+		BaseURL:         server.URL,
+		AzureDeployment: "my-gpt4o",
+	})
+
+	models, err := provider.GetAvailableModels(context.Background())
+	if err != nil {
+		t.Fatalf("GetAvailableModels() error: %v", err)
+	}
+	if len(models) != 1 || models[0] != "my-gpt4o" {
+		t.Errorf("Expected [my-gpt4o], got %v", models)
+	}
+}
+
+func TestOpenAIProviderAzureProtoRoundTrip(t *testing.T) {
+	provider := NewOpenAIProviderFromProto(&storepb.LLMOpenAIConfig{
+		ApiKey:          "azure-key",
+		BaseUrl:         "https://example.openai.azure.com",
+		AzureDeployment: "my-gpt4o",
+		AzureApiVersion: "2024-02-01",
+	})
+
+	pb := provider.ToProto()
+	if pb.AzureDeployment != "my-gpt4o" {
+		t.Errorf("Expected AzureDeployment my-gpt4o, got %s", pb.AzureDeployment)
+	}
+	if pb.AzureApiVersion != "2024-02-01" {
+		t.Errorf("Expected AzureApiVersion 2024-02-01, got %s", pb.AzureApiVersion)
+	}
+}
+
+func TestOpenAIProviderOrganizationAndProjectHeaders(t *testing.T) {
+	tests := []struct {
+		name         string
+		organization string
+		project      string
+	}{
+		{"configured", "org-abc123", "proj_xyz789"},
+		{"not configured", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got, present := r.Header["Openai-Organization"]; (tt.organization != "") != present || (present && got[0] != tt.organization) {
+					t.Errorf("Expected OpenAI-Organization %q, got %v", tt.organization, got)
+				}
+				if got, present := r.Header["Openai-Project"]; (tt.project != "") != present || (present && got[0] != tt.project) {
+					t.Errorf("Expected OpenAI-Project %q, got %v", tt.project, got)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+					t.Errorf("Expected Authorization header, got %q", got)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"model":"gpt-4o-mini","choices":[{"message":{"role":"assistant","content":"Hi"}}]}`))
+			}))
+			defer server.Close()
+
+			provider := NewOpenAIProvider(&ProviderConfig{
+				Type:               ProviderOpenAI,
+				APIKey:             "test-key",
+				BaseURL:            server.URL,
+				OpenAIOrganization: tt.organization,
+				OpenAIProject:      tt.project,
+			})
+
+			if _, err := provider.Complete(context.Background(), &CompletionRequest{
+				Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+			}); err != nil {
+				t.Fatalf("Complete() error: %v", err)
+			}
+		})
+	}
+}
+
+func TestOpenAIProviderOrganizationProtoRoundTrip(t *testing.T) {
+	provider := NewOpenAIProviderFromProto(&storepb.LLMOpenAIConfig{
+		ApiKey:       "test-key",
+		Organization: "org-abc123",
+		Project:      "proj_xyz789",
+	})
+
+	pb := provider.ToProto()
+	if pb.Organization != "org-abc123" || pb.Project != "proj_xyz789" {
+		t.Errorf("Expected organization and project to round-trip, got %q and %q", pb.Organization, pb.Project)
+	}
+}
+
+func TestOpenAICompatibleProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer local-token" {
+			t.Errorf("Expected Bearer local-token, got %q", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/models":
+			w.Write([]byte(`{"data":[{"id":"meta-llama/Llama-3.1-8B-Instruct"},{"id":"qwen2.5-7b"},{"id":"BAAI/bge-small-en"}]}`))
+		case "/chat/completions":
+			var req openAIChatRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("Failed to decode request: %v", err)
+			}
+			if req.Model != "qwen2.5-7b" {
+				t.Errorf("Expected model qwen2.5-7b, got %s", req.Model)
+			}
+			w.Write([]byte(`{"model":"qwen2.5-7b","choices":[{"message":{"role":"assistant","content":"Hi"}}]}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewOpenAICompatibleProvider(&ProviderConfig{
+		Type:         ProviderOpenAICompatible,
+		APIKey:       "local-token",
+		BaseURL:      server.URL,
+		DefaultModel: "qwen2.5-7b",
+	})
+
+	if provider.GetType() != ProviderOpenAICompatible {
+		t.Errorf("Expected type %s, got %s", ProviderOpenAICompatible, provider.GetType())
+	}
+	if err := ValidateAPIKeyFormat(provider.GetType(), "local-token"); err != nil {
+		t.Errorf("Expected non-sk key to be accepted, got %v", err)
+	}
+
+	models, err := provider.GetAvailableModels(context.Background())
+	if err != nil {
+		t.Fatalf("GetAvailableModels() error: %v", err)
+	}
+	if len(models) != 3 {
+		t.Errorf("Expected all 3 models without filtering, got %v", models)
+	}
+
+	resp, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if resp.Content != "Hi" {
+		t.Errorf("Expected content Hi, got %q", resp.Content)
+	}
+}
+
+func TestOpenAICompatibleProviderWithoutKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("Expected no Authorization header, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"local","choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAICompatibleProvider(&ProviderConfig{
+		Type:    ProviderOpenAICompatible,
+		BaseURL: server.URL,
+	})
+
+	if !provider.IsConfigured(context.Background()) {
+		t.Fatal("Expected provider with base URL and no key to be configured")
+	}
+	if _, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	}); err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+
+	unconfigured := NewOpenAICompatibleProvider(&ProviderConfig{Type: ProviderOpenAICompatible})
+	if unconfigured.IsConfigured(context.Background()) {
+		t.Error("Expected provider without base URL to be unconfigured")
+	}
+}
+
+func TestOpenAIProviderCheckHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" {
+			t.Errorf("Expected path /models, got %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"Incorrect API key provided"}}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(&ProviderConfig{
+		Type:    ProviderOpenAI,
+		APIKey:  "bad-key",
+		BaseURL: server.URL,
+	})
+
+	if err := provider.CheckHealth(context.Background()); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected ErrInvalidAPIKey, got %v", err)
+	}
+}
+
+func TestOpenAIProviderCheckHealthStatuses(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr error
+	}{
+		{"healthy", http.StatusOK, nil},
+		{"bad key", http.StatusUnauthorized, ErrInvalidAPIKey},
+		{"unavailable", http.StatusServiceUnavailable, ErrProviderUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != "/models" {
+					t.Errorf("Expected GET /models, got %s %s", r.Method, r.URL.Path)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer sk-test1234567890abcdefghij" {
+					t.Errorf("Expected the API key to be sent, got %q", got)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"data":[{"id":"gpt-4o-mini"}]}`))
+			}))
+			defer server.Close()
+
+			provider := NewOpenAIProvider(&ProviderConfig{
+				Type:        ProviderOpenAI,
+				APIKey:      "sk-test1234567890abcdefghij",
+				BaseURL:     server.URL,
+				RetryPolicy: &RetryPolicy{MaxAttempts: 1},
+			})
+
+			err := provider.CheckHealth(context.Background())
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("CheckHealth() error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOpenAIProviderCheckHealthUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	provider := NewOpenAIProvider(&ProviderConfig{
+		Type:        ProviderOpenAI,
+		APIKey:      "sk-test1234567890abcdefghij",
+		BaseURL:     url,
+		RetryPolicy: &RetryPolicy{MaxAttempts: 1},
+	})
+
+	if err := provider.CheckHealth(context.Background()); !errors.Is(err, ErrProviderUnavailable) {
+		t.Errorf("Expected ErrProviderUnavailable, got %v", err)
+	}
+}
+
+func TestOpenAIProviderCompleteWithImages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Role    string              `json:"role"`
+				Content []openAIContentPart `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+
+		parts := req.Messages[0].Content
+		if len(parts) != 3 {
+			t.Fatalf("Expected 1 text part and 2 image parts, got %+v", parts)
+		}
+		if parts[0].Type != "text" || parts[0].Text != "Describe these" {
+			t.Errorf("Unexpected text part: %+v", parts[0])
+		}
+		if parts[1].Type != "image_url" || parts[1].ImageURL == nil || parts[1].ImageURL.URL != "https://example.com/cat.jpg" {
+			t.Errorf("Unexpected URL image part: %+v", parts[1])
+		}
+		if parts[2].Type != "image_url" || parts[2].ImageURL == nil || parts[2].ImageURL.URL != "data:image/png;base64,aGVsbG8=" {
+			t.Errorf("Unexpected base64 image part: %+v", parts[2])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"gpt-4o","choices":[{"message":{"role":"assistant","content":"Two cats"}}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(&ProviderConfig{
+		Type:    ProviderOpenAI,
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+	})
+
+	_, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{{
+			Role:    RoleUser,
+			Content: "Describe these",
+			Images: []ImagePart{
+				{URL: "https://example.com/cat.jpg"},
+				{Base64: "aGVsbG8=", MimeType: "image/png"},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+}
+
+func TestOpenAICompatibleProviderRejectsImages(t *testing.T) {
+	provider := NewOpenAICompatibleProvider(&ProviderConfig{BaseURL: "http://localhost:8000/v1"})
+
+	_, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Describe", Images: []ImagePart{{URL: "https://example.com/cat.jpg"}}}},
+	})
+	if !errors.Is(err, ErrCapabilityNotSupported) {
+		t.Errorf("Expected ErrCapabilityNotSupported, got %v", err)
+	}
+}
+
+func TestOpenAIProviderCompleteRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(1500 * time.Millisecond):
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"gpt-4o-mini","choices":[{"message":{"role":"assistant","content":"slow"}}]}`))
+	}))
+	defer server.Close()
+
+	// The client default (1s) is shorter than the server's response time
+	provider := NewOpenAIProvider(&ProviderConfig{
+		Type:       ProviderOpenAI,
+		APIKey:     "test-key",
+		BaseURL:    server.URL,
+		Timeout:    1,
+		MaxRetries: 1,
+	})
+
+	resp, err := provider.Complete(context.Background(), &CompletionRequest{
+		Messages:       []Message{{Role: RoleUser, Content: "Hello"}},
+		TimeoutSeconds: 3,
+	})
+	if err != nil {
+		t.Fatalf("Expected request-level timeout to override client default, got %v", err)
+	}
+	if resp.Content != "slow" {
+		t.Errorf("Expected content slow, got %q", resp.Content)
+	}
+
+	// A shorter request-level timeout bounds the call, including retry backoff
+	provider = NewOpenAIProvider(&ProviderConfig{
+		Type:       ProviderOpenAI,
+		APIKey:     "test-key",
+		BaseURL:    server.URL,
+		MaxRetries: 3,
+	})
+
+	start := time.Now()
+	_, err = provider.Complete(context.Background(), &CompletionRequest{
+		Messages:       []Message{{Role: RoleUser, Content: "Hello"}},
+		TimeoutSeconds: 1,
+	})
+	if err == nil {
+		t.Fatal("Expected timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 1400*time.Millisecond {
+		t.Errorf("Expected call to stop at the request deadline, took %v", elapsed)
+	}
+}
+
+func TestOpenAIProviderCompleteFinishReason(t *testing.T) {
+	tests := map[string]string{
+		"stop":           FinishReasonStop,
+		"length":         FinishReasonLength,
+		"content_filter": FinishReasonContentFilter,
+		"tool_calls":     FinishReasonToolCalls,
+		"function_call":  FinishReasonToolCalls,
+	}
+
+	for native, want := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"model":"gpt-4o-mini","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":%q}]}`, native)
+		}))
+
+		provider := NewOpenAIProvider(&ProviderConfig{Type: ProviderOpenAI, APIKey: "test-key", BaseURL: server.URL})
+		resp, err := provider.Complete(context.Background(), &CompletionRequest{
+			Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+		})
+		server.Close()
+
+		if err != nil {
+			t.Fatalf("Complete() error for %s: %v", native, err)
+		}
+		if resp.FinishReason != want {
+			t.Errorf("finish_reason %q: expected %q, got %q", native, want, resp.FinishReason)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package llm
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// ModelPricing holds the price of a model in USD per 1K tokens.
+type ModelPricing struct {
+	// PromptPer1K is the price per 1K prompt (input) tokens.
+	PromptPer1K float64 `json:"prompt_per_1k"`
+
+	// CompletionPer1K is the price per 1K completion (output) tokens.
+	CompletionPer1K float64 `json:"completion_per_1k"`
+}
+
+var (
+	pricingMu sync.RWMutex
+
+	// modelPricing maps a model name (or model name prefix) to its pricing.
+	// Seeded with published list prices; override with RegisterModelPricing.
+	modelPricing = map[string]ModelPricing{
+		// OpenAI
+		"gpt-4o-mini":            {PromptPer1K: 0.00015, CompletionPer1K: 0.0006},
+		"gpt-4o":                 {PromptPer1K: 0.0025, CompletionPer1K: 0.01},
+		"gpt-4-turbo":            {PromptPer1K: 0.01, CompletionPer1K: 0.03},
+		"gpt-4":                  {PromptPer1K: 0.03, CompletionPer1K: 0.06},
+		"gpt-3.5-turbo":          {PromptPer1K: 0.0005, CompletionPer1K: 0.0015},
+		"o1-mini":                {PromptPer1K: 0.003, CompletionPer1K: 0.012},
+		"o1":                     {PromptPer1K: 0.015, CompletionPer1K: 0.06},
+		"text-embedding-3-small": {PromptPer1K: 0.00002},
+		"text-embedding-3-large": {PromptPer1K: 0.00013},
+
+		// Anthropic
+		"claude-3-5-sonnet": {PromptPer1K: 0.003, CompletionPer1K: 0.015},
+		"claude-3-5-haiku":  {PromptPer1K: 0.0008, CompletionPer1K: 0.004},
+		"claude-3-opus":     {PromptPer1K: 0.015, CompletionPer1K: 0.075},
+		"claude-3-sonnet":   {PromptPer1K: 0.003, CompletionPer1K: 0.015},
+		"claude-3-haiku":    {PromptPer1K: 0.00025, CompletionPer1K: 0.00125},
+
+		// Gemini
+		"gemini-1.5-flash": {PromptPer1K: 0.000075, CompletionPer1K: 0.0003},
+		"gemini-1.5-pro":   {PromptPer1K: 0.00125, CompletionPer1K: 0.005},
+		"gemini-2.0-flash": {PromptPer1K: 0.0001, CompletionPer1K: 0.0004},
+	}
+)
+
+// RegisterModelPricing sets the price for a model, overriding any existing entry.
+// The model name also matches versioned variants (e.g., "gpt-4o" matches "gpt-4o-2024-08-06").
+func RegisterModelPricing(model string, promptPer1K, completionPer1K float64) {
+	pricingMu.Lock()
+	defer pricingMu.Unlock()
+
+	modelPricing[model] = ModelPricing{
+		PromptPer1K:     promptPer1K,
+		CompletionPer1K: completionPer1K,
+	}
+}
+
+// GetModelPricing returns the pricing for a model.
+// An exact match wins; otherwise the longest registered name followed by a
+// "-" suffix is used, so "gpt-4o-2024-08-06" matches "gpt-4o" but "gpt-4.1"
+// does not match "gpt-4".
+func GetModelPricing(model string) (ModelPricing, bool) {
+	pricingMu.RLock()
+	defer pricingMu.RUnlock()
+
+	if pricing, ok := modelPricing[model]; ok {
+		return pricing, true
+	}
+
+	var best string
+	for name := range modelPricing {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelPricing{}, false
+	}
+
+	return modelPricing[best], true
+}
+
+// EstimatedCostUSD estimates the cost of this completion in USD.
+// If model is empty, the model reported in the response is used.
+// Returns 0 if usage is missing or the model has no registered pricing.
+func (r *CompletionResponse) EstimatedCostUSD(model string) float64 {
+	if r == nil {
+		return 0
+	}
+	if model == "" {
+		model = r.Model
+	}
+
+	return estimateCostUSD(model, r.Usage)
+}
+
+// estimateCostUSD computes the cost of the given token usage for a model.
+func estimateCostUSD(model string, usage *TokenUsage) float64 {
+	if usage == nil {
+		return 0
+	}
+
+	pricing, ok := GetModelPricing(model)
+	if !ok {
+		slog.Debug("No pricing registered for model, cost estimated as 0", slog.String("model", model))
+		return 0
+	}
+
+	return float64(usage.PromptTokens)/1000*pricing.PromptPer1K +
+		float64(usage.CompletionTokens)/1000*pricing.CompletionPer1K
+}
@@ -0,0 +1,166 @@
+package llm
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func floatEquals(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestEstimatedCostUSD_GPT4oMini(t *testing.T) {
+	resp := &CompletionResponse{
+		Model: "gpt-4o-mini",
+		Usage: &TokenUsage{
+			PromptTokens:     1000,
+			CompletionTokens: 500,
+			TotalTokens:      1500,
+		},
+	}
+
+	// 1000 * 0.00015/1K + 500 * 0.0006/1K = 0.00015 + 0.0003
+	expected := 0.00045
+	if cost := resp.EstimatedCostUSD("gpt-4o-mini"); !floatEquals(cost, expected) {
+		t.Errorf("Expected cost %f, got %f", expected, cost)
+	}
+
+	// Empty model falls back to the response model
+	if cost := resp.EstimatedCostUSD(""); !floatEquals(cost, expected) {
+		t.Errorf("Expected cost %f with empty model, got %f", expected, cost)
+	}
+}
+
+func TestEstimatedCostUSD_VersionedModel(t *testing.T) {
+	resp := &CompletionResponse{
+		Usage: &TokenUsage{PromptTokens: 1000, CompletionTokens: 1000},
+	}
+
+	// Versioned names match the longest registered prefix (gpt-4o-mini, not gpt-4o)
+	expected := 0.00015 + 0.0006
+	if cost := resp.EstimatedCostUSD("gpt-4o-mini-2024-07-18"); !floatEquals(cost, expected) {
+		t.Errorf("Expected cost %f, got %f", expected, cost)
+	}
+}
+
+func TestGetModelPricing_PrefixBoundary(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"gpt-4-0613", "gpt-4"},
+		{"gpt-4-turbo-2024-04-09", "gpt-4-turbo"},
+		{"o1-preview", "o1"},
+		{"gpt-4.1", ""},
+		{"gpt-4.1-mini", ""},
+		{"o1pro", ""},
+	}
+
+	for _, tt := range tests {
+		got, ok := GetModelPricing(tt.model)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("GetModelPricing(%q) = %+v, want no match", tt.model, got)
+			}
+			continue
+		}
+		want, _ := GetModelPricing(tt.want)
+		if !ok || got != want {
+			t.Errorf("GetModelPricing(%q) = %+v, %v, want pricing of %s", tt.model, got, ok, tt.want)
+		}
+	}
+}
+
+func TestEstimatedCostUSD_UnknownModel(t *testing.T) {
+	resp := &CompletionResponse{
+		Usage: &TokenUsage{PromptTokens: 1000, CompletionTokens: 1000},
+	}
+
+	if cost := resp.EstimatedCostUSD("unknown-model"); cost != 0 {
+		t.Errorf("Expected 0 for unknown model, got %f", cost)
+	}
+
+	// Nil usage and nil response should not panic
+	if cost := (&CompletionResponse{Model: "gpt-4o"}).EstimatedCostUSD(""); cost != 0 {
+		t.Errorf("Expected 0 for nil usage, got %f", cost)
+	}
+	var nilResp *CompletionResponse
+	if cost := nilResp.EstimatedCostUSD("gpt-4o"); cost != 0 {
+		t.Errorf("Expected 0 for nil response, got %f", cost)
+	}
+}
+
+func TestRegisterModelPricing_Override(t *testing.T) {
+	original, ok := GetModelPricing("gpt-4o-mini")
+	if !ok {
+		t.Fatal("Expected default pricing for gpt-4o-mini")
+	}
+	defer RegisterModelPricing("gpt-4o-mini", original.PromptPer1K, original.CompletionPer1K)
+
+	RegisterModelPricing("gpt-4o-mini", 1.0, 2.0)
+
+	resp := &CompletionResponse{
+		Usage: &TokenUsage{PromptTokens: 1000, CompletionTokens: 500},
+	}
+	if cost := resp.EstimatedCostUSD("gpt-4o-mini"); !floatEquals(cost, 2.0) {
+		t.Errorf("Expected overridden cost 2.0, got %f", cost)
+	}
+}
+
+func TestServiceUsageStats_CostTracking(t *testing.T) {
+	svc := NewService(WithCostTracking())
+	svc.RegisterProvider(&mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		completeResp: &CompletionResponse{
+			Model: "gpt-4o-mini",
+			Usage: &TokenUsage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500},
+		},
+	})
+
+	req := &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "Hello"}}}
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Complete(context.Background(), req); err != nil {
+			t.Fatalf("Complete() error: %v", err)
+		}
+	}
+
+	stats := svc.GetUsageStats()
+	if stats.Requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", stats.Requests)
+	}
+	if stats.TotalTokens != 3000 {
+		t.Errorf("Expected 3000 total tokens, got %d", stats.TotalTokens)
+	}
+	if !floatEquals(stats.EstimatedCostUSD, 0.0009) {
+		t.Errorf("Expected estimated cost 0.0009, got %f", stats.EstimatedCostUSD)
+	}
+}
+
+func TestServiceUsageStats_CostTrackingDisabled(t *testing.T) {
+	svc := NewService()
+	svc.RegisterProvider(&mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		completeResp: &CompletionResponse{
+			Model: "gpt-4o-mini",
+			Usage: &TokenUsage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500},
+		},
+	})
+
+	req := &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "Hello"}}}
+	if _, err := svc.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+
+	stats := svc.GetUsageStats()
+	if stats.TotalTokens != 1500 {
+		t.Errorf("Expected 1500 total tokens, got %d", stats.TotalTokens)
+	}
+	if stats.EstimatedCostUSD != 0 {
+		t.Errorf("Expected no cost without cost tracking, got %f", stats.EstimatedCostUSD)
+	}
+}
@@ -1,11 +1,16 @@
 // Package llm provides a unified interface for Large Language Model providers.
-// It supports multiple providers (OpenAI, Anthropic, Gemini, Ollama) with a
+// It supports multiple providers (OpenAI, Anthropic, Gemini, Ollama, Cohere) with a
 // common interface for chat completion, embeddings, and AI-assisted features.
 package llm
 
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
 )
 
 // Common errors for LLM operations.
@@ -27,6 +32,19 @@ var (
 
 	// ErrProviderUnavailable indicates the provider service is unavailable.
 	ErrProviderUnavailable = errors.New("provider service unavailable")
+
+	// ErrCapabilityNotSupported indicates the provider lacks the capability a request needs.
+	ErrCapabilityNotSupported = errors.New("capability not supported by provider")
+
+	// ErrEmptyResponse indicates the model finished without producing any content.
+	ErrEmptyResponse = errors.New("model returned an empty response")
+
+	// ErrInvalidSampling indicates Temperature or TopP is outside the provider's
+	// range (returned only with ProviderConfig.StrictSampling).
+	ErrInvalidSampling = errors.New("sampling parameter out of range")
+
+	// ErrResponseTooLarge indicates a response body exceeded ProviderConfig.MaxResponseBytes.
+	ErrResponseTooLarge = errors.New("response body too large")
 )
 
 // ProviderType identifies the LLM provider.
@@ -44,6 +62,19 @@ const (
 
 	// ProviderOllama is the local Ollama provider.
 	ProviderOllama ProviderType = "ollama"
+
+	// ProviderOpenAICompatible is any endpoint speaking the OpenAI chat/embeddings protocol
+	// (e.g., vLLM, LM Studio, LocalAI).
+	ProviderOpenAICompatible ProviderType = "openai_compatible"
+
+	// ProviderGroq is Groq's OpenAI-compatible API for low-latency inference.
+	ProviderGroq ProviderType = "groq"
+
+	// ProviderCohere is the Cohere provider (Command, Embed, Rerank).
+	ProviderCohere ProviderType = "cohere"
+
+	// ProviderMock is the offline echo provider (see MockEchoProvider).
+	ProviderMock ProviderType = "mock"
 )
 
 // Role represents the role of a message sender.
@@ -67,6 +98,39 @@ type Message struct {
 
 	// Content is the text content of the message.
 	Content string `json:"content"`
+
+	// Images are optional image inputs (requires Capabilities().Vision).
+	Images []ImagePart `json:"images,omitempty"`
+}
+
+// ImagePart is an image attached to a message. Set either URL or Base64.
+type ImagePart struct {
+	// URL is a publicly reachable image URL.
+	URL string `json:"url,omitempty"`
+
+	// Base64 is the base64-encoded image data (without a data: prefix).
+	Base64 string `json:"base64,omitempty"`
+
+	// MimeType is the image media type (e.g., "image/png"); required with Base64.
+	MimeType string `json:"mime_type,omitempty"`
+}
+
+// hasImages reports whether any message carries image parts.
+func hasImages(messages []Message) bool {
+	for _, m := range messages {
+		if len(m.Images) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVision returns ErrCapabilityNotSupported if the request has images the provider can't accept.
+func checkVision(provider Provider, messages []Message) error {
+	if hasImages(messages) && !provider.Capabilities().Vision {
+		return fmt.Errorf("%w: %s does not accept image inputs", ErrCapabilityNotSupported, provider.GetName())
+	}
+	return nil
 }
 
 // CompletionRequest contains parameters for a chat completion request.
@@ -86,8 +150,58 @@ type CompletionRequest struct {
 	// TopP controls nucleus sampling (0.0-1.0).
 	TopP float64 `json:"top_p,omitempty"`
 
+	// Stop lists sequences at which generation stops.
+	Stop []string `json:"stop,omitempty"`
+
+	// Seed requests deterministic sampling where supported (nil means unset).
+	Seed *int `json:"seed,omitempty"`
+
+	// FrequencyPenalty penalizes tokens by how often they appear (-2.0-2.0, OpenAI only).
+	FrequencyPenalty float64 `json:"frequency_penalty,omitempty"`
+
+	// PresencePenalty penalizes tokens that have already appeared (-2.0-2.0, OpenAI only).
+	PresencePenalty float64 `json:"presence_penalty,omitempty"`
+
+	// ResponseFormat constrains the response to JSON (optional, ignored by providers without JSON mode).
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+
 	// Stream indicates whether to stream the response.
 	Stream bool `json:"stream,omitempty"`
+
+	// TimeoutSeconds overrides the provider's request timeout for this call,
+	// including retries (0 uses the provider default).
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+
+	// Metadata is sent as HTTP headers with this call only (e.g., a tenant id for
+	// a gateway). It overrides ProviderConfig.ExtraHeaders but never auth headers.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// TruncationStrategy shortens the longest user message when the estimated
+	// prompt plus MaxTokens exceeds the model's known context window
+	// (default TruncationNone). CompletionResponse.Truncated reports whether it applied.
+	TruncationStrategy TruncationStrategy `json:"truncation_strategy,omitempty"`
+}
+
+// Response format types for CompletionRequest.ResponseFormat.
+const (
+	// ResponseFormatJSONObject requests any valid JSON object.
+	ResponseFormatJSONObject = "json_object"
+
+	// ResponseFormatJSONSchema requests JSON matching ResponseFormat.Schema.
+	ResponseFormatJSONSchema = "json_schema"
+)
+
+// ResponseFormat constrains the shape of a completion response.
+type ResponseFormat struct {
+	// Type is ResponseFormatJSONObject or ResponseFormatJSONSchema.
+	Type string `json:"type"`
+
+	// Name identifies the schema (json_schema only).
+	Name string `json:"name,omitempty"`
+
+	// Schema is the JSON schema the response must match (json_schema), or a
+	// hint for providers that accept one with json_object.
+	Schema map[string]any `json:"schema,omitempty"`
 }
 
 // CompletionResponse contains the result of a chat completion.
@@ -101,8 +215,65 @@ type CompletionResponse struct {
 	// Usage contains token usage statistics.
 	Usage *TokenUsage `json:"usage,omitempty"`
 
-	// FinishReason indicates why the generation stopped.
+	// FinishReason indicates why the generation stopped, normalized to one of
+	// the FinishReason* constants when the provider's native value is known.
 	FinishReason string `json:"finish_reason,omitempty"`
+
+	// Truncated reports that the prompt was shortened per CompletionRequest.TruncationStrategy.
+	Truncated bool `json:"truncated,omitempty"`
+
+	// Warnings are set by Service, e.g. when the model is deprecated (see
+	// RegisterModelDeprecation).
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Normalized finish reasons reported in CompletionResponse and CompletionChunk.
+const (
+	// FinishReasonStop means the model finished naturally or hit a stop sequence.
+	FinishReasonStop = "stop"
+
+	// FinishReasonLength means the output was truncated at the token limit.
+	FinishReasonLength = "length"
+
+	// FinishReasonContentFilter means the output was withheld or cut by a safety filter.
+	FinishReasonContentFilter = "content_filter"
+
+	// FinishReasonToolCalls means the model stopped to call a tool.
+	FinishReasonToolCalls = "tool_calls"
+)
+
+// normalizeFinishReason maps a provider's native stop reason to a FinishReason*
+// constant. Unrecognized values are returned unchanged.
+func normalizeFinishReason(native string) string {
+	switch strings.ToLower(native) {
+	case "stop", "end_turn", "stop_sequence", "complete":
+		return FinishReasonStop
+	case "length", "max_tokens":
+		return FinishReasonLength
+	case "content_filter", "refusal", "safety", "error_toxic":
+		return FinishReasonContentFilter
+	case "tool_calls", "tool_use", "tool_call", "function_call":
+		return FinishReasonToolCalls
+	}
+	return native
+}
+
+// addUsage returns the sum of a and b, or nil if both are nil.
+func addUsage(a, b *TokenUsage) *TokenUsage {
+	if a == nil && b == nil {
+		return nil
+	}
+	var sum TokenUsage
+	for _, u := range []*TokenUsage{a, b} {
+		if u != nil {
+			sum.PromptTokens += u.PromptTokens
+			sum.CompletionTokens += u.CompletionTokens
+			sum.TotalTokens += u.TotalTokens
+			sum.CacheCreationTokens += u.CacheCreationTokens
+			sum.CacheReadTokens += u.CacheReadTokens
+		}
+	}
+	return &sum
 }
 
 // TokenUsage tracks token consumption for billing/monitoring.
@@ -115,6 +286,14 @@ type TokenUsage struct {
 
 	// TotalTokens is the sum of prompt and completion tokens.
 	TotalTokens int `json:"total_tokens"`
+
+	// CacheCreationTokens is the number of prompt tokens written to the
+	// provider's prompt cache. They are not included in PromptTokens.
+	CacheCreationTokens int `json:"cache_creation_tokens,omitempty"`
+
+	// CacheReadTokens is the number of prompt tokens served from the provider's
+	// prompt cache. They are not included in PromptTokens.
+	CacheReadTokens int `json:"cache_read_tokens,omitempty"`
 }
 
 // EmbeddingRequest contains parameters for an embedding request.
@@ -127,6 +306,17 @@ type EmbeddingRequest struct {
 
 	// Dimensions specifies the output embedding dimensions (if supported).
 	Dimensions int `json:"dimensions,omitempty"`
+
+	// Normalize scales every returned vector to unit length (applied by Service
+	// for all providers).
+	Normalize bool `json:"normalize,omitempty"`
+
+	// TargetDimension fits every returned vector to this length client-side
+	// (by default zero-padding or truncating, see WithDimensionAdapter), so
+	// vectors from different providers stay comparable. Unlike Dimensions it
+	// is applied by Service for all providers, before Normalize. Zero uses the
+	// service default set with WithTargetDimension.
+	TargetDimension int `json:"target_dimension,omitempty"`
 }
 
 // EmbeddingResponse contains the result of an embedding request.
@@ -139,6 +329,9 @@ type EmbeddingResponse struct {
 
 	// Usage contains token usage statistics.
 	Usage *TokenUsage `json:"usage,omitempty"`
+
+	// Dimension is the length of the returned vectors (set by Service from the first vector).
+	Dimension int `json:"dimension,omitempty"`
 }
 
 // SuggestTagsRequest contains parameters for tag suggestion.
@@ -154,6 +347,18 @@ type SuggestTagsRequest struct {
 
 	// Language is the preferred language for tags (e.g., "en", "zh").
 	Language string `json:"language,omitempty"`
+
+	// PromptTemplate overrides the default user prompt (text/template over TagPromptData).
+	PromptTemplate string `json:"prompt_template,omitempty"`
+
+	// Model overrides the provider's default model (optional).
+	Model string `json:"model,omitempty"`
+
+	// ReuseMode asks the model to pick tags from ExistingTags first and only
+	// invent new ones when none fit, instead of treating ExistingTags as a hint.
+	// Reused tags take their ExistingTags spelling and the others are listed
+	// in SuggestTagsResponse.NewTags.
+	ReuseMode bool `json:"reuse_mode,omitempty"`
 }
 
 // SuggestTagsResponse contains suggested tags for content.
@@ -163,6 +368,15 @@ type SuggestTagsResponse struct {
 
 	// Confidence scores for each tag (0.0-1.0).
 	Confidence []float64 `json:"confidence,omitempty"`
+
+	// NewTags are the suggested tags not found in ExistingTags (only set in ReuseMode).
+	NewTags []string `json:"new_tags,omitempty"`
+
+	// Model is the model that generated the suggestions, if reported.
+	Model string `json:"model,omitempty"`
+
+	// Usage is the token usage of the underlying completion, if reported.
+	Usage *TokenUsage `json:"usage,omitempty"`
 }
 
 // SummarizeRequest contains parameters for content summarization.
@@ -175,6 +389,15 @@ type SummarizeRequest struct {
 
 	// Style is the summarization style (e.g., "brief", "detailed", "bullet").
 	Style string `json:"style,omitempty"`
+
+	// Language is the preferred language for the summary (e.g., "en", "zh").
+	Language string `json:"language,omitempty"`
+
+	// ExtractKeyPoints asks for a list of key points alongside the prose summary.
+	ExtractKeyPoints bool `json:"extract_key_points,omitempty"`
+
+	// Model overrides the provider's default model (optional).
+	Model string `json:"model,omitempty"`
 }
 
 // SummarizeResponse contains the summarized content.
@@ -184,6 +407,39 @@ type SummarizeResponse struct {
 
 	// KeyPoints are the main points extracted (optional).
 	KeyPoints []string `json:"key_points,omitempty"`
+
+	// Model is the model that generated the summary, if reported.
+	Model string `json:"model,omitempty"`
+
+	// Usage is the token usage of the underlying completions, if reported.
+	Usage *TokenUsage `json:"usage,omitempty"`
+}
+
+// SuggestTitleRequest contains parameters for title generation.
+type SuggestTitleRequest struct {
+	// Content is the text to title.
+	Content string `json:"content"`
+
+	// MaxLength is the maximum title length in characters (default 60).
+	MaxLength int `json:"max_length,omitempty"`
+
+	// Language is the preferred language for the title (e.g., "en", "zh").
+	Language string `json:"language,omitempty"`
+
+	// Model overrides the provider's default model (optional).
+	Model string `json:"model,omitempty"`
+}
+
+// SuggestTitleResponse contains the suggested title.
+type SuggestTitleResponse struct {
+	// Title is the generated title, unquoted and at most MaxLength characters.
+	Title string `json:"title"`
+
+	// Model is the model that generated the title, if reported.
+	Model string `json:"model,omitempty"`
+
+	// Usage is the token usage of the underlying completion, if reported.
+	Usage *TokenUsage `json:"usage,omitempty"`
 }
 
 // Provider defines the interface for LLM providers.
@@ -204,6 +460,9 @@ type Provider interface {
 	// GetAvailableModels returns a list of available models.
 	GetAvailableModels(ctx context.Context) ([]string, error)
 
+	// GetModelInfo returns the available models with their context window and output limits.
+	GetModelInfo(ctx context.Context) ([]ModelInfo, error)
+
 	// Complete performs a chat completion request.
 	Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error)
 
@@ -215,6 +474,36 @@ type Provider interface {
 
 	// Summarize generates a summary of the content.
 	Summarize(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error)
+
+	// SuggestTitle generates a short title for the content.
+	SuggestTitle(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error)
+
+	// Capabilities describes the features this provider supports.
+	Capabilities() ProviderCapabilities
+
+	// CheckHealth verifies the provider is configured and reachable.
+	CheckHealth(ctx context.Context) error
+
+	// ValidateConfig checks the provider configuration without making network calls.
+	ValidateConfig(ctx context.Context) error
+}
+
+// ProviderCapabilities describes which optional features a provider supports.
+type ProviderCapabilities struct {
+	// Embeddings indicates Embed is supported.
+	Embeddings bool `json:"embeddings"`
+
+	// Streaming indicates streamed completions are supported.
+	Streaming bool `json:"streaming"`
+
+	// ToolCalling indicates tool/function calling is supported.
+	ToolCalling bool `json:"tool_calling"`
+
+	// JSONMode indicates CompletionRequest.ResponseFormat is honored.
+	JSONMode bool `json:"json_mode"`
+
+	// Vision indicates image inputs are supported.
+	Vision bool `json:"vision"`
 }
 
 // ProviderConfig holds configuration for creating a provider.
@@ -234,14 +523,111 @@ type ProviderConfig struct {
 	// EmbeddingModel is the model to use for embeddings.
 	EmbeddingModel string `json:"embedding_model,omitempty"`
 
+	// AzureDeployment is the Azure OpenAI deployment name (only for OpenAI provider).
+	// When set, requests use Azure's deployment URLs and api-key header.
+	AzureDeployment string `json:"azure_deployment,omitempty"`
+
+	// AzureAPIVersion is the Azure OpenAI API version (only for OpenAI provider).
+	AzureAPIVersion string `json:"azure_api_version,omitempty"`
+
+	// OpenAIOrganization is sent as the OpenAI-Organization header so usage is
+	// billed to that organization (only for OpenAI provider).
+	OpenAIOrganization string `json:"openai_organization,omitempty"`
+
+	// OpenAIProject is sent as the OpenAI-Project header (only for OpenAI provider).
+	OpenAIProject string `json:"openai_project,omitempty"`
+
 	// OllamaHost is the Ollama server address (only for Ollama provider).
 	OllamaHost string `json:"ollama_host,omitempty"`
 
+	// OllamaNumCtx sets the context window size (options.num_ctx) for Ollama chat
+	// requests. When zero, the model's default is used.
+	OllamaNumCtx int `json:"ollama_num_ctx,omitempty"`
+
+	// OllamaKeepAlive controls how long Ollama keeps the model loaded after a
+	// request (e.g. "10m", "-1" to keep it loaded). When empty, the server default is used.
+	OllamaKeepAlive string `json:"ollama_keep_alive,omitempty"`
+
+	// OllamaBatchEmbed sends all embedding inputs in a single /api/embed request
+	// (Ollama 0.3.4+). Servers that return fewer embeddings than inputs are
+	// retried one input at a time.
+	OllamaBatchEmbed bool `json:"ollama_batch_embed,omitempty"`
+
 	// Timeout is the request timeout in seconds.
 	Timeout int `json:"timeout,omitempty"`
 
 	// MaxRetries is the number of retries for failed requests.
 	MaxRetries int `json:"max_retries,omitempty"`
+
+	// ModelsCacheTTL is how long GetAvailableModels results are cached
+	// (default 60s). A negative value disables caching.
+	ModelsCacheTTL time.Duration `json:"models_cache_ttl,omitempty"`
+
+	// RetryPolicy customizes backoff, jitter, and which failures are retried.
+	// When nil, MaxRetries retries are made with jittered exponential backoff.
+	RetryPolicy *RetryPolicy `json:"-"`
+
+	// TagPromptTemplate overrides the tag suggestion user prompt for this provider
+	// (text/template over TagPromptData). It takes precedence over SuggestTagsRequest.PromptTemplate.
+	TagPromptTemplate string `json:"tag_prompt_template,omitempty"`
+
+	// SummarizeChunkTokens is the chunk size, in estimated tokens, used when content
+	// is too long for the model's context window and is summarized in chunks (default 2000).
+	SummarizeChunkTokens int `json:"summarize_chunk_tokens,omitempty"`
+
+	// SummarizeChunkOverlap is the number of estimated tokens shared by adjacent
+	// chunks so context isn't lost at chunk boundaries (default 200).
+	SummarizeChunkOverlap int `json:"summarize_chunk_overlap,omitempty"`
+
+	// SummaryMaxTokens maps a summary's MaxLength (characters) and style to the
+	// completion MaxTokens. When nil, DefaultSummaryMaxTokens is used.
+	SummaryMaxTokens func(maxLength int, style string) int `json:"-"`
+
+	// ExtraHeaders are sent with every request (e.g., HTTP-Referer and X-Title for
+	// OpenRouter). Headers set by the provider, such as auth, take precedence.
+	ExtraHeaders map[string]string `json:"extra_headers,omitempty"`
+
+	// EnablePromptCaching marks the system prompt as cacheable with Anthropic
+	// prompt caching, so repeated instructions (tagging, summarizing) are
+	// billed at the cached rate. Ignored by other providers.
+	EnablePromptCaching bool `json:"enable_prompt_caching,omitempty"`
+
+	// CaptureRawResponses keeps the most recent completion responses (content,
+	// usage and finish reason) in memory for debugging, e.g., to see why a
+	// model's tags won't parse. Read them with Service.LastResponses.
+	CaptureRawResponses bool `json:"capture_raw_responses,omitempty"`
+
+	// RawResponseBufferSize is the number of responses kept when
+	// CaptureRawResponses is set (default 50).
+	RawResponseBufferSize int `json:"raw_response_buffer_size,omitempty"`
+
+	// StrictSampling rejects an out-of-range Temperature or TopP with
+	// ErrInvalidSampling instead of clamping it to the provider's range.
+	StrictSampling bool `json:"strict_sampling,omitempty"`
+
+	// MaxResponseBytes caps the size of a response body read into memory
+	// (default 8 MiB); larger responses fail with ErrResponseTooLarge. Streamed
+	// completions are not capped, but their error bodies are.
+	MaxResponseBytes int64 `json:"max_response_bytes,omitempty"`
+
+	// HTTPClient overrides the default HTTP client (e.g., for proxies or custom TLS).
+	// When nil, a client with Timeout is used.
+	HTTPClient *http.Client `json:"-"`
+
+	// Logger receives a debug record for every request made by DoRequest
+	// (method, URL, headers, bodies, status, latency). API keys are masked.
+	// When nil, requests are not logged.
+	Logger *slog.Logger `json:"-"`
+}
+
+// Validate checks the configuration for errors that would only surface at request time.
+func (c *ProviderConfig) Validate() error {
+	if c.TagPromptTemplate != "" {
+		if err := ValidateTagPromptTemplate(c.TagPromptTemplate); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // DefaultConfig returns sensible defaults for the given provider type.
@@ -256,15 +642,32 @@ func DefaultConfig(providerType ProviderType) *ProviderConfig {
 	case ProviderOpenAI:
 		config.BaseURL = "https://api.openai.com/v1"
 		config.DefaultModel = "gpt-4o-mini"
+		config.EmbeddingModel = openAIEmbeddingModel
 	case ProviderAnthropic:
+		// Anthropic has no embeddings API, so EmbeddingModel stays empty
 		config.BaseURL = "https://api.anthropic.com"
 		config.DefaultModel = "claude-3-5-sonnet-20241022"
 	case ProviderGemini:
 		config.BaseURL = "https://generativelanguage.googleapis.com/v1beta"
 		config.DefaultModel = "gemini-1.5-flash"
+		config.EmbeddingModel = "text-embedding-004"
 	case ProviderOllama:
 		config.OllamaHost = "http://localhost:11434"
 		config.DefaultModel = "llama3.2"
+		config.EmbeddingModel = ollamaDefaultEmbeddingModel
+	case ProviderOpenAICompatible:
+		// Model names vary by server, so no default model is assumed
+		config.BaseURL = "http://localhost:8000/v1"
+	case ProviderGroq:
+		// Groq has no embeddings API, so EmbeddingModel stays empty
+		config.BaseURL = groqBaseURL
+		config.DefaultModel = groqDefaultModel
+	case ProviderCohere:
+		config.BaseURL = "https://api.cohere.com"
+		config.DefaultModel = "command-r-08-2024"
+		config.EmbeddingModel = cohereEmbeddingModel
+	case ProviderMock:
+		config.DefaultModel = mockEchoModel
 	}
 
 	return config
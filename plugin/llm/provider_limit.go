@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ProviderRateLimit is a request budget for one provider type, shared by all
+// callers of the Service. It guards the upstream quota independently of the
+// per-user limits enforced by TagService.
+type ProviderRateLimit struct {
+	// RequestsPerMinute is the sustained request rate. Values <= 0 disable the limit.
+	RequestsPerMinute int
+
+	// Burst is the number of requests allowed at once (default RequestsPerMinute).
+	Burst int
+
+	// Wait makes callers block until budget is available (bounded by their
+	// context) instead of failing immediately with ErrRateLimited.
+	Wait bool
+}
+
+// providerLimiter is the token bucket enforcing a ProviderRateLimit.
+type providerLimiter struct {
+	limiter *rate.Limiter
+	wait    bool
+}
+
+// WithProviderRateLimit limits requests to providers of the given type.
+func WithProviderRateLimit(providerType ProviderType, limit ProviderRateLimit) ServiceOption {
+	return func(s *service) {
+		if limit.RequestsPerMinute <= 0 {
+			delete(s.limiters, providerType)
+			return
+		}
+		burst := limit.Burst
+		if burst <= 0 {
+			burst = limit.RequestsPerMinute
+		}
+		s.limiters[providerType] = &providerLimiter{
+			limiter: rate.NewLimiter(rate.Every(time.Minute/time.Duration(limit.RequestsPerMinute)), burst),
+			wait:    limit.Wait,
+		}
+	}
+}
+
+// acquire takes one request from the provider's budget, if it has one.
+func (s *service) acquire(ctx context.Context, provider Provider) error {
+	l, ok := s.limiters[provider.GetType()]
+	if !ok {
+		return nil
+	}
+
+	if !l.wait {
+		if !l.limiter.Allow() {
+			return fmt.Errorf("%w: %s request budget exhausted", ErrRateLimited, provider.GetType())
+		}
+		return nil
+	}
+
+	if err := l.limiter.Wait(ctx); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		// The wait would outlast the context deadline
+		return fmt.Errorf("%w: %s request budget exhausted: %v", ErrRateLimited, provider.GetType(), err)
+	}
+	return nil
+}
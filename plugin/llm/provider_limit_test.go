@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProviderRateLimitExhausted(t *testing.T) {
+	provider := &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		completeResp: &CompletionResponse{Content: "ok"},
+	}
+	svc := NewService(WithProviderRateLimit(ProviderOpenAI, ProviderRateLimit{RequestsPerMinute: 2}))
+	svc.RegisterProvider(provider)
+
+	req := &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "Hi"}}}
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Complete(context.Background(), req); err != nil {
+			t.Fatalf("Complete() #%d error: %v", i+1, err)
+		}
+	}
+
+	_, err := svc.Complete(context.Background(), req)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited once the budget is exhausted, got %v", err)
+	}
+
+	// The budget is shared across operations on the same provider
+	if _, err := svc.SuggestTags(context.Background(), &SuggestTagsRequest{Content: "memo"}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected SuggestTags to share the exhausted budget, got %v", err)
+	}
+}
+
+func TestProviderRateLimitPerProviderType(t *testing.T) {
+	svc := NewService(WithProviderRateLimit(ProviderOpenAI, ProviderRateLimit{RequestsPerMinute: 1}))
+	svc.RegisterProvider(&mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		completeResp: &CompletionResponse{Content: "ok"},
+	})
+	svc.RegisterProvider(&mockProvider{
+		providerType: ProviderOllama,
+		name:         "Ollama",
+		configured:   true,
+		completeResp: &CompletionResponse{Content: "ok"},
+	})
+
+	req := &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "Hi"}}}
+	svc.SetActiveProvider(ProviderOpenAI)
+	svc.Complete(context.Background(), req)
+	if _, err := svc.Complete(context.Background(), req); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected OpenAI budget to be exhausted, got %v", err)
+	}
+
+	// Providers without a limit are unaffected
+	svc.SetActiveProvider(ProviderOllama)
+	for i := 0; i < 5; i++ {
+		if _, err := svc.Complete(context.Background(), req); err != nil {
+			t.Fatalf("Complete() on unlimited provider error: %v", err)
+		}
+	}
+}
+
+func TestProviderRateLimitWait(t *testing.T) {
+	provider := &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		completeResp: &CompletionResponse{Content: "ok"},
+	}
+	// 1200/min refills a token every 50ms
+	svc := NewService(WithProviderRateLimit(ProviderOpenAI, ProviderRateLimit{
+		RequestsPerMinute: 1200,
+		Burst:             1,
+		Wait:              true,
+	}))
+	svc.RegisterProvider(provider)
+
+	req := &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "Hi"}}}
+	if _, err := svc.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := svc.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Expected Complete to block for the next token, returned after %v", elapsed)
+	}
+
+	// A context that ends before the next token fails without calling the provider
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	provider.lastCompleteReq = nil
+	if _, err := svc.Complete(ctx, req); err == nil {
+		t.Fatal("Expected error when the context ends before budget is available")
+	}
+	if provider.lastCompleteReq != nil {
+		t.Error("Expected the provider not to be called")
+	}
+}
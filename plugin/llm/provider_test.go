@@ -3,6 +3,7 @@ package llm
 import (
 	"context"
 	"testing"
+	"time"
 )
 
 // mockProvider is a mock implementation for testing.
@@ -20,6 +21,25 @@ type mockProvider struct {
 	suggestErr    error
 	summarizeResp *SummarizeResponse
 	summarizeErr  error
+	titleResp     *SuggestTitleResponse
+	titleErr      error
+
+	// lastCompleteReq records the most recent request passed to Complete.
+	lastCompleteReq *CompletionRequest
+
+	// completeCalls counts the calls to Complete.
+	completeCalls int
+
+	// lastEmbedReq records the most recent request passed to Embed; embedCalls counts them.
+	lastEmbedReq *EmbeddingRequest
+	embedCalls   int
+
+	// capabilities overrides the advertised capabilities (defaults to embeddings only).
+	capabilities *ProviderCapabilities
+
+	// healthErr is returned by CheckHealth; healthDelay delays it (respecting ctx).
+	healthErr   error
+	healthDelay time.Duration
 }
 
 func (m *mockProvider) GetType() ProviderType {
@@ -42,7 +62,18 @@ func (m *mockProvider) GetAvailableModels(ctx context.Context) ([]string, error)
 	return m.models, nil
 }
 
+func (m *mockProvider) GetModelInfo(ctx context.Context) ([]ModelInfo, error) {
+	infos := make([]ModelInfo, 0, len(m.models))
+	for _, model := range m.models {
+		info, _ := LookupModelInfo(model)
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
 func (m *mockProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	m.lastCompleteReq = req
+	m.completeCalls++
 	if m.completeErr != nil {
 		return nil, m.completeErr
 	}
@@ -50,6 +81,8 @@ func (m *mockProvider) Complete(ctx context.Context, req *CompletionRequest) (*C
 }
 
 func (m *mockProvider) Embed(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+	m.lastEmbedReq = req
+	m.embedCalls++
 	if m.embedErr != nil {
 		return nil, m.embedErr
 	}
@@ -70,6 +103,97 @@ func (m *mockProvider) Summarize(ctx context.Context, req *SummarizeRequest) (*S
 	return m.summarizeResp, nil
 }
 
+func (m *mockProvider) SuggestTitle(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error) {
+	if m.titleErr != nil {
+		return nil, m.titleErr
+	}
+	return m.titleResp, nil
+}
+
+func (m *mockProvider) Capabilities() ProviderCapabilities {
+	if m.capabilities != nil {
+		return *m.capabilities
+	}
+	return ProviderCapabilities{Embeddings: true}
+}
+
+func (m *mockProvider) CheckHealth(ctx context.Context) error {
+	if m.healthDelay > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.healthDelay):
+		}
+	}
+	return m.healthErr
+}
+
+func (m *mockProvider) ValidateConfig(ctx context.Context) error {
+	if !m.configured {
+		return ErrProviderNotConfigured
+	}
+	return nil
+}
+
+func TestProviderCapabilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		expected ProviderCapabilities
+	}{
+		{
+			name:     "openai",
+			provider: NewOpenAIProvider(&ProviderConfig{Type: ProviderOpenAI}),
+			expected: ProviderCapabilities{Embeddings: true, JSONMode: true, Vision: true},
+		},
+		{
+			name: "azure default api version",
+			provider: NewOpenAIProvider(&ProviderConfig{
+				Type:            ProviderOpenAI,
+				AzureDeployment: "my-gpt4o",
+			}),
+			expected: ProviderCapabilities{Embeddings: true, Vision: true},
+		},
+		{
+			name: "azure structured outputs api version",
+			provider: NewOpenAIProvider(&ProviderConfig{
+				Type:            ProviderOpenAI,
+				AzureDeployment: "my-gpt4o",
+				AzureAPIVersion: "2024-08-01-preview",
+			}),
+			expected: ProviderCapabilities{Embeddings: true, JSONMode: true, Vision: true},
+		},
+		{
+			name:     "anthropic",
+			provider: NewAnthropicProvider(&ProviderConfig{Type: ProviderAnthropic}),
+			expected: ProviderCapabilities{Streaming: true, Vision: true},
+		},
+		{
+			name:     "gemini",
+			provider: NewGeminiProvider(&ProviderConfig{Type: ProviderGemini}),
+			expected: ProviderCapabilities{JSONMode: true},
+		},
+		{
+			name:     "ollama",
+			provider: NewOllamaProvider(&ProviderConfig{Type: ProviderOllama}),
+			expected: ProviderCapabilities{Embeddings: true, Streaming: true, Vision: true},
+		},
+		{
+			name:     "cohere",
+			provider: NewCohereProvider(&ProviderConfig{Type: ProviderCohere}),
+			expected: ProviderCapabilities{Embeddings: true, JSONMode: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.provider.Capabilities(); got != tt.expected {
+				t.Errorf("Capabilities() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestProviderTypes(t *testing.T) {
 	tests := []struct {
 		providerType ProviderType
@@ -107,14 +231,17 @@ func TestRoleTypes(t *testing.T) {
 
 func TestDefaultConfig(t *testing.T) {
 	tests := []struct {
-		providerType    ProviderType
-		expectedModel   string
-		expectedTimeout int
+		providerType           ProviderType
+		expectedModel          string
+		expectedEmbeddingModel string
+		expectedTimeout        int
 	}{
-		{ProviderOpenAI, "gpt-4o-mini", 30},
-		{ProviderAnthropic, "claude-3-5-sonnet-20241022", 30},
-		{ProviderGemini, "gemini-1.5-flash", 30},
-		{ProviderOllama, "llama3.2", 30},
+		{ProviderOpenAI, "gpt-4o-mini", "text-embedding-3-small", 30},
+		{ProviderAnthropic, "claude-3-5-sonnet-20241022", "", 30},
+		{ProviderGemini, "gemini-1.5-flash", "text-embedding-004", 30},
+		{ProviderOllama, "llama3.2", "nomic-embed-text", 30},
+		{ProviderCohere, "command-r-08-2024", "embed-english-v3.0", 30},
+		{ProviderGroq, "llama-3.1-8b-instant", "", 30},
 	}
 
 	for _, tt := range tests {
@@ -128,12 +255,29 @@ func TestDefaultConfig(t *testing.T) {
 			t.Errorf("DefaultConfig(%v): expected model %s, got %s", tt.providerType, tt.expectedModel, config.DefaultModel)
 		}
 
+		if config.EmbeddingModel != tt.expectedEmbeddingModel {
+			t.Errorf("DefaultConfig(%v): expected embedding model %q, got %q", tt.providerType, tt.expectedEmbeddingModel, config.EmbeddingModel)
+		}
+
 		if config.Timeout != tt.expectedTimeout {
 			t.Errorf("DefaultConfig(%v): expected timeout %d, got %d", tt.providerType, tt.expectedTimeout, config.Timeout)
 		}
 	}
 }
 
+func TestDefaultConfig_ProvidersUseEmbeddingModel(t *testing.T) {
+	openAIConfig := DefaultConfig(ProviderOpenAI)
+	openAIConfig.EmbeddingModel = "text-embedding-3-large"
+	if got := NewOpenAIProvider(openAIConfig).ToProto().EmbeddingModel; got != "text-embedding-3-large" {
+		t.Errorf("OpenAI: expected embedding model %q, got %q", "text-embedding-3-large", got)
+	}
+
+	ollamaConfig := DefaultConfig(ProviderOllama)
+	if got := NewOllamaProvider(ollamaConfig).ToProto().EmbeddingModel; got != ollamaConfig.EmbeddingModel {
+		t.Errorf("Ollama: expected embedding model %q, got %q", ollamaConfig.EmbeddingModel, got)
+	}
+}
+
 func TestMessage(t *testing.T) {
 	msg := Message{
 		Role:    RoleUser,
@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultRawResponseBufferSize is the number of responses kept per provider
+// when ProviderConfig.RawResponseBufferSize is unset.
+const defaultRawResponseBufferSize = 50
+
+// RawResponse is a completion response captured for debugging.
+type RawResponse struct {
+	// Provider is the provider type that returned the response.
+	Provider ProviderType `json:"provider"`
+
+	// Model is the model reported by the provider.
+	Model string `json:"model"`
+
+	// Content is the completion text exactly as returned, before any parsing.
+	Content string `json:"content"`
+
+	// Usage is the token usage of the call, if reported.
+	Usage *TokenUsage `json:"usage,omitempty"`
+
+	// FinishReason is the normalized finish reason.
+	FinishReason string `json:"finish_reason,omitempty"`
+
+	// CapturedAt is when the response was received.
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+// rawResponseBuffer is a fixed-size ring of captured responses.
+type rawResponseBuffer struct {
+	mu      sync.Mutex
+	entries []RawResponse
+	next    int
+	full    bool
+}
+
+func (r *rawResponseBuffer) add(size int, resp RawResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.entries == nil {
+		r.entries = make([]RawResponse, size)
+	}
+	r.entries[r.next] = resp
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// last returns up to n responses, newest first.
+func (r *rawResponseBuffer) last(n int) []RawResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := r.next
+	if r.full {
+		count = len(r.entries)
+	}
+	if n > count {
+		n = count
+	}
+
+	result := make([]RawResponse, 0, n)
+	for i := 1; i <= n; i++ {
+		result = append(result, r.entries[(r.next-i+len(r.entries))%len(r.entries)])
+	}
+	return result
+}
+
+// captureResponse records resp if CaptureRawResponses is enabled and returns
+// it unchanged.
+func (b *BaseProvider) captureResponse(resp *CompletionResponse) *CompletionResponse {
+	if !b.Config.CaptureRawResponses {
+		return resp
+	}
+
+	size := b.Config.RawResponseBufferSize
+	if size <= 0 {
+		size = defaultRawResponseBufferSize
+	}
+	b.rawResponses.add(size, RawResponse{
+		Provider:     b.Config.Type,
+		Model:        resp.Model,
+		Content:      resp.Content,
+		Usage:        resp.Usage,
+		FinishReason: resp.FinishReason,
+		CapturedAt:   time.Now(),
+	})
+	return resp
+}
+
+// LastResponses returns up to n captured completion responses, newest first.
+// Streamed completions are not captured.
+func (b *BaseProvider) LastResponses(n int) []RawResponse {
+	return b.rawResponses.last(n)
+}
+
+// rawResponseSource is implemented by providers that capture raw responses.
+type rawResponseSource interface {
+	LastResponses(n int) []RawResponse
+}
+
+// LastResponses returns up to n captured completion responses across all
+// registered providers, newest first.
+func (s *service) LastResponses(n int) []RawResponse {
+	if n <= 0 {
+		return nil
+	}
+
+	s.mu.RLock()
+	var sources []rawResponseSource
+	for _, instances := range s.providers {
+		for _, instance := range instances {
+			if source, ok := instance.provider.(rawResponseSource); ok {
+				sources = append(sources, source)
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	var result []RawResponse
+	for _, source := range sources {
+		result = append(result, source.LastResponses(n)...)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CapturedAt.After(result[j].CapturedAt)
+	})
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
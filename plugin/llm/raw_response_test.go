@@ -0,0 +1,94 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRawResponseBuffer(t *testing.T) {
+	var buf rawResponseBuffer
+	if got := buf.last(5); len(got) != 0 {
+		t.Fatalf("Expected an empty buffer, got %d entries", len(got))
+	}
+
+	for i := 0; i < 5; i++ {
+		buf.add(3, RawResponse{Content: fmt.Sprintf("r%d", i)})
+	}
+
+	got := buf.last(10)
+	if len(got) != 3 {
+		t.Fatalf("Expected the buffer to cap at 3, got %d", len(got))
+	}
+	for i, want := range []string{"r4", "r3", "r2"} {
+		if got[i].Content != want {
+			t.Errorf("Entry %d: expected %s, got %s", i, want, got[i].Content)
+		}
+	}
+	if got := buf.last(1); len(got) != 1 || got[0].Content != "r4" {
+		t.Errorf("Expected only the newest entry, got %+v", got)
+	}
+}
+
+func TestServiceLastResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"llama3","message":{"role":"assistant","content":"tags: not json"},"done":true,"done_reason":"stop","prompt_eval_count":7,"eval_count":3}`))
+	}))
+	defer server.Close()
+
+	svc := NewService()
+	svc.RegisterProvider(NewOllamaProvider(&ProviderConfig{
+		Type:                  ProviderOllama,
+		OllamaHost:            server.URL,
+		CaptureRawResponses:   true,
+		RawResponseBufferSize: 2,
+	}))
+	svc.SetActiveProvider(ProviderOllama)
+
+	if got := svc.LastResponses(5); len(got) != 0 {
+		t.Fatalf("Expected no captured responses yet, got %d", len(got))
+	}
+
+	// The raw content is captured before tag parsing
+	svc.SuggestTags(context.Background(), &SuggestTagsRequest{Content: "memo", MaxTags: 3})
+	if got := svc.LastResponses(5); len(got) != 1 || got[0].Content != "tags: not json" {
+		t.Fatalf("Expected the unparsed tag response, got %+v", got)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := svc.Complete(context.Background(), &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+			t.Fatalf("Complete() error: %v", err)
+		}
+	}
+
+	got := svc.LastResponses(5)
+	if len(got) != 2 {
+		t.Fatalf("Expected the buffer to cap at 2, got %d", len(got))
+	}
+	r := got[0]
+	if r.Provider != ProviderOllama || r.Model != "llama3" || r.Content != "tags: not json" || r.FinishReason != FinishReasonStop {
+		t.Errorf("Unexpected captured response: %+v", r)
+	}
+	if r.Usage == nil || r.Usage.TotalTokens != 10 {
+		t.Errorf("Expected usage to be captured, got %+v", r.Usage)
+	}
+}
+
+func TestLastResponses_DisabledByDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(&ProviderConfig{Type: ProviderOpenAI, APIKey: "test-key", BaseURL: server.URL})
+	if _, err := provider.Complete(context.Background(), &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "hi"}}}); err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if got := provider.LastResponses(5); len(got) != 0 {
+		t.Errorf("Expected nothing captured without CaptureRawResponses, got %d", len(got))
+	}
+}
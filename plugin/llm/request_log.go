@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxLoggedBodyBytes caps how much of a request or response body is logged.
+const maxLoggedBodyBytes = 2048
+
+// secretHeaders are request headers whose values are API keys.
+var secretHeaders = map[string]bool{
+	"authorization":  true,
+	"x-api-key":      true,
+	"api-key":        true,
+	"x-goog-api-key": true,
+}
+
+// secretHeaderHints are substrings of header names, such as custom gateway or
+// tenant headers, whose values are treated as secrets.
+var secretHeaderHints = []string{"key", "token", "secret", "auth", "password", "cookie", "session"}
+
+// secretQueryParams are URL query parameters whose values are API keys.
+var secretQueryParams = []string{"key", "api_key", "api-key"}
+
+// logRequest writes a debug record for one DoRequest attempt to the provider's
+// Logger, if configured. API keys in headers and query parameters are masked.
+func (b *BaseProvider) logRequest(ctx context.Context, req *http.Request, reqBody []byte, statusCode int, respBody []byte, latency time.Duration, err error) {
+	logger := b.Config.Logger
+	if logger == nil || !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("provider", string(b.Config.Type)),
+		slog.String("method", req.Method),
+		slog.String("url", sanitizeURL(req.URL)),
+		slog.Any("headers", sanitizeHeaders(req.Header)),
+		slog.String("request_body", truncateForLog(reqBody)),
+		slog.Duration("latency", latency),
+	}
+	if statusCode != 0 {
+		attrs = append(attrs,
+			slog.Int("status", statusCode),
+			slog.String("response_body", truncateForLog(respBody)))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	logger.LogAttrs(ctx, slog.LevelDebug, "LLM provider request", attrs...)
+}
+
+// sanitizeHeaders flattens headers for logging, masking API keys.
+func sanitizeHeaders(header http.Header) map[string]string {
+	out := make(map[string]string, len(header))
+	for name, values := range header {
+		value := strings.Join(values, ", ")
+		if isSecretHeader(name) {
+			value = maskHeaderValue(value)
+		}
+		out[name] = value
+	}
+	return out
+}
+
+// isSecretHeader reports whether a header's value should be masked in logs.
+func isSecretHeader(name string) bool {
+	name = strings.ToLower(name)
+	if secretHeaders[name] {
+		return true
+	}
+	for _, hint := range secretHeaderHints {
+		if strings.Contains(name, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskHeaderValue masks a credential, keeping an auth scheme such as "Bearer".
+func maskHeaderValue(value string) string {
+	if scheme, token, ok := strings.Cut(value, " "); ok {
+		return scheme + " " + MaskAPIKey(token)
+	}
+	return MaskAPIKey(value)
+}
+
+// sanitizeURL returns u as a string with API key query parameters masked.
+func sanitizeURL(u *url.URL) string {
+	query := u.Query()
+	masked := false
+	for _, param := range secretQueryParams {
+		if value := query.Get(param); value != "" {
+			query.Set(param, MaskAPIKey(value))
+			masked = true
+		}
+	}
+	if !masked {
+		return u.String()
+	}
+
+	clone := *u
+	clone.RawQuery = query.Encode()
+	return clone.String()
+}
+
+// truncateForLog returns body as a string, truncated to maxLoggedBodyBytes.
+func truncateForLog(body []byte) string {
+	if len(body) <= maxLoggedBodyBytes {
+		return string(body)
+	}
+	return string(body[:maxLoggedBodyBytes]) + "...(truncated)"
+}
@@ -0,0 +1,24 @@
+package llm
+
+import (
+	"context"
+)
+
+// RerankResult is a document scored against a rerank query.
+type RerankResult struct {
+	// Index is the position of the document in the input slice.
+	Index int `json:"index"`
+
+	// Document is the document text.
+	Document string `json:"document"`
+
+	// Score is the relevance score (higher is more relevant).
+	Score float64 `json:"score"`
+}
+
+// Reranker is implemented by providers with a native reranking endpoint.
+type Reranker interface {
+	// Rerank orders docs by relevance to query, most relevant first.
+	// topN limits the number of results (0 returns all).
+	Rerank(ctx context.Context, query string, docs []string, topN int) ([]RerankResult, error)
+}
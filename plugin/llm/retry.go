@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// JitterMode selects how randomness is applied to retry delays.
+type JitterMode string
+
+const (
+	// JitterNone uses the computed backoff as-is.
+	JitterNone JitterMode = "none"
+
+	// JitterFull picks a delay uniformly in [0, backoff].
+	JitterFull JitterMode = "full"
+
+	// JitterEqual keeps half the backoff and randomizes the other half,
+	// picking a delay uniformly in [backoff/2, backoff].
+	JitterEqual JitterMode = "equal"
+)
+
+// Default retry policy values. They match the historical 1s, 2s, 4s backoff
+// with equal jitter added so concurrent clients don't retry in lockstep.
+const (
+	defaultRetryBaseDelay  = time.Second
+	defaultRetryMaxDelay   = 30 * time.Second
+	defaultRetryMultiplier = 2.0
+	defaultRetryJitter     = JitterEqual
+)
+
+// RetryPolicy controls how DoRequest retries failed requests.
+// Zero-valued fields fall back to the defaults.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Defaults to ProviderConfig.MaxRetries + 1.
+	MaxAttempts int
+
+	// BaseDelay is the backoff before the first retry (default 1s).
+	BaseDelay time.Duration
+
+	// MaxDelay caps the backoff between attempts (default 30s).
+	MaxDelay time.Duration
+
+	// Multiplier is the exponential growth factor between retries (default 2).
+	Multiplier float64
+
+	// Jitter selects how the backoff is randomized (default JitterEqual).
+	Jitter JitterMode
+
+	// ShouldRetry reports whether a failed attempt may be retried. statusCode is 0
+	// when the request failed before a response was received. Defaults to
+	// DefaultShouldRetry.
+	ShouldRetry func(statusCode int, err error) bool
+}
+
+// DefaultShouldRetry retries network errors, 429 and 5xx responses. Client
+// errors and context cancellation are never retried.
+func DefaultShouldRetry(statusCode int, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if statusCode == 0 {
+		return true
+	}
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// retryPolicy returns the provider's retry policy with defaults applied.
+func (b *BaseProvider) retryPolicy() RetryPolicy {
+	var policy RetryPolicy
+	if b.Config.RetryPolicy != nil {
+		policy = *b.Config.RetryPolicy
+	}
+
+	if policy.MaxAttempts <= 0 {
+		maxRetries := b.Config.MaxRetries
+		if maxRetries == 0 {
+			maxRetries = 3
+		}
+		policy.MaxAttempts = maxRetries + 1
+	}
+
+	return policy.withDefaults()
+}
+
+// withDefaults returns the policy with zero-valued fields other than MaxAttempts
+// replaced by the defaults.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = defaultRetryBaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = defaultRetryMaxDelay
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = defaultRetryMultiplier
+	}
+	if p.Jitter == "" {
+		p.Jitter = defaultRetryJitter
+	}
+	if p.ShouldRetry == nil {
+		p.ShouldRetry = DefaultShouldRetry
+	}
+
+	return p
+}
+
+// backoff returns the jittered delay before the given retry (1 for the first retry).
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := float64(p.BaseDelay) * math.Pow(p.Multiplier, float64(retry-1))
+	if delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	switch p.Jitter {
+	case JitterFull:
+		delay = rand.Float64() * delay
+	case JitterEqual:
+		delay = delay/2 + rand.Float64()*delay/2
+	}
+
+	return time.Duration(delay)
+}
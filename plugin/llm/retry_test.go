@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDefaults(t *testing.T) {
+	base := NewBaseProvider(&ProviderConfig{})
+	policy := base.retryPolicy()
+
+	if policy.MaxAttempts != 4 {
+		t.Errorf("Expected 4 attempts (3 retries), got %d", policy.MaxAttempts)
+	}
+	if policy.BaseDelay != time.Second || policy.Multiplier != 2 || policy.Jitter != JitterEqual {
+		t.Errorf("Unexpected default policy: %+v", policy)
+	}
+
+	base = NewBaseProvider(&ProviderConfig{MaxRetries: 1})
+	if got := base.retryPolicy().MaxAttempts; got != 2 {
+		t.Errorf("Expected MaxRetries 1 to give 2 attempts, got %d", got)
+	}
+}
+
+func TestRetryPolicyBackoffJitter(t *testing.T) {
+	tests := []struct {
+		jitter   JitterMode
+		retry    int
+		min, max time.Duration
+	}{
+		{JitterNone, 1, 100 * time.Millisecond, 100 * time.Millisecond},
+		{JitterNone, 3, 400 * time.Millisecond, 400 * time.Millisecond},
+		{JitterFull, 1, 0, 100 * time.Millisecond},
+		{JitterFull, 2, 0, 200 * time.Millisecond},
+		{JitterEqual, 1, 50 * time.Millisecond, 100 * time.Millisecond},
+		{JitterEqual, 3, 200 * time.Millisecond, 400 * time.Millisecond},
+		// Capped by MaxDelay
+		{JitterNone, 10, time.Second, time.Second},
+		{JitterEqual, 10, 500 * time.Millisecond, time.Second},
+	}
+
+	for _, tt := range tests {
+		policy := RetryPolicy{
+			BaseDelay:  100 * time.Millisecond,
+			MaxDelay:   time.Second,
+			Multiplier: 2,
+			Jitter:     tt.jitter,
+		}
+		for i := 0; i < 100; i++ {
+			delay := policy.backoff(tt.retry)
+			if delay < tt.min || delay > tt.max {
+				t.Fatalf("%s jitter, retry %d: delay %v outside [%v, %v]", tt.jitter, tt.retry, delay, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestDefaultShouldRetry(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		err        error
+		expected   bool
+	}{
+		{0, nil, true},
+		{0, context.Canceled, false},
+		{0, context.DeadlineExceeded, false},
+		{http.StatusBadRequest, nil, false},
+		{http.StatusUnauthorized, nil, false},
+		{http.StatusNotFound, nil, false},
+		{http.StatusTooManyRequests, nil, true},
+		{http.StatusInternalServerError, nil, true},
+		{http.StatusServiceUnavailable, nil, true},
+	}
+
+	for _, tt := range tests {
+		if got := DefaultShouldRetry(tt.statusCode, tt.err); got != tt.expected {
+			t.Errorf("DefaultShouldRetry(%d, %v) = %v, expected %v", tt.statusCode, tt.err, got, tt.expected)
+		}
+	}
+}
+
+func TestDoRequestNeverRetriesBadRequest(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"bad request"}}`))
+	}))
+	defer server.Close()
+
+	base := NewBaseProvider(&ProviderConfig{
+		MaxRetries:  3,
+		RetryPolicy: &RetryPolicy{BaseDelay: time.Millisecond},
+	})
+
+	if _, err := base.DoRequest(context.Background(), http.MethodPost, server.URL, nil, nil); err == nil {
+		t.Fatal("Expected error for 400 response")
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("Expected a single attempt for 400, got %d", got)
+	}
+}
+
+func TestDoRequestRetryPolicy(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	base := NewBaseProvider(&ProviderConfig{
+		RetryPolicy: &RetryPolicy{
+			MaxAttempts: 3,
+			BaseDelay:   time.Millisecond,
+			Jitter:      JitterFull,
+		},
+	})
+
+	if _, err := base.DoRequest(context.Background(), http.MethodGet, server.URL, nil, nil); err == nil {
+		t.Fatal("Expected error for 500 response")
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+
+	// A custom predicate can opt out of retrying server errors
+	attempts.Store(0)
+	base = NewBaseProvider(&ProviderConfig{
+		RetryPolicy: &RetryPolicy{
+			MaxAttempts: 3,
+			BaseDelay:   time.Millisecond,
+			ShouldRetry: func(statusCode int, err error) bool {
+				return statusCode == http.StatusServiceUnavailable
+			},
+		},
+	})
+
+	if _, err := base.DoRequest(context.Background(), http.MethodGet, server.URL, nil, nil); err == nil {
+		t.Fatal("Expected error for 500 response")
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("Expected the predicate to stop retries after 1 attempt, got %d", got)
+	}
+}
@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckSampling(t *testing.T) {
+	b := &BaseProvider{Config: &ProviderConfig{Type: ProviderAnthropic}}
+
+	tests := []struct {
+		name            string
+		temperature     float64
+		topP            float64
+		wantTemperature float64
+		wantTopP        float64
+	}{
+		{"in range", 0.7, 0.9, 0.7, 0.9},
+		{"temperature too high", 1.5, 0.9, 1.0, 0.9},
+		{"negative", -0.5, -1, 0, 0},
+		{"top_p too high", 0.5, 1.2, 0.5, 1},
+	}
+
+	for _, tt := range tests {
+		req := &CompletionRequest{Temperature: tt.temperature, TopP: tt.topP}
+		out, err := b.checkSampling(req, maxTemperatureAnthropic)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if out.Temperature != tt.wantTemperature || out.TopP != tt.wantTopP {
+			t.Errorf("%s: got temperature %v, top_p %v; want %v, %v",
+				tt.name, out.Temperature, out.TopP, tt.wantTemperature, tt.wantTopP)
+		}
+		if req.Temperature != tt.temperature || req.TopP != tt.topP {
+			t.Errorf("%s: caller's request must not be modified", tt.name)
+		}
+	}
+}
+
+func TestCheckSampling_Strict(t *testing.T) {
+	b := &BaseProvider{Config: &ProviderConfig{Type: ProviderOpenAI, StrictSampling: true}}
+
+	if _, err := b.checkSampling(&CompletionRequest{Temperature: 2.5}, maxTemperatureOpenAI); !errors.Is(err, ErrInvalidSampling) {
+		t.Errorf("Expected ErrInvalidSampling, got %v", err)
+	}
+	if _, err := b.checkSampling(&CompletionRequest{Temperature: 2, TopP: 1}, maxTemperatureOpenAI); err != nil {
+		t.Errorf("Expected boundary values to pass, got %v", err)
+	}
+}
+
+// TestProviderCompleteClampsSampling checks the temperature and top_p each
+// provider actually sends for the same out-of-range request.
+func TestProviderCompleteClampsSampling(t *testing.T) {
+	tests := []struct {
+		providerType    ProviderType
+		newProvider     func(*ProviderConfig) Provider
+		response        string
+		wantTemperature float64
+	}{
+		{
+			ProviderOpenAI,
+			func(c *ProviderConfig) Provider { return NewOpenAIProvider(c) },
+			`{"model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`,
+			2.0,
+		},
+		{
+			ProviderAnthropic,
+			func(c *ProviderConfig) Provider { return NewAnthropicProvider(c) },
+			`{"id":"msg_1","type":"message","role":"assistant","model":"claude-3-5-sonnet-20241022","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn"}`,
+			1.0,
+		},
+		{
+			ProviderOllama,
+			func(c *ProviderConfig) Provider { return NewOllamaProvider(c) },
+			`{"model":"llama3","message":{"role":"assistant","content":"ok"},"done":true}`,
+			2.0,
+		},
+		{
+			ProviderCohere,
+			func(c *ProviderConfig) Provider { return NewCohereProvider(c) },
+			`{"id":"1","message":{"role":"assistant","content":[{"type":"text","text":"ok"}]},"finish_reason":"COMPLETE"}`,
+			1.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.providerType), func(t *testing.T) {
+			var sent map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+					t.Errorf("Failed to decode request: %v", err)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			provider := tt.newProvider(&ProviderConfig{
+				Type:       tt.providerType,
+				APIKey:     "test-key",
+				BaseURL:    server.URL,
+				OllamaHost: server.URL,
+			})
+			_, err := provider.Complete(context.Background(), &CompletionRequest{
+				Messages:    []Message{{Role: RoleUser, Content: "hello"}},
+				Temperature: 3,
+				TopP:        1.5,
+			})
+			if err != nil {
+				t.Fatalf("Complete() error: %v", err)
+			}
+
+			temperature, topP := sentSampling(sent)
+			if temperature != tt.wantTemperature {
+				t.Errorf("Expected temperature %v, got %v", tt.wantTemperature, temperature)
+			}
+			if topP != 1 {
+				t.Errorf("Expected top_p 1, got %v", topP)
+			}
+		})
+	}
+}
+
+// sentSampling extracts temperature and top_p from a decoded request body,
+// looking inside Ollama's options object when present.
+func sentSampling(body map[string]any) (float64, float64) {
+	if options, ok := body["options"].(map[string]any); ok {
+		body = options
+	}
+	temperature, _ := body["temperature"].(float64)
+	topP, ok := body["top_p"].(float64)
+	if !ok {
+		topP, _ = body["p"].(float64)
+	}
+	return temperature, topP
+}
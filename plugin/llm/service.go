@@ -2,9 +2,12 @@ package llm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"sync"
+	"time"
 )
 
 // Service manages multiple LLM providers and provides a unified interface.
@@ -20,17 +23,57 @@ type Service interface {
 	SetActiveProvider(providerType ProviderType) error
 
 	// RegisterProvider adds a provider to the service.
+	// It replaces the default instance of the provider's type.
 	RegisterProvider(provider Provider) error
 
+	// DeregisterProvider removes all instances of a provider type. If it was the
+	// active provider, a configured fallback is selected (or none, if none remain).
+	// Removed providers that implement io.Closer are closed.
+	DeregisterProvider(providerType ProviderType) error
+
+	// Shutdown closes every registered provider that implements io.Closer,
+	// releasing idle HTTP connections. Providers stay registered and usable.
+	Shutdown() error
+
+	// RegisterAndValidate validates the provider's configuration and registers it
+	// only if validation succeeds. When live is true, the provider's health check
+	// (bounded by the health check timeout) must also pass, so an unreachable
+	// endpoint is reported at registration rather than on the first request.
+	RegisterAndValidate(ctx context.Context, provider Provider, live bool) error
+
+	// RegisterProviderInstance adds an additional instance of a provider type under
+	// a distinct ID (e.g., a second API key). Instances of the active type are
+	// balanced per call according to the service's BalancingStrategy. Weight is only
+	// used by BalancingWeighted; values below 1 are treated as 1.
+	RegisterProviderInstance(id string, provider Provider, weight int) error
+
+	// TestProvider verifies the registered provider's credentials with a
+	// minimal authenticated request. Errors wrap ErrInvalidAPIKey when the key
+	// is rejected and ErrProviderUnavailable when the provider is unreachable.
+	TestProvider(ctx context.Context, providerType ProviderType) error
+
+	// TestStoredKey is like TestProvider but tests the user's stored key for
+	// providerType, marking it used on success. The key is never returned.
+	TestStoredKey(ctx context.Context, keys KeyStorageService, userID int32, providerType ProviderType) error
+
 	// ListProviders returns all registered providers and their status.
 	ListProviders() []ProviderStatus
 
+	// ListProvidersWithHealth is like ListProviders but also probes every provider
+	// concurrently (each bounded by the health check timeout) and sets Reachable and
+	// LastError, distinguishing "key is set but server down" from "not configured".
+	ListProvidersWithHealth(ctx context.Context) []ProviderStatus
+
 	// IsConfigured checks if any provider is configured and ready.
 	IsConfigured(ctx context.Context) bool
 
 	// Complete performs a chat completion using the active provider.
 	Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error)
 
+	// CompleteStream streams a chat completion from the active provider.
+	// Returns ErrCapabilityNotSupported if the provider does not implement StreamingProvider.
+	CompleteStream(ctx context.Context, req *CompletionRequest) (<-chan CompletionChunk, error)
+
 	// Embed generates embeddings using the active provider.
 	Embed(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error)
 
@@ -39,8 +82,84 @@ type Service interface {
 
 	// Summarize generates a summary using the active provider.
 	Summarize(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error)
+
+	// SuggestTitle suggests a title using the active provider.
+	SuggestTitle(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error)
+
+	// AnalyzeMemo suggests tags, a title and a summary in one call using the active provider.
+	AnalyzeMemo(ctx context.Context, req *AnalyzeMemoRequest) (*AnalyzeMemoResponse, error)
+
+	// GetUsageStats returns the accumulated token usage (and estimated cost, if enabled).
+	GetUsageStats() UsageStats
+
+	// OperationMetrics returns call counts, errors and approximate latency
+	// percentiles per provider and operation.
+	OperationMetrics() []OpMetric
+
+	// LastResponses returns up to n completion responses captured by providers
+	// with CaptureRawResponses enabled, newest first.
+	LastResponses(n int) []RawResponse
+
+	// EmbedCacheStats returns the embedding cache counters (zero value if
+	// WithEmbeddingCache was not set).
+	EmbedCacheStats() EmbedCacheStats
+
+	// ActiveCapabilities returns the capabilities of the active provider (zero value if none).
+	ActiveCapabilities() ProviderCapabilities
+
+	// Rerank orders docs by relevance to query using the active provider.
+	// Returns ErrCapabilityNotSupported if the provider doesn't implement Reranker.
+	Rerank(ctx context.Context, query string, docs []string, topN int) ([]RerankResult, error)
+
+	// HealthCheckAll probes every registered provider concurrently and returns the
+	// result per type (nil for healthy). Each probe is bounded by the health check timeout.
+	HealthCheckAll(ctx context.Context) map[ProviderType]error
+
+	// Warmup issues one cheap request to the active provider to avoid a
+	// cold-start penalty on the first real request.
+	Warmup(ctx context.Context) error
+
+	// SetSystemPreamble sets a system message (e.g., a persona or guardrails) applied
+	// to every Complete request according to the service's PreambleMode.
+	// An empty preamble disables it.
+	SetSystemPreamble(preamble string)
+
+	// SetProviderChangeListener sets a function called whenever the active provider
+	// changes, with the previous and new provider types ("" for none) and one of the
+	// ProviderChange* reasons. The listener is called without service locks held.
+	// A nil listener disables notifications.
+	SetProviderChangeListener(listener ProviderChangeListener)
 }
 
+// ProviderChangeListener is notified when the active provider changes.
+type ProviderChangeListener func(old, new ProviderType, reason string)
+
+// Reasons passed to a ProviderChangeListener.
+const (
+	// ProviderChangeAutoSelect means the first configured provider was selected on registration.
+	ProviderChangeAutoSelect = "auto_select"
+
+	// ProviderChangeExplicit means SetActiveProvider was called.
+	ProviderChangeExplicit = "explicit"
+
+	// ProviderChangeFallback means the previous provider became unavailable
+	// (deregistered or not configured) and another was selected.
+	ProviderChangeFallback = "fallback"
+)
+
+// PreambleMode controls how the system preamble combines with a caller's own system message.
+type PreambleMode string
+
+const (
+	// PreambleCallerPrecedence adds the preamble only when the request has no
+	// system message; an explicit caller system message is left as-is.
+	PreambleCallerPrecedence PreambleMode = "caller_precedence"
+
+	// PreambleMerge prepends the preamble to the caller's first system message,
+	// or adds it as a system message if there is none.
+	PreambleMerge PreambleMode = "merge"
+)
+
 // ProviderStatus represents the status of a registered provider.
 type ProviderStatus struct {
 	// Type is the provider type.
@@ -57,20 +176,175 @@ type ProviderStatus struct {
 
 	// DefaultModel is the default model for this provider.
 	DefaultModel string `json:"default_model"`
+
+	// InstanceID identifies the provider instance (equal to Type for the default instance).
+	InstanceID string `json:"instance_id"`
+
+	// Reachable indicates the provider passed its health check.
+	// Only set by ListProvidersWithHealth.
+	Reachable bool `json:"reachable"`
+
+	// LastError is the health check error, if any. Only set by ListProvidersWithHealth.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// UsageStats aggregates token consumption across requests handled by the service.
+type UsageStats struct {
+	// Requests is the number of successful requests that reported usage.
+	Requests int64 `json:"requests"`
+
+	// PromptTokens is the total number of prompt tokens consumed.
+	PromptTokens int64 `json:"prompt_tokens"`
+
+	// CompletionTokens is the total number of completion tokens generated.
+	CompletionTokens int64 `json:"completion_tokens"`
+
+	// TotalTokens is the sum of prompt and completion tokens.
+	TotalTokens int64 `json:"total_tokens"`
+
+	// EstimatedCostUSD is the accumulated estimated cost (only when cost tracking is enabled).
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
 }
 
 // service implements the Service interface.
 type service struct {
 	mu             sync.RWMutex
-	providers      map[ProviderType]Provider
+	providers      map[ProviderType][]*providerInstance
 	activeProvider ProviderType
+	balancing      BalancingStrategy
+	rrCounters     map[ProviderType]uint64
+	fallbackOrder  []ProviderType
+
+	healthCheckTimeout time.Duration
+	defaultTimeout     time.Duration
+
+	preamble     string
+	preambleMode PreambleMode
+
+	changeListener ProviderChangeListener
+
+	// middleware wraps the core operations; ops is the resulting chain
+	middleware []Middleware
+	ops        Operations
+
+	// limiters is populated by options at construction and read-only afterwards
+	limiters map[ProviderType]*providerLimiter
+
+	usageMu      sync.Mutex
+	usage        UsageStats
+	costTracking bool
+
+	// embedCache is nil unless WithEmbeddingCache is set
+	embedCache *embeddingCache
+
+	// deprecationsLogged records the deprecated models already warned about
+	deprecationsLogged sync.Map
+
+	// targetDimension and dimensionAdapter fit embeddings to a fixed length
+	targetDimension  int
+	dimensionAdapter DimensionAdapter
+
+	opMetrics opMetrics
+}
+
+// ServiceOption configures a Service.
+type ServiceOption func(*service)
+
+// WithCostTracking enables accumulation of estimated cost alongside token usage.
+func WithCostTracking() ServiceOption {
+	return func(s *service) {
+		s.costTracking = true
+	}
+}
+
+// WithBalancingStrategy sets how calls are spread across instances of the active provider type.
+func WithBalancingStrategy(strategy BalancingStrategy) ServiceOption {
+	return func(s *service) {
+		s.balancing = strategy
+	}
+}
+
+// defaultHealthCheckTimeout bounds each provider probe in HealthCheckAll.
+const defaultHealthCheckTimeout = 5 * time.Second
+
+// WithHealthCheckTimeout sets the per-provider timeout used by HealthCheckAll.
+func WithHealthCheckTimeout(timeout time.Duration) ServiceOption {
+	return func(s *service) {
+		s.healthCheckTimeout = timeout
+	}
+}
+
+// WithDefaultTimeout bounds Complete, Embed, SuggestTags, Summarize and
+// SuggestTitle calls whose context has no deadline, so a hung provider can't
+// block a background worker forever. The budget covers the whole call,
+// including middleware retries. A caller's own deadline is never extended.
+// Zero (the default) disables it.
+func WithDefaultTimeout(timeout time.Duration) ServiceOption {
+	return func(s *service) {
+		s.defaultTimeout = timeout
+	}
+}
+
+// withDefaultDeadline applies the service default timeout to ctx if it has no deadline.
+func (s *service) withDefaultDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.defaultTimeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, s.defaultTimeout)
+}
+
+// WithTargetDimension sets the default EmbeddingRequest.TargetDimension, e.g.
+// to keep a semantic index consistent when the embedding provider changes.
+func WithTargetDimension(dims int) ServiceOption {
+	return func(s *service) {
+		s.targetDimension = dims
+	}
+}
+
+// WithDimensionAdapter replaces PadOrTruncate as the way embeddings are fitted
+// to the target dimension.
+func WithDimensionAdapter(adapter DimensionAdapter) ServiceOption {
+	return func(s *service) {
+		s.dimensionAdapter = adapter
+	}
+}
+
+// WithPreambleMode sets how the system preamble combines with caller system
+// messages. The default is PreambleCallerPrecedence.
+func WithPreambleMode(mode PreambleMode) ServiceOption {
+	return func(s *service) {
+		s.preambleMode = mode
+	}
 }
 
 // NewService creates a new LLM service.
-func NewService() Service {
-	return &service{
-		providers: make(map[ProviderType]Provider),
+func NewService(opts ...ServiceOption) Service {
+	s := &service{
+		providers:          make(map[ProviderType][]*providerInstance),
+		rrCounters:         make(map[ProviderType]uint64),
+		fallbackOrder:      defaultFallbackOrder,
+		healthCheckTimeout: defaultHealthCheckTimeout,
+		preambleMode:       PreambleCallerPrecedence,
+		limiters:           make(map[ProviderType]*providerLimiter),
+		dimensionAdapter:   PadOrTruncate,
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	s.ops = chainMiddleware(Operations{
+		Complete:     s.complete,
+		Embed:        s.embed,
+		SuggestTags:  s.suggestTags,
+		Summarize:    s.summarize,
+		SuggestTitle: s.suggestTitle,
+	}, s.middleware)
+
+	return s
 }
 
 // GetProvider returns the currently active provider.
@@ -82,7 +356,7 @@ func (s *service) GetProvider() Provider {
 		return nil
 	}
 
-	return s.providers[s.activeProvider]
+	return s.primaryLocked(s.activeProvider)
 }
 
 // GetProviderByType returns a specific provider by type.
@@ -90,8 +364,8 @@ func (s *service) GetProviderByType(providerType ProviderType) (Provider, error)
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	provider, ok := s.providers[providerType]
-	if !ok {
+	provider := s.primaryLocked(providerType)
+	if provider == nil {
 		return nil, fmt.Errorf("provider %s not registered", providerType)
 	}
 
@@ -100,38 +374,242 @@ func (s *service) GetProviderByType(providerType ProviderType) (Provider, error)
 
 // SetActiveProvider sets the active provider.
 func (s *service) SetActiveProvider(providerType ProviderType) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	return s.setActiveProvider(providerType, ProviderChangeExplicit)
+}
 
-	if _, ok := s.providers[providerType]; !ok {
+// setActiveProvider sets the active provider, reporting the change with the given reason.
+func (s *service) setActiveProvider(providerType ProviderType, reason string) error {
+	s.mu.Lock()
+	if len(s.providers[providerType]) == 0 {
+		s.mu.Unlock()
 		return fmt.Errorf("provider %s not registered", providerType)
 	}
 
+	old := s.activeProvider
 	s.activeProvider = providerType
-	slog.Info("LLM active provider changed", slog.String("provider", string(providerType)))
+	listener := s.changeListener
+	s.mu.Unlock()
+
+	slog.Info("LLM active provider changed",
+		slog.String("provider", string(providerType)),
+		slog.String("reason", reason))
+	notifyProviderChange(listener, old, providerType, reason)
 
 	return nil
 }
 
+// SetProviderChangeListener sets the function notified of active provider changes.
+func (s *service) SetProviderChangeListener(listener ProviderChangeListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.changeListener = listener
+}
+
+// notifyProviderChange calls listener if it is set and the provider actually changed.
+func notifyProviderChange(listener ProviderChangeListener, old, new ProviderType, reason string) {
+	if listener == nil || old == new {
+		return
+	}
+	listener(old, new, reason)
+}
+
 // RegisterProvider adds a provider to the service.
 func (s *service) RegisterProvider(provider Provider) error {
 	if provider == nil {
 		return fmt.Errorf("cannot register nil provider")
 	}
 
+	providerType := provider.GetType()
+	return s.registerInstance(string(providerType), provider, 1)
+}
+
+// RegisterAndValidate validates the provider and registers it on success.
+func (s *service) RegisterAndValidate(ctx context.Context, provider Provider, live bool) error {
+	if provider == nil {
+		return fmt.Errorf("cannot register nil provider")
+	}
+
+	providerType := provider.GetType()
+	if err := provider.ValidateConfig(ctx); err != nil {
+		return fmt.Errorf("invalid %s configuration: %w", providerType, err)
+	}
+
+	if live {
+		checkCtx, cancel := context.WithTimeout(ctx, s.healthCheckTimeout)
+		defer cancel()
+		if err := provider.CheckHealth(checkCtx); err != nil {
+			return fmt.Errorf("%s failed live validation: %w", providerType, err)
+		}
+	}
+
+	return s.RegisterProvider(provider)
+}
+
+// DeregisterProvider removes all instances of a provider type.
+func (s *service) DeregisterProvider(providerType ProviderType) error {
+	// Deferred first so the listener runs after the lock is released
+	var old, selected ProviderType
+	var listener ProviderChangeListener
+	defer func() {
+		notifyProviderChange(listener, old, selected, ProviderChangeFallback)
+	}()
+
+	// Also deferred so providers are closed outside the lock
+	var removed []*providerInstance
+	defer func() {
+		for _, instance := range removed {
+			closeProvider(instance.provider)
+		}
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed = s.providers[providerType]
+	if len(removed) == 0 {
+		return fmt.Errorf("provider %s not registered", providerType)
+	}
+
+	delete(s.providers, providerType)
+	delete(s.rrCounters, providerType)
+	slog.Info("LLM provider deregistered", slog.String("provider", string(providerType)))
+
+	if s.activeProvider == providerType {
+		old, listener = s.activeProvider, s.changeListener
+		s.activeProvider = s.fallbackLocked(context.Background())
+		selected = s.activeProvider
+		if s.activeProvider != "" {
+			slog.Info("Fallback provider selected", slog.String("provider", string(s.activeProvider)))
+		} else {
+			slog.Warn("No fallback provider available after deregistration")
+		}
+	}
+
+	return nil
+}
+
+// Shutdown closes every registered provider that implements io.Closer.
+func (s *service) Shutdown() error {
+	s.mu.RLock()
+	var providers []Provider
+	for _, instances := range s.providers {
+		for _, instance := range instances {
+			providers = append(providers, instance.provider)
+		}
+	}
+	s.mu.RUnlock()
+
+	var errs []error
+	for _, provider := range providers {
+		if err := closeProvider(provider); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.GetType(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeProvider closes provider if it implements io.Closer, logging any error.
+func closeProvider(provider Provider) error {
+	closer, ok := provider.(io.Closer)
+	if !ok {
+		return nil
+	}
+	if err := closer.Close(); err != nil {
+		slog.Warn("Failed to close LLM provider",
+			slog.String("provider", string(provider.GetType())),
+			slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// getFallbackOrder returns the priority order used to pick a fallback provider.
+func (s *service) getFallbackOrder() []ProviderType {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.fallbackOrder
+}
+
+// setFallbackOrder replaces the fallback priority order. The caller validates it.
+func (s *service) setFallbackOrder(order []ProviderType) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallbackOrder = order
+}
+
+// fallbackLocked returns the first configured provider type in the fallback order,
+// or "" if none is configured. Callers must hold s.mu.
+func (s *service) fallbackLocked(ctx context.Context) ProviderType {
+	for _, providerType := range s.fallbackOrder {
+		for _, instance := range s.providers[providerType] {
+			if instance.provider.IsConfigured(ctx) {
+				return providerType
+			}
+		}
+	}
+	return ""
+}
+
+// RegisterProviderInstance adds an additional instance of a provider type.
+func (s *service) RegisterProviderInstance(id string, provider Provider, weight int) error {
+	if provider == nil {
+		return fmt.Errorf("cannot register nil provider")
+	}
+	if id == "" {
+		return fmt.Errorf("provider instance id is required")
+	}
+
+	return s.registerInstance(id, provider, weight)
+}
+
+// registerInstance adds or replaces the instance with the given ID.
+func (s *service) registerInstance(id string, provider Provider, weight int) error {
+	if weight < 1 {
+		weight = 1
+	}
+
+	// Deferred first so the listener runs after the lock is released
+	var selected ProviderType
+	var listener ProviderChangeListener
+	defer func() {
+		notifyProviderChange(listener, "", selected, ProviderChangeAutoSelect)
+	}()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	providerType := provider.GetType()
-	s.providers[providerType] = provider
+	instance := &providerInstance{id: id, provider: provider, weight: weight}
+
+	instances := s.providers[providerType]
+	replaced := false
+	for i, existing := range instances {
+		if existing.id == id {
+			instances[i] = instance
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		if id == string(providerType) {
+			// The default instance is always the primary one
+			instances = append([]*providerInstance{instance}, instances...)
+		} else {
+			instances = append(instances, instance)
+		}
+	}
+	s.providers[providerType] = instances
 
 	slog.Info("LLM provider registered",
 		slog.String("provider", string(providerType)),
+		slog.String("instance", id),
 		slog.String("name", provider.GetName()))
 
 	// Auto-select first configured provider as active
 	if s.activeProvider == "" && provider.IsConfigured(context.Background()) {
 		s.activeProvider = providerType
+		selected, listener = providerType, s.changeListener
 		slog.Info("LLM auto-selected active provider", slog.String("provider", string(providerType)))
 	}
 
@@ -140,23 +618,119 @@ func (s *service) RegisterProvider(provider Provider) error {
 
 // ListProviders returns all registered providers and their status.
 func (s *service) ListProviders() []ProviderStatus {
+	statuses, _ := s.listProviders()
+	return statuses
+}
+
+// ListProvidersWithHealth returns all registered providers with their status and health.
+func (s *service) ListProvidersWithHealth(ctx context.Context) []ProviderStatus {
+	statuses, providers := s.listProviders()
+
+	s.mu.RLock()
+	timeout := s.healthCheckTimeout
+	s.mu.RUnlock()
+
+	errs := probeHealth(ctx, providers, timeout)
+	for i, err := range errs {
+		statuses[i].Reachable = err == nil
+		if err != nil {
+			statuses[i].LastError = err.Error()
+		}
+	}
+
+	return statuses
+}
+
+// listProviders returns the status of every registered instance along with the
+// instance's provider, in the same order.
+func (s *service) listProviders() ([]ProviderStatus, []Provider) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	ctx := context.Background()
 	statuses := make([]ProviderStatus, 0, len(s.providers))
+	providers := make([]Provider, 0, len(s.providers))
 
-	for providerType, provider := range s.providers {
-		statuses = append(statuses, ProviderStatus{
-			Type:         providerType,
-			Name:         provider.GetName(),
-			Configured:   provider.IsConfigured(ctx),
-			Active:       providerType == s.activeProvider,
-			DefaultModel: provider.GetDefaultModel(),
-		})
+	for providerType, instances := range s.providers {
+		for _, instance := range instances {
+			statuses = append(statuses, ProviderStatus{
+				Type:         providerType,
+				Name:         instance.provider.GetName(),
+				Configured:   instance.provider.IsConfigured(ctx),
+				Active:       providerType == s.activeProvider,
+				DefaultModel: instance.provider.GetDefaultModel(),
+				InstanceID:   instance.id,
+			})
+			providers = append(providers, instance.provider)
+		}
 	}
 
-	return statuses
+	return statuses, providers
+}
+
+// probeHealth runs CheckHealth on every provider concurrently, each bounded by
+// timeout, and returns the results in the same order.
+func probeHealth(ctx context.Context, providers []Provider, timeout time.Duration) []error {
+	errs := make([]error, len(providers))
+
+	var wg sync.WaitGroup
+	for i, provider := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			errs[i] = provider.CheckHealth(probeCtx)
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// Rerank orders docs by relevance to query using the active provider.
+func (s *service) Rerank(ctx context.Context, query string, docs []string, topN int) ([]RerankResult, error) {
+	provider := s.pickProvider(ctx)
+	if provider == nil {
+		return nil, ErrProviderNotConfigured
+	}
+
+	reranker, ok := provider.(Reranker)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s does not support reranking", ErrCapabilityNotSupported, provider.GetName())
+	}
+
+	return reranker.Rerank(ctx, query, docs, topN)
+}
+
+// HealthCheckAll probes every registered provider instance concurrently.
+// A type is healthy only if all of its instances are; failures of
+// non-default instances are prefixed with the instance ID.
+func (s *service) HealthCheckAll(ctx context.Context) map[ProviderType]error {
+	statuses, providers := s.listProviders()
+
+	s.mu.RLock()
+	timeout := s.healthCheckTimeout
+	s.mu.RUnlock()
+
+	errs := probeHealth(ctx, providers, timeout)
+
+	results := make(map[ProviderType][]error)
+	for i, status := range statuses {
+		err := errs[i]
+		if err != nil && status.InstanceID != string(status.Type) {
+			err = fmt.Errorf("instance %s: %w", status.InstanceID, err)
+		}
+		results[status.Type] = append(results[status.Type], err)
+	}
+
+	health := make(map[ProviderType]error, len(results))
+	for providerType, errs := range results {
+		// errors.Join discards nil entries and returns nil if all are nil
+		health[providerType] = errors.Join(errs...)
+	}
+
+	return health
 }
 
 // IsConfigured checks if any provider is configured and ready.
@@ -164,9 +738,11 @@ func (s *service) IsConfigured(ctx context.Context) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	for _, provider := range s.providers {
-		if provider.IsConfigured(ctx) {
-			return true
+	for _, instances := range s.providers {
+		for _, instance := range instances {
+			if instance.provider.IsConfigured(ctx) {
+				return true
+			}
 		}
 	}
 
@@ -175,35 +751,193 @@ func (s *service) IsConfigured(ctx context.Context) bool {
 
 // Complete performs a chat completion using the active provider.
 func (s *service) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
-	provider := s.GetProvider()
+	ctx, cancel := s.withDefaultDeadline(ctx)
+	defer cancel()
+	return s.ops.Complete(ctx, req)
+}
+
+// complete is the core Complete operation, wrapped by middleware.
+func (s *service) complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	provider := s.pickProvider(ctx)
 	if provider == nil {
 		return nil, ErrProviderNotConfigured
 	}
 
-	if !provider.IsConfigured(ctx) {
+	if err := s.acquire(ctx, provider); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := provider.Complete(ctx, s.applyPreamble(req))
+	s.observe(provider, OperationComplete, start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp != nil {
+		s.recordUsage(resp.Model, resp.Usage)
+
+		model := req.Model
+		if model == "" {
+			model = provider.GetDefaultModel()
+		}
+		if warning := s.warnDeprecatedModel(provider, model); warning != "" {
+			out := *resp
+			out.Warnings = append(append([]string(nil), resp.Warnings...), warning)
+			resp = &out
+		}
+	}
+	return resp, nil
+}
+
+// CompleteStream streams a chat completion using the active provider. Usage
+// reported on the terminal chunk is recorded; middleware is not applied.
+func (s *service) CompleteStream(ctx context.Context, req *CompletionRequest) (<-chan CompletionChunk, error) {
+	provider := s.pickProvider(ctx)
+	if provider == nil {
 		return nil, ErrProviderNotConfigured
 	}
 
-	return provider.Complete(ctx, req)
+	streamer, ok := provider.(StreamingProvider)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s does not support streaming", ErrCapabilityNotSupported, provider.GetName())
+	}
+
+	if err := s.acquire(ctx, provider); err != nil {
+		return nil, err
+	}
+
+	in, err := streamer.CompleteStream(ctx, s.applyPreamble(req))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan CompletionChunk)
+	go func() {
+		defer close(out)
+		for chunk := range in {
+			if chunk.Done {
+				s.recordUsage(chunk.Model, chunk.Usage)
+			}
+			if !sendChunk(ctx, out, chunk) {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// SetSystemPreamble sets the system preamble applied to every Complete request.
+func (s *service) SetSystemPreamble(preamble string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.preamble = preamble
+}
+
+// applyPreamble returns req with the system preamble applied. The caller's
+// request is never modified; a copy is returned when changes are needed.
+func (s *service) applyPreamble(req *CompletionRequest) *CompletionRequest {
+	s.mu.RLock()
+	preamble, mode := s.preamble, s.preambleMode
+	s.mu.RUnlock()
+
+	if preamble == "" || req == nil {
+		return req
+	}
+
+	systemIdx := -1
+	for i, m := range req.Messages {
+		if m.Role == RoleSystem {
+			systemIdx = i
+			break
+		}
+	}
+
+	out := *req
+	switch {
+	case systemIdx < 0:
+		out.Messages = append([]Message{{Role: RoleSystem, Content: preamble}}, req.Messages...)
+	case mode == PreambleMerge:
+		out.Messages = append([]Message(nil), req.Messages...)
+		out.Messages[systemIdx].Content = preamble + "\n\n" + out.Messages[systemIdx].Content
+	default:
+		return req
+	}
+	return &out
 }
 
 // Embed generates embeddings using the active provider.
 func (s *service) Embed(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
-	provider := s.GetProvider()
+	ctx, cancel := s.withDefaultDeadline(ctx)
+	defer cancel()
+	return s.ops.Embed(ctx, req)
+}
+
+// embed is the core Embed operation, wrapped by middleware.
+func (s *service) embed(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+	provider := s.pickProvider(ctx)
 	if provider == nil {
 		return nil, ErrProviderNotConfigured
 	}
 
-	if !provider.IsConfigured(ctx) {
-		return nil, ErrProviderNotConfigured
+	if !provider.Capabilities().Embeddings {
+		return nil, fmt.Errorf("%s embeddings: %w", provider.GetName(), ErrCapabilityNotSupported)
+	}
+
+	var resp *EmbeddingResponse
+	var err error
+	if s.embedCache != nil {
+		resp, err = s.embedCached(ctx, provider, req)
+	} else {
+		resp, err = s.embedUncached(ctx, provider, req)
+	}
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	model := req.Model
+	if model == "" {
+		model = resp.Model
 	}
+	s.warnDeprecatedModel(provider, model)
 
-	return provider.Embed(ctx, req)
+	target := req.TargetDimension
+	if target == 0 {
+		target = s.targetDimension
+	}
+	resp = adaptEmbeddings(resp, target, s.dimensionAdapter)
+	return finishEmbeddings(resp, req.Normalize), nil
+}
+
+// embedUncached sends req straight to the provider.
+func (s *service) embedUncached(ctx context.Context, provider Provider, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+	if err := s.acquire(ctx, provider); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := provider.Embed(ctx, req)
+	s.observe(provider, OperationEmbed, start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp != nil {
+		s.recordUsage(resp.Model, resp.Usage)
+	}
+	return resp, nil
 }
 
 // SuggestTags suggests tags using the active provider.
 func (s *service) SuggestTags(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
-	provider := s.GetProvider()
+	ctx, cancel := s.withDefaultDeadline(ctx)
+	defer cancel()
+	return s.ops.SuggestTags(ctx, req)
+}
+
+// suggestTags is the core SuggestTags operation, wrapped by middleware.
+func (s *service) suggestTags(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+	provider := s.primaryProvider(ctx)
 	if provider == nil {
 		return nil, ErrProviderNotConfigured
 	}
@@ -212,12 +946,33 @@ func (s *service) SuggestTags(ctx context.Context, req *SuggestTagsRequest) (*Su
 		return nil, ErrProviderNotConfigured
 	}
 
-	return provider.SuggestTags(ctx, req)
+	if err := s.acquire(ctx, provider); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := provider.SuggestTags(ctx, req)
+	s.observe(provider, OperationSuggestTags, start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp != nil {
+		s.recordUsage(resp.Model, resp.Usage)
+	}
+	return resp, nil
 }
 
 // Summarize generates a summary using the active provider.
 func (s *service) Summarize(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error) {
-	provider := s.GetProvider()
+	ctx, cancel := s.withDefaultDeadline(ctx)
+	defer cancel()
+	return s.ops.Summarize(ctx, req)
+}
+
+// summarize is the core Summarize operation, wrapped by middleware.
+func (s *service) summarize(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error) {
+	provider := s.primaryProvider(ctx)
 	if provider == nil {
 		return nil, ErrProviderNotConfigured
 	}
@@ -226,5 +981,93 @@ func (s *service) Summarize(ctx context.Context, req *SummarizeRequest) (*Summar
 		return nil, ErrProviderNotConfigured
 	}
 
-	return provider.Summarize(ctx, req)
+	if err := s.acquire(ctx, provider); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := provider.Summarize(ctx, req)
+	s.observe(provider, OperationSummarize, start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp != nil {
+		s.recordUsage(resp.Model, resp.Usage)
+	}
+	return resp, nil
+}
+
+// SuggestTitle suggests a title using the active provider.
+func (s *service) SuggestTitle(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error) {
+	ctx, cancel := s.withDefaultDeadline(ctx)
+	defer cancel()
+	return s.ops.SuggestTitle(ctx, req)
+}
+
+// suggestTitle is the core SuggestTitle operation, wrapped by middleware.
+func (s *service) suggestTitle(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error) {
+	provider := s.primaryProvider(ctx)
+	if provider == nil {
+		return nil, ErrProviderNotConfigured
+	}
+
+	if !provider.IsConfigured(ctx) {
+		return nil, ErrProviderNotConfigured
+	}
+
+	if err := s.acquire(ctx, provider); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := provider.SuggestTitle(ctx, req)
+	s.observe(provider, OperationSuggestTitle, start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp != nil {
+		s.recordUsage(resp.Model, resp.Usage)
+	}
+	return resp, nil
+}
+
+// ActiveCapabilities returns the capabilities of the active provider.
+func (s *service) ActiveCapabilities() ProviderCapabilities {
+	provider := s.GetProvider()
+	if provider == nil {
+		return ProviderCapabilities{}
+	}
+
+	return provider.Capabilities()
+}
+
+// GetUsageStats returns the accumulated token usage.
+func (s *service) GetUsageStats() UsageStats {
+	s.usageMu.Lock()
+	defer s.usageMu.Unlock()
+
+	return s.usage
+}
+
+// recordUsage accumulates token usage and, if enabled, estimated cost.
+func (s *service) recordUsage(model string, usage *TokenUsage) {
+	if usage == nil {
+		return
+	}
+
+	var cost float64
+	if s.costTracking {
+		cost = estimateCostUSD(model, usage)
+	}
+
+	s.usageMu.Lock()
+	defer s.usageMu.Unlock()
+
+	s.usage.Requests++
+	s.usage.PromptTokens += int64(usage.PromptTokens)
+	s.usage.CompletionTokens += int64(usage.CompletionTokens)
+	s.usage.TotalTokens += int64(usage.TotalTokens)
+	s.usage.EstimatedCostUSD += cost
 }
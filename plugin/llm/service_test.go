@@ -2,7 +2,16 @@ package llm
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestNewService(t *testing.T) {
@@ -70,6 +79,190 @@ func TestRegisterNilProvider(t *testing.T) {
 	}
 }
 
+func TestRegisterAndValidate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version":"0.5.0"}`))
+	}))
+	defer server.Close()
+
+	svc := NewService()
+	provider := NewOllamaProvider(&ProviderConfig{Type: ProviderOllama, OllamaHost: server.URL})
+
+	if err := svc.RegisterAndValidate(context.Background(), provider, true); err != nil {
+		t.Fatalf("RegisterAndValidate() error: %v", err)
+	}
+	if _, err := svc.GetProviderByType(ProviderOllama); err != nil {
+		t.Errorf("Expected provider to be registered: %v", err)
+	}
+}
+
+func TestRegisterAndValidateUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	host := server.URL
+	server.Close()
+
+	svc := NewService(WithHealthCheckTimeout(time.Second))
+	provider := NewOllamaProvider(&ProviderConfig{Type: ProviderOllama, OllamaHost: host})
+
+	// Static validation alone cannot detect an unreachable host
+	if err := provider.ValidateConfig(context.Background()); err != nil {
+		t.Fatalf("ValidateConfig() error: %v", err)
+	}
+
+	start := time.Now()
+	if err := svc.RegisterAndValidate(context.Background(), provider, true); err == nil {
+		t.Fatal("Expected live validation to fail for unreachable host")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected registration to fail fast, took %v", elapsed)
+	}
+	if len(svc.ListProviders()) != 0 {
+		t.Error("Expected provider not to be registered after failed validation")
+	}
+}
+
+func TestRegisterAndValidateMalformedBaseURL(t *testing.T) {
+	svc := NewService()
+	provider := NewOpenAIProvider(&ProviderConfig{
+		Type:    ProviderOpenAI,
+		APIKey:  "sk-test",
+		BaseURL: "api.openai.com/v1",
+	})
+
+	err := svc.RegisterAndValidate(context.Background(), provider, false)
+	if err == nil || !strings.Contains(err.Error(), "invalid base URL") {
+		t.Fatalf("Expected invalid base URL error, got %v", err)
+	}
+	if len(svc.ListProviders()) != 0 {
+		t.Error("Expected provider not to be registered after failed validation")
+	}
+}
+
+func TestRegisterAndValidateNotConfigured(t *testing.T) {
+	svc := NewService()
+
+	err := svc.RegisterAndValidate(context.Background(), &mockProvider{providerType: ProviderOpenAI, name: "OpenAI"}, false)
+	if !errors.Is(err, ErrProviderNotConfigured) {
+		t.Errorf("Expected ErrProviderNotConfigured, got %v", err)
+	}
+}
+
+func TestDeregisterActiveProviderSelectsFallback(t *testing.T) {
+	svc := NewService()
+
+	svc.RegisterProvider(&mockProvider{providerType: ProviderAnthropic, name: "Anthropic", configured: true})
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOpenAI, name: "OpenAI", configured: true})
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOllama, name: "Ollama", configured: false})
+
+	if svc.GetProvider().GetType() != ProviderAnthropic {
+		t.Fatal("Expected Anthropic to be auto-selected")
+	}
+
+	if err := svc.DeregisterProvider(ProviderAnthropic); err != nil {
+		t.Fatalf("DeregisterProvider() error: %v", err)
+	}
+
+	// Ollama ranks first in fallback order but is not configured
+	if provider := svc.GetProvider(); provider == nil || provider.GetType() != ProviderOpenAI {
+		t.Errorf("Expected OpenAI fallback to be active, got %v", provider)
+	}
+	if _, err := svc.GetProviderByType(ProviderAnthropic); err == nil {
+		t.Error("Expected Anthropic to be removed")
+	}
+}
+
+// closeRecordingTransport records CloseIdleConnections calls.
+type closeRecordingTransport struct {
+	http.RoundTripper
+	closed atomic.Int32
+}
+
+func (t *closeRecordingTransport) CloseIdleConnections() {
+	t.closed.Add(1)
+}
+
+func TestShutdownClosesProviderConnections(t *testing.T) {
+	openAITransport := &closeRecordingTransport{RoundTripper: http.DefaultTransport}
+	ollamaTransport := &closeRecordingTransport{RoundTripper: http.DefaultTransport}
+
+	svc := NewService()
+	svc.RegisterProvider(NewOpenAIProvider(&ProviderConfig{
+		Type:       ProviderOpenAI,
+		APIKey:     "test-key",
+		HTTPClient: &http.Client{Transport: openAITransport},
+	}))
+	svc.RegisterProvider(NewOllamaProvider(&ProviderConfig{
+		Type:       ProviderOllama,
+		HTTPClient: &http.Client{Transport: ollamaTransport},
+	}))
+	// Providers that don't implement io.Closer are skipped
+	svc.RegisterProvider(&mockProvider{providerType: ProviderAnthropic, name: "Anthropic", configured: true})
+
+	if err := svc.Shutdown(); err != nil {
+		t.Fatalf("Shutdown() error: %v", err)
+	}
+	if openAITransport.closed.Load() != 1 || ollamaTransport.closed.Load() != 1 {
+		t.Errorf("Expected each transport closed once, got %d and %d",
+			openAITransport.closed.Load(), ollamaTransport.closed.Load())
+	}
+
+	if err := svc.DeregisterProvider(ProviderOpenAI); err != nil {
+		t.Fatalf("DeregisterProvider() error: %v", err)
+	}
+	if got := openAITransport.closed.Load(); got != 2 {
+		t.Errorf("Expected DeregisterProvider to close the provider, got %d closes", got)
+	}
+	if got := ollamaTransport.closed.Load(); got != 1 {
+		t.Errorf("Expected other providers to stay open, got %d closes", got)
+	}
+}
+
+func TestDeregisterLastProviderClearsActive(t *testing.T) {
+	svc := NewService()
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOpenAI, name: "OpenAI", configured: true})
+
+	if err := svc.DeregisterProvider(ProviderOpenAI); err != nil {
+		t.Fatalf("DeregisterProvider() error: %v", err)
+	}
+
+	if svc.GetProvider() != nil {
+		t.Error("Expected no active provider")
+	}
+	if _, err := svc.Complete(context.Background(), &CompletionRequest{}); err != ErrProviderNotConfigured {
+		t.Errorf("Expected ErrProviderNotConfigured, got %v", err)
+	}
+	if err := svc.DeregisterProvider(ProviderOpenAI); err == nil {
+		t.Error("Expected error when deregistering an unregistered provider")
+	}
+}
+
+func TestDeregisterInactiveProviderKeepsActive(t *testing.T) {
+	svc := NewService()
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOpenAI, name: "OpenAI", configured: true})
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOllama, name: "Ollama", configured: true})
+
+	if err := svc.DeregisterProvider(ProviderOllama); err != nil {
+		t.Fatalf("DeregisterProvider() error: %v", err)
+	}
+
+	if svc.GetProvider().GetType() != ProviderOpenAI {
+		t.Error("Expected OpenAI to remain active")
+	}
+}
+
+func TestRegisterProviderReplacesSameType(t *testing.T) {
+	svc := NewService()
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOpenAI, name: "Old", configured: true})
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOpenAI, name: "New", configured: true})
+
+	if len(svc.ListProviders()) != 1 {
+		t.Fatalf("Expected 1 provider after re-registering, got %d", len(svc.ListProviders()))
+	}
+	if svc.GetProvider().GetName() != "New" {
+		t.Errorf("Expected replaced provider, got %s", svc.GetProvider().GetName())
+	}
+}
+
 func TestSetActiveProvider(t *testing.T) {
 	svc := NewService()
 
@@ -277,6 +470,44 @@ func TestServiceEmbed(t *testing.T) {
 	}
 }
 
+func TestServiceEmbedUnsupported(t *testing.T) {
+	svc := NewService()
+
+	provider := &mockProvider{
+		providerType: ProviderAnthropic,
+		name:         "Anthropic",
+		configured:   true,
+		capabilities: &ProviderCapabilities{},
+		embedResp:    &EmbeddingResponse{Embeddings: [][]float32{{0.1}}},
+	}
+	svc.RegisterProvider(provider)
+
+	_, err := svc.Embed(context.Background(), &EmbeddingRequest{Input: []string{"Hello"}})
+	if !errors.Is(err, ErrCapabilityNotSupported) {
+		t.Errorf("Expected ErrCapabilityNotSupported, got %v", err)
+	}
+}
+
+func TestServiceActiveCapabilities(t *testing.T) {
+	svc := NewService()
+
+	if caps := svc.ActiveCapabilities(); caps != (ProviderCapabilities{}) {
+		t.Errorf("Expected zero capabilities without a provider, got %+v", caps)
+	}
+
+	svc.RegisterProvider(&mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		capabilities: &ProviderCapabilities{Embeddings: true, JSONMode: true},
+	})
+
+	caps := svc.ActiveCapabilities()
+	if !caps.Embeddings || !caps.JSONMode {
+		t.Errorf("Expected embeddings and JSON mode, got %+v", caps)
+	}
+}
+
 func TestServiceSuggestTags(t *testing.T) {
 	svc := NewService()
 
@@ -336,3 +567,519 @@ func TestServiceSummarize(t *testing.T) {
 		t.Errorf("Expected summary '%s', got '%s'", expectedResp.Summary, resp.Summary)
 	}
 }
+
+func TestServiceHealthCheckAll(t *testing.T) {
+	svc := NewService(WithHealthCheckTimeout(50 * time.Millisecond))
+
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOpenAI, name: "OpenAI", configured: true})
+	svc.RegisterProvider(&mockProvider{providerType: ProviderAnthropic, name: "Anthropic", configured: true, healthErr: ErrInvalidAPIKey})
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOllama, name: "Ollama", configured: true, healthDelay: time.Second})
+
+	start := time.Now()
+	health := svc.HealthCheckAll(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected probes to be bounded by the timeout, took %v", elapsed)
+	}
+
+	if len(health) != 3 {
+		t.Fatalf("Expected 3 results, got %v", health)
+	}
+	if err := health[ProviderOpenAI]; err != nil {
+		t.Errorf("Expected OpenAI to be healthy, got %v", err)
+	}
+	if err := health[ProviderAnthropic]; !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected Anthropic ErrInvalidAPIKey, got %v", err)
+	}
+	if err := health[ProviderOllama]; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected Ollama to time out, got %v", err)
+	}
+}
+
+func TestServiceHealthCheckAll_Instances(t *testing.T) {
+	svc := NewService()
+
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOpenAI, name: "OpenAI", configured: true})
+	svc.RegisterProviderInstance("openai-backup", &mockProvider{providerType: ProviderOpenAI, name: "OpenAI", configured: true, healthErr: ErrProviderUnavailable}, 1)
+
+	err := svc.HealthCheckAll(context.Background())[ProviderOpenAI]
+	if !errors.Is(err, ErrProviderUnavailable) {
+		t.Fatalf("Expected a failing instance to fail the type, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "openai-backup") {
+		t.Errorf("Expected error to name the failing instance, got %v", err)
+	}
+}
+
+func TestServiceListProvidersWithHealth(t *testing.T) {
+	svc := NewService(WithHealthCheckTimeout(50 * time.Millisecond))
+
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOpenAI, name: "OpenAI", configured: true})
+	svc.RegisterProvider(&mockProvider{providerType: ProviderAnthropic, name: "Anthropic", configured: true, healthErr: ErrProviderUnavailable})
+
+	statuses := svc.ListProvidersWithHealth(context.Background())
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 statuses, got %d", len(statuses))
+	}
+
+	for _, status := range statuses {
+		switch status.Type {
+		case ProviderOpenAI:
+			if !status.Reachable || status.LastError != "" {
+				t.Errorf("Expected OpenAI to be reachable, got %+v", status)
+			}
+		case ProviderAnthropic:
+			if status.Reachable {
+				t.Errorf("Expected Anthropic to be unreachable")
+			}
+			if !status.Configured {
+				t.Errorf("Expected Anthropic to still report configured")
+			}
+			if status.LastError != ErrProviderUnavailable.Error() {
+				t.Errorf("Expected LastError %q, got %q", ErrProviderUnavailable.Error(), status.LastError)
+			}
+		default:
+			t.Errorf("Unexpected provider %s", status.Type)
+		}
+	}
+
+	// The cheap listing does not probe
+	for _, status := range svc.ListProviders() {
+		if status.Reachable || status.LastError != "" {
+			t.Errorf("Expected ListProviders to skip health probes, got %+v", status)
+		}
+	}
+}
+
+func TestServiceSystemPreamble(t *testing.T) {
+	provider := &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		completeResp: &CompletionResponse{Content: "ok"},
+	}
+	svc := NewService()
+	svc.RegisterProvider(provider)
+	svc.SetSystemPreamble("You are the Memos assistant.")
+
+	req := &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "Hi"}}}
+	if _, err := svc.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+
+	sent := provider.lastCompleteReq.Messages
+	if len(sent) != 2 || sent[0].Role != RoleSystem || sent[0].Content != "You are the Memos assistant." {
+		t.Errorf("Expected preamble as leading system message, got %+v", sent)
+	}
+	if len(req.Messages) != 1 {
+		t.Errorf("Expected caller's request to be unmodified, got %+v", req.Messages)
+	}
+
+	// An empty preamble disables it
+	svc.SetSystemPreamble("")
+	svc.Complete(context.Background(), req)
+	if len(provider.lastCompleteReq.Messages) != 1 {
+		t.Errorf("Expected no preamble after clearing it, got %+v", provider.lastCompleteReq.Messages)
+	}
+}
+
+func TestServiceSystemPreambleModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     PreambleMode
+		expected string
+	}{
+		{"caller precedence", PreambleCallerPrecedence, "Be brief."},
+		{"merge", PreambleMerge, "Be polite.\n\nBe brief."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &mockProvider{
+				providerType: ProviderOpenAI,
+				name:         "OpenAI",
+				configured:   true,
+				completeResp: &CompletionResponse{Content: "ok"},
+			}
+			svc := NewService(WithPreambleMode(tt.mode))
+			svc.RegisterProvider(provider)
+			svc.SetSystemPreamble("Be polite.")
+
+			req := &CompletionRequest{Messages: []Message{
+				{Role: RoleSystem, Content: "Be brief."},
+				{Role: RoleUser, Content: "Hi"},
+			}}
+			if _, err := svc.Complete(context.Background(), req); err != nil {
+				t.Fatalf("Complete() error: %v", err)
+			}
+
+			sent := provider.lastCompleteReq.Messages
+			if len(sent) != 2 || sent[0].Role != RoleSystem || sent[0].Content != tt.expected {
+				t.Errorf("Expected system message %q, got %+v", tt.expected, sent)
+			}
+			if req.Messages[0].Content != "Be brief." {
+				t.Errorf("Expected caller's system message to be unmodified, got %q", req.Messages[0].Content)
+			}
+		})
+	}
+}
+
+type providerChange struct {
+	old, new ProviderType
+	reason   string
+}
+
+func recordProviderChanges(svc Service) *[]providerChange {
+	var changes []providerChange
+	svc.SetProviderChangeListener(func(old, new ProviderType, reason string) {
+		changes = append(changes, providerChange{old, new, reason})
+	})
+	return &changes
+}
+
+func TestProviderChangeListener_AutoSelectAndExplicit(t *testing.T) {
+	svc := NewService()
+	changes := recordProviderChanges(svc)
+
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOpenAI, name: "OpenAI", configured: false})
+	if len(*changes) != 0 {
+		t.Fatalf("Expected no change for an unconfigured provider, got %+v", *changes)
+	}
+
+	svc.RegisterProvider(&mockProvider{providerType: ProviderAnthropic, name: "Anthropic", configured: true})
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOllama, name: "Ollama", configured: true})
+	svc.SetActiveProvider(ProviderOllama)
+	// Re-selecting the active provider is not a change
+	svc.SetActiveProvider(ProviderOllama)
+
+	expected := []providerChange{
+		{"", ProviderAnthropic, ProviderChangeAutoSelect},
+		{ProviderAnthropic, ProviderOllama, ProviderChangeExplicit},
+	}
+	if fmt.Sprint(*changes) != fmt.Sprint(expected) {
+		t.Errorf("Expected changes %+v, got %+v", expected, *changes)
+	}
+}
+
+func TestProviderChangeListener_Fallback(t *testing.T) {
+	svc := NewService()
+	svc.RegisterProvider(&mockProvider{providerType: ProviderAnthropic, name: "Anthropic", configured: true})
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOpenAI, name: "OpenAI", configured: true})
+	changes := recordProviderChanges(svc)
+
+	svc.DeregisterProvider(ProviderAnthropic)
+	svc.DeregisterProvider(ProviderOpenAI)
+
+	expected := []providerChange{
+		{ProviderAnthropic, ProviderOpenAI, ProviderChangeFallback},
+		{ProviderOpenAI, "", ProviderChangeFallback},
+	}
+	if fmt.Sprint(*changes) != fmt.Sprint(expected) {
+		t.Errorf("Expected changes %+v, got %+v", expected, *changes)
+	}
+}
+
+func TestProviderChangeListener_ConfigManagerFallback(t *testing.T) {
+	svc := NewService()
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOpenAI, name: "OpenAI", configured: false})
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOllama, name: "Ollama", configured: true})
+	changes := recordProviderChanges(svc)
+
+	// OpenAI is registered but unconfigured, so the manager falls back to Ollama
+	manager := NewConfigManager(svc)
+	if err := manager.SetActiveProviderWithFallback(context.Background(), ProviderOpenAI); err != nil {
+		t.Fatalf("SetActiveProviderWithFallback() error: %v", err)
+	}
+
+	expected := []providerChange{
+		{ProviderOllama, ProviderOpenAI, ProviderChangeExplicit},
+		{ProviderOpenAI, ProviderOllama, ProviderChangeFallback},
+	}
+	if fmt.Sprint(*changes) != fmt.Sprint(expected) {
+		t.Errorf("Expected changes %+v, got %+v", expected, *changes)
+	}
+}
+
+func TestProviderChangeListener_CanCallService(t *testing.T) {
+	svc := NewService()
+	var active Provider
+	svc.SetProviderChangeListener(func(old, new ProviderType, reason string) {
+		// Must not deadlock: the listener runs without service locks held
+		active = svc.GetProvider()
+	})
+
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOpenAI, name: "OpenAI", configured: true})
+	if active == nil || active.GetType() != ProviderOpenAI {
+		t.Errorf("Expected listener to observe the new active provider, got %v", active)
+	}
+}
+
+// streamingMockProvider adds CompleteStream to mockProvider.
+type streamingMockProvider struct {
+	*mockProvider
+	chunks []CompletionChunk
+}
+
+func (m *streamingMockProvider) CompleteStream(ctx context.Context, req *CompletionRequest) (<-chan CompletionChunk, error) {
+	m.lastCompleteReq = req
+	ch := make(chan CompletionChunk, len(m.chunks))
+	for _, chunk := range m.chunks {
+		ch <- chunk
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestServiceCompleteStream(t *testing.T) {
+	provider := &streamingMockProvider{
+		mockProvider: &mockProvider{providerType: ProviderOpenAI, name: "OpenAI", configured: true},
+		chunks: []CompletionChunk{
+			{Content: "Hello"},
+			{Content: " world"},
+			{Done: true, Model: "gpt-4o-mini", Usage: &TokenUsage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}},
+		},
+	}
+	svc := NewService()
+	svc.RegisterProvider(provider)
+	svc.SetSystemPreamble("Be brief.")
+
+	chunks, err := svc.CompleteStream(context.Background(), &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "Hi"}}})
+	if err != nil {
+		t.Fatalf("CompleteStream() error: %v", err)
+	}
+
+	var content strings.Builder
+	for chunk := range chunks {
+		content.WriteString(chunk.Content)
+	}
+	if content.String() != "Hello world" {
+		t.Errorf("Expected streamed content 'Hello world', got %q", content.String())
+	}
+	if msgs := provider.lastCompleteReq.Messages; len(msgs) != 2 || msgs[0].Content != "Be brief." {
+		t.Errorf("Expected the system preamble to be applied, got %+v", msgs)
+	}
+	if stats := svc.GetUsageStats(); stats.TotalTokens != 5 {
+		t.Errorf("Expected usage from the terminal chunk to be recorded, got %+v", stats)
+	}
+}
+
+func TestServiceCompleteStream_NotSupported(t *testing.T) {
+	svc := NewService()
+	svc.RegisterProvider(&mockProvider{providerType: ProviderOpenAI, name: "OpenAI", configured: true})
+
+	_, err := svc.CompleteStream(context.Background(), &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "Hi"}}})
+	if !errors.Is(err, ErrCapabilityNotSupported) {
+		t.Errorf("Expected ErrCapabilityNotSupported, got %v", err)
+	}
+}
+
+func TestServiceSuggestTitle(t *testing.T) {
+	svc := NewService()
+	provider := &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		titleResp:    &SuggestTitleResponse{Title: "Trip Plans", Model: "gpt-4o-mini", Usage: &TokenUsage{TotalTokens: 12}},
+	}
+	svc.RegisterProvider(provider)
+
+	resp, err := svc.SuggestTitle(context.Background(), &SuggestTitleRequest{Content: "Packing list for the trip"})
+	if err != nil {
+		t.Fatalf("SuggestTitle() error: %v", err)
+	}
+	if resp.Title != "Trip Plans" {
+		t.Errorf("Expected title Trip Plans, got %q", resp.Title)
+	}
+	if got := svc.GetUsageStats().TotalTokens; got != 12 {
+		t.Errorf("Expected 12 tokens recorded, got %d", got)
+	}
+}
+
+func TestServiceEmbedNormalize(t *testing.T) {
+	svc := NewService()
+	raw := [][]float32{{3, 4}, {0, 5}, {0, 0}}
+	provider := &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		embedResp:    &EmbeddingResponse{Embeddings: raw},
+	}
+	svc.RegisterProvider(provider)
+
+	resp, err := svc.Embed(context.Background(), &EmbeddingRequest{
+		Input:     []string{"a", "b", "c"},
+		Normalize: true,
+	})
+	if err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+
+	for i, vec := range resp.Embeddings[:2] {
+		var sum float64
+		for _, v := range vec {
+			sum += float64(v) * float64(v)
+		}
+		if math.Abs(math.Sqrt(sum)-1) > 1e-6 {
+			t.Errorf("Vector %d has length %f, want 1", i, math.Sqrt(sum))
+		}
+	}
+	if resp.Embeddings[2][0] != 0 || resp.Embeddings[2][1] != 0 {
+		t.Errorf("Expected the zero vector unchanged, got %v", resp.Embeddings[2])
+	}
+	if raw[0][0] != 3 {
+		t.Error("The provider's vectors must not be modified")
+	}
+	if resp.Dimension != 2 {
+		t.Errorf("Expected Dimension 2, got %d", resp.Dimension)
+	}
+}
+
+func TestServiceEmbedDimension(t *testing.T) {
+	svc := NewService()
+	provider := &mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		embedResp:    &EmbeddingResponse{Embeddings: [][]float32{{1, 2, 3, 4}}},
+	}
+	svc.RegisterProvider(provider)
+
+	resp, err := svc.Embed(context.Background(), &EmbeddingRequest{Input: []string{"a"}})
+	if err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+	if resp.Dimension != 4 {
+		t.Errorf("Expected Dimension 4, got %d", resp.Dimension)
+	}
+	if resp.Embeddings[0][0] != 1 {
+		t.Errorf("Expected vectors unchanged without Normalize, got %v", resp.Embeddings[0])
+	}
+}
+
+func TestServiceEmbedTargetDimension(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []ServiceOption
+		target   int
+		raw      []float32
+		expected []float32
+	}{
+		{"pad", nil, 4, []float32{1, 2}, []float32{1, 2, 0, 0}},
+		{"truncate", nil, 2, []float32{1, 2, 3, 4}, []float32{1, 2}},
+		{"already matching", nil, 3, []float32{1, 2, 3}, []float32{1, 2, 3}},
+		{"service default", []ServiceOption{WithTargetDimension(3)}, 0, []float32{1, 2, 3, 4}, []float32{1, 2, 3}},
+		{"request overrides default", []ServiceOption{WithTargetDimension(3)}, 1, []float32{1, 2}, []float32{1}},
+		{"custom adapter", []ServiceOption{WithDimensionAdapter(func(vec []float32, target int) []float32 {
+			return make([]float32, target)
+		})}, 2, []float32{1, 2, 3}, []float32{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewService(tt.opts...)
+			raw := append([]float32(nil), tt.raw...)
+			svc.RegisterProvider(&mockProvider{
+				providerType: ProviderOpenAI,
+				name:         "OpenAI",
+				configured:   true,
+				embedResp:    &EmbeddingResponse{Embeddings: [][]float32{raw}},
+			})
+
+			resp, err := svc.Embed(context.Background(), &EmbeddingRequest{
+				Input:           []string{"a"},
+				TargetDimension: tt.target,
+			})
+			if err != nil {
+				t.Fatalf("Embed() error: %v", err)
+			}
+			if !reflect.DeepEqual(resp.Embeddings[0], tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, resp.Embeddings[0])
+			}
+			if resp.Dimension != len(tt.expected) {
+				t.Errorf("Expected Dimension %d, got %d", len(tt.expected), resp.Dimension)
+			}
+			if !reflect.DeepEqual(raw, tt.raw) {
+				t.Error("The provider's vectors must not be modified")
+			}
+		})
+	}
+}
+
+func TestServiceDefaultTimeout(t *testing.T) {
+	var deadline time.Time
+	var hasDeadline bool
+	capture := func(next Operations) Operations {
+		wrapped := next
+		wrapped.Complete = func(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+			deadline, hasDeadline = ctx.Deadline()
+			return next.Complete(ctx, req)
+		}
+		return wrapped
+	}
+
+	svc := NewService(WithDefaultTimeout(time.Minute), WithMiddleware(capture))
+	svc.RegisterProvider(&mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		completeResp: &CompletionResponse{Content: "ok"},
+	})
+	req := &CompletionRequest{Messages: []Message{{Role: RoleUser, Content: "Hi"}}}
+
+	// No caller deadline: the default applies
+	start := time.Now()
+	if _, err := svc.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if !hasDeadline || deadline.Before(start.Add(time.Minute)) || deadline.After(time.Now().Add(time.Minute)) {
+		t.Errorf("Expected a 1m default deadline, got %v (set=%v)", deadline, hasDeadline)
+	}
+
+	// A longer caller deadline is kept, not shortened or extended
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	want, _ := ctx.Deadline()
+	if _, err := svc.Complete(ctx, req); err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if !deadline.Equal(want) {
+		t.Errorf("Expected the caller deadline %v, got %v", want, deadline)
+	}
+
+	// A shorter caller deadline is kept too
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	want, _ = ctx.Deadline()
+	if _, err := svc.Complete(ctx, req); err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if !deadline.Equal(want) {
+		t.Errorf("Expected the caller deadline %v, got %v", want, deadline)
+	}
+}
+
+func TestServiceNoDefaultTimeout(t *testing.T) {
+	var hasDeadline bool
+	capture := func(next Operations) Operations {
+		wrapped := next
+		wrapped.Embed = func(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+			_, hasDeadline = ctx.Deadline()
+			return next.Embed(ctx, req)
+		}
+		return wrapped
+	}
+
+	svc := NewService(WithMiddleware(capture))
+	svc.RegisterProvider(&mockProvider{
+		providerType: ProviderOpenAI,
+		name:         "OpenAI",
+		configured:   true,
+		embedResp:    &EmbeddingResponse{Embeddings: [][]float32{{1}}},
+	})
+
+	if _, err := svc.Embed(context.Background(), &EmbeddingRequest{Input: []string{"a"}}); err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+	if hasDeadline {
+		t.Error("Expected no deadline without WithDefaultTimeout")
+	}
+}
@@ -0,0 +1,64 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ErrDimensionMismatch indicates two embeddings have different lengths.
+var ErrDimensionMismatch = errors.New("embedding dimensions do not match")
+
+// SimilarityResult is a corpus entry scored against a query embedding.
+type SimilarityResult struct {
+	// Index is the position of the entry in the corpus.
+	Index int `json:"index"`
+
+	// Score is the cosine similarity to the query, in [-1, 1].
+	Score float64 `json:"score"`
+}
+
+// CosineSimilarity returns the cosine similarity of a and b.
+// A zero-length or zero-magnitude vector has similarity 0 to everything.
+func CosineSimilarity(a, b []float32) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("%w: %d vs %d", ErrDimensionMismatch, len(a), len(b))
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		normA += x * x
+		normB += y * y
+	}
+	if normA == 0 || normB == 0 {
+		return 0, nil
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB)), nil
+}
+
+// TopKSimilar returns the k corpus entries most similar to query, most similar
+// first (ties keep corpus order). Entries whose dimensions don't match the
+// query are skipped. k <= 0 returns every comparable entry.
+func TopKSimilar(query []float32, corpus [][]float32, k int) []SimilarityResult {
+	results := make([]SimilarityResult, 0, len(corpus))
+	for i, vec := range corpus {
+		score, err := CosineSimilarity(query, vec)
+		if err != nil {
+			continue
+		}
+		results = append(results, SimilarityResult{Index: i, Score: score})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if k > 0 && k < len(results) {
+		results = results[:k]
+	}
+	return results
+}
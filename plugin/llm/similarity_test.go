@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     []float32
+		expected float64
+	}{
+		{"identical", []float32{1, 2, 3}, []float32{1, 2, 3}, 1},
+		{"scaled", []float32{1, 2, 3}, []float32{2, 4, 6}, 1},
+		{"orthogonal", []float32{1, 0}, []float32{0, 1}, 0},
+		{"opposite", []float32{1, -1}, []float32{-1, 1}, -1},
+		{"45 degrees", []float32{1, 0}, []float32{1, 1}, 1 / math.Sqrt2},
+		{"zero vector", []float32{0, 0}, []float32{1, 1}, 0},
+		{"empty", []float32{}, []float32{}, 0},
+	}
+
+	for _, tt := range tests {
+		got, err := CosineSimilarity(tt.a, tt.b)
+		if err != nil {
+			t.Fatalf("%s: CosineSimilarity() error: %v", tt.name, err)
+		}
+		if math.Abs(got-tt.expected) > 1e-6 {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestCosineSimilarityDimensionMismatch(t *testing.T) {
+	_, err := CosineSimilarity([]float32{1, 2}, []float32{1, 2, 3})
+	if !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("Expected ErrDimensionMismatch, got %v", err)
+	}
+}
+
+func TestTopKSimilar(t *testing.T) {
+	query := []float32{1, 0}
+	corpus := [][]float32{
+		{0, 1},    // orthogonal
+		{1, 0.1},  // nearly identical
+		{-1, 0},   // opposite
+		{1, 1},    // 45 degrees
+		{1, 0, 0}, // wrong dimensions, skipped
+		{2, 0.2},  // same direction as index 1
+	}
+
+	results := TopKSimilar(query, corpus, 3)
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %v", results)
+	}
+
+	// Ties keep corpus order
+	expectedOrder := []int{1, 5, 3}
+	for i, idx := range expectedOrder {
+		if results[i].Index != idx {
+			t.Errorf("Result %d: expected index %d, got %d (%v)", i, idx, results[i].Index, results)
+		}
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Score > results[i-1].Score {
+			t.Errorf("Results not sorted descending: %v", results)
+		}
+	}
+
+	all := TopKSimilar(query, corpus, 0)
+	if len(all) != 5 {
+		t.Errorf("Expected all 5 comparable entries for k=0, got %d", len(all))
+	}
+	if all[len(all)-1].Index != 2 {
+		t.Errorf("Expected the opposite vector last, got %v", all)
+	}
+}
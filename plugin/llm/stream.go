@@ -0,0 +1,184 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CompletionChunk is one incremental piece of a streamed completion.
+type CompletionChunk struct {
+	// Content is the text delta carried by this chunk.
+	Content string `json:"content,omitempty"`
+
+	// Done marks the terminal chunk of the stream.
+	Done bool `json:"done,omitempty"`
+
+	// Model is the model that produced the stream (set on the terminal chunk).
+	Model string `json:"model,omitempty"`
+
+	// FinishReason is why generation stopped (set on the terminal chunk).
+	FinishReason string `json:"finish_reason,omitempty"`
+
+	// Usage holds token usage (set on the terminal chunk, if reported).
+	Usage *TokenUsage `json:"usage,omitempty"`
+
+	// Err is set if the stream failed; it is always the last chunk sent.
+	Err error `json:"-"`
+}
+
+// StreamingProvider is implemented by providers that support streamed completions.
+type StreamingProvider interface {
+	// CompleteStream performs a chat completion and streams the response.
+	// The returned channel is closed after the terminal (Done or Err) chunk.
+	CompleteStream(ctx context.Context, req *CompletionRequest) (<-chan CompletionChunk, error)
+}
+
+// DoStreamRequest performs a single HTTP request and returns the response body
+// for incremental reading. Unlike DoRequest it does not retry, since a partially
+// consumed stream cannot be replayed. The client timeout is not applied, since a
+// stream may legitimately outlive it; ctx bounds the stream instead. The caller
+// must close the returned body.
+func (b *BaseProvider) DoStreamRequest(ctx context.Context, method, url string, body interface{}, headers map[string]string) (io.ReadCloser, error) {
+	var reqBody io.Reader
+	var jsonBody []byte
+	if body != nil {
+		var err error
+		jsonBody, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		reqBody = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	b.setExtraHeaders(ctx, req)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	client := *b.HTTPClient
+	client.Timeout = 0
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		b.logRequest(ctx, req, jsonBody, 0, nil, time.Since(start), err)
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		respBody, err := b.readBody(resp.Body)
+		b.logRequest(ctx, req, jsonBody, resp.StatusCode, respBody, time.Since(start), err)
+		if errors.Is(err, ErrResponseTooLarge) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		return nil, b.handleHTTPError(resp.StatusCode, resp.Header, respBody)
+	}
+
+	// The body is streamed to the caller, so latency here is time to response headers
+	b.logRequest(ctx, req, jsonBody, resp.StatusCode, nil, time.Since(start), nil)
+	return resp.Body, nil
+}
+
+// readSSE reads server-sent events from r and calls fn for each event.
+// Events without an explicit "event:" field have an empty event name.
+// Reading stops at EOF or when fn returns an error (errStopStream stops cleanly).
+func readSSE(r io.Reader, fn func(event, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var event string
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			event = ""
+			return nil
+		}
+		err := fn(event, strings.Join(data, "\n"))
+		event, data = "", nil
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return stopStreamErr(err)
+			}
+		case strings.HasPrefix(line, ":"):
+			// Comment line
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	// Flush a final event not followed by a blank line
+	return stopStreamErr(dispatch())
+}
+
+// readNDJSON reads newline-delimited JSON from r and calls fn for each non-empty line.
+// Reading stops at EOF or when fn returns an error (errStopStream stops cleanly).
+func readNDJSON(r io.Reader, fn func(line []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return stopStreamErr(err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	return nil
+}
+
+// errStopStream is returned by readSSE and readNDJSON callbacks to stop reading without error.
+var errStopStream = errors.New("stop stream")
+
+// stopStreamErr maps errStopStream to nil.
+func stopStreamErr(err error) error {
+	if errors.Is(err, errStopStream) {
+		return nil
+	}
+	return err
+}
+
+// sendChunk delivers a chunk unless the context is canceled first.
+func sendChunk(ctx context.Context, ch chan<- CompletionChunk, chunk CompletionChunk) bool {
+	select {
+	case ch <- chunk:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
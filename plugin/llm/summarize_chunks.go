@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	defaultSummarizeChunkTokens  = 2000
+	defaultSummarizeChunkOverlap = 200
+
+	// defaultSummarizeContextWindow is assumed for models missing from the model registry
+	defaultSummarizeContextWindow = 8192
+
+	// summarizeReservedTokens leaves room in the window for the prompt and the summary
+	summarizeReservedTokens = 1000
+
+	// summarizeMaxReduceRounds bounds repeated reduction of chunk summaries
+	summarizeMaxReduceRounds = 3
+
+	// charsPerToken is the rough characters-per-token ratio used for estimates
+	charsPerToken = 4
+)
+
+// estimateTokens roughly estimates the number of tokens in text.
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// summarizeInputLimit returns the largest content size, in estimated tokens,
+// that can be summarized in a single completion with the given model.
+func summarizeInputLimit(model string) int {
+	window := defaultSummarizeContextWindow
+	if info, ok := LookupModelInfo(model); ok && info.ContextWindow > 0 {
+		window = info.ContextWindow
+	}
+
+	limit := window - summarizeReservedTokens
+	if limit < window/2 {
+		limit = window / 2
+	}
+	return limit
+}
+
+// summarizeChunked summarizes long content with map-reduce: the content is split
+// into overlapping chunks, each chunk is summarized, and the chunk summaries are
+// combined and summarized again. limit is the largest input, in estimated tokens,
+// that fits in a single completion.
+func (b *BaseProvider) summarizeChunked(ctx context.Context, provider Provider, req *SummarizeRequest, limit int) (*SummarizeResponse, error) {
+	chunkTokens := b.Config.SummarizeChunkTokens
+	if chunkTokens <= 0 {
+		chunkTokens = defaultSummarizeChunkTokens
+	}
+	if chunkTokens > limit {
+		chunkTokens = limit
+	}
+	overlap := b.Config.SummarizeChunkOverlap
+	if overlap <= 0 {
+		overlap = defaultSummarizeChunkOverlap
+	}
+	if overlap > chunkTokens/2 {
+		overlap = chunkTokens / 2
+	}
+
+	// Usage covers every completion, not just the final one
+	var usage *TokenUsage
+	content := req.Content
+	for round := 0; round < summarizeMaxReduceRounds && estimateTokens(content) > limit; round++ {
+		chunks := splitIntoChunks(content, chunkTokens*charsPerToken, overlap*charsPerToken)
+
+		summaries := make([]string, 0, len(chunks))
+		for i, chunk := range chunks {
+			// Key points are only extracted from the final summary
+			chunkReq := *req
+			chunkReq.Content = chunk
+			chunkReq.ExtractKeyPoints = false
+
+			resp, err := b.summarizeOnce(ctx, provider, &chunkReq)
+			if err != nil {
+				return nil, fmt.Errorf("failed to summarize chunk %d of %d: %w", i+1, len(chunks), err)
+			}
+			summaries = append(summaries, resp.Summary)
+			usage = addUsage(usage, resp.Usage)
+		}
+		content = strings.Join(summaries, "\n\n")
+	}
+
+	if estimateTokens(content) > limit {
+		return nil, fmt.Errorf("%w: chunk summaries still too long after %d rounds", ErrContextTooLong, summarizeMaxReduceRounds)
+	}
+
+	reduceReq := *req
+	reduceReq.Content = content
+	resp, err := b.summarizeOnce(ctx, provider, &reduceReq)
+	if err != nil {
+		return nil, err
+	}
+	resp.Usage = addUsage(usage, resp.Usage)
+	return resp, nil
+}
+
+// splitIntoChunks splits text into chunks of at most chunkSize runes, with
+// adjacent chunks sharing about overlap runes. Chunks break at whitespace when possible.
+func splitIntoChunks(text string, chunkSize, overlap int) []string {
+	runes := []rune(text)
+	if len(runes) <= chunkSize {
+		return []string{text}
+	}
+
+	var chunks []string
+	start := 0
+	for start < len(runes) {
+		end := start + chunkSize
+		if end >= len(runes) {
+			chunks = append(chunks, strings.TrimSpace(string(runes[start:])))
+			break
+		}
+
+		// Prefer to break at whitespace in the second half of the chunk
+		for i := end; i > start+chunkSize/2; i-- {
+			if unicode.IsSpace(runes[i]) {
+				end = i
+				break
+			}
+		}
+		chunks = append(chunks, strings.TrimSpace(string(runes[start:end])))
+
+		next := end - overlap
+		// Start the next chunk at a word boundary
+		for next < end && next > start && !unicode.IsSpace(runes[next-1]) {
+			next++
+		}
+		if next <= start {
+			next = end
+		}
+		start = next
+	}
+
+	return chunks
+}
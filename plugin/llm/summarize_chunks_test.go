@@ -0,0 +1,124 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// longContent returns roughly the given number of estimated tokens of prose.
+func longContent(tokens int) string {
+	sentence := "The quarterly review covered hiring, budget, and the product roadmap. "
+	return strings.Repeat(sentence, tokens*charsPerToken/len(sentence)+1)
+}
+
+func TestSplitIntoChunks(t *testing.T) {
+	text := longContent(1000)
+	chunks := splitIntoChunks(text, 800, 80)
+	if len(chunks) < 5 {
+		t.Fatalf("Expected at least 5 chunks, got %d", len(chunks))
+	}
+
+	words := make(map[string]bool)
+	for _, word := range strings.Fields(text) {
+		words[word] = true
+	}
+	for i, chunk := range chunks {
+		if n := len([]rune(chunk)); n > 800 {
+			t.Errorf("Chunk %d has %d runes, expected at most 800", i, n)
+		}
+		// Chunks break at word boundaries
+		fields := strings.Fields(chunk)
+		if first, last := fields[0], fields[len(fields)-1]; !words[first] || !words[last] {
+			t.Errorf("Chunk %d does not start and end on whole words: %q ... %q", i, first, last)
+		}
+	}
+
+	// Adjacent chunks overlap
+	for i := 1; i < len(chunks); i++ {
+		tail := chunks[i-1][len(chunks[i-1])-40:]
+		if !strings.Contains(chunks[i], strings.Fields(tail)[1]) {
+			t.Errorf("Expected chunk %d to overlap the end of chunk %d", i, i-1)
+		}
+	}
+
+	if got := splitIntoChunks("short text", 800, 80); len(got) != 1 || got[0] != "short text" {
+		t.Errorf("Expected short text to be a single chunk, got %v", got)
+	}
+}
+
+func TestDefaultSummarizeMapReduce(t *testing.T) {
+	provider := &mockProvider{
+		defaultModel: "gpt-4", // 8192-token window
+		completeResp: &CompletionResponse{Content: "Chunk summary."},
+	}
+	base := NewBaseProvider(&ProviderConfig{})
+
+	resp, err := base.DefaultSummarize(context.Background(), provider, &SummarizeRequest{
+		Content: longContent(20000),
+	})
+	if err != nil {
+		t.Fatalf("DefaultSummarize() error: %v", err)
+	}
+	if resp.Summary != "Chunk summary." {
+		t.Errorf("Expected the reduced summary, got %q", resp.Summary)
+	}
+
+	// 20000 tokens in 2000-token chunks with 200 overlap: 12 chunks, plus the reduce step
+	if provider.completeCalls != 13 {
+		t.Errorf("Expected 13 completions, got %d", provider.completeCalls)
+	}
+	// The final completion summarizes the combined chunk summaries
+	final := provider.lastCompleteReq.Messages[1].Content
+	if !strings.Contains(final, "Chunk summary.\n\nChunk summary.") || strings.Contains(final, "quarterly") {
+		t.Errorf("Expected the reduce step to summarize chunk summaries, got %q", final)
+	}
+}
+
+func TestDefaultSummarizeChunkConfig(t *testing.T) {
+	provider := &mockProvider{
+		defaultModel: "gpt-4",
+		completeResp: &CompletionResponse{Content: "Chunk summary."},
+	}
+	base := NewBaseProvider(&ProviderConfig{SummarizeChunkTokens: 5000, SummarizeChunkOverlap: 100})
+
+	if _, err := base.DefaultSummarize(context.Background(), provider, &SummarizeRequest{Content: longContent(20000)}); err != nil {
+		t.Fatalf("DefaultSummarize() error: %v", err)
+	}
+	// 5000-token chunks with 100 overlap: 5 chunks, plus the reduce step
+	if provider.completeCalls != 6 {
+		t.Errorf("Expected 6 completions, got %d", provider.completeCalls)
+	}
+}
+
+func TestDefaultSummarizeShortContentSinglePass(t *testing.T) {
+	provider := &mockProvider{
+		defaultModel: "gpt-4",
+		completeResp: &CompletionResponse{Content: "Short summary."},
+	}
+	base := NewBaseProvider(&ProviderConfig{})
+
+	if _, err := base.DefaultSummarize(context.Background(), provider, &SummarizeRequest{Content: longContent(1000)}); err != nil {
+		t.Fatalf("DefaultSummarize() error: %v", err)
+	}
+	if provider.completeCalls != 1 {
+		t.Errorf("Expected a single completion, got %d", provider.completeCalls)
+	}
+}
+
+func TestDefaultSummarizeMapReduceChunkError(t *testing.T) {
+	provider := &mockProvider{
+		defaultModel: "gpt-4",
+		completeErr:  ErrRateLimited,
+	}
+	base := NewBaseProvider(&ProviderConfig{})
+
+	_, err := base.DefaultSummarize(context.Background(), provider, &SummarizeRequest{Content: longContent(20000)})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected the chunk error to be wrapped, got %v", err)
+	}
+	if provider.completeCalls != 1 {
+		t.Errorf("Expected to stop after the first failed chunk, got %d completions", provider.completeCalls)
+	}
+}
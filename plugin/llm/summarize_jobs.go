@@ -0,0 +1,289 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// SummarizeOptions controls how content is summarized.
+type SummarizeOptions struct {
+	// MaxLength is the maximum summary length in characters.
+	MaxLength int
+
+	// Style is the summarization style (e.g., "brief", "detailed", "bullet").
+	Style string
+
+	// Language is the preferred language for the summary (e.g., "en", "zh").
+	Language string
+
+	// ExtractKeyPoints requests key points alongside the summary.
+	ExtractKeyPoints bool
+
+	// Model overrides TagServiceConfig.Model for this summary.
+	Model string
+}
+
+// SummarizeJob represents an asynchronous summarization job.
+type SummarizeJob struct {
+	ID          string
+	MemoID      int32
+	Content     string
+	Options     SummarizeOptions
+	UserID      int32
+	Status      TagJobStatus
+	Result      *SummarizeResponse
+	Error       error
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// SummarizeJobCallback is called when an async summarize job completes.
+type SummarizeJobCallback func(job *SummarizeJob)
+
+// cachedSummary represents a cached summarization result.
+type cachedSummary struct {
+	result    *SummarizeResponse
+	createdAt time.Time
+}
+
+// SetSummarizeJobCallback sets the callback for summarize job completion.
+func (ts *TagService) SetSummarizeJobCallback(cb SummarizeJobCallback) {
+	ts.summarizeCallback = cb
+}
+
+// SummarizeAsync queues an async summarization job.
+// Summaries share the per-user rate limit budget with tag suggestions.
+func (ts *TagService) SummarizeAsync(userID int32, memoID int32, content string, opts SummarizeOptions) (*SummarizeJob, error) {
+	if !ts.config.EnableAsync {
+		return nil, errors.New("async summarization is disabled")
+	}
+	// Workers are gone once stopped, so a queued job would never run
+	if ts.stopping() {
+		return nil, ErrServiceStopping
+	}
+
+	// Check rate limit
+	if !ts.checkRateLimit(userID) {
+		return nil, ErrRateLimitExceeded
+	}
+
+	job := &SummarizeJob{
+		// Hash the options too, so the same content summarized differently gets a distinct ID
+		ID:        generateJobID(memoID, summaryCacheKey(content, opts)),
+		MemoID:    memoID,
+		Content:   content,
+		Options:   opts,
+		UserID:    userID,
+		Status:    TagJobStatusPending,
+		CreatedAt: ts.clock.Now(),
+	}
+
+	// Check cache first
+	if cached := ts.getSummaryFromCache(content, opts); cached != nil {
+		// Return completed job immediately
+		job.Status = TagJobStatusCompleted
+		job.Result = cached
+		job.CompletedAt = &job.CreatedAt
+		ts.storeSummarizeJob(job)
+		return job, nil
+	}
+
+	ts.storeSummarizeJob(job)
+
+	// The worker mutates its own copy, so job stays safe to return
+	work := *job
+	select {
+	case ts.summarizeQueue <- &work:
+		slog.Info("Summarize job queued",
+			slog.String("job_id", job.ID),
+			slog.Int("memo_id", int(memoID)))
+		return job, nil
+	default:
+	}
+
+	// The job never ran, so don't leave it reported as pending
+	ts.deleteSummarizeJob(job.ID)
+	return nil, ErrJobQueueFull
+}
+
+// PreviewSummarizePrompt returns the system and user prompts a summarize job
+// would send for the content, without calling the LLM.
+func (ts *TagService) PreviewSummarizePrompt(content string, opts SummarizeOptions) (system, user string) {
+	return buildSummarizePrompts(&SummarizeRequest{
+		Content:   content,
+		MaxLength: opts.MaxLength,
+		Style:     opts.Style,
+		Language:  opts.Language,
+
+		ExtractKeyPoints: opts.ExtractKeyPoints,
+	})
+}
+
+// GetSummarizeJob retrieves a summarize job by ID.
+func (ts *TagService) GetSummarizeJob(jobID string) (*SummarizeJob, bool) {
+	ts.summarizeJobsMu.RLock()
+	defer ts.summarizeJobsMu.RUnlock()
+
+	job, exists := ts.summarizeJobs[jobID]
+	if !exists {
+		return nil, false
+	}
+
+	// Return a copy to prevent modification
+	copy := *job
+	return &copy, true
+}
+
+// processSummarizeJob processes a single summarize job.
+func (ts *TagService) processSummarizeJob(job *SummarizeJob) {
+	job.Status = TagJobStatusRunning
+	ts.storeSummarizeJob(job)
+
+	ctx, cancel := context.WithTimeout(context.Background(), ts.jobTimeout())
+	defer cancel()
+
+	model := job.Options.Model
+	if model == "" {
+		model = ts.config.Model
+	}
+
+	ts.metrics.llmCalls.Add(1)
+	result, err := ts.llmService.Summarize(ctx, &SummarizeRequest{
+		Content:   job.Content,
+		MaxLength: job.Options.MaxLength,
+		Style:     job.Options.Style,
+		Language:  job.Options.Language,
+		Model:     model,
+
+		ExtractKeyPoints: job.Options.ExtractKeyPoints,
+	})
+
+	now := ts.clock.Now()
+	job.CompletedAt = &now
+
+	if err != nil {
+		job.Status = TagJobStatusFailed
+		job.Error = err
+		slog.Error("Summarize job failed",
+			slog.String("job_id", job.ID),
+			slog.Int("memo_id", int(job.MemoID)),
+			slog.String("error", err.Error()))
+	} else {
+		job.Status = TagJobStatusCompleted
+		job.Result = result
+		ts.recordUserUsage(job.UserID, result.Model, result.Usage)
+		ts.cacheSummary(job.Content, job.Options, result)
+		slog.Info("Summarize job completed",
+			slog.String("job_id", job.ID),
+			slog.Int("memo_id", int(job.MemoID)))
+	}
+
+	ts.storeSummarizeJob(job)
+
+	if ts.summarizeCallback != nil {
+		ts.summarizeCallback(job)
+	}
+}
+
+// storeSummarizeJob saves a snapshot of the job.
+func (ts *TagService) storeSummarizeJob(job *SummarizeJob) {
+	ts.summarizeJobsMu.Lock()
+	defer ts.summarizeJobsMu.Unlock()
+
+	copy := *job
+	ts.summarizeJobs[job.ID] = &copy
+}
+
+// deleteSummarizeJob removes a job from the summarize job index.
+func (ts *TagService) deleteSummarizeJob(jobID string) {
+	ts.summarizeJobsMu.Lock()
+	defer ts.summarizeJobsMu.Unlock()
+
+	delete(ts.summarizeJobs, jobID)
+}
+
+// cleanupExpiredSummarizeJobs removes completed/failed summarize jobs that
+// finished more than maxAge before now.
+func (ts *TagService) cleanupExpiredSummarizeJobs(now time.Time, maxAge time.Duration) int {
+	ts.summarizeJobsMu.Lock()
+	defer ts.summarizeJobsMu.Unlock()
+
+	removed := 0
+	for id, job := range ts.summarizeJobs {
+		if job.Status != TagJobStatusCompleted && job.Status != TagJobStatusFailed {
+			continue
+		}
+		if job.CompletedAt != nil && now.Sub(*job.CompletedAt) > maxAge {
+			delete(ts.summarizeJobs, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// summaryCacheKey generates a cache key from content and summarize options.
+func summaryCacheKey(content string, opts SummarizeOptions) string {
+	h := sha256.New()
+	h.Write([]byte(content))
+	fmt.Fprintf(h, "\x00%s\x00%d\x00%s\x00%t\x00%s", opts.Style, opts.MaxLength, opts.Language, opts.ExtractKeyPoints, opts.Model)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// getSummaryFromCache retrieves a summary from cache if available and not expired.
+func (ts *TagService) getSummaryFromCache(content string, opts SummarizeOptions) *SummarizeResponse {
+	key := summaryCacheKey(content, opts)
+
+	ts.summaryCacheMu.RLock()
+	defer ts.summaryCacheMu.RUnlock()
+
+	cached, exists := ts.summaryCache[key]
+	if !exists {
+		return nil
+	}
+
+	if ts.clock.Now().Sub(cached.createdAt) > ts.config.CacheTTL {
+		return nil
+	}
+
+	// The cached result cost nothing this time
+	result := *cached.result
+	result.Usage = nil
+	return &result
+}
+
+// cacheSummary stores a summary in the cache.
+func (ts *TagService) cacheSummary(content string, opts SummarizeOptions, result *SummarizeResponse) {
+	key := summaryCacheKey(content, opts)
+
+	ts.summaryCacheMu.Lock()
+	defer ts.summaryCacheMu.Unlock()
+
+	// Evict expired entries, then the oldest one, if the cache is full
+	if len(ts.summaryCache) >= ts.config.MaxCacheSize {
+		now := ts.clock.Now()
+		var oldestKey string
+		var oldest time.Time
+		for k, entry := range ts.summaryCache {
+			if now.Sub(entry.createdAt) > ts.config.CacheTTL {
+				delete(ts.summaryCache, k)
+				continue
+			}
+			if oldestKey == "" || entry.createdAt.Before(oldest) {
+				oldestKey, oldest = k, entry.createdAt
+			}
+		}
+		if len(ts.summaryCache) >= ts.config.MaxCacheSize && oldestKey != "" {
+			delete(ts.summaryCache, oldestKey)
+		}
+	}
+
+	ts.summaryCache[key] = &cachedSummary{
+		result:    result,
+		createdAt: ts.clock.Now(),
+	}
+}
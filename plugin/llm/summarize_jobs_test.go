@@ -0,0 +1,373 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSummarizeTagService(mock *mockLLMService, rateLimit int) *TagService {
+	return NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: rateLimit,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    10,
+	})
+}
+
+func TestSummarizeAsync(t *testing.T) {
+	var gotReq *SummarizeRequest
+	mock := &mockLLMService{
+		summarizeFunc: func(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error) {
+			gotReq = req
+			return &SummarizeResponse{Summary: "A short summary."}, nil
+		},
+	}
+	ts := newTestSummarizeTagService(mock, 100)
+	defer ts.Stop()
+
+	job, err := ts.SummarizeAsync(1, 100, "Async summarize content", SummarizeOptions{Style: "bullet", MaxLength: 120})
+	if err != nil {
+		t.Fatalf("SummarizeAsync failed: %v", err)
+	}
+	if job.MemoID != 100 {
+		t.Errorf("Expected MemoID 100, got %d", job.MemoID)
+	}
+
+	// Wait for job to complete
+	time.Sleep(100 * time.Millisecond)
+
+	completedJob, exists := ts.GetSummarizeJob(job.ID)
+	if !exists {
+		t.Fatal("Job should exist")
+	}
+	if completedJob.Status != TagJobStatusCompleted {
+		t.Errorf("Expected status Completed, got %s", completedJob.Status)
+	}
+	if completedJob.Result == nil || completedJob.Result.Summary != "A short summary." {
+		t.Errorf("Unexpected result: %+v", completedJob.Result)
+	}
+	if gotReq == nil || gotReq.Style != "bullet" || gotReq.MaxLength != 120 {
+		t.Errorf("Expected options to reach Summarize, got %+v", gotReq)
+	}
+}
+
+func TestSummarizeAsync_Callback(t *testing.T) {
+	mock := &mockLLMService{}
+	ts := newTestSummarizeTagService(mock, 100)
+	defer ts.Stop()
+
+	callbackCalled := make(chan *SummarizeJob, 1)
+	ts.SetSummarizeJobCallback(func(job *SummarizeJob) {
+		callbackCalled <- job
+	})
+
+	if _, err := ts.SummarizeAsync(1, 100, "Callback summarize content", SummarizeOptions{}); err != nil {
+		t.Fatalf("SummarizeAsync failed: %v", err)
+	}
+
+	select {
+	case job := <-callbackCalled:
+		if job.Status != TagJobStatusCompleted {
+			t.Errorf("Expected completed job in callback, got %s", job.Status)
+		}
+	case <-time.After(1 * time.Second):
+		t.Error("Callback was not called within timeout")
+	}
+}
+
+func TestSummarizeAsync_JobTimeout(t *testing.T) {
+	mock := &mockLLMService{
+		summarizeFunc: func(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    10,
+		JobTimeout:        50 * time.Millisecond,
+	})
+	defer ts.Stop()
+
+	done := make(chan *SummarizeJob, 1)
+	ts.SetSummarizeJobCallback(func(job *SummarizeJob) { done <- job })
+
+	if _, err := ts.SummarizeAsync(1, 100, "Slow summarize content", SummarizeOptions{}); err != nil {
+		t.Fatalf("SummarizeAsync failed: %v", err)
+	}
+
+	select {
+	case job := <-done:
+		if job.Status != TagJobStatusFailed || !errors.Is(job.Error, context.DeadlineExceeded) {
+			t.Errorf("Expected job to fail with the configured timeout, got %s: %v", job.Status, job.Error)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Summarize job did not honor JobTimeout")
+	}
+}
+
+func TestSummarizeAsync_JobIDIncludesOptions(t *testing.T) {
+	mock := &mockLLMService{}
+	ts := newTestSummarizeTagService(mock, 100)
+	defer ts.Stop()
+
+	brief, err := ts.SummarizeAsync(1, 100, "Same content", SummarizeOptions{Style: "brief"})
+	if err != nil {
+		t.Fatalf("SummarizeAsync failed: %v", err)
+	}
+	detailed, err := ts.SummarizeAsync(1, 100, "Same content", SummarizeOptions{Style: "detailed"})
+	if err != nil {
+		t.Fatalf("SummarizeAsync failed: %v", err)
+	}
+	if brief.ID == detailed.ID {
+		t.Error("Expected distinct job IDs for different options")
+	}
+	if job, ok := ts.GetSummarizeJob(brief.ID); !ok || job.Options.Style != "brief" {
+		t.Errorf("Expected the brief job to keep its options, got %+v", job)
+	}
+}
+
+func TestSummarizeAsync_CacheHit(t *testing.T) {
+	mock := &mockLLMService{}
+	ts := newTestSummarizeTagService(mock, 100)
+	defer ts.Stop()
+
+	opts := SummarizeOptions{Style: "brief", MaxLength: 100}
+	if _, err := ts.SummarizeAsync(1, 100, "Cached summarize content", opts); err != nil {
+		t.Fatalf("SummarizeAsync failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// Same content and options should be served from cache
+	job, err := ts.SummarizeAsync(1, 100, "Cached summarize content", opts)
+	if err != nil {
+		t.Fatalf("SummarizeAsync failed: %v", err)
+	}
+	if job.Status != TagJobStatusCompleted {
+		t.Errorf("Expected completed status for cache hit, got %s", job.Status)
+	}
+	if mock.GetCallCount() != 1 {
+		t.Errorf("Expected 1 LLM call, got %d", mock.GetCallCount())
+	}
+
+	// Different style should miss the cache
+	if _, err := ts.SummarizeAsync(1, 100, "Cached summarize content", SummarizeOptions{Style: "detailed", MaxLength: 100}); err != nil {
+		t.Fatalf("SummarizeAsync failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if mock.GetCallCount() != 2 {
+		t.Errorf("Expected 2 LLM calls after style change, got %d", mock.GetCallCount())
+	}
+}
+
+func TestSummarizeAsync_SharedRateLimit(t *testing.T) {
+	mock := &mockLLMService{}
+	ts := newTestSummarizeTagService(mock, 2)
+	defer ts.Stop()
+
+	if _, err := ts.SuggestTagsAsync(1, 100, "Tag content", nil); err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+	if _, err := ts.SummarizeAsync(1, 100, "Summary content", SummarizeOptions{}); err != nil {
+		t.Fatalf("SummarizeAsync failed: %v", err)
+	}
+
+	// Budget of 2 is shared between tags and summaries
+	if _, err := ts.SummarizeAsync(1, 100, "More content", SummarizeOptions{}); err != ErrRateLimitExceeded {
+		t.Errorf("Expected ErrRateLimitExceeded, got %v", err)
+	}
+}
+
+func TestSummarizeAsync_Disabled(t *testing.T) {
+	ts := NewTagService(&mockLLMService{}, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       false,
+	})
+	defer ts.Stop()
+
+	if _, err := ts.SummarizeAsync(1, 100, "Test content", SummarizeOptions{}); err == nil {
+		t.Error("Expected error when async is disabled")
+	}
+}
+
+func TestSummaryCacheKey(t *testing.T) {
+	base := summaryCacheKey("content", SummarizeOptions{Style: "brief", MaxLength: 100})
+	if base != summaryCacheKey("content", SummarizeOptions{Style: "brief", MaxLength: 100}) {
+		t.Error("Same inputs should produce same cache key")
+	}
+	if base == summaryCacheKey("content", SummarizeOptions{Style: "brief", MaxLength: 200}) {
+		t.Error("Different max length should produce different cache key")
+	}
+	if base == summaryCacheKey("content", SummarizeOptions{Style: "bullet", MaxLength: 100}) {
+		t.Error("Different style should produce different cache key")
+	}
+	if base == summaryCacheKey("content", SummarizeOptions{Style: "brief", MaxLength: 100, ExtractKeyPoints: true}) {
+		t.Error("Requesting key points should produce different cache key")
+	}
+}
+
+func TestPreviewSummarizePrompt(t *testing.T) {
+	ts := newTestSummarizeTagService(&mockLLMService{}, 100)
+	defer ts.Stop()
+
+	system, user := ts.PreviewSummarizePrompt("Meeting notes", SummarizeOptions{
+		Style:            "detailed",
+		MaxLength:        150,
+		ExtractKeyPoints: true,
+	})
+	if !strings.Contains(system, "Create a detailed summary") || !strings.Contains(system, "under 150 characters") {
+		t.Errorf("Expected preview to reflect style and length, got %q", system)
+	}
+	if !strings.Contains(system, "key_points") {
+		t.Errorf("Expected preview to include the key points instruction, got %q", system)
+	}
+	if user != "Summarize this content:\n\nMeeting notes" {
+		t.Errorf("Unexpected user prompt %q", user)
+	}
+}
+
+func TestSummarizeAsync_ModelOverride(t *testing.T) {
+	tests := []struct {
+		name        string
+		configModel string
+		optsModel   string
+		expected    string
+	}{
+		{"config model", "gpt-4o-mini", "", "gpt-4o-mini"},
+		{"options take precedence", "gpt-4o-mini", "gpt-4o", "gpt-4o"},
+		{"provider default", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &mockProvider{
+				completeResp: &CompletionResponse{Content: "A summary."},
+			}
+			base := NewBaseProvider(&ProviderConfig{})
+			mock := &mockLLMService{
+				summarizeFunc: func(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error) {
+					return base.DefaultSummarize(ctx, provider, req)
+				},
+			}
+			ts := newTestSummarizeTagService(mock, 100)
+			ts.config.Model = tt.configModel
+			defer ts.Stop()
+
+			done := make(chan *SummarizeJob, 1)
+			ts.SetSummarizeJobCallback(func(job *SummarizeJob) { done <- job })
+
+			if _, err := ts.SummarizeAsync(1, 100, "Model override content", SummarizeOptions{Model: tt.optsModel}); err != nil {
+				t.Fatalf("SummarizeAsync failed: %v", err)
+			}
+
+			select {
+			case job := <-done:
+				if job.Status != TagJobStatusCompleted {
+					t.Fatalf("Expected completed job, got %s (%v)", job.Status, job.Error)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Timed out waiting for job")
+			}
+			if got := provider.lastCompleteReq.Model; got != tt.expected {
+				t.Errorf("Expected model %q to reach Complete, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSummarizeAsync_QueueFull(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	mock := &mockLLMService{
+		summarizeFunc: func(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error) {
+			started <- struct{}{}
+			<-release
+			return &SummarizeResponse{Summary: "Done."}, nil
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    1,
+	})
+	defer ts.Stop()
+	defer close(release)
+
+	// Occupy the worker, then fill the single queue slot
+	if _, err := ts.SummarizeAsync(1, 1, "running", SummarizeOptions{}); err != nil {
+		t.Fatalf("SummarizeAsync failed: %v", err)
+	}
+	<-started
+	if _, err := ts.SummarizeAsync(1, 2, "queued", SummarizeOptions{}); err != nil {
+		t.Fatalf("SummarizeAsync failed: %v", err)
+	}
+
+	if _, err := ts.SummarizeAsync(1, 3, "rejected", SummarizeOptions{}); !errors.Is(err, ErrJobQueueFull) {
+		t.Errorf("Expected ErrJobQueueFull, got %v", err)
+	}
+	rejectedID := generateJobID(3, summaryCacheKey("rejected", SummarizeOptions{}))
+	if job, exists := ts.GetSummarizeJob(rejectedID); exists {
+		t.Errorf("Expected no record of the rejected job, got %s", job.Status)
+	}
+}
+
+func TestCleanupExpiredJobs_SummarizeJobs(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	ts := NewTagService(&mockLLMService{}, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    10,
+		Clock:             clock,
+	})
+	defer ts.Stop()
+
+	// Warm the cache so the async job completes immediately
+	opts := SummarizeOptions{Style: "brief"}
+	ts.cacheSummary("Cleanup test", opts, &SummarizeResponse{Summary: "Cached."})
+	job, err := ts.SummarizeAsync(1, 100, "Cleanup test", opts)
+	if err != nil {
+		t.Fatalf("SummarizeAsync failed: %v", err)
+	}
+	if job.Status != TagJobStatusCompleted {
+		t.Fatalf("Expected cached job to complete immediately, got %s", job.Status)
+	}
+
+	if removed := ts.CleanupExpiredJobs(time.Hour); removed != 0 {
+		t.Errorf("Expected no jobs removed before max age, got %d", removed)
+	}
+
+	clock.Advance(2 * time.Hour)
+	if removed := ts.CleanupExpiredJobs(time.Hour); removed != 1 {
+		t.Errorf("Expected 1 job removed after max age, got %d", removed)
+	}
+	if _, exists := ts.GetSummarizeJob(job.ID); exists {
+		t.Error("Expected the expired summarize job to be removed")
+	}
+}
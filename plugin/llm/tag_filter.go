@@ -0,0 +1,116 @@
+package llm
+
+import "strings"
+
+// tagVocabulary holds the compiled AllowedTags and BlockedTags lists.
+type tagVocabulary struct {
+	// allowed maps a tag key to its spelling in AllowedTags; nil means any tag is allowed
+	allowed map[string]string
+	blocked map[string]bool
+}
+
+// newTagVocabulary compiles the allow and block lists from config.
+func newTagVocabulary(config *TagServiceConfig) tagVocabulary {
+	var vocab tagVocabulary
+	if len(config.AllowedTags) > 0 {
+		vocab.allowed = make(map[string]string, len(config.AllowedTags))
+		for _, tag := range config.AllowedTags {
+			if key := tagKey(tag); key != "" {
+				if _, exists := vocab.allowed[key]; !exists {
+					vocab.allowed[key] = tag
+				}
+			}
+		}
+	}
+	if len(config.BlockedTags) > 0 {
+		vocab.blocked = make(map[string]bool, len(config.BlockedTags))
+		for _, tag := range config.BlockedTags {
+			vocab.blocked[tagKey(tag)] = true
+		}
+	}
+	return vocab
+}
+
+// tagKey normalizes a tag for list matching: case-insensitive, with spaces and
+// underscores treated as hyphens and a plural "s" ignored.
+func tagKey(tag string) string {
+	key := strings.ToLower(trimTag(tag))
+	key = strings.NewReplacer(" ", "-", "_", "-").Replace(key)
+	if len(key) > 3 {
+		key = strings.TrimSuffix(key, "s")
+	}
+	return key
+}
+
+// matchExistingTags returns tags with those matching existing (compared like
+// AllowedTags) in their existing spelling, and the tags that matched none.
+// tags is not modified.
+func matchExistingTags(tags, existing []string) (matched, unmatched []string) {
+	corpus := make(map[string]string, len(existing))
+	for _, tag := range existing {
+		if key := tagKey(tag); key != "" {
+			if _, ok := corpus[key]; !ok {
+				corpus[key] = tag
+			}
+		}
+	}
+
+	matched = make([]string, len(tags))
+	for i, tag := range tags {
+		if canonical, ok := corpus[tagKey(tag)]; ok {
+			matched[i] = canonical
+			continue
+		}
+		matched[i] = tag
+		unmatched = append(unmatched, tag)
+	}
+	return matched, unmatched
+}
+
+// applyTagFilter returns result with BlockedTags removed, tags outside AllowedTags
+// dropped (survivors take the vocabulary's spelling), and then TagFilter applied.
+// Confidence scores stay aligned with the surviving tags. result is not modified.
+func (ts *TagService) applyTagFilter(result *SuggestTagsResponse) *SuggestTagsResponse {
+	if ts.vocabulary.allowed == nil && ts.vocabulary.blocked == nil && ts.config.TagFilter == nil {
+		return result
+	}
+
+	hasConfidence := len(result.Confidence) == len(result.Tags)
+	confidence := make(map[string]float64, len(result.Tags))
+
+	tags := make([]string, 0, len(result.Tags))
+	seen := make(map[string]bool, len(result.Tags))
+	for i, tag := range result.Tags {
+		key := tagKey(tag)
+		if ts.vocabulary.blocked[key] || seen[key] {
+			continue
+		}
+		if ts.vocabulary.allowed != nil {
+			canonical, ok := ts.vocabulary.allowed[key]
+			if !ok {
+				continue
+			}
+			tag = canonical
+		}
+		seen[key] = true
+		tags = append(tags, tag)
+		if hasConfidence {
+			confidence[tag] = result.Confidence[i]
+		}
+	}
+
+	if ts.config.TagFilter != nil {
+		tags = ts.config.TagFilter(tags)
+	}
+
+	out := *result
+	out.Tags = tags
+	out.Confidence = nil
+	if hasConfidence {
+		out.Confidence = make([]float64, len(tags))
+		for i, tag := range tags {
+			out.Confidence[i] = confidence[tag]
+		}
+	}
+	return &out
+}
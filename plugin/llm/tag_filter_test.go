@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newFilterTestService(tags []string, confidence []float64, configure func(*TagServiceConfig)) *TagService {
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			return &SuggestTagsResponse{Tags: tags, Confidence: confidence}, nil
+		},
+	}
+	config := &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+	}
+	configure(config)
+	return NewTagService(mock, config)
+}
+
+func TestTagFilter_BlockedTags(t *testing.T) {
+	ts := newFilterTestService(
+		[]string{"work", "Salary", "meeting", "ssn"},
+		[]float64{0.9, 0.8, 0.7, 0.6},
+		func(c *TagServiceConfig) { c.BlockedTags = []string{"salary", "SSN"} },
+	)
+
+	resp, err := ts.SuggestTags(context.Background(), 1, "content", nil)
+	if err != nil {
+		t.Fatalf("SuggestTags() error: %v", err)
+	}
+
+	if want := []string{"work", "meeting"}; !reflect.DeepEqual(resp.Tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, resp.Tags)
+	}
+	if want := []float64{0.9, 0.7}; !reflect.DeepEqual(resp.Confidence, want) {
+		t.Errorf("Expected confidence %v, got %v", want, resp.Confidence)
+	}
+
+	// The filtered result is what gets cached
+	cached, _ := ts.SuggestTags(context.Background(), 1, "content", nil)
+	if !reflect.DeepEqual(cached.Tags, resp.Tags) {
+		t.Errorf("Expected cached tags %v, got %v", resp.Tags, cached.Tags)
+	}
+}
+
+func TestTagFilter_AllowedTags(t *testing.T) {
+	ts := newFilterTestService(
+		[]string{"Projects", "random", "machine learning", "meeting_notes", "project"},
+		[]float64{0.9, 0.85, 0.8, 0.7, 0.6},
+		func(c *TagServiceConfig) {
+			c.AllowedTags = []string{"project", "machine-learning", "meeting-notes", "travel"}
+		},
+	)
+
+	resp, err := ts.SuggestTags(context.Background(), 1, "content", nil)
+	if err != nil {
+		t.Fatalf("SuggestTags() error: %v", err)
+	}
+
+	// Fuzzy matches take the vocabulary spelling, and duplicates collapse
+	if want := []string{"project", "machine-learning", "meeting-notes"}; !reflect.DeepEqual(resp.Tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, resp.Tags)
+	}
+	if want := []float64{0.9, 0.8, 0.7}; !reflect.DeepEqual(resp.Confidence, want) {
+		t.Errorf("Expected confidence %v, got %v", want, resp.Confidence)
+	}
+}
+
+func TestTagFilter_CustomFilter(t *testing.T) {
+	ts := newFilterTestService(
+		[]string{"work", "tmp-draft", "meeting"},
+		nil,
+		func(c *TagServiceConfig) {
+			c.BlockedTags = []string{"meeting"}
+			c.TagFilter = func(tags []string) []string {
+				var out []string
+				for _, tag := range tags {
+					if !strings.HasPrefix(tag, "tmp-") {
+						out = append(out, tag)
+					}
+				}
+				return out
+			}
+		},
+	)
+
+	resp, err := ts.SuggestTags(context.Background(), 1, "content", nil)
+	if err != nil {
+		t.Fatalf("SuggestTags() error: %v", err)
+	}
+
+	if want := []string{"work"}; !reflect.DeepEqual(resp.Tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, resp.Tags)
+	}
+	if resp.Confidence != nil {
+		t.Errorf("Expected no confidence scores, got %v", resp.Confidence)
+	}
+}
+
+func TestTagKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"Machine Learning", "machine-learning"},
+		{"meeting_notes", "Meeting-Notes"},
+		{"projects", "project"},
+		{"#travel", "travel"},
+	}
+
+	for _, tt := range tests {
+		if tagKey(tt.a) != tagKey(tt.b) {
+			t.Errorf("Expected %q and %q to match (%q vs %q)", tt.a, tt.b, tagKey(tt.a), tagKey(tt.b))
+		}
+	}
+	if tagKey("bus") == tagKey("bu") {
+		t.Error("Expected short words to keep a trailing s")
+	}
+}
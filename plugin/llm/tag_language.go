@@ -0,0 +1,57 @@
+package llm
+
+import "unicode"
+
+// tagLanguage returns the language for tag suggestions: the configured
+// Language, else the language detected from content if AutoDetectLanguage is
+// set, else "" (the model chooses).
+func (ts *TagService) tagLanguage(content string) string {
+	if ts.config.Language != "" || !ts.config.AutoDetectLanguage {
+		return ts.config.Language
+	}
+	return detectLanguage(content)
+}
+
+// detectLanguage guesses the language of text from the Unicode scripts of its
+// letters: "zh" (Han), "ja" (any kana), "ko" (Hangul), "ru" (Cyrillic) or
+// "en" (Latin), whichever script has the most letters. Han characters count
+// toward Japanese when kana are present. It returns "" for text without letters.
+func detectLanguage(text string) string {
+	var han, kana, hangul, cyrillic, latin int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	if kana > 0 {
+		kana += han
+		han = 0
+	}
+
+	best, language := 0, ""
+	for _, c := range []struct {
+		count    int
+		language string
+	}{
+		{han, "zh"},
+		{kana, "ja"},
+		{hangul, "ko"},
+		{cyrillic, "ru"},
+		{latin, "en"},
+	} {
+		if c.count > best {
+			best, language = c.count, c.language
+		}
+	}
+	return language
+}
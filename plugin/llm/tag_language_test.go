@@ -0,0 +1,87 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"今天学习了 Go 语言的并发模型", "zh"},
+		{"Learned about Go concurrency today", "en"},
+		{"今日はGoの並行処理を勉強した", "ja"},
+		{"오늘 Go 동시성을 공부했다", "ko"},
+		{"Сегодня изучал конкурентность в Go", "ru"},
+		{"12345 !?", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := detectLanguage(tt.text); got != tt.want {
+			t.Errorf("detectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTagServiceAutoDetectLanguage(t *testing.T) {
+	languages := make(map[string]string)
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			languages[req.Content] = req.Language
+			return &SuggestTagsResponse{Tags: []string{"tag"}}, nil
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest:  5,
+		CacheTTL:           15 * time.Minute,
+		MaxCacheSize:       100,
+		RateLimitRequests:  100,
+		RateLimitWindow:    time.Minute,
+		AutoDetectLanguage: true,
+	})
+	defer ts.Stop()
+
+	chinese := "今天学习了并发模型"
+	english := "Learned about concurrency today"
+	for _, content := range []string{chinese, english} {
+		if _, err := ts.SuggestTags(context.Background(), 1, content, nil); err != nil {
+			t.Fatalf("SuggestTags failed: %v", err)
+		}
+	}
+
+	if languages[chinese] != "zh" || languages[english] != "en" {
+		t.Errorf("Expected zh and en to reach the request, got %v", languages)
+	}
+	if system, _ := ts.PreviewTagPrompt(chinese, nil); !strings.Contains(system, "language: zh") {
+		t.Errorf("Expected the detected language in the prompt, got %q", system)
+	}
+
+	// The detected language is part of the cache key
+	req := ts.suggestTagsRequest(chinese, nil)
+	ts.cacheMu.Lock()
+	_, zhCached := ts.cache[cacheKey(req)]
+	req.Language = ""
+	_, undetected := ts.cache[cacheKey(req)]
+	ts.cacheMu.Unlock()
+	if !zhCached || undetected {
+		t.Errorf("Expected the Chinese entry to be cached under zh (zh=%v, none=%v)", zhCached, undetected)
+	}
+}
+
+func TestTagServiceAutoDetectLanguage_ConfiguredWins(t *testing.T) {
+	ts := NewTagService(&mockLLMService{}, &TagServiceConfig{
+		MaxTagsPerRequest:  5,
+		Language:           "fr",
+		AutoDetectLanguage: true,
+	})
+	defer ts.Stop()
+
+	if got := ts.tagLanguage("今天学习了并发模型"); got != "fr" {
+		t.Errorf("Expected the configured language, got %q", got)
+	}
+}
@@ -0,0 +1,131 @@
+package llm
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// parseTagArray extracts tags from the first JSON array in content, which may
+// be wrapped in a markdown code fence, surrounded by prose, nested in
+// {"tags": [...]}, or cut off mid-array. Elements may be strings or
+// {"tag": ...} objects. It returns the tags and whether a complete array was
+// found; for a truncated array, the tags of its complete elements are
+// returned with false.
+func parseTagArray(content string) ([]string, bool) {
+	tags, _, complete := parseScoredTagArray(content)
+	return tags, complete
+}
+
+// parseScoredTagArray is like parseTagArray but also returns confidence scores
+// (clamped to [0, 1]) when every element is a {"tag", "score"} object.
+func parseScoredTagArray(content string) ([]string, []float64, bool) {
+	for offset := 0; ; {
+		i := strings.IndexByte(content[offset:], '[')
+		if i < 0 {
+			return nil, nil, false
+		}
+		start := offset + i
+
+		end, lastElement, complete := scanJSONArray(content, start)
+		if !complete {
+			// Salvage the complete elements of a truncated array
+			if lastElement < 0 {
+				return nil, nil, false
+			}
+			tags, confidence, ok := decodeTagArray(content[start:lastElement] + "]")
+			if !ok {
+				return nil, nil, false
+			}
+			return tags, confidence, false
+		}
+
+		if tags, confidence, ok := decodeTagArray(content[start:end]); ok {
+			return tags, confidence, true
+		}
+		// Not a tag array (e.g., "[see below]" in prose); try the next one
+		offset = start + 1
+	}
+}
+
+// scanJSONArray scans the JSON array starting at content[start] (a '['),
+// skipping brackets inside strings. It returns the index just past the closing
+// bracket and true, or, if content ends first, false. lastElement is the index
+// just past the last complete string or object element (-1 if none).
+func scanJSONArray(content string, start int) (end, lastElement int, complete bool) {
+	lastElement = -1
+	depth := 0
+	inString, escaped := false, false
+
+	for i := start; i < len(content); i++ {
+		c := content[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+				if depth == 1 {
+					lastElement = i + 1
+				}
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				return i + 1, lastElement, true
+			}
+			if depth == 1 {
+				lastElement = i + 1
+			}
+		}
+	}
+	return len(content), lastElement, false
+}
+
+// decodeTagArray decodes a JSON array of tag strings or {"tag", "score"}
+// objects, dropping elements that fail isValidTag. Confidence is nil unless
+// every element is an object.
+func decodeTagArray(array string) ([]string, []float64, bool) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal([]byte(array), &elements); err != nil {
+		return nil, nil, false
+	}
+
+	tags := make([]string, 0, len(elements))
+	confidence := make([]float64, 0, len(elements))
+	scored := true
+	for _, element := range elements {
+		var tag string
+		if err := json.Unmarshal(element, &tag); err == nil {
+			scored = false
+			if isValidTag(tag) {
+				tags = append(tags, tag)
+			}
+			continue
+		}
+
+		var st scoredTag
+		if err := json.Unmarshal(element, &st); err != nil {
+			return nil, nil, false
+		}
+		if !isValidTag(st.Tag) {
+			continue
+		}
+		tags = append(tags, st.Tag)
+		confidence = append(confidence, clampScore(st.Score))
+	}
+
+	if !scored {
+		confidence = nil
+	}
+	return tags, confidence, true
+}
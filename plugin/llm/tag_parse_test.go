@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseTagArray(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		wantTags     []string
+		wantComplete bool
+	}{
+		{"plain", `["go", "testing"]`, []string{"go", "testing"}, true},
+		{"fenced", "```json\n[\"go\", \"testing\"]\n```", []string{"go", "testing"}, true},
+		{"fenced objects", "```\n[{\"tag\": \"go\", \"score\": 0.9}]\n```", []string{"go"}, true},
+		{"prose", `Sure! Here are the tags: ["go", "testing"]. Let me know if you need more.`, []string{"go", "testing"}, true},
+		{"bracketed prose first", `Tags [see below]: ["go", "testing"]`, []string{"go", "testing"}, true},
+		{"json mode wrapper", `{"tags": [{"tag": "go", "score": 0.8}]}`, []string{"go"}, true},
+		// The array is scanned past the brackets, then "c[++]" fails isValidTag
+		{"brackets in strings", `["c[++]", "go"]`, []string{"go"}, true},
+		{"truncated", `["go", "testing", "ben`, []string{"go", "testing"}, false},
+		{"truncated objects", `[{"tag": "go", "score": 0.9}, {"tag": "te`, []string{"go"}, false},
+		{"truncated before any element", `["gola`, nil, false},
+		{"no array", `go, testing`, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, complete := parseTagArray(tt.content)
+			if complete != tt.wantComplete {
+				t.Errorf("Expected complete=%v, got %v", tt.wantComplete, complete)
+			}
+			if len(tags) != len(tt.wantTags) || (len(tags) > 0 && !reflect.DeepEqual(tags, tt.wantTags)) {
+				t.Errorf("Expected tags %v, got %v", tt.wantTags, tags)
+			}
+		})
+	}
+}
+
+func TestParseScoredTagArray_Confidence(t *testing.T) {
+	tags, confidence, complete := parseScoredTagArray("```json\n[{\"tag\": \"go\", \"score\": 0.9}, {\"tag\": \"ci\", \"score\": 1.5}]\n```")
+	if !complete || !reflect.DeepEqual(tags, []string{"go", "ci"}) {
+		t.Fatalf("Unexpected result: %v, %v", tags, complete)
+	}
+	if !reflect.DeepEqual(confidence, []float64{0.9, 1}) {
+		t.Errorf("Expected clamped scores, got %v", confidence)
+	}
+
+	if _, confidence, _ := parseScoredTagArray(`["go", {"tag": "ci", "score": 0.5}]`); confidence != nil {
+		t.Errorf("Expected no confidence for a mixed array, got %v", confidence)
+	}
+}
+
+func TestDefaultSuggestTags_FencedAndTruncated(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"Here you go:\n```json\n[{\"tag\": \"golang\", \"score\": 0.9}, {\"tag\": \"testing\", \"score\": 0.7}]\n```", []string{"golang", "testing"}},
+		{`[{"tag": "golang", "score": 0.9}, {"tag": "testing", "score": 0.7}, {"tag": "ci", "sc`, []string{"golang", "testing"}},
+	}
+
+	for _, tt := range tests {
+		provider := &mockProvider{completeResp: &CompletionResponse{Content: tt.content}}
+		base := NewBaseProvider(&ProviderConfig{})
+
+		resp, err := base.DefaultSuggestTags(context.Background(), provider, &SuggestTagsRequest{Content: "Writing Go tests"})
+		if err != nil {
+			t.Fatalf("DefaultSuggestTags() error: %v", err)
+		}
+		if !reflect.DeepEqual(resp.Tags, tt.want) {
+			t.Errorf("Content %q: expected %v, got %v", tt.content, tt.want, resp.Tags)
+		}
+		if len(resp.Confidence) != len(tt.want) {
+			t.Errorf("Content %q: expected scores for each tag, got %v", tt.content, resp.Confidence)
+		}
+	}
+}
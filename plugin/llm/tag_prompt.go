@@ -0,0 +1,71 @@
+package llm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// TagPromptData is the data available to tag prompt templates.
+//
+// Example template:
+//
+//	Suggest up to {{.MaxTags}} legal tags for this content (existing: {{join .ExistingTags ", "}}):
+//	{{.Content}}
+type TagPromptData struct {
+	// Content is the memo content to analyze.
+	Content string
+
+	// MaxTags is the maximum number of tags to suggest.
+	MaxTags int
+
+	// ExistingTags are tags already in the system.
+	ExistingTags []string
+
+	// Language is the preferred language for tags (may be empty).
+	Language string
+}
+
+// tagPromptFuncs are the functions available to tag prompt templates.
+var tagPromptFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// parseTagPromptTemplate parses a user prompt template for tag suggestions.
+func parseTagPromptTemplate(text string) (*template.Template, error) {
+	return template.New("tag_prompt").Funcs(tagPromptFuncs).Option("missingkey=error").Parse(text)
+}
+
+// ValidateTagPromptTemplate checks that text parses as a tag prompt template
+// and only references fields of TagPromptData.
+func ValidateTagPromptTemplate(text string) error {
+	tmpl, err := parseTagPromptTemplate(text)
+	if err != nil {
+		return fmt.Errorf("invalid tag prompt template: %w", err)
+	}
+
+	// Executing against sample data catches references to unknown fields
+	sample := TagPromptData{Content: "content", MaxTags: 5, ExistingTags: []string{"tag"}, Language: "en"}
+	if err := tmpl.Execute(io.Discard, sample); err != nil {
+		return fmt.Errorf("invalid tag prompt template: %w", err)
+	}
+
+	return nil
+}
+
+// renderTagPrompt renders a tag prompt template with the given data.
+func renderTagPrompt(text string, data TagPromptData) (string, error) {
+	tmpl, err := parseTagPromptTemplate(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid tag prompt template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render tag prompt: %w", err)
+	}
+
+	return buf.String(), nil
+}
@@ -1,13 +1,20 @@
 package llm
 
 import (
+	"container/list"
 	"context"
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 )
 
 var (
@@ -16,6 +23,25 @@ var (
 
 	// ErrTagServiceNotConfigured indicates the tag service is not properly configured.
 	ErrTagServiceNotConfigured = errors.New("tag service not configured")
+
+	// ErrJobCanceled is the error recorded on a tag job canceled with CancelJob.
+	ErrJobCanceled = errors.New("tag job canceled")
+
+	// ErrJobFinished indicates a job can no longer be canceled because it has finished.
+	ErrJobFinished = errors.New("tag job already finished")
+
+	// ErrServiceStopping is the error recorded on queued jobs that were not
+	// processed before StopWithTimeout gave up draining the queue.
+	ErrServiceStopping = errors.New("service stopping")
+
+	// ErrJobQueueFull indicates the async job queue has no free slot.
+	ErrJobQueueFull = errors.New("job queue is full")
+
+	// ErrContentTooLarge indicates content longer than TagServiceConfig.MaxContentLength.
+	ErrContentTooLarge = errors.New("content too large for tag suggestions")
+
+	// ErrInvalidWorkerCount indicates a worker count below one, which would strand queued jobs.
+	ErrInvalidWorkerCount = errors.New("async worker count must be at least 1")
 )
 
 // TagServiceConfig holds configuration for the tag service.
@@ -23,6 +49,23 @@ type TagServiceConfig struct {
 	// MaxTagsPerRequest is the maximum number of tags to return per request.
 	MaxTagsPerRequest int
 
+	// Language is the preferred language for suggested tags (e.g., "en", "zh").
+	// Empty lets the model choose.
+	Language string
+
+	// AutoDetectLanguage sets the tag language from the content's script
+	// (Chinese, Japanese, Korean, Cyrillic or Latin) when Language is empty.
+	AutoDetectLanguage bool
+
+	// MaxContentLength is the maximum content size in bytes accepted for tag
+	// suggestions; longer content is rejected with ErrContentTooLarge before
+	// calling the LLM, unless TruncateOversized is set. 0 means no limit.
+	MaxContentLength int
+
+	// TruncateOversized truncates content longer than MaxContentLength
+	// instead of rejecting it.
+	TruncateOversized bool
+
 	// CacheTTL is how long to cache tag suggestions.
 	CacheTTL time.Duration
 
@@ -43,12 +86,66 @@ type TagServiceConfig struct {
 
 	// AsyncQueueSize is the size of the async job queue.
 	AsyncQueueSize int
+
+	// JobStore persists async jobs. Defaults to an in-memory store if nil.
+	JobStore JobStore
+
+	// JobTimeout bounds each attempt of an async tag job and each async
+	// summarize job. Defaults to 30 seconds if zero.
+	JobTimeout time.Duration
+
+	// TagPromptTemplate overrides the tag suggestion user prompt (text/template over
+	// TagPromptData). Empty uses the built-in prompt.
+	TagPromptTemplate string
+
+	// Model overrides the provider's default model for tag and summary requests,
+	// e.g. to use a cheaper model for automated jobs. Empty uses the provider default.
+	Model string
+
+	// JobRetryPolicy controls retries of async tag jobs that fail with a transient
+	// error (rate limiting, provider unavailability, retryable provider errors).
+	// Permanent errors such as ErrInvalidAPIKey fail the job immediately. When nil,
+	// jobs get 3 attempts with the default backoff. If ShouldRetry is set it
+	// replaces the transient check and is called with a status code of 0.
+	JobRetryPolicy *RetryPolicy
+
+	// AllowedTags restricts suggestions to a controlled vocabulary. Matching ignores
+	// case, treats spaces and underscores as hyphens and ignores a plural "s";
+	// surviving tags use the vocabulary's spelling. Empty allows any tag.
+	AllowedTags []string
+
+	// BlockedTags are never suggested (matched like AllowedTags).
+	BlockedTags []string
+
+	// TagFilter, if set, post-processes suggested tags after AllowedTags and
+	// BlockedTags are applied and before results are cached.
+	TagFilter func(tags []string) []string
+
+	// ProviderResolver, if set, picks the provider for each user's tag
+	// requests (see UserProviderResolver). Nil uses the service's active
+	// provider for everyone.
+	ProviderResolver *UserProviderResolver
+
+	// Clock supplies the current time for cache, rate-limit, and job bookkeeping.
+	// Defaults to the system clock if nil.
+	Clock Clock
+}
+
+// Validate checks the configuration for errors, such as a malformed TagPromptTemplate.
+func (c *TagServiceConfig) Validate() error {
+	if c.TagPromptTemplate != "" {
+		if err := ValidateTagPromptTemplate(c.TagPromptTemplate); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // DefaultTagServiceConfig returns the default configuration.
 func DefaultTagServiceConfig() *TagServiceConfig {
 	return &TagServiceConfig{
 		MaxTagsPerRequest: 5,
+		MaxContentLength:  16 * 1024,
 		CacheTTL:          15 * time.Minute,
 		MaxCacheSize:      1000,
 		RateLimitRequests: 60,
@@ -56,12 +153,25 @@ func DefaultTagServiceConfig() *TagServiceConfig {
 		EnableAsync:       true,
 		AsyncWorkers:      2,
 		AsyncQueueSize:    100,
+		JobTimeout:        defaultJobTimeout,
 	}
 }
 
+// defaultJobTimeout is used when TagServiceConfig.JobTimeout is zero.
+const defaultJobTimeout = 30 * time.Second
+
+// jobTimeout returns the configured async job timeout with the default applied.
+func (ts *TagService) jobTimeout() time.Duration {
+	if ts.config.JobTimeout > 0 {
+		return ts.config.JobTimeout
+	}
+	return defaultJobTimeout
+}
+
 // cachedTags represents a cached tag suggestion result.
 type cachedTags struct {
-	tags      []string
+	key       string
+	result    *SuggestTagsResponse
 	createdAt time.Time
 }
 
@@ -81,6 +191,7 @@ type TagJob struct {
 	Status       TagJobStatus
 	Result       *SuggestTagsResponse
 	Error        error
+	Attempts     int
 	CreatedAt    time.Time
 	CompletedAt  *time.Time
 }
@@ -98,14 +209,47 @@ const (
 // TagJobCallback is called when an async tag job completes.
 type TagJobCallback func(job *TagJob)
 
+// TagServiceMetrics is a snapshot of the tag service's cumulative counters.
+// Counters survive ClearCache and only reset on ResetMetrics.
+type TagServiceMetrics struct {
+	// CacheHits is the number of tag suggestions served from the cache.
+	CacheHits int64 `json:"cache_hits"`
+
+	// CacheMisses is the number of tag lookups not found in the cache (including expired entries).
+	CacheMisses int64 `json:"cache_misses"`
+
+	// CacheEvictions is the number of entries evicted to make room for new ones.
+	CacheEvictions int64 `json:"cache_evictions"`
+
+	// RateLimitRejections is the number of requests rejected by the rate limiter.
+	RateLimitRejections int64 `json:"rate_limit_rejections"`
+
+	// LLMCalls is the number of requests forwarded to the LLM service.
+	LLMCalls int64 `json:"llm_calls"`
+
+	// CacheSize is the current number of cached entries.
+	CacheSize int `json:"cache_size"`
+}
+
+// tagServiceCounters holds the atomic counters behind TagServiceMetrics.
+type tagServiceCounters struct {
+	cacheHits           atomic.Int64
+	cacheMisses         atomic.Int64
+	cacheEvictions      atomic.Int64
+	rateLimitRejections atomic.Int64
+	llmCalls            atomic.Int64
+}
+
 // TagService provides tag suggestion functionality with caching and rate limiting.
 type TagService struct {
 	llmService Service
 	config     *TagServiceConfig
+	clock      Clock
 
-	// Cache
-	cache   map[string]*cachedTags
-	cacheMu sync.RWMutex
+	// Cache (LRU: most recently used entries at the front of cacheList)
+	cache     map[string]*list.Element
+	cacheList *list.List
+	cacheMu   sync.Mutex
 
 	// Rate limiting
 	rateLimits   map[int32]*rateLimitEntry
@@ -113,11 +257,52 @@ type TagService struct {
 
 	// Async job handling
 	jobQueue    chan *TagJob
-	jobs        map[string]*TagJob
-	jobsMu      sync.RWMutex
+	jobStore    JobStore
 	jobCallback TagJobCallback
-	stopCh      chan struct{}
-	wg          sync.WaitGroup
+
+	// Cancellation: running jobs have a cancel func; canceled pending jobs are
+	// skipped when dequeued
+	jobCancels   map[string]context.CancelCauseFunc
+	canceledJobs map[string]bool
+	// activeJobs indexes pending and running jobs by memo and content so a
+	// duplicate request returns the existing job. Indexed jobs are never
+	// mutated; workers update their own copy and the job store
+	activeJobs map[string]*TagJob
+	jobsMu     sync.Mutex
+
+	// Async summarization handling (shares workers with tag jobs)
+	summarizeQueue    chan *SummarizeJob
+	summarizeJobs     map[string]*SummarizeJob
+	summarizeJobsMu   sync.RWMutex
+	summarizeCallback SummarizeJobCallback
+	summaryCache      map[string]*cachedSummary
+	summaryCacheMu    sync.RWMutex
+
+	titleCache   map[string]*cachedTitle
+	titleCacheMu sync.Mutex
+
+	vocabulary tagVocabulary
+
+	metrics tagServiceCounters
+
+	userUsage   map[int32]*UsageSummary
+	userUsageMu sync.Mutex
+
+	// drainCh tells workers to exit once the queues are empty; stopCh tells
+	// them to exit after their current job
+	drainCh chan struct{}
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+	// stopOnce makes Stop and StopWithTimeout safe to call more than once
+	stopOnce sync.Once
+
+	// workerQuits holds one channel per running worker; closing it retires
+	// that worker after its current job. workersMu also orders worker starts
+	// against Stop so wg.Add never races wg.Wait.
+	workerQuits    []chan struct{}
+	nextWorkerID   int
+	runningWorkers atomic.Int32
+	workersMu      sync.Mutex
 }
 
 // NewTagService creates a new tag service.
@@ -126,62 +311,236 @@ func NewTagService(llmService Service, config *TagServiceConfig) *TagService {
 		config = DefaultTagServiceConfig()
 	}
 
+	jobStore := config.JobStore
+	if jobStore == nil {
+		jobStore = NewInMemoryJobStore()
+	}
+
+	clock := config.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+
 	ts := &TagService{
 		llmService: llmService,
 		config:     config,
-		cache:      make(map[string]*cachedTags),
+		clock:      clock,
+		cache:      make(map[string]*list.Element),
+		cacheList:  list.New(),
 		rateLimits: make(map[int32]*rateLimitEntry),
-		jobs:       make(map[string]*TagJob),
+		vocabulary: newTagVocabulary(config),
+		jobStore:   jobStore,
+		drainCh:    make(chan struct{}),
 		stopCh:     make(chan struct{}),
+
+		jobCancels:   make(map[string]context.CancelCauseFunc),
+		canceledJobs: make(map[string]bool),
+		activeJobs:   make(map[string]*TagJob),
+
+		summarizeJobs: make(map[string]*SummarizeJob),
+		summaryCache:  make(map[string]*cachedSummary),
+		titleCache:    make(map[string]*cachedTitle),
+
+		userUsage: make(map[int32]*UsageSummary),
 	}
 
 	if config.EnableAsync {
 		ts.jobQueue = make(chan *TagJob, config.AsyncQueueSize)
+		ts.summarizeQueue = make(chan *SummarizeJob, config.AsyncQueueSize)
 		ts.startWorkers()
+		ts.resumePendingJobs()
 	}
 
 	return ts
 }
 
+// resumePendingJobs re-enqueues jobs that were pending or running when the service last stopped.
+func (ts *TagService) resumePendingJobs() {
+	jobs, err := ts.jobStore.ListJobs(context.Background())
+	if err != nil {
+		slog.Warn("Failed to load tag jobs from store", slog.Any("error", err))
+		return
+	}
+
+	resumed := 0
+	for _, job := range jobs {
+		if job.Status != TagJobStatusPending && job.Status != TagJobStatusRunning {
+			continue
+		}
+
+		job.Status = TagJobStatusPending
+		// The worker mutates its own copy; the indexed job is never modified
+		work := *job
+		select {
+		case ts.jobQueue <- &work:
+			ts.jobsMu.Lock()
+			ts.activeJobs[ts.activeJobKey(job.MemoID, job.Content, job.ExistingTags)] = job
+			ts.jobsMu.Unlock()
+			resumed++
+		default:
+			slog.Warn("Job queue full, tag job not resumed", slog.String("job_id", job.ID))
+		}
+	}
+
+	if resumed > 0 {
+		slog.Info("Resumed pending tag jobs", slog.Int("count", resumed))
+	}
+}
+
 // startWorkers starts the async job workers.
 func (ts *TagService) startWorkers() {
+	ts.workersMu.Lock()
 	for i := 0; i < ts.config.AsyncWorkers; i++ {
-		ts.wg.Add(1)
-		go ts.worker(i)
+		ts.spawnWorkerLocked()
 	}
+	ts.workersMu.Unlock()
 	slog.Info("Tag service async workers started",
 		slog.Int("workers", ts.config.AsyncWorkers))
 }
 
-// worker processes async tag jobs.
-func (ts *TagService) worker(id int) {
+// spawnWorkerLocked starts one worker. Callers hold workersMu.
+func (ts *TagService) spawnWorkerLocked() {
+	quit := make(chan struct{})
+	ts.workerQuits = append(ts.workerQuits, quit)
+	ts.wg.Add(1)
+	ts.runningWorkers.Add(1)
+	go ts.worker(ts.nextWorkerID, quit)
+	ts.nextWorkerID++
+}
+
+// SetAsyncWorkerCount scales the async workers to n while the service runs.
+// New workers start immediately; excess workers exit after finishing their
+// current job. It fails with ErrInvalidWorkerCount for n < 1 and with
+// ErrServiceStopping once Stop or StopWithTimeout has been called.
+func (ts *TagService) SetAsyncWorkerCount(n int) error {
+	if !ts.config.EnableAsync {
+		return errors.New("async tag generation is disabled")
+	}
+	if n < 1 {
+		return ErrInvalidWorkerCount
+	}
+
+	ts.workersMu.Lock()
+	defer ts.workersMu.Unlock()
+
+	if ts.stopping() {
+		return ErrServiceStopping
+	}
+
+	current := len(ts.workerQuits)
+	for i := current; i < n; i++ {
+		ts.spawnWorkerLocked()
+	}
+	for i := n; i < current; i++ {
+		close(ts.workerQuits[i])
+	}
+	if n < current {
+		ts.workerQuits = ts.workerQuits[:n]
+	}
+
+	if n != current {
+		slog.Info("Tag service async workers resized",
+			slog.Int("from", current),
+			slog.Int("to", n))
+	}
+	return nil
+}
+
+// AsyncWorkerCount returns the number of async workers currently configured.
+func (ts *TagService) AsyncWorkerCount() int {
+	ts.workersMu.Lock()
+	defer ts.workersMu.Unlock()
+	return len(ts.workerQuits)
+}
+
+// worker processes async tag and summarize jobs until the service stops or
+// quit is closed.
+func (ts *TagService) worker(id int, quit <-chan struct{}) {
 	defer ts.wg.Done()
+	defer ts.runningWorkers.Add(-1)
 
 	for {
+		// Don't pick up another job once stopping or retired, even if one is ready
+		select {
+		case <-ts.stopCh:
+			slog.Info("Tag service worker stopping", slog.Int("worker_id", id))
+			return
+		case <-quit:
+			slog.Info("Tag service worker retired", slog.Int("worker_id", id))
+			return
+		default:
+		}
+
 		select {
 		case job := <-ts.jobQueue:
 			ts.processJob(job)
+		case job := <-ts.summarizeQueue:
+			ts.processSummarizeJob(job)
 		case <-ts.stopCh:
 			slog.Info("Tag service worker stopping", slog.Int("worker_id", id))
 			return
+		case <-quit:
+			slog.Info("Tag service worker retired", slog.Int("worker_id", id))
+			return
+		case <-ts.drainCh:
+			if len(ts.jobQueue) == 0 && len(ts.summarizeQueue) == 0 {
+				slog.Info("Tag service worker drained", slog.Int("worker_id", id))
+				return
+			}
 		}
 	}
 }
 
 // processJob processes a single tag job.
 func (ts *TagService) processJob(job *TagJob) {
-	ts.updateJobStatus(job.ID, TagJobStatusRunning)
+	ctx, cancelCause := context.WithCancelCause(context.Background())
+	defer cancelCause(nil)
+
+	ts.jobsMu.Lock()
+	if ts.canceledJobs[job.ID] {
+		// CancelJob already recorded the job as failed and released it
+		delete(ts.canceledJobs, job.ID)
+		ts.jobsMu.Unlock()
+		return
+	}
+	ts.jobCancels[job.ID] = cancelCause
+	ts.jobsMu.Unlock()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	job.Status = TagJobStatusRunning
+	ts.saveJob(job)
+
+	policy, shouldRetry := ts.jobRetryPolicy()
+	var resultKey string
+	result, err := withRetry(ctx, policy, shouldRetry, func() (*SuggestTagsResponse, error) {
+		job.Attempts++
+		if job.Attempts > 1 {
+			slog.Info("Retrying tag job",
+				slog.String("job_id", job.ID),
+				slog.Int("attempt", job.Attempts))
+		}
 
-	result, err := ts.llmService.SuggestTags(ctx, &SuggestTagsRequest{
-		Content:      job.Content,
-		ExistingTags: job.ExistingTags,
-		MaxTags:      ts.config.MaxTagsPerRequest,
+		attemptCtx, cancel := context.WithTimeout(ctx, ts.jobTimeout())
+		defer cancel()
+
+		attemptCtx, err := ts.withUserProvider(attemptCtx, job.UserID)
+		if err != nil {
+			return nil, err
+		}
+		resultKey = ts.tagCacheKey(attemptCtx, job.Content, job.ExistingTags)
+
+		ts.metrics.llmCalls.Add(1)
+		return ts.llmService.SuggestTags(attemptCtx, ts.suggestTagsRequest(job.Content, job.ExistingTags))
 	})
+	if err != nil && errors.Is(context.Cause(ctx), ErrJobCanceled) {
+		err = ErrJobCanceled
+	}
 
-	now := time.Now()
+	ts.jobsMu.Lock()
+	delete(ts.jobCancels, job.ID)
+	ts.releaseActiveJobLocked(job)
+	ts.jobsMu.Unlock()
+
+	now := ts.clock.Now()
 	job.CompletedAt = &now
 
 	if err != nil {
@@ -190,44 +549,159 @@ func (ts *TagService) processJob(job *TagJob) {
 		slog.Error("Tag job failed",
 			slog.String("job_id", job.ID),
 			slog.Int("memo_id", int(job.MemoID)),
+			slog.Int("attempts", job.Attempts),
 			slog.String("error", err.Error()))
 	} else {
+		result = ts.applyTagFilter(result)
 		job.Status = TagJobStatusCompleted
 		job.Result = result
+		ts.recordUserUsage(job.UserID, result.Model, result.Usage)
 		// Cache the result
-		ts.cacheResult(job.Content, job.ExistingTags, result.Tags)
+		ts.cacheResult(resultKey, result)
 		slog.Info("Tag job completed",
 			slog.String("job_id", job.ID),
 			slog.Int("memo_id", int(job.MemoID)),
 			slog.Int("tags_count", len(result.Tags)))
 	}
 
-	ts.jobsMu.Lock()
-	ts.jobs[job.ID] = job
-	ts.jobsMu.Unlock()
+	ts.saveJob(job)
 
 	if ts.jobCallback != nil {
 		ts.jobCallback(job)
 	}
 }
 
-// updateJobStatus updates the status of a job.
-func (ts *TagService) updateJobStatus(jobID string, status TagJobStatus) {
-	ts.jobsMu.Lock()
-	defer ts.jobsMu.Unlock()
+// jobRetryPolicy returns the retry policy for async tag jobs with defaults applied.
+func (ts *TagService) jobRetryPolicy() (RetryPolicy, func(int, error) bool) {
+	var policy RetryPolicy
+	if ts.config.JobRetryPolicy != nil {
+		policy = *ts.config.JobRetryPolicy
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
 
-	if job, exists := ts.jobs[jobID]; exists {
-		job.Status = status
+	shouldRetry := policy.ShouldRetry
+	if shouldRetry == nil {
+		shouldRetry = func(_ int, err error) bool { return isTransientError(err) }
 	}
+	return policy.withDefaults(), shouldRetry
 }
 
-// Stop gracefully stops the tag service.
+// saveJob persists a job, logging on failure.
+func (ts *TagService) saveJob(job *TagJob) {
+	if err := ts.jobStore.SaveJob(context.Background(), job); err != nil {
+		slog.Warn("Failed to save tag job",
+			slog.String("job_id", job.ID),
+			slog.Any("error", err))
+	}
+}
+
+// stopping reports whether Stop or StopWithTimeout has been called.
+func (ts *TagService) stopping() bool {
+	select {
+	case <-ts.stopCh:
+		return true
+	case <-ts.drainCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop gracefully stops the tag service. Running jobs finish; queued jobs are
+// left pending so a persistent JobStore can resume them on the next start.
+// Only the first call to Stop or StopWithTimeout has any effect.
 func (ts *TagService) Stop() {
+	ts.stopOnce.Do(func() {
+		ts.workersMu.Lock()
+		close(ts.stopCh)
+		ts.workersMu.Unlock()
+		ts.wg.Wait()
+		slog.Info("Tag service stopped")
+	})
+}
+
+// StopWithTimeout stops the tag service after draining the job queues. Workers
+// keep processing queued jobs for up to d; jobs still queued after that are
+// marked failed with ErrServiceStopping and their callbacks are invoked.
+// Only the first call to Stop or StopWithTimeout has any effect.
+func (ts *TagService) StopWithTimeout(d time.Duration) {
+	ts.stopOnce.Do(func() { ts.stopWithTimeout(d) })
+}
+
+// stopWithTimeout implements StopWithTimeout.
+func (ts *TagService) stopWithTimeout(d time.Duration) {
+	ts.workersMu.Lock()
+	close(ts.drainCh)
+	ts.workersMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		ts.wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		slog.Warn("Tag service drain timed out",
+			slog.Duration("timeout", d),
+			slog.Int("queued_tag_jobs", len(ts.jobQueue)),
+			slog.Int("queued_summarize_jobs", len(ts.summarizeQueue)))
+	}
+
 	close(ts.stopCh)
-	ts.wg.Wait()
+	<-done
+
+	ts.failQueuedJobs()
 	slog.Info("Tag service stopped")
 }
 
+// failQueuedJobs marks every job left in the queues as failed with ErrServiceStopping.
+// It must only be called once the workers have exited.
+func (ts *TagService) failQueuedJobs() {
+	for {
+		select {
+		case job := <-ts.jobQueue:
+			ts.jobsMu.Lock()
+			canceled := ts.canceledJobs[job.ID]
+			delete(ts.canceledJobs, job.ID)
+			ts.releaseActiveJobLocked(job)
+			ts.jobsMu.Unlock()
+			// CancelJob already recorded the job as failed
+			if canceled {
+				continue
+			}
+
+			now := ts.clock.Now()
+			job.Status = TagJobStatusFailed
+			job.Error = ErrServiceStopping
+			job.CompletedAt = &now
+			ts.saveJob(job)
+
+			if ts.jobCallback != nil {
+				ts.jobCallback(job)
+			}
+		case job := <-ts.summarizeQueue:
+			now := ts.clock.Now()
+			job.Status = TagJobStatusFailed
+			job.Error = ErrServiceStopping
+			job.CompletedAt = &now
+			ts.storeSummarizeJob(job)
+
+			if ts.summarizeCallback != nil {
+				ts.summarizeCallback(job)
+			}
+		default:
+			return
+		}
+	}
+}
+
 // SetJobCallback sets the callback for job completion.
 func (ts *TagService) SetJobCallback(cb TagJobCallback) {
 	ts.jobCallback = cb
@@ -235,31 +709,42 @@ func (ts *TagService) SetJobCallback(cb TagJobCallback) {
 
 // SuggestTags suggests tags for the given content with caching and rate limiting.
 func (ts *TagService) SuggestTags(ctx context.Context, userID int32, content string, existingTags []string) (*SuggestTagsResponse, error) {
+	content, err := ts.limitContent(content)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check rate limit
 	if !ts.checkRateLimit(userID) {
 		return nil, ErrRateLimitExceeded
 	}
 
+	// The user's provider determines the model, which is part of the cache key
+	ctx, err = ts.withUserProvider(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check cache
-	if cached := ts.getFromCache(content, existingTags); cached != nil {
+	key := ts.tagCacheKey(ctx, content, existingTags)
+	if cached := ts.getFromCache(key); cached != nil {
 		slog.Debug("Tag suggestion cache hit",
 			slog.Int("user_id", int(userID)),
-			slog.Int("tags_count", len(cached)))
-		return &SuggestTagsResponse{Tags: cached}, nil
+			slog.Int("tags_count", len(cached.Tags)))
+		return cached, nil
 	}
 
 	// Call LLM service
-	result, err := ts.llmService.SuggestTags(ctx, &SuggestTagsRequest{
-		Content:      content,
-		ExistingTags: existingTags,
-		MaxTags:      ts.config.MaxTagsPerRequest,
-	})
+	ts.metrics.llmCalls.Add(1)
+	result, err := ts.llmService.SuggestTags(ctx, ts.suggestTagsRequest(content, existingTags))
 	if err != nil {
 		return nil, err
 	}
+	ts.recordUserUsage(userID, result.Model, result.Usage)
+	result = ts.applyTagFilter(result)
 
 	// Cache the result
-	ts.cacheResult(content, existingTags, result.Tags)
+	ts.cacheResult(key, result)
 
 	slog.Info("Tag suggestion generated",
 		slog.Int("user_id", int(userID)),
@@ -268,21 +753,94 @@ func (ts *TagService) SuggestTags(ctx context.Context, userID int32, content str
 	return result, nil
 }
 
-// SuggestTagsAsync queues an async tag suggestion job.
+// limitContent applies MaxContentLength to content, truncating it at a rune
+// boundary if TruncateOversized is set and returning ErrContentTooLarge otherwise.
+func (ts *TagService) limitContent(content string) (string, error) {
+	limit := ts.config.MaxContentLength
+	if limit <= 0 || len(content) <= limit {
+		return content, nil
+	}
+	if !ts.config.TruncateOversized {
+		return "", fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrContentTooLarge, len(content), limit)
+	}
+
+	end := limit
+	for end > 0 && !utf8.RuneStart(content[end]) {
+		end--
+	}
+	slog.Debug("Truncated oversized tag content",
+		slog.Int("length", len(content)),
+		slog.Int("limit", limit))
+	return content[:end], nil
+}
+
+// suggestTagsRequest builds an LLM tag request for content using the service config.
+func (ts *TagService) suggestTagsRequest(content string, existingTags []string) *SuggestTagsRequest {
+	return &SuggestTagsRequest{
+		Content:        content,
+		ExistingTags:   existingTags,
+		MaxTags:        ts.config.MaxTagsPerRequest,
+		Language:       ts.tagLanguage(content),
+		PromptTemplate: ts.config.TagPromptTemplate,
+		Model:          ts.config.Model,
+	}
+}
+
+// SuggestTagsAsync queues an async tag suggestion job. It fails immediately
+// with ErrJobQueueFull if the queue is full.
 func (ts *TagService) SuggestTagsAsync(userID int32, memoID int32, content string, existingTags []string) (*TagJob, error) {
+	return ts.suggestTagsAsync(context.Background(), false, userID, memoID, content, existingTags)
+}
+
+// SuggestTagsAsyncWait is like SuggestTagsAsync but, when the queue is full,
+// waits for a free slot until ctx is done. It then fails with an error wrapping
+// both ErrJobQueueFull and the context error (e.g., context.DeadlineExceeded).
+func (ts *TagService) SuggestTagsAsyncWait(ctx context.Context, userID int32, memoID int32, content string, existingTags []string) (*TagJob, error) {
+	return ts.suggestTagsAsync(ctx, true, userID, memoID, content, existingTags)
+}
+
+// suggestTagsAsync queues a tag job. If wait is set and the queue is full, it
+// waits for a slot until waitCtx is done.
+func (ts *TagService) suggestTagsAsync(waitCtx context.Context, wait bool, userID int32, memoID int32, content string, existingTags []string) (*TagJob, error) {
 	if !ts.config.EnableAsync {
 		return nil, errors.New("async tag generation is disabled")
 	}
+	// Workers are gone once stopped, so a queued job would never run
+	if ts.stopping() {
+		return nil, ErrServiceStopping
+	}
+
+	content, err := ts.limitContent(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// An identical pending or running job for the memo is shared rather than
+	// repeated, and doesn't count against the rate limit
+	key := ts.activeJobKey(memoID, content, existingTags)
+	ts.jobsMu.Lock()
+	if existing, ok := ts.activeJobs[key]; ok {
+		ts.jobsMu.Unlock()
+		slog.Debug("Tag job deduplicated",
+			slog.String("job_id", existing.ID),
+			slog.Int("memo_id", int(memoID)))
+		return ts.jobSnapshot(existing), nil
+	}
+	ts.jobsMu.Unlock()
 
 	// Check rate limit
 	if !ts.checkRateLimit(userID) {
 		return nil, ErrRateLimitExceeded
 	}
 
-	// Check cache first
-	if cached := ts.getFromCache(content, existingTags); cached != nil {
+	// Check cache first, keyed on the model of the provider the job would use
+	providerCtx, err := ts.withUserProvider(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+	if cached := ts.getFromCache(ts.tagCacheKey(providerCtx, content, existingTags)); cached != nil {
 		// Return completed job immediately
-		now := time.Now()
+		now := ts.clock.Now()
 		job := &TagJob{
 			ID:           generateJobID(memoID, content),
 			MemoID:       memoID,
@@ -290,10 +848,11 @@ func (ts *TagService) SuggestTagsAsync(userID int32, memoID int32, content strin
 			ExistingTags: existingTags,
 			UserID:       userID,
 			Status:       TagJobStatusCompleted,
-			Result:       &SuggestTagsResponse{Tags: cached},
+			Result:       cached,
 			CreatedAt:    now,
 			CompletedAt:  &now,
 		}
+		ts.saveJob(job)
 		return job, nil
 	}
 
@@ -304,133 +863,302 @@ func (ts *TagService) SuggestTagsAsync(userID int32, memoID int32, content strin
 		ExistingTags: existingTags,
 		UserID:       userID,
 		Status:       TagJobStatusPending,
-		CreatedAt:    time.Now(),
+		CreatedAt:    ts.clock.Now(),
 	}
 
+	// Re-check under the lock: a concurrent caller may have registered the job
+	// since the check above
 	ts.jobsMu.Lock()
-	ts.jobs[job.ID] = job
+	if existing, ok := ts.activeJobs[key]; ok {
+		ts.jobsMu.Unlock()
+		return ts.jobSnapshot(existing), nil
+	}
+	ts.activeJobs[key] = job
 	ts.jobsMu.Unlock()
 
+	if err := ts.jobStore.SaveJob(context.Background(), job); err != nil {
+		ts.releaseActiveJob(job)
+		return nil, fmt.Errorf("failed to save tag job: %w", err)
+	}
+
+	// The worker mutates its own copy, so job stays safe to return and index
+	work := *job
 	select {
-	case ts.jobQueue <- job:
+	case ts.jobQueue <- &work:
 		slog.Info("Tag job queued",
 			slog.String("job_id", job.ID),
 			slog.Int("memo_id", int(memoID)))
 		return job, nil
 	default:
-		return nil, errors.New("job queue is full")
 	}
+
+	err = ErrJobQueueFull
+	if wait {
+		select {
+		case ts.jobQueue <- &work:
+			slog.Info("Tag job queued after waiting",
+				slog.String("job_id", job.ID),
+				slog.Int("memo_id", int(memoID)))
+			return job, nil
+		case <-ts.stopCh:
+			err = ErrServiceStopping
+		case <-waitCtx.Done():
+			err = fmt.Errorf("%w: %w", ErrJobQueueFull, waitCtx.Err())
+		}
+	}
+
+	// The job never ran, so don't leave it pending in the store to be resumed
+	ts.releaseActiveJob(job)
+	if deleteErr := ts.jobStore.DeleteJob(context.Background(), job.ID); deleteErr != nil {
+		slog.Warn("Failed to delete unqueued tag job", slog.String("job_id", job.ID), slog.Any("error", deleteErr))
+	}
+	return nil, err
+}
+
+// activeJobKey identifies a tag job by memo and by the inputs that determine
+// its result.
+func (ts *TagService) activeJobKey(memoID int32, content string, existingTags []string) string {
+	return fmt.Sprintf("%d:%s", memoID, cacheKey(ts.suggestTagsRequest(content, existingTags)))
+}
+
+// jobSnapshot returns the latest stored state of an indexed job, falling back
+// to a copy of the job as queued if the store has no record of it.
+func (ts *TagService) jobSnapshot(job *TagJob) *TagJob {
+	if stored, ok := ts.GetJob(job.ID); ok {
+		return stored
+	}
+	snapshot := *job
+	return &snapshot
+}
+
+// releaseActiveJob removes job from the active job index.
+func (ts *TagService) releaseActiveJob(job *TagJob) {
+	ts.jobsMu.Lock()
+	ts.releaseActiveJobLocked(job)
+	ts.jobsMu.Unlock()
+}
+
+// releaseActiveJobLocked removes job from the active job index if it is the
+// indexed job for its key. Callers must hold ts.jobsMu.
+func (ts *TagService) releaseActiveJobLocked(job *TagJob) {
+	key := ts.activeJobKey(job.MemoID, job.Content, job.ExistingTags)
+	if active, ok := ts.activeJobs[key]; ok && active.ID == job.ID {
+		delete(ts.activeJobs, key)
+	}
+}
+
+// PreviewTagPrompt returns the system and user prompts SuggestTags would send
+// for the content, without calling the LLM. It honors the configured
+// TagPromptTemplate, MaxTagsPerRequest and (detected) Language; provider-level template
+// overrides and JSON-mode instructions are not reflected.
+func (ts *TagService) PreviewTagPrompt(content string, existingTags []string) (system, user string) {
+	req := &SuggestTagsRequest{
+		Content:      content,
+		ExistingTags: existingTags,
+		MaxTags:      ts.config.MaxTagsPerRequest,
+		Language:     ts.tagLanguage(content),
+	}
+
+	system, user, err := buildTagPrompts(req, ts.config.TagPromptTemplate)
+	if err != nil {
+		// Validate rejects templates that fail to parse, so this is a render error
+		slog.Warn("Failed to render tag prompt template, previewing the built-in prompt",
+			slog.String("error", err.Error()))
+		system, user, _ = buildTagPrompts(req, "")
+	}
+	return system, user
+}
+
+// CancelJob cancels an async tag job. A pending job is skipped when a worker
+// dequeues it; a running job has its LLM call aborted. Either way the job is
+// recorded as failed with ErrJobCanceled. Returns ErrJobNotFound for unknown
+// jobs and ErrJobFinished for jobs that have already completed or failed.
+func (ts *TagService) CancelJob(jobID string) error {
+	ts.jobsMu.Lock()
+	if cancel, running := ts.jobCancels[jobID]; running {
+		ts.jobsMu.Unlock()
+		cancel(ErrJobCanceled)
+		slog.Info("Tag job canceled", slog.String("job_id", jobID), slog.String("status", string(TagJobStatusRunning)))
+		return nil
+	}
+
+	// The store returns a copy, so job is this call's own snapshot
+	job, err := ts.jobStore.GetJob(context.Background(), jobID)
+	if err != nil {
+		ts.jobsMu.Unlock()
+		return err
+	}
+	// A running job without a cancel func is already recording its result
+	if job.Status != TagJobStatusPending {
+		ts.jobsMu.Unlock()
+		return ErrJobFinished
+	}
+
+	ts.canceledJobs[jobID] = true
+	ts.releaseActiveJobLocked(job)
+
+	now := ts.clock.Now()
+	job.Status = TagJobStatusFailed
+	job.Error = ErrJobCanceled
+	job.CompletedAt = &now
+	ts.saveJob(job)
+	ts.jobsMu.Unlock()
+
+	slog.Info("Tag job canceled", slog.String("job_id", jobID), slog.String("status", string(TagJobStatusPending)))
+
+	// Called without jobsMu so the callback may use the service
+	if ts.jobCallback != nil {
+		ts.jobCallback(job)
+	}
+	return nil
 }
 
 // GetJob retrieves a job by ID.
 func (ts *TagService) GetJob(jobID string) (*TagJob, bool) {
-	ts.jobsMu.RLock()
-	defer ts.jobsMu.RUnlock()
-
-	job, exists := ts.jobs[jobID]
-	return job, exists
+	job, err := ts.jobStore.GetJob(context.Background(), jobID)
+	if err != nil {
+		if !errors.Is(err, ErrJobNotFound) {
+			slog.Warn("Failed to get tag job", slog.String("job_id", jobID), slog.Any("error", err))
+		}
+		return nil, false
+	}
+	return job, true
 }
 
+// jobIDCounter is mixed into every job ID so that jobs created with the same
+// content in the same clock tick still get distinct IDs.
+var jobIDCounter atomic.Uint64
+
 // generateJobID creates a unique job ID.
 func generateJobID(memoID int32, content string) string {
+	var seq [8]byte
+	binary.BigEndian.PutUint64(seq[:], jobIDCounter.Add(1))
+
 	h := sha256.New()
 	h.Write([]byte(content))
 	h.Write([]byte{byte(memoID >> 24), byte(memoID >> 16), byte(memoID >> 8), byte(memoID)})
 	h.Write([]byte(time.Now().String()))
-	return hex.EncodeToString(h.Sum(nil))[:16]
+	h.Write(seq[:])
+	return hex.EncodeToString(h.Sum(nil))[:24]
 }
 
-// cacheKey generates a cache key from content and existing tags.
-func cacheKey(content string, existingTags []string) string {
+// cacheKey generates a cache key from the request inputs that determine the
+// suggested tags: content, existing tags, language, tag limit and model.
+func cacheKey(req *SuggestTagsRequest) string {
 	h := sha256.New()
-	h.Write([]byte(content))
-	for _, tag := range existingTags {
+	// Terminate every field so that shifting bytes between content and tags,
+	// or between tags, changes the key
+	h.Write([]byte(req.Content))
+	h.Write([]byte{0})
+	for _, tag := range req.ExistingTags {
 		h.Write([]byte(tag))
+		h.Write([]byte{0})
 	}
+	h.Write([]byte{0})
+	h.Write([]byte(req.Language))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(req.MaxTags)))
+	h.Write([]byte{0})
+	h.Write([]byte(req.Model))
 	return hex.EncodeToString(h.Sum(nil))[:32]
 }
 
-// getFromCache retrieves tags from cache if available and not expired.
-func (ts *TagService) getFromCache(content string, existingTags []string) []string {
-	key := cacheKey(content, existingTags)
+// tagCacheKey returns the cache key for a tag request for content, using the
+// model it resolves to under ctx (see resolvedModel).
+func (ts *TagService) tagCacheKey(ctx context.Context, content string, existingTags []string) string {
+	req := ts.suggestTagsRequest(content, existingTags)
+	req.Model = ts.resolvedModel(ctx)
+	return cacheKey(req)
+}
 
-	ts.cacheMu.RLock()
-	defer ts.cacheMu.RUnlock()
+// resolvedModel returns the model a tag request uses under ctx: the configured
+// Model, else the default model of the provider overriding ctx or the active
+// provider.
+func (ts *TagService) resolvedModel(ctx context.Context) string {
+	if ts.config.Model != "" {
+		return ts.config.Model
+	}
+	provider := providerOverride(ctx)
+	if provider == nil {
+		provider = ts.llmService.GetProvider()
+	}
+	if provider == nil {
+		return ""
+	}
+	return provider.GetDefaultModel()
+}
 
-	cached, exists := ts.cache[key]
+// getFromCache retrieves tags from cache if available and not expired.
+// A hit marks the entry as most recently used.
+func (ts *TagService) getFromCache(key string) *SuggestTagsResponse {
+	ts.cacheMu.Lock()
+	defer ts.cacheMu.Unlock()
+
+	elem, exists := ts.cache[key]
 	if !exists {
+		ts.metrics.cacheMisses.Add(1)
 		return nil
 	}
 
-	if time.Since(cached.createdAt) > ts.config.CacheTTL {
+	cached := elem.Value.(*cachedTags)
+	if ts.clock.Now().Sub(cached.createdAt) > ts.config.CacheTTL {
+		ts.removeCacheElement(elem)
+		ts.metrics.cacheMisses.Add(1)
 		return nil
 	}
 
-	// Return a copy to prevent modification
-	result := make([]string, len(cached.tags))
-	copy(result, cached.tags)
+	ts.cacheList.MoveToFront(elem)
+	ts.metrics.cacheHits.Add(1)
+
+	// Return a copy to prevent modification; the cached result cost nothing this time
+	result := cloneSuggestTagsResponse(cached.result)
+	result.Usage = nil
 	return result
 }
 
-// cacheResult stores tags in the cache.
-func (ts *TagService) cacheResult(content string, existingTags []string, tags []string) {
-	key := cacheKey(content, existingTags)
-
+// cacheResult stores a copy of the tag suggestion result in the cache under key.
+func (ts *TagService) cacheResult(key string, result *SuggestTagsResponse) {
 	ts.cacheMu.Lock()
 	defer ts.cacheMu.Unlock()
 
-	// Evict old entries if cache is full
-	if len(ts.cache) >= ts.config.MaxCacheSize {
-		ts.evictOldestEntries()
+	entry := &cachedTags{
+		key:       key,
+		result:    cloneSuggestTagsResponse(result),
+		createdAt: ts.clock.Now(),
 	}
 
-	ts.cache[key] = &cachedTags{
-		tags:      tags,
-		createdAt: time.Now(),
+	if elem, exists := ts.cache[key]; exists {
+		elem.Value = entry
+		ts.cacheList.MoveToFront(elem)
+		return
 	}
-}
 
-// evictOldestEntries removes the oldest cache entries.
-func (ts *TagService) evictOldestEntries() {
-	// Remove expired entries first
-	now := time.Now()
-	for key, entry := range ts.cache {
-		if now.Sub(entry.createdAt) > ts.config.CacheTTL {
-			delete(ts.cache, key)
-		}
+	// Evict least recently used entries if cache is full
+	for len(ts.cache) >= ts.config.MaxCacheSize && ts.cacheList.Len() > 0 {
+		ts.removeCacheElement(ts.cacheList.Back())
+		ts.metrics.cacheEvictions.Add(1)
 	}
 
-	// If still over limit, remove oldest entries
-	if len(ts.cache) >= ts.config.MaxCacheSize {
-		// Find and remove the 10% oldest entries
-		toRemove := ts.config.MaxCacheSize / 10
-		if toRemove < 1 {
-			toRemove = 1
-		}
-
-		type keyTime struct {
-			key       string
-			createdAt time.Time
-		}
-		entries := make([]keyTime, 0, len(ts.cache))
-		for key, entry := range ts.cache {
-			entries = append(entries, keyTime{key, entry.createdAt})
-		}
+	ts.cache[key] = ts.cacheList.PushFront(entry)
+}
 
-		// Sort by creation time and remove oldest
-		for i := 0; i < toRemove && i < len(entries); i++ {
-			oldest := i
-			for j := i + 1; j < len(entries); j++ {
-				if entries[j].createdAt.Before(entries[oldest].createdAt) {
-					oldest = j
-				}
-			}
-			if oldest != i {
-				entries[i], entries[oldest] = entries[oldest], entries[i]
-			}
-			delete(ts.cache, entries[i].key)
-		}
+// cloneSuggestTagsResponse returns a deep copy of result.
+func cloneSuggestTagsResponse(result *SuggestTagsResponse) *SuggestTagsResponse {
+	clone := *result
+	clone.Tags = slices.Clone(result.Tags)
+	clone.Confidence = slices.Clone(result.Confidence)
+	clone.NewTags = slices.Clone(result.NewTags)
+	if result.Usage != nil {
+		usage := *result.Usage
+		clone.Usage = &usage
 	}
+	return &clone
+}
+
+// removeCacheElement removes an entry from both the LRU list and the index.
+func (ts *TagService) removeCacheElement(elem *list.Element) {
+	ts.cacheList.Remove(elem)
+	delete(ts.cache, elem.Value.(*cachedTags).key)
 }
 
 // checkRateLimit checks if the user has exceeded the rate limit.
@@ -438,7 +1166,7 @@ func (ts *TagService) checkRateLimit(userID int32) bool {
 	ts.rateLimitsMu.Lock()
 	defer ts.rateLimitsMu.Unlock()
 
-	now := time.Now()
+	now := ts.clock.Now()
 	entry, exists := ts.rateLimits[userID]
 
 	if !exists || now.After(entry.windowEnd) {
@@ -451,6 +1179,7 @@ func (ts *TagService) checkRateLimit(userID int32) bool {
 	}
 
 	if entry.count >= ts.config.RateLimitRequests {
+		ts.metrics.rateLimitRejections.Add(1)
 		return false
 	}
 
@@ -463,7 +1192,7 @@ func (ts *TagService) GetRateLimitStatus(userID int32) (remaining int, resetAt t
 	ts.rateLimitsMu.Lock()
 	defer ts.rateLimitsMu.Unlock()
 
-	now := time.Now()
+	now := ts.clock.Now()
 	entry, exists := ts.rateLimits[userID]
 
 	if !exists || now.After(entry.windowEnd) {
@@ -482,37 +1211,67 @@ func (ts *TagService) ClearCache() {
 	ts.cacheMu.Lock()
 	defer ts.cacheMu.Unlock()
 
-	ts.cache = make(map[string]*cachedTags)
+	ts.cache = make(map[string]*list.Element)
+	ts.cacheList = list.New()
 	slog.Info("Tag service cache cleared")
 }
 
 // GetCacheStats returns cache statistics.
 func (ts *TagService) GetCacheStats() (size int, maxSize int) {
-	ts.cacheMu.RLock()
-	defer ts.cacheMu.RUnlock()
+	ts.cacheMu.Lock()
+	defer ts.cacheMu.Unlock()
 
 	return len(ts.cache), ts.config.MaxCacheSize
 }
 
-// CleanupExpiredJobs removes old completed/failed jobs.
+// Metrics returns a snapshot of the cumulative counters and the current cache size.
+func (ts *TagService) Metrics() TagServiceMetrics {
+	size, _ := ts.GetCacheStats()
+	return TagServiceMetrics{
+		CacheHits:           ts.metrics.cacheHits.Load(),
+		CacheMisses:         ts.metrics.cacheMisses.Load(),
+		CacheEvictions:      ts.metrics.cacheEvictions.Load(),
+		RateLimitRejections: ts.metrics.rateLimitRejections.Load(),
+		LLMCalls:            ts.metrics.llmCalls.Load(),
+		CacheSize:           size,
+	}
+}
+
+// ResetMetrics zeroes the cumulative counters. The cache itself is unaffected.
+func (ts *TagService) ResetMetrics() {
+	ts.metrics.cacheHits.Store(0)
+	ts.metrics.cacheMisses.Store(0)
+	ts.metrics.cacheEvictions.Store(0)
+	ts.metrics.rateLimitRejections.Store(0)
+	ts.metrics.llmCalls.Store(0)
+}
+
+// CleanupExpiredJobs removes old completed/failed tag and summarize jobs.
 func (ts *TagService) CleanupExpiredJobs(maxAge time.Duration) int {
-	ts.jobsMu.Lock()
-	defer ts.jobsMu.Unlock()
+	now := ts.clock.Now()
+	removed := ts.cleanupExpiredSummarizeJobs(now, maxAge)
 
-	now := time.Now()
-	removed := 0
+	ctx := context.Background()
+	jobs, err := ts.jobStore.ListJobs(ctx)
+	if err != nil {
+		slog.Warn("Failed to list tag jobs for cleanup", slog.Any("error", err))
+		return removed
+	}
 
-	for id, job := range ts.jobs {
+	for _, job := range jobs {
 		if job.Status == TagJobStatusCompleted || job.Status == TagJobStatusFailed {
 			if job.CompletedAt != nil && now.Sub(*job.CompletedAt) > maxAge {
-				delete(ts.jobs, id)
+				if err := ts.jobStore.DeleteJob(ctx, job.ID); err != nil {
+					slog.Warn("Failed to delete tag job", slog.String("job_id", job.ID), slog.Any("error", err))
+					continue
+				}
 				removed++
 			}
 		}
 	}
 
 	if removed > 0 {
-		slog.Info("Cleaned up expired tag jobs", slog.Int("removed", removed))
+		slog.Info("Cleaned up expired jobs", slog.Int("removed", removed))
 	}
 
 	return removed
@@ -2,6 +2,10 @@ package llm
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -10,15 +14,46 @@ import (
 
 // mockLLMService implements Service interface for testing.
 type mockLLMService struct {
-	suggestTagsFunc func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error)
-	callCount       int32
-	mu              sync.Mutex
+	suggestTagsFunc  func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error)
+	summarizeFunc    func(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error)
+	suggestTitleFunc func(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error)
+	streamFunc       func(ctx context.Context, req *CompletionRequest) (<-chan CompletionChunk, error)
+	callCount        int32
+	mu               sync.Mutex
 }
 
 func (m *mockLLMService) RegisterProvider(provider Provider) error {
 	return nil
 }
 
+func (m *mockLLMService) DeregisterProvider(providerType ProviderType) error {
+	return nil
+}
+
+func (m *mockLLMService) LastResponses(n int) []RawResponse {
+	return nil
+}
+
+func (m *mockLLMService) TestProvider(ctx context.Context, providerType ProviderType) error {
+	return nil
+}
+
+func (m *mockLLMService) TestStoredKey(ctx context.Context, keys KeyStorageService, userID int32, providerType ProviderType) error {
+	return nil
+}
+
+func (m *mockLLMService) Shutdown() error {
+	return nil
+}
+
+func (m *mockLLMService) RegisterAndValidate(ctx context.Context, provider Provider, live bool) error {
+	return nil
+}
+
+func (m *mockLLMService) RegisterProviderInstance(id string, provider Provider, weight int) error {
+	return nil
+}
+
 func (m *mockLLMService) GetProvider() Provider {
 	return nil
 }
@@ -35,6 +70,10 @@ func (m *mockLLMService) ListProviders() []ProviderStatus {
 	return nil
 }
 
+func (m *mockLLMService) ListProvidersWithHealth(ctx context.Context) []ProviderStatus {
+	return nil
+}
+
 func (m *mockLLMService) IsConfigured(ctx context.Context) bool {
 	return true
 }
@@ -43,6 +82,13 @@ func (m *mockLLMService) Complete(ctx context.Context, req *CompletionRequest) (
 	return nil, nil
 }
 
+func (m *mockLLMService) CompleteStream(ctx context.Context, req *CompletionRequest) (<-chan CompletionChunk, error) {
+	if m.streamFunc != nil {
+		return m.streamFunc(ctx, req)
+	}
+	return nil, ErrCapabilityNotSupported
+}
+
 func (m *mockLLMService) Embed(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
 	return nil, nil
 }
@@ -58,9 +104,59 @@ func (m *mockLLMService) SuggestTags(ctx context.Context, req *SuggestTagsReques
 }
 
 func (m *mockLLMService) Summarize(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error) {
+	atomic.AddInt32(&m.callCount, 1)
+	if m.summarizeFunc != nil {
+		return m.summarizeFunc(ctx, req)
+	}
+	return &SummarizeResponse{
+		Summary: "summary",
+	}, nil
+}
+
+func (m *mockLLMService) SuggestTitle(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error) {
+	atomic.AddInt32(&m.callCount, 1)
+	if m.suggestTitleFunc != nil {
+		return m.suggestTitleFunc(ctx, req)
+	}
+	return &SuggestTitleResponse{Title: "Title"}, nil
+}
+
+func (m *mockLLMService) AnalyzeMemo(ctx context.Context, req *AnalyzeMemoRequest) (*AnalyzeMemoResponse, error) {
+	return nil, nil
+}
+
+func (m *mockLLMService) GetUsageStats() UsageStats {
+	return UsageStats{}
+}
+
+func (m *mockLLMService) OperationMetrics() []OpMetric {
+	return nil
+}
+
+func (m *mockLLMService) EmbedCacheStats() EmbedCacheStats {
+	return EmbedCacheStats{}
+}
+
+func (m *mockLLMService) ActiveCapabilities() ProviderCapabilities {
+	return ProviderCapabilities{}
+}
+
+func (m *mockLLMService) Rerank(ctx context.Context, query string, docs []string, topN int) ([]RerankResult, error) {
 	return nil, nil
 }
 
+func (m *mockLLMService) HealthCheckAll(ctx context.Context) map[ProviderType]error {
+	return nil
+}
+
+func (m *mockLLMService) Warmup(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockLLMService) SetSystemPreamble(preamble string) {}
+
+func (m *mockLLMService) SetProviderChangeListener(listener ProviderChangeListener) {}
+
 func (m *mockLLMService) GetCallCount() int32 {
 	return atomic.LoadInt32(&m.callCount)
 }
@@ -209,6 +305,7 @@ func TestSuggestTags_CacheWithDifferentTags(t *testing.T) {
 
 func TestSuggestTags_RateLimiting(t *testing.T) {
 	mock := &mockLLMService{}
+	clock := NewFakeClock(time.Now())
 	ts := NewTagService(mock, &TagServiceConfig{
 		MaxTagsPerRequest: 5,
 		CacheTTL:          1 * time.Millisecond, // Very short TTL to avoid caching
@@ -216,6 +313,7 @@ func TestSuggestTags_RateLimiting(t *testing.T) {
 		RateLimitRequests: 3,
 		RateLimitWindow:   time.Minute,
 		EnableAsync:       false,
+		Clock:             clock,
 	})
 	defer ts.Stop()
 
@@ -225,7 +323,7 @@ func TestSuggestTags_RateLimiting(t *testing.T) {
 	for i := 0; i < 3; i++ {
 		// Use different content each time to avoid cache
 		content := "content " + string(rune('a'+i))
-		time.Sleep(5 * time.Millisecond) // Wait for cache to expire
+		clock.Advance(5 * time.Millisecond) // Expire the cache
 		_, err := ts.SuggestTags(ctx, 1, content, nil)
 		if err != nil {
 			t.Errorf("Request %d should succeed: %v", i+1, err)
@@ -233,15 +331,22 @@ func TestSuggestTags_RateLimiting(t *testing.T) {
 	}
 
 	// 4th request should fail due to rate limit
-	time.Sleep(5 * time.Millisecond) // Wait for cache to expire
+	clock.Advance(5 * time.Millisecond) // Expire the cache
 	_, err := ts.SuggestTags(ctx, 1, "content d", nil)
 	if err != ErrRateLimitExceeded {
 		t.Errorf("Expected ErrRateLimitExceeded, got %v", err)
 	}
+
+	// A new window starts once the current one has passed
+	clock.Advance(time.Minute)
+	if _, err := ts.SuggestTags(ctx, 1, "content d", nil); err != nil {
+		t.Errorf("Request in a new window should succeed: %v", err)
+	}
 }
 
 func TestSuggestTags_RateLimitPerUser(t *testing.T) {
 	mock := &mockLLMService{}
+	clock := NewFakeClock(time.Now())
 	ts := NewTagService(mock, &TagServiceConfig{
 		MaxTagsPerRequest: 5,
 		CacheTTL:          1 * time.Millisecond,
@@ -249,6 +354,7 @@ func TestSuggestTags_RateLimitPerUser(t *testing.T) {
 		RateLimitRequests: 2,
 		RateLimitWindow:   time.Minute,
 		EnableAsync:       false,
+		Clock:             clock,
 	})
 	defer ts.Stop()
 
@@ -256,7 +362,7 @@ func TestSuggestTags_RateLimitPerUser(t *testing.T) {
 
 	// User 1 makes 2 requests
 	for i := 0; i < 2; i++ {
-		time.Sleep(5 * time.Millisecond)
+		clock.Advance(5 * time.Millisecond)
 		_, err := ts.SuggestTags(ctx, 1, "user1 content "+string(rune('a'+i)), nil)
 		if err != nil {
 			t.Errorf("User 1 request %d should succeed: %v", i+1, err)
@@ -264,7 +370,7 @@ func TestSuggestTags_RateLimitPerUser(t *testing.T) {
 	}
 
 	// User 1's 3rd request should fail
-	time.Sleep(5 * time.Millisecond)
+	clock.Advance(5 * time.Millisecond)
 	_, err := ts.SuggestTags(ctx, 1, "user1 content c", nil)
 	if err != ErrRateLimitExceeded {
 		t.Errorf("User 1's 3rd request should be rate limited")
@@ -531,6 +637,45 @@ func TestCleanupExpiredJobs(t *testing.T) {
 	}
 }
 
+func TestCleanupExpiredJobs_FakeClock(t *testing.T) {
+	mock := &mockLLMService{}
+	clock := NewFakeClock(time.Now())
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    10,
+		Clock:             clock,
+	})
+	defer ts.Stop()
+
+	// Warm the cache so the async job completes immediately
+	ctx := context.Background()
+	if _, err := ts.SuggestTags(ctx, 1, "Cleanup test", nil); err != nil {
+		t.Fatalf("SuggestTags failed: %v", err)
+	}
+	job, err := ts.SuggestTagsAsync(1, 100, "Cleanup test", nil)
+	if err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+	if job.Status != TagJobStatusCompleted {
+		t.Fatalf("Expected cached job to complete immediately, got %s", job.Status)
+	}
+
+	if removed := ts.CleanupExpiredJobs(time.Hour); removed != 0 {
+		t.Errorf("Expected no jobs removed before max age, got %d", removed)
+	}
+
+	clock.Advance(2 * time.Hour)
+	if removed := ts.CleanupExpiredJobs(time.Hour); removed != 1 {
+		t.Errorf("Expected 1 job removed after max age, got %d", removed)
+	}
+}
+
 func TestConcurrentAccess(t *testing.T) {
 	mock := &mockLLMService{}
 	ts := NewTagService(mock, &TagServiceConfig{
@@ -564,24 +709,133 @@ func TestConcurrentAccess(t *testing.T) {
 }
 
 func TestCacheKey(t *testing.T) {
+	base := SuggestTagsRequest{Content: "content", ExistingTags: []string{"tag1", "tag2"}, MaxTags: 5}
+
 	// Same content and tags should produce same key
-	key1 := cacheKey("content", []string{"tag1", "tag2"})
-	key2 := cacheKey("content", []string{"tag1", "tag2"})
-	if key1 != key2 {
+	key1 := cacheKey(&base)
+	same := base
+	if key1 != cacheKey(&same) {
 		t.Error("Same inputs should produce same cache key")
 	}
 
 	// Different content should produce different key
-	key3 := cacheKey("different content", []string{"tag1", "tag2"})
-	if key1 == key3 {
+	differentContent := base
+	differentContent.Content = "different content"
+	if key1 == cacheKey(&differentContent) {
 		t.Error("Different content should produce different cache key")
 	}
 
 	// Different tags should produce different key
-	key4 := cacheKey("content", []string{"tag1"})
-	if key1 == key4 {
+	differentTags := base
+	differentTags.ExistingTags = []string{"tag1"}
+	if key1 == cacheKey(&differentTags) {
 		t.Error("Different tags should produce different cache key")
 	}
+
+	// Moving bytes between tags, or between content and tags, should change the key
+	joined, split := base, base
+	joined.ExistingTags = []string{"ab"}
+	split.ExistingTags = []string{"a", "b"}
+	if cacheKey(&joined) == cacheKey(&split) {
+		t.Error("Split tags should produce a different cache key than joined tags")
+	}
+	shifted := base
+	shifted.Content = "contenttag1"
+	shifted.ExistingTags = []string{"tag2"}
+	if key1 == cacheKey(&shifted) {
+		t.Error("Shifting a tag into the content should produce a different cache key")
+	}
+
+	// Different language should produce different key
+	zh, en := base, base
+	zh.Language = "zh"
+	en.Language = "en"
+	if key1 == cacheKey(&zh) || cacheKey(&zh) == cacheKey(&en) {
+		t.Error("Different languages should produce different cache keys")
+	}
+
+	// Different tag limit should produce different key
+	fewerTags := base
+	fewerTags.MaxTags = 3
+	if key1 == cacheKey(&fewerTags) {
+		t.Error("Different MaxTags should produce different cache key")
+	}
+
+	// Different model should produce different key
+	otherModel := base
+	otherModel.Model = "gpt-4o"
+	if key1 == cacheKey(&otherModel) {
+		t.Error("Different models should produce different cache key")
+	}
+}
+
+func TestSuggestTags_CacheKeyIncludesMaxTags(t *testing.T) {
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			tags := make([]string, req.MaxTags)
+			for i := range tags {
+				tags[i] = fmt.Sprintf("tag%d", i)
+			}
+			return &SuggestTagsResponse{Tags: tags}, nil
+		},
+	}
+	config := &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       false,
+	}
+	ts := NewTagService(mock, config)
+	defer ts.Stop()
+
+	ctx := context.Background()
+	first, err := ts.SuggestTags(ctx, 1, "same content", nil)
+	if err != nil {
+		t.Fatalf("SuggestTags failed: %v", err)
+	}
+
+	// Lowering the limit must not return the entry cached under the old one
+	config.MaxTagsPerRequest = 2
+	second, err := ts.SuggestTags(ctx, 1, "same content", nil)
+	if err != nil {
+		t.Fatalf("SuggestTags failed: %v", err)
+	}
+
+	if len(first.Tags) != 5 || len(second.Tags) != 2 {
+		t.Errorf("Expected 5 then 2 tags, got %v then %v", first.Tags, second.Tags)
+	}
+	if mock.GetCallCount() != 2 {
+		t.Errorf("Expected 2 LLM calls (different cache keys), got %d", mock.GetCallCount())
+	}
+	if size, _ := ts.GetCacheStats(); size != 2 {
+		t.Errorf("Expected 2 cache entries, got %d", size)
+	}
+}
+
+func TestSuggestTags_CacheKeyIncludesModel(t *testing.T) {
+	mock := &mockLLMService{}
+	config := &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       false,
+		Model:             "gpt-4o-mini",
+	}
+	ts := NewTagService(mock, config)
+	defer ts.Stop()
+
+	ctx := context.Background()
+	ts.SuggestTags(ctx, 1, "same content", nil)
+	config.Model = "gpt-4o"
+	ts.SuggestTags(ctx, 1, "same content", nil)
+
+	if mock.GetCallCount() != 2 {
+		t.Errorf("Expected 2 LLM calls (different models), got %d", mock.GetCallCount())
+	}
 }
 
 func TestGenerateJobID(t *testing.T) {
@@ -589,13 +843,1340 @@ func TestGenerateJobID(t *testing.T) {
 	id1 := generateJobID(1, "content")
 	id2 := generateJobID(1, "content")
 
-	// Even with same inputs, IDs should differ (includes timestamp)
+	// Even with same inputs, IDs should differ (includes timestamp and counter)
 	if id1 == id2 {
 		t.Error("Job IDs should be unique")
 	}
 
-	// IDs should be 16 characters
-	if len(id1) != 16 {
-		t.Errorf("Expected job ID length 16, got %d", len(id1))
+	// IDs should be 24 characters
+	if len(id1) != 24 {
+		t.Errorf("Expected job ID length 24, got %d", len(id1))
+	}
+}
+
+func TestGenerateJobID_ConcurrentUnique(t *testing.T) {
+	const goroutines = 50
+	const perGoroutine = 200
+
+	ids := make(chan string, goroutines*perGoroutine)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				ids <- generateJobID(1, "same content")
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool, goroutines*perGoroutine)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("duplicate job ID %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestSuggestTags_CachesConfidence(t *testing.T) {
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			return &SuggestTagsResponse{
+				Tags:       []string{"alpha", "beta"},
+				Confidence: []float64{0.9, 0.6},
+			}, nil
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       false,
+	})
+	defer ts.Stop()
+
+	ctx := context.Background()
+	if _, err := ts.SuggestTags(ctx, 1, "confidence content", nil); err != nil {
+		t.Fatalf("SuggestTags failed: %v", err)
+	}
+
+	cached, err := ts.SuggestTags(ctx, 1, "confidence content", nil)
+	if err != nil {
+		t.Fatalf("SuggestTags (cached) failed: %v", err)
+	}
+	if mock.GetCallCount() != 1 {
+		t.Errorf("Expected 1 LLM call, got %d", mock.GetCallCount())
+	}
+	if len(cached.Confidence) != 2 || cached.Confidence[0] != 0.9 || cached.Confidence[1] != 0.6 {
+		t.Errorf("Expected cached confidence [0.9 0.6], got %v", cached.Confidence)
+	}
+}
+
+func TestSuggestTags_CachesFullResponseCopy(t *testing.T) {
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			return &SuggestTagsResponse{
+				Tags:       []string{"alpha", "beta"},
+				Confidence: []float64{0.9, 0.6},
+				NewTags:    []string{"beta"},
+				Model:      "gpt-4o-mini",
+				Usage:      &TokenUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+			}, nil
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       false,
+	})
+	defer ts.Stop()
+
+	ctx := context.Background()
+	first, err := ts.SuggestTags(ctx, 1, "full response content", nil)
+	if err != nil {
+		t.Fatalf("SuggestTags failed: %v", err)
+	}
+	// Mutating the caller's result must not reach the cache
+	first.Tags[0] = "mutated"
+	first.NewTags[0] = "mutated"
+
+	cached, err := ts.SuggestTags(ctx, 1, "full response content", nil)
+	if err != nil {
+		t.Fatalf("SuggestTags (cached) failed: %v", err)
+	}
+	if mock.GetCallCount() != 1 {
+		t.Errorf("Expected 1 LLM call, got %d", mock.GetCallCount())
+	}
+	if !slices.Equal(cached.Tags, []string{"alpha", "beta"}) || !slices.Equal(cached.NewTags, []string{"beta"}) {
+		t.Errorf("Expected the cached copy to be unaffected, got tags %v new tags %v", cached.Tags, cached.NewTags)
+	}
+	if cached.Model != "gpt-4o-mini" {
+		t.Errorf("Expected cached model gpt-4o-mini, got %q", cached.Model)
+	}
+	if cached.Usage != nil {
+		t.Errorf("Expected no usage on a cache hit, got %+v", cached.Usage)
+	}
+}
+
+func TestSuggestTags_LanguagePassedThrough(t *testing.T) {
+	var gotLanguage string
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			gotLanguage = req.Language
+			return &SuggestTagsResponse{Tags: []string{"标签"}}, nil
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		Language:          "zh",
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       false,
+	})
+	defer ts.Stop()
+
+	if _, err := ts.SuggestTags(context.Background(), 1, "content", nil); err != nil {
+		t.Fatalf("SuggestTags failed: %v", err)
+	}
+	if gotLanguage != "zh" {
+		t.Errorf("Expected language zh in request, got %q", gotLanguage)
+	}
+}
+
+func TestSuggestTags_CustomPromptTemplate(t *testing.T) {
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{Content: `["contract"]`},
+	}
+	base := NewBaseProvider(&ProviderConfig{})
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			return base.DefaultSuggestTags(ctx, provider, req)
+		},
+	}
+	config := &TagServiceConfig{
+		MaxTagsPerRequest: 3,
+		Language:          "en",
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       false,
+		TagPromptTemplate: `Suggest {{.MaxTags}} legal tags ({{.Language}}, existing: {{join .ExistingTags ","}}) for: {{.Content}}`,
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	ts := NewTagService(mock, config)
+	defer ts.Stop()
+
+	if _, err := ts.SuggestTags(context.Background(), 1, "The lease ends in May", []string{"lease", "nda"}); err != nil {
+		t.Fatalf("SuggestTags failed: %v", err)
+	}
+
+	userPrompt := provider.lastCompleteReq.Messages[1].Content
+	expected := "Suggest 3 legal tags (en, existing: lease,nda) for: The lease ends in May"
+	if userPrompt != expected {
+		t.Errorf("Expected user prompt %q, got %q", expected, userPrompt)
+	}
+}
+
+func TestTagServiceConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  bool
+	}{
+		{name: "empty uses default", template: "", wantErr: false},
+		{name: "valid", template: "Tags for {{.Content}} (max {{.MaxTags}})", wantErr: false},
+		{name: "syntax error", template: "Tags for {{.Content", wantErr: true},
+		{name: "unknown field", template: "Tags for {{.Body}}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultTagServiceConfig()
+			config.TagPromptTemplate = tt.template
+			err := config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCacheEviction_LRU(t *testing.T) {
+	mock := &mockLLMService{}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      3,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       false,
+	})
+	defer ts.Stop()
+
+	ctx := context.Background()
+	ts.SuggestTags(ctx, 1, "hot", nil)
+	ts.SuggestTags(ctx, 1, "stale", nil)
+	ts.SuggestTags(ctx, 1, "other", nil)
+
+	// Touch the oldest entry so it becomes most recently used
+	ts.SuggestTags(ctx, 1, "hot", nil)
+
+	// Inserting a new entry must evict the least recently used one ("stale")
+	ts.SuggestTags(ctx, 1, "new", nil)
+
+	if ts.getFromCache(ts.tagCacheKey(context.Background(), "hot", nil)) == nil {
+		t.Error("Repeatedly accessed entry should survive eviction")
+	}
+	if ts.getFromCache(ts.tagCacheKey(context.Background(), "stale", nil)) != nil {
+		t.Error("Least recently used entry should be evicted")
+	}
+	if size, _ := ts.GetCacheStats(); size != 3 {
+		t.Errorf("Expected cache size 3, got %d", size)
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	mock := &mockLLMService{}
+	clock := NewFakeClock(time.Now())
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          10 * time.Minute,
+		MaxCacheSize:      10,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       false,
+		Clock:             clock,
+	})
+	defer ts.Stop()
+
+	ts.SuggestTags(context.Background(), 1, "expiring", nil)
+
+	clock.Advance(9 * time.Minute)
+	if ts.getFromCache(ts.tagCacheKey(context.Background(), "expiring", nil)) == nil {
+		t.Fatal("Entry should still be cached before the TTL elapses")
+	}
+
+	clock.Advance(2 * time.Minute)
+	if ts.getFromCache(ts.tagCacheKey(context.Background(), "expiring", nil)) != nil {
+		t.Error("Expired entry should not be returned")
+	}
+	if size, _ := ts.GetCacheStats(); size != 0 {
+		t.Errorf("Expired entry should be removed on access, got size %d", size)
+	}
+}
+
+func BenchmarkCacheResult_Eviction(b *testing.B) {
+	for _, size := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			ts := NewTagService(&mockLLMService{}, &TagServiceConfig{
+				MaxTagsPerRequest: 5,
+				CacheTTL:          time.Hour,
+				MaxCacheSize:      size,
+				EnableAsync:       false,
+			})
+			defer ts.Stop()
+
+			result := &SuggestTagsResponse{Tags: []string{"tag"}}
+			for i := 0; i < size; i++ {
+				ts.cacheResult(fmt.Sprintf("warm %d", i), result)
+			}
+
+			// Every insert evicts; cost per op should stay flat as size grows
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ts.cacheResult(fmt.Sprintf("content %d", i), result)
+			}
+		})
+	}
+}
+
+func TestTagServiceMetrics(t *testing.T) {
+	mock := &mockLLMService{}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      1,
+		RateLimitRequests: 4,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       false,
+	})
+	defer ts.Stop()
+
+	ctx := context.Background()
+
+	// Fresh request: miss + LLM call
+	_, _ = ts.SuggestTags(ctx, 1, "first content", nil)
+	m := ts.Metrics()
+	if m.CacheMisses != 1 || m.CacheHits != 0 || m.LLMCalls != 1 {
+		t.Errorf("After fresh request expected 1 miss, 0 hits, 1 LLM call, got %+v", m)
+	}
+
+	// Same content: hit, no LLM call
+	_, _ = ts.SuggestTags(ctx, 1, "first content", nil)
+	m = ts.Metrics()
+	if m.CacheHits != 1 || m.LLMCalls != 1 {
+		t.Errorf("After cached request expected 1 hit and 1 LLM call, got %+v", m)
+	}
+
+	// Different content with a cache of size 1 evicts the first entry
+	_, _ = ts.SuggestTags(ctx, 1, "second content", nil)
+	m = ts.Metrics()
+	if m.CacheEvictions != 1 || m.CacheMisses != 2 || m.CacheSize != 1 {
+		t.Errorf("Expected 1 eviction, 2 misses and cache size 1, got %+v", m)
+	}
+
+	// Exhaust the rate limit
+	_, _ = ts.SuggestTags(ctx, 1, "second content", nil)
+	if _, err := ts.SuggestTags(ctx, 1, "second content", nil); err != ErrRateLimitExceeded {
+		t.Fatalf("Expected ErrRateLimitExceeded, got %v", err)
+	}
+	if m = ts.Metrics(); m.RateLimitRejections != 1 {
+		t.Errorf("Expected 1 rate limit rejection, got %d", m.RateLimitRejections)
+	}
+}
+
+func TestTagServiceMetrics_ClearCacheKeepsCounters(t *testing.T) {
+	mock := &mockLLMService{}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       false,
+	})
+	defer ts.Stop()
+
+	ctx := context.Background()
+	_, _ = ts.SuggestTags(ctx, 1, "test content", nil)
+	_, _ = ts.SuggestTags(ctx, 1, "test content", nil)
+
+	ts.ClearCache()
+
+	m := ts.Metrics()
+	if m.CacheSize != 0 {
+		t.Errorf("Expected cache size 0 after clear, got %d", m.CacheSize)
+	}
+	if m.CacheHits != 1 || m.CacheMisses != 1 || m.LLMCalls != 1 {
+		t.Errorf("Expected counters to survive ClearCache, got %+v", m)
+	}
+
+	ts.ResetMetrics()
+
+	if m = ts.Metrics(); m != (TagServiceMetrics{}) {
+		t.Errorf("Expected zeroed metrics after ResetMetrics, got %+v", m)
+	}
+}
+
+func TestCancelJob_Pending(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	var cancelledContentCalls atomic.Int32
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			if req.Content == "to be canceled" {
+				cancelledContentCalls.Add(1)
+			} else {
+				started <- struct{}{}
+				<-release
+			}
+			return &SuggestTagsResponse{Tags: []string{"tag1"}}, nil
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    10,
+	})
+	defer ts.Stop()
+
+	done := make(chan *TagJob, 2)
+	ts.SetJobCallback(func(job *TagJob) { done <- job })
+
+	// Occupy the single worker so the next job stays queued
+	if _, err := ts.SuggestTagsAsync(1, 100, "blocking content", nil); err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+	<-started
+
+	queued, err := ts.SuggestTagsAsync(1, 101, "to be canceled", nil)
+	if err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+	if err := ts.CancelJob(queued.ID); err != nil {
+		t.Fatalf("CancelJob() error: %v", err)
+	}
+
+	canceled := <-done
+	if canceled.ID != queued.ID || canceled.Status != TagJobStatusFailed || !errors.Is(canceled.Error, ErrJobCanceled) {
+		t.Errorf("Expected canceled job to be failed with ErrJobCanceled, got %+v", canceled)
+	}
+
+	// A job queued after the canceled one completes only once the worker has
+	// dequeued (and skipped) the canceled job
+	if _, err := ts.SuggestTagsAsync(1, 102, "after cancel", nil); err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+	close(release)
+	<-done
+	<-started
+	last := <-done
+	if last.MemoID != 102 || last.Status != TagJobStatusCompleted {
+		t.Errorf("Expected the following job to complete, got %+v", last)
+	}
+
+	if got := cancelledContentCalls.Load(); got != 0 {
+		t.Errorf("Expected canceled job never to call the LLM, got %d calls", got)
+	}
+	if err := ts.CancelJob("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound for unknown job, got %v", err)
+	}
+}
+
+func TestCancelJob_CallbackMayUseService(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			if req.Content == "blocking content" {
+				started <- struct{}{}
+				<-release
+			}
+			return &SuggestTagsResponse{Tags: []string{"tag1"}}, nil
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    10,
+	})
+	defer ts.Stop()
+	defer close(release)
+
+	// The callback re-enters the service, which needs jobsMu
+	reentered := make(chan error, 2)
+	ts.SetJobCallback(func(job *TagJob) { reentered <- ts.CancelJob(job.ID) })
+
+	if _, err := ts.SuggestTagsAsync(1, 100, "blocking content", nil); err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+	<-started
+
+	queued, err := ts.SuggestTagsAsync(1, 101, "to be canceled", nil)
+	if err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+
+	canceled := make(chan error, 1)
+	go func() { canceled <- ts.CancelJob(queued.ID) }()
+
+	select {
+	case err := <-canceled:
+		if err != nil {
+			t.Fatalf("CancelJob() error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("CancelJob deadlocked while running the job callback")
+	}
+	if err := <-reentered; !errors.Is(err, ErrJobFinished) {
+		t.Errorf("Expected ErrJobFinished from the callback, got %v", err)
+	}
+}
+
+func TestProcessJob_RetriesTransientErrors(t *testing.T) {
+	var calls atomic.Int32
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			if calls.Add(1) <= 2 {
+				return nil, &ProviderError{Provider: ProviderOpenAI, StatusCode: 503, Retryable: true, Err: ErrProviderUnavailable}
+			}
+			return &SuggestTagsResponse{Tags: []string{"tag1"}}, nil
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    10,
+		JobRetryPolicy:    &RetryPolicy{BaseDelay: time.Millisecond, Jitter: JitterNone},
+	})
+	defer ts.Stop()
+
+	done := make(chan *TagJob, 1)
+	ts.SetJobCallback(func(job *TagJob) { done <- job })
+
+	if _, err := ts.SuggestTagsAsync(1, 100, "content", nil); err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+
+	job := <-done
+	if job.Status != TagJobStatusCompleted {
+		t.Fatalf("Expected job to complete after retries, got %s (%v)", job.Status, job.Error)
+	}
+	if job.Attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", job.Attempts)
+	}
+}
+
+func TestProcessJob_PermanentErrorsFailImmediately(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"invalid api key", ErrInvalidAPIKey},
+		{"non-retryable provider error", &ProviderError{Provider: ProviderOpenAI, StatusCode: 400, Message: "bad request"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockLLMService{
+				suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+					return nil, tt.err
+				},
+			}
+			ts := NewTagService(mock, &TagServiceConfig{
+				MaxTagsPerRequest: 5,
+				CacheTTL:          15 * time.Minute,
+				MaxCacheSize:      100,
+				RateLimitRequests: 100,
+				RateLimitWindow:   time.Minute,
+				EnableAsync:       true,
+				AsyncWorkers:      1,
+				AsyncQueueSize:    10,
+				JobRetryPolicy:    &RetryPolicy{BaseDelay: time.Millisecond},
+			})
+			defer ts.Stop()
+
+			done := make(chan *TagJob, 1)
+			ts.SetJobCallback(func(job *TagJob) { done <- job })
+
+			if _, err := ts.SuggestTagsAsync(1, 100, "content", nil); err != nil {
+				t.Fatalf("SuggestTagsAsync failed: %v", err)
+			}
+
+			job := <-done
+			if job.Status != TagJobStatusFailed || !errors.Is(job.Error, tt.err) {
+				t.Errorf("Expected job to fail with %v, got %s (%v)", tt.err, job.Status, job.Error)
+			}
+			if job.Attempts != 1 {
+				t.Errorf("Expected 1 attempt, got %d", job.Attempts)
+			}
+		})
+	}
+}
+
+func TestProcessJob_ExhaustsRetries(t *testing.T) {
+	var calls atomic.Int32
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			calls.Add(1)
+			return nil, ErrRateLimited
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    10,
+		JobRetryPolicy:    &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+	})
+	defer ts.Stop()
+
+	done := make(chan *TagJob, 1)
+	ts.SetJobCallback(func(job *TagJob) { done <- job })
+
+	if _, err := ts.SuggestTagsAsync(1, 100, "content", nil); err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+
+	job := <-done
+	if job.Status != TagJobStatusFailed || !errors.Is(job.Error, ErrRateLimited) {
+		t.Errorf("Expected job to fail with ErrRateLimited, got %s (%v)", job.Status, job.Error)
+	}
+	if job.Attempts != 2 || calls.Load() != 2 {
+		t.Errorf("Expected 2 attempts, got %d (%d calls)", job.Attempts, calls.Load())
+	}
+}
+
+func TestStopWithTimeout_FailsUndrainedJobs(t *testing.T) {
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			time.Sleep(100 * time.Millisecond)
+			return &SuggestTagsResponse{Tags: []string{"tag1"}}, nil
+		},
+		summarizeFunc: func(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error) {
+			time.Sleep(100 * time.Millisecond)
+			return &SummarizeResponse{Summary: "summary"}, nil
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    10,
+	})
+
+	var mu sync.Mutex
+	tagJobs := make(map[string]*TagJob)
+	var summarizeJobs []*SummarizeJob
+	ts.SetJobCallback(func(job *TagJob) {
+		mu.Lock()
+		defer mu.Unlock()
+		tagJobs[job.ID] = job
+	})
+	ts.SetSummarizeJobCallback(func(job *SummarizeJob) {
+		mu.Lock()
+		defer mu.Unlock()
+		summarizeJobs = append(summarizeJobs, job)
+	})
+
+	const total = 5
+	for i := 0; i < total; i++ {
+		if _, err := ts.SuggestTagsAsync(1, int32(100+i), fmt.Sprintf("content %d", i), nil); err != nil {
+			t.Fatalf("SuggestTagsAsync failed: %v", err)
+		}
+	}
+	if _, err := ts.SummarizeAsync(1, 200, "summary content", SummarizeOptions{}); err != nil {
+		t.Fatalf("SummarizeAsync failed: %v", err)
+	}
+
+	ts.StopWithTimeout(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(tagJobs) != total {
+		t.Fatalf("Expected callbacks for all %d tag jobs, got %d", total, len(tagJobs))
+	}
+	completed, stopped := 0, 0
+	for _, job := range tagJobs {
+		switch {
+		case job.Status == TagJobStatusCompleted:
+			completed++
+		case job.Status == TagJobStatusFailed && errors.Is(job.Error, ErrServiceStopping):
+			stopped++
+			if job.CompletedAt == nil {
+				t.Errorf("Expected CompletedAt to be set on job %s", job.ID)
+			}
+		default:
+			t.Errorf("Unexpected job state: %+v", job)
+		}
+	}
+
+	if len(summarizeJobs) != 1 {
+		t.Fatalf("Expected a callback for the summarize job, got %d", len(summarizeJobs))
+	}
+	switch job := summarizeJobs[0]; {
+	case job.Status == TagJobStatusCompleted:
+		completed++
+	case job.Status == TagJobStatusFailed && errors.Is(job.Error, ErrServiceStopping):
+		stopped++
+	default:
+		t.Errorf("Unexpected summarize job state: %+v", job)
+	}
+
+	// The single worker finishes the job it picked up; everything else is failed
+	if completed != 1 || stopped != total {
+		t.Errorf("Expected 1 completed and %d stopped jobs, got %d completed, %d stopped", total, completed, stopped)
+	}
+
+	// Failed jobs are persisted too
+	for id, job := range tagJobs {
+		stored, ok := ts.GetJob(id)
+		if !ok || stored.Status != job.Status {
+			t.Errorf("Expected stored job %s to be %s, got %+v", id, job.Status, stored)
+		}
+	}
+}
+
+func TestStopWithTimeout_DrainsQueue(t *testing.T) {
+	ts := NewTagService(&mockLLMService{}, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      2,
+		AsyncQueueSize:    10,
+	})
+
+	var completed atomic.Int32
+	ts.SetJobCallback(func(job *TagJob) {
+		if job.Status == TagJobStatusCompleted {
+			completed.Add(1)
+		}
+	})
+
+	for i := 0; i < 5; i++ {
+		if _, err := ts.SuggestTagsAsync(1, int32(100+i), fmt.Sprintf("content %d", i), nil); err != nil {
+			t.Fatalf("SuggestTagsAsync failed: %v", err)
+		}
+	}
+
+	ts.StopWithTimeout(5 * time.Second)
+
+	if got := completed.Load(); got != 5 {
+		t.Errorf("Expected all 5 jobs to be drained, got %d completed", got)
+	}
+}
+
+func TestStop_Twice(t *testing.T) {
+	ts := NewTagService(&mockLLMService{}, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      2,
+		AsyncQueueSize:    10,
+	})
+
+	ts.Stop()
+	ts.Stop()
+	ts.StopWithTimeout(time.Second)
+
+	drained := NewTagService(&mockLLMService{}, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      2,
+		AsyncQueueSize:    10,
+	})
+
+	drained.StopWithTimeout(time.Second)
+	drained.Stop()
+}
+
+func TestSuggestTagsAsync_AfterStop(t *testing.T) {
+	ts := NewTagService(&mockLLMService{}, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    10,
+	})
+	ts.Stop()
+
+	if _, err := ts.SuggestTagsAsync(1, 100, "after stop", nil); !errors.Is(err, ErrServiceStopping) {
+		t.Errorf("Expected ErrServiceStopping from SuggestTagsAsync, got %v", err)
+	}
+	if _, err := ts.SuggestTagsAsyncWait(context.Background(), 1, 100, "after stop", nil); !errors.Is(err, ErrServiceStopping) {
+		t.Errorf("Expected ErrServiceStopping from SuggestTagsAsyncWait, got %v", err)
+	}
+	if _, err := ts.SummarizeAsync(1, 100, "after stop", SummarizeOptions{}); !errors.Is(err, ErrServiceStopping) {
+		t.Errorf("Expected ErrServiceStopping from SummarizeAsync, got %v", err)
+	}
+}
+
+func TestCancelJob_Running(t *testing.T) {
+	started := make(chan struct{})
+	interrupted := make(chan error, 1)
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			close(started)
+			<-ctx.Done()
+			interrupted <- ctx.Err()
+			return nil, ctx.Err()
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    10,
+	})
+	defer ts.Stop()
+
+	done := make(chan *TagJob, 1)
+	ts.SetJobCallback(func(job *TagJob) { done <- job })
+
+	job, err := ts.SuggestTagsAsync(1, 100, "long running", nil)
+	if err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+	<-started
+
+	if err := ts.CancelJob(job.ID); err != nil {
+		t.Fatalf("CancelJob() error: %v", err)
+	}
+
+	select {
+	case err := <-interrupted:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected LLM call context to be canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("LLM call was not interrupted")
+	}
+
+	finished := <-done
+	if finished.Status != TagJobStatusFailed || !errors.Is(finished.Error, ErrJobCanceled) {
+		t.Errorf("Expected job to fail with ErrJobCanceled, got %+v", finished)
+	}
+
+	// A finished job can't be canceled again
+	if err := ts.CancelJob(job.ID); !errors.Is(err, ErrJobFinished) {
+		t.Errorf("Expected ErrJobFinished, got %v", err)
+	}
+}
+
+func TestPreviewTagPrompt(t *testing.T) {
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			t.Error("Preview must not call the LLM")
+			return nil, nil
+		},
+	}
+	config := DefaultTagServiceConfig()
+	config.MaxTagsPerRequest = 7
+	config.EnableAsync = false
+	ts := NewTagService(mock, config)
+	defer ts.Stop()
+
+	system, user := ts.PreviewTagPrompt("Quarterly planning notes", []string{"work", "planning"})
+	if !strings.Contains(system, "suggests relevant tags") {
+		t.Errorf("Expected the built-in system prompt, got %q", system)
+	}
+	if !strings.Contains(user, "Suggest up to 7 tags") {
+		t.Errorf("Expected preview to reflect MaxTagsPerRequest, got %q", user)
+	}
+	if !strings.Contains(user, "Prefer using these existing tags when relevant: [work planning]") {
+		t.Errorf("Expected preview to include the existing tags hint, got %q", user)
+	}
+	if !strings.Contains(user, "Quarterly planning notes") {
+		t.Errorf("Expected preview to include the content, got %q", user)
+	}
+
+	_, user = ts.PreviewTagPrompt("No hints here", nil)
+	if strings.Contains(user, "Prefer using these existing tags") {
+		t.Errorf("Expected no existing tags hint without existing tags, got %q", user)
+	}
+}
+
+func TestPreviewTagPrompt_Template(t *testing.T) {
+	config := DefaultTagServiceConfig()
+	config.MaxTagsPerRequest = 2
+	config.EnableAsync = false
+	config.TagPromptTemplate = `Pick {{.MaxTags}} of {{join .ExistingTags "|"}} for: {{.Content}}`
+	ts := NewTagService(&mockLLMService{}, config)
+	defer ts.Stop()
+
+	_, user := ts.PreviewTagPrompt("Trip to Lisbon", []string{"travel", "family"})
+	if expected := "Pick 2 of travel|family for: Trip to Lisbon"; user != expected {
+		t.Errorf("Expected templated prompt %q, got %q", expected, user)
+	}
+}
+
+func TestSuggestTags_ModelOverride(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		expected string
+	}{
+		{"override", "gpt-4o-mini", "gpt-4o-mini"},
+		{"provider default", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &mockProvider{
+				completeResp: &CompletionResponse{Content: `["contract"]`},
+			}
+			base := NewBaseProvider(&ProviderConfig{})
+			mock := &mockLLMService{
+				suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+					return base.DefaultSuggestTags(ctx, provider, req)
+				},
+			}
+			config := DefaultTagServiceConfig()
+			config.EnableAsync = false
+			config.Model = tt.model
+			ts := NewTagService(mock, config)
+			defer ts.Stop()
+
+			if _, err := ts.SuggestTags(context.Background(), 1, "The lease ends in May", nil); err != nil {
+				t.Fatalf("SuggestTags failed: %v", err)
+			}
+			if got := provider.lastCompleteReq.Model; got != tt.expected {
+				t.Errorf("Expected model %q to reach Complete, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSuggestTagsAsync_ModelOverride(t *testing.T) {
+	provider := &mockProvider{
+		completeResp: &CompletionResponse{Content: `["contract"]`},
+	}
+	base := NewBaseProvider(&ProviderConfig{})
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			return base.DefaultSuggestTags(ctx, provider, req)
+		},
+	}
+	config := DefaultTagServiceConfig()
+	config.Model = "llama3.2:1b"
+	ts := NewTagService(mock, config)
+	defer ts.Stop()
+
+	done := make(chan *TagJob, 1)
+	ts.SetJobCallback(func(job *TagJob) { done <- job })
+
+	if _, err := ts.SuggestTagsAsync(1, 100, "The lease ends in May", nil); err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+
+	select {
+	case job := <-done:
+		if job.Status != TagJobStatusCompleted {
+			t.Fatalf("Expected completed job, got %s (%v)", job.Status, job.Error)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for job")
+	}
+	if got := provider.lastCompleteReq.Model; got != "llama3.2:1b" {
+		t.Errorf("Expected model override to reach Complete, got %q", got)
+	}
+}
+
+func TestSetAsyncWorkerCount_Scales(t *testing.T) {
+	var inflight, maxInflight atomic.Int32
+	var gateMu sync.Mutex
+	gate := make(chan struct{})
+	close(gate)
+
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			n := inflight.Add(1)
+			defer inflight.Add(-1)
+			for {
+				m := maxInflight.Load()
+				if n <= m || maxInflight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			gateMu.Lock()
+			wait := gate
+			gateMu.Unlock()
+			<-wait
+			time.Sleep(5 * time.Millisecond)
+			return &SuggestTagsResponse{Tags: []string{"tag"}}, nil
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    20,
+	})
+
+	var completed atomic.Int32
+	ts.SetJobCallback(func(job *TagJob) {
+		completed.Add(1)
+	})
+
+	next := 0
+	runJobs := func(n int) {
+		t.Helper()
+		completed.Store(0)
+		for i := 0; i < n; i++ {
+			next++
+			if _, err := ts.SuggestTagsAsync(1, int32(next), fmt.Sprintf("content %d", next), nil); err != nil {
+				t.Fatalf("SuggestTagsAsync failed: %v", err)
+			}
+		}
+	}
+	waitUntil := func(what string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// One worker processes jobs one at a time
+	runJobs(4)
+	waitUntil("jobs with 1 worker", func() bool { return completed.Load() == 4 })
+	if got := maxInflight.Load(); got != 1 {
+		t.Errorf("Expected 1 job in flight with 1 worker, got %d", got)
+	}
+
+	// Three workers pick up three jobs at once
+	if err := ts.SetAsyncWorkerCount(3); err != nil {
+		t.Fatalf("SetAsyncWorkerCount(3) failed: %v", err)
+	}
+	if got := ts.AsyncWorkerCount(); got != 3 {
+		t.Errorf("AsyncWorkerCount() = %d, want 3", got)
+	}
+	gateMu.Lock()
+	gate = make(chan struct{})
+	release := gate
+	gateMu.Unlock()
+	maxInflight.Store(0)
+	runJobs(6)
+	waitUntil("3 jobs in flight", func() bool { return inflight.Load() == 3 })
+	close(release)
+	waitUntil("jobs with 3 workers", func() bool { return completed.Load() == 6 })
+
+	// Back to one worker: the retired workers exit
+	if err := ts.SetAsyncWorkerCount(1); err != nil {
+		t.Fatalf("SetAsyncWorkerCount(1) failed: %v", err)
+	}
+	waitUntil("retired workers to exit", func() bool { return ts.runningWorkers.Load() == 1 })
+	maxInflight.Store(0)
+	runJobs(4)
+	waitUntil("jobs after scaling down", func() bool { return completed.Load() == 4 })
+	if got := maxInflight.Load(); got != 1 {
+		t.Errorf("Expected 1 job in flight after scaling down, got %d", got)
+	}
+
+	ts.Stop()
+	if got := ts.runningWorkers.Load(); got != 0 {
+		t.Errorf("Expected no running workers after Stop, got %d", got)
+	}
+}
+
+func TestSetAsyncWorkerCount_Invalid(t *testing.T) {
+	ts := NewTagService(&mockLLMService{}, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      2,
+		AsyncQueueSize:    10,
+	})
+
+	if err := ts.SetAsyncWorkerCount(0); !errors.Is(err, ErrInvalidWorkerCount) {
+		t.Errorf("Expected ErrInvalidWorkerCount, got %v", err)
+	}
+	if got := ts.AsyncWorkerCount(); got != 2 {
+		t.Errorf("AsyncWorkerCount() = %d, want 2", got)
+	}
+
+	ts.Stop()
+	if err := ts.SetAsyncWorkerCount(3); !errors.Is(err, ErrServiceStopping) {
+		t.Errorf("Expected ErrServiceStopping after Stop, got %v", err)
+	}
+}
+
+func TestSuggestTagsAsyncWait(t *testing.T) {
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			started <- struct{}{}
+			<-release
+			return &SuggestTagsResponse{Tags: []string{"tag"}}, nil
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    1,
+	})
+	defer ts.Stop()
+
+	// Occupy the worker, then fill the single queue slot
+	if _, err := ts.SuggestTagsAsync(1, 1, "running", nil); err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+	<-started
+	if _, err := ts.SuggestTagsAsync(1, 2, "queued", nil); err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+
+	if _, err := ts.SuggestTagsAsync(1, 3, "rejected", nil); !errors.Is(err, ErrJobQueueFull) {
+		t.Errorf("Expected ErrJobQueueFull from the non-blocking call, got %v", err)
+	}
+
+	// The queue stays full: the wait times out
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := ts.SuggestTagsAsyncWait(ctx, 1, 4, "timed out", nil); !errors.Is(err, ErrJobQueueFull) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected ErrJobQueueFull and DeadlineExceeded, got %v", err)
+	}
+	if jobs, _ := ts.jobStore.ListJobs(context.Background()); len(jobs) != 2 {
+		t.Errorf("Expected unqueued jobs to be removed from the store, got %d jobs", len(jobs))
+	}
+
+	// A worker frees a slot: the wait succeeds
+	type result struct {
+		job *TagJob
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		job, err := ts.SuggestTagsAsyncWait(ctx, 1, 5, "waited", nil)
+		done <- result{job, err}
+	}()
+
+	close(release)
+	select {
+	case r := <-done:
+		if r.err != nil {
+			t.Fatalf("SuggestTagsAsyncWait failed: %v", r.err)
+		}
+		if r.job == nil || r.job.ID == "" {
+			t.Errorf("Expected a queued job, got %+v", r.job)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("SuggestTagsAsyncWait did not return")
+	}
+}
+
+func TestSuggestTagsAsync_Deduplicates(t *testing.T) {
+	release := make(chan struct{})
+	done := make(chan struct{}, 10)
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			<-release
+			return &SuggestTagsResponse{Tags: []string{"tag"}}, nil
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      2,
+		AsyncQueueSize:    10,
+	})
+	defer ts.Stop()
+	ts.SetJobCallback(func(job *TagJob) { done <- struct{}{} })
+
+	first, err := ts.SuggestTagsAsync(1, 1, "same content", nil)
+	if err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+	second, err := ts.SuggestTagsAsync(1, 1, "same content", nil)
+	if err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+	if first.ID != second.ID {
+		t.Errorf("Expected both callers to get job %s, got %s", first.ID, second.ID)
+	}
+
+	// A different memo with the same content is a separate job
+	other, err := ts.SuggestTagsAsync(1, 2, "same content", nil)
+	if err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+	if other.ID == first.ID {
+		t.Error("Expected a separate job for a different memo")
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for jobs")
+		}
+	}
+
+	if calls := mock.GetCallCount(); calls != 2 {
+		t.Errorf("Expected 2 LLM calls (one per memo), got %d", calls)
+	}
+	ts.jobsMu.Lock()
+	active := len(ts.activeJobs)
+	ts.jobsMu.Unlock()
+	if active != 0 {
+		t.Errorf("Expected finished jobs to be released, got %d active", active)
+	}
+}
+
+func TestSuggestTagsAsync_ReturnsSnapshots(t *testing.T) {
+	release := make(chan struct{})
+	done := make(chan *TagJob, 1)
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			<-release
+			return &SuggestTagsResponse{Tags: []string{"tag"}}, nil
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+		EnableAsync:       true,
+		AsyncWorkers:      1,
+		AsyncQueueSize:    10,
+	})
+	defer ts.Stop()
+	ts.SetJobCallback(func(job *TagJob) { done <- job })
+
+	first, err := ts.SuggestTagsAsync(1, 1, "snapshot content", nil)
+	if err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+	second, err := ts.SuggestTagsAsync(1, 1, "snapshot content", nil)
+	if err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+	if first == second {
+		t.Error("Expected the deduplicated job to be a copy")
+	}
+
+	close(release)
+	var completed *TagJob
+	select {
+	case completed = <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for job")
+	}
+
+	// The worker updates its own copy, never the jobs handed to callers
+	if completed == first || completed == second {
+		t.Error("Expected the worker to own a separate copy of the job")
+	}
+	for _, job := range []*TagJob{first, second} {
+		if job.Status == TagJobStatusCompleted || job.Result != nil {
+			t.Errorf("Expected returned job to be unaffected by the worker, got %+v", job)
+		}
+	}
+	if got, ok := ts.GetJob(first.ID); !ok || got.Status != TagJobStatusCompleted {
+		t.Errorf("Expected the stored job to be completed, got %+v", got)
+	}
+}
+
+func TestSuggestTags_MaxContentLength(t *testing.T) {
+	var gotContent string
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			gotContent = req.Content
+			return &SuggestTagsResponse{Tags: []string{"long"}}, nil
+		},
+	}
+	// "é" is two bytes, so a 5-byte limit falls inside the third rune
+	content := strings.Repeat("é", 10)
+
+	config := DefaultTagServiceConfig()
+	config.MaxContentLength = 5
+	ts := NewTagService(mock, config)
+	defer ts.Stop()
+
+	if _, err := ts.SuggestTags(context.Background(), 1, content, nil); !errors.Is(err, ErrContentTooLarge) {
+		t.Errorf("Expected ErrContentTooLarge, got %v", err)
+	}
+	if _, err := ts.SuggestTagsAsync(1, 1, content, nil); !errors.Is(err, ErrContentTooLarge) {
+		t.Errorf("Expected ErrContentTooLarge from the async path, got %v", err)
+	}
+	if mock.GetCallCount() != 0 {
+		t.Errorf("Expected no LLM calls for oversized content, got %d", mock.GetCallCount())
+	}
+
+	config.TruncateOversized = true
+	if _, err := ts.SuggestTags(context.Background(), 1, content, nil); err != nil {
+		t.Fatalf("SuggestTags failed: %v", err)
+	}
+	if gotContent != "éé" {
+		t.Errorf("Expected content truncated at a rune boundary to %q, got %q", "éé", gotContent)
 	}
 }
@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// SuggestTagsStreaming suggests tags like SuggestTags, but streams the completion
+// and returns as soon as a complete JSON array has been received, canceling the
+// rest of the stream to save tokens. Usage is not reported when the stream is cut
+// short, and provider-level prompt templates are not applied. When the active
+// provider can't stream, it falls back to the synchronous path.
+func (ts *TagService) SuggestTagsStreaming(ctx context.Context, userID int32, content string, existingTags []string) (*SuggestTagsResponse, error) {
+	content, err := ts.limitContent(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check rate limit
+	if !ts.checkRateLimit(userID) {
+		return nil, ErrRateLimitExceeded
+	}
+
+	// The user's provider determines the model, which is part of the cache key
+	ctx, err = ts.withUserProvider(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check cache
+	key := ts.tagCacheKey(ctx, content, existingTags)
+	if cached := ts.getFromCache(key); cached != nil {
+		slog.Debug("Tag suggestion cache hit",
+			slog.Int("user_id", int(userID)),
+			slog.Int("tags_count", len(cached.Tags)))
+		return cached, nil
+	}
+	req := ts.suggestTagsRequest(content, existingTags)
+
+	ts.metrics.llmCalls.Add(1)
+	result, err := ts.streamTags(ctx, req)
+	if errors.Is(err, ErrCapabilityNotSupported) {
+		slog.Debug("Provider cannot stream, suggesting tags synchronously", slog.String("error", err.Error()))
+		result, err = ts.llmService.SuggestTags(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ts.recordUserUsage(userID, result.Model, result.Usage)
+	result = ts.applyTagFilter(result)
+
+	// Cache the result
+	ts.cacheResult(key, result)
+
+	slog.Info("Tag suggestion generated",
+		slog.Int("user_id", int(userID)),
+		slog.Int("tags_count", len(result.Tags)))
+
+	return result, nil
+}
+
+// streamTags requests tag suggestions as a streamed completion and parses the
+// first complete JSON array in the output. If the stream ends without one, the
+// full text is parsed as SuggestTags would.
+func (ts *TagService) streamTags(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+	systemPrompt, userPrompt, err := buildTagPrompts(req, req.PromptTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Canceling stops the provider once the tags have been parsed
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chunks, err := ts.llmService.CompleteStream(ctx, &CompletionRequest{
+		Model: req.Model,
+		Messages: []Message{
+			{Role: RoleSystem, Content: systemPrompt},
+			{Role: RoleUser, Content: userPrompt},
+		},
+		Temperature: 0.3,
+		MaxTokens:   100,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			return nil, fmt.Errorf("failed to get tag suggestions: %w", chunk.Err)
+		}
+		text.WriteString(chunk.Content)
+
+		if chunk.Done {
+			return newTagsResponse(req, text.String(), chunk.Model, chunk.Usage), nil
+		}
+		if array, ok := firstJSONArray(text.String()); ok {
+			return newTagsResponse(req, array, req.Model, nil), nil
+		}
+	}
+
+	// The channel closed without a terminal chunk, so ctx was canceled
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return newTagsResponse(req, text.String(), req.Model, nil), nil
+}
+
+// firstJSONArray returns the first complete JSON array in text, if any.
+func firstJSONArray(text string) (string, bool) {
+	start := strings.Index(text, "[")
+	if start < 0 {
+		return "", false
+	}
+
+	var array json.RawMessage
+	if err := json.NewDecoder(strings.NewReader(text[start:])).Decode(&array); err != nil {
+		return "", false
+	}
+	return string(array), true
+}
+
+// newTagsResponse parses tag suggestions from content, limited to the request's MaxTags.
+func newTagsResponse(req *SuggestTagsRequest, content, model string, usage *TokenUsage) *SuggestTagsResponse {
+	tags, confidence := parseTagSuggestions(content)
+
+	maxTags := tagLimit(req)
+	if len(tags) > maxTags {
+		tags = tags[:maxTags]
+	}
+	if len(confidence) > maxTags {
+		confidence = confidence[:maxTags]
+	}
+
+	return &SuggestTagsResponse{
+		Tags:       tags,
+		Confidence: confidence,
+		Model:      model,
+		Usage:      usage,
+	}
+}
@@ -0,0 +1,160 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// streamChunks returns a stream func that sends chunks in order and then keeps
+// sending filler until the context is canceled, closing canceled when it is.
+func streamChunks(chunks []CompletionChunk, canceled chan struct{}) func(ctx context.Context, req *CompletionRequest) (<-chan CompletionChunk, error) {
+	return func(ctx context.Context, req *CompletionRequest) (<-chan CompletionChunk, error) {
+		ch := make(chan CompletionChunk)
+		go func() {
+			defer close(ch)
+			for _, chunk := range chunks {
+				if !sendChunk(ctx, ch, chunk) {
+					close(canceled)
+					return
+				}
+			}
+			for sendChunk(ctx, ch, CompletionChunk{Content: " and more"}) {
+			}
+			close(canceled)
+		}()
+		return ch, nil
+	}
+}
+
+func TestSuggestTagsStreaming_EarlyTermination(t *testing.T) {
+	canceled := make(chan struct{})
+	mock := &mockLLMService{
+		streamFunc: streamChunks([]CompletionChunk{
+			{Content: `Here you go: [{"tag": "go`},
+			{Content: `lang", "score": 0.9}, {"tag": "test`},
+			{Content: `ing", "score": 0.8}]`},
+		}, canceled),
+	}
+	ts := NewTagService(mock, DefaultTagServiceConfig())
+	defer ts.Stop()
+
+	resp, err := ts.SuggestTagsStreaming(context.Background(), 1, "Writing Go tests", nil)
+	if err != nil {
+		t.Fatalf("SuggestTagsStreaming() error: %v", err)
+	}
+
+	if want := []string{"golang", "testing"}; !reflect.DeepEqual(resp.Tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, resp.Tags)
+	}
+	if want := []float64{0.9, 0.8}; !reflect.DeepEqual(resp.Confidence, want) {
+		t.Errorf("Expected confidence %v, got %v", want, resp.Confidence)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the stream to be canceled once the array was parsed")
+	}
+
+	// The synchronous path was not used, and the result is cached
+	if got := atomic.LoadInt32(&mock.callCount); got != 0 {
+		t.Errorf("Expected no synchronous SuggestTags calls, got %d", got)
+	}
+	if _, err := ts.SuggestTagsStreaming(context.Background(), 1, "Writing Go tests", nil); err != nil {
+		t.Fatalf("SuggestTagsStreaming() error: %v", err)
+	}
+	if hits := ts.Metrics().CacheHits; hits != 1 {
+		t.Errorf("Expected 1 cache hit, got %d", hits)
+	}
+}
+
+func TestSuggestTagsStreaming_TextResponse(t *testing.T) {
+	mock := &mockLLMService{
+		streamFunc: func(ctx context.Context, req *CompletionRequest) (<-chan CompletionChunk, error) {
+			ch := make(chan CompletionChunk, 3)
+			ch <- CompletionChunk{Content: "Tags: golang,"}
+			ch <- CompletionChunk{Content: " testing"}
+			ch <- CompletionChunk{Done: true, Model: "gpt-4o-mini", Usage: &TokenUsage{PromptTokens: 20, CompletionTokens: 5, TotalTokens: 25}}
+			close(ch)
+			return ch, nil
+		},
+	}
+	ts := NewTagService(mock, DefaultTagServiceConfig())
+	defer ts.Stop()
+
+	resp, err := ts.SuggestTagsStreaming(context.Background(), 1, "Writing Go tests", nil)
+	if err != nil {
+		t.Fatalf("SuggestTagsStreaming() error: %v", err)
+	}
+
+	if want := []string{"golang", "testing"}; !reflect.DeepEqual(resp.Tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, resp.Tags)
+	}
+	if resp.Model != "gpt-4o-mini" || resp.Usage == nil || resp.Usage.TotalTokens != 25 {
+		t.Errorf("Expected model and usage from the terminal chunk, got %q %+v", resp.Model, resp.Usage)
+	}
+	if usage := ts.GetUserUsage(1); usage.TotalTokens != 25 {
+		t.Errorf("Expected 25 tokens recorded for the user, got %d", usage.TotalTokens)
+	}
+}
+
+func TestSuggestTagsStreaming_StreamError(t *testing.T) {
+	mock := &mockLLMService{
+		streamFunc: func(ctx context.Context, req *CompletionRequest) (<-chan CompletionChunk, error) {
+			ch := make(chan CompletionChunk, 2)
+			ch <- CompletionChunk{Content: `[{"tag": "go`}
+			ch <- CompletionChunk{Err: ErrProviderUnavailable}
+			close(ch)
+			return ch, nil
+		},
+	}
+	ts := NewTagService(mock, DefaultTagServiceConfig())
+	defer ts.Stop()
+
+	if _, err := ts.SuggestTagsStreaming(context.Background(), 1, "content", nil); !errors.Is(err, ErrProviderUnavailable) {
+		t.Errorf("Expected ErrProviderUnavailable, got %v", err)
+	}
+}
+
+func TestSuggestTagsStreaming_FallsBackWithoutStreaming(t *testing.T) {
+	mock := &mockLLMService{}
+	ts := NewTagService(mock, DefaultTagServiceConfig())
+	defer ts.Stop()
+
+	resp, err := ts.SuggestTagsStreaming(context.Background(), 1, "content", nil)
+	if err != nil {
+		t.Fatalf("SuggestTagsStreaming() error: %v", err)
+	}
+
+	if want := []string{"tag1", "tag2", "tag3"}; !reflect.DeepEqual(resp.Tags, want) {
+		t.Errorf("Expected tags from the synchronous path %v, got %v", want, resp.Tags)
+	}
+	if got := atomic.LoadInt32(&mock.callCount); got != 1 {
+		t.Errorf("Expected 1 synchronous SuggestTags call, got %d", got)
+	}
+}
+
+func TestFirstJSONArray(t *testing.T) {
+	tests := []struct {
+		text  string
+		want  string
+		found bool
+	}{
+		{`["a", "b"]`, `["a", "b"]`, true},
+		{`Tags: ["a", "b"] done`, `["a", "b"]`, true},
+		{`[{"tag": "a", "score": 0.5}, {"tag": "b"`, "", false},
+		{`no array here`, "", false},
+		{`["a", "b"`, "", false},
+	}
+
+	for _, tt := range tests {
+		got, found := firstJSONArray(tt.text)
+		if got != tt.want || found != tt.found {
+			t.Errorf("firstJSONArray(%q) = %q, %v; want %q, %v", tt.text, got, found, tt.want, tt.found)
+		}
+	}
+}
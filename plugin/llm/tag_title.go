@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// cachedTitle represents a cached title suggestion.
+type cachedTitle struct {
+	result    *SuggestTitleResponse
+	createdAt time.Time
+}
+
+// SuggestTitle suggests a title for content with the same rate limiting and
+// caching as SuggestTags. maxLength of 0 uses the provider default.
+func (ts *TagService) SuggestTitle(ctx context.Context, userID int32, content string, maxLength int) (*SuggestTitleResponse, error) {
+	if !ts.checkRateLimit(userID) {
+		return nil, ErrRateLimitExceeded
+	}
+
+	key := titleCacheKey(content, maxLength, ts.config.Language)
+	if cached := ts.getTitleFromCache(key); cached != nil {
+		return cached, nil
+	}
+
+	ts.metrics.llmCalls.Add(1)
+	result, err := ts.llmService.SuggestTitle(ctx, &SuggestTitleRequest{
+		Content:   content,
+		MaxLength: maxLength,
+		Language:  ts.config.Language,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ts.recordUserUsage(userID, result.Model, result.Usage)
+	ts.cacheTitle(key, result)
+
+	slog.Info("Title suggestion generated",
+		slog.Int("user_id", int(userID)),
+		slog.Int("title_length", len(result.Title)))
+
+	return result, nil
+}
+
+// titleCacheKey generates a cache key from content and title options.
+func titleCacheKey(content string, maxLength int, language string) string {
+	h := sha256.New()
+	h.Write([]byte(content))
+	fmt.Fprintf(h, "\x00%d\x00%s", maxLength, language)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// getTitleFromCache retrieves a title from cache if available and not expired.
+func (ts *TagService) getTitleFromCache(key string) *SuggestTitleResponse {
+	ts.titleCacheMu.Lock()
+	defer ts.titleCacheMu.Unlock()
+
+	cached, exists := ts.titleCache[key]
+	if !exists {
+		return nil
+	}
+	if ts.clock.Now().Sub(cached.createdAt) > ts.config.CacheTTL {
+		delete(ts.titleCache, key)
+		return nil
+	}
+
+	// The cached result cost nothing this time
+	result := *cached.result
+	result.Usage = nil
+	return &result
+}
+
+// cacheTitle stores a title in the cache, evicting the oldest entry if full.
+func (ts *TagService) cacheTitle(key string, result *SuggestTitleResponse) {
+	ts.titleCacheMu.Lock()
+	defer ts.titleCacheMu.Unlock()
+
+	if len(ts.titleCache) >= ts.config.MaxCacheSize {
+		var oldestKey string
+		var oldest time.Time
+		for k, entry := range ts.titleCache {
+			if oldestKey == "" || entry.createdAt.Before(oldest) {
+				oldestKey, oldest = k, entry.createdAt
+			}
+		}
+		delete(ts.titleCache, oldestKey)
+	}
+
+	ts.titleCache[key] = &cachedTitle{
+		result:    result,
+		createdAt: ts.clock.Now(),
+	}
+}
@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTagServiceSuggestTitle_Caches(t *testing.T) {
+	mock := &mockLLMService{
+		suggestTitleFunc: func(ctx context.Context, req *SuggestTitleRequest) (*SuggestTitleResponse, error) {
+			if req.MaxLength != 40 || req.Language != "en" {
+				t.Errorf("Unexpected request: %+v", req)
+			}
+			return &SuggestTitleResponse{Title: "Meeting Notes", Usage: &TokenUsage{TotalTokens: 10}}, nil
+		},
+	}
+	ts := NewTagService(mock, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		Language:          "en",
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 100,
+		RateLimitWindow:   time.Minute,
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		resp, err := ts.SuggestTitle(ctx, 1, "notes from the meeting", 40)
+		if err != nil {
+			t.Fatalf("SuggestTitle() error: %v", err)
+		}
+		if resp.Title != "Meeting Notes" {
+			t.Errorf("Expected title Meeting Notes, got %q", resp.Title)
+		}
+	}
+
+	if got := mock.GetCallCount(); got != 1 {
+		t.Errorf("Expected 1 LLM call with caching, got %d", got)
+	}
+}
+
+func TestTagServiceSuggestTitle_RateLimited(t *testing.T) {
+	ts := NewTagService(&mockLLMService{}, &TagServiceConfig{
+		MaxTagsPerRequest: 5,
+		CacheTTL:          15 * time.Minute,
+		MaxCacheSize:      100,
+		RateLimitRequests: 1,
+		RateLimitWindow:   time.Minute,
+	})
+
+	ctx := context.Background()
+	if _, err := ts.SuggestTitle(ctx, 1, "first", 0); err != nil {
+		t.Fatalf("SuggestTitle() error: %v", err)
+	}
+	if _, err := ts.SuggestTitle(ctx, 1, "second", 0); err != ErrRateLimitExceeded {
+		t.Errorf("Expected ErrRateLimitExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// connectionTestRequest is the minimal authenticated request sent by TestProvider.
+var connectionTestRequest = &CompletionRequest{
+	Messages:  []Message{{Role: RoleUser, Content: "ping"}},
+	MaxTokens: 1,
+}
+
+// TestProvider verifies that the registered provider of the given type accepts
+// its credentials by sending a 1-token completion, bounded by the health check
+// timeout. It returns nil on success, an error wrapping ErrInvalidAPIKey if the
+// key was rejected, ErrProviderUnavailable if the provider could not be
+// reached, or ErrProviderNotConfigured if it has no key.
+func (s *service) TestProvider(ctx context.Context, providerType ProviderType) error {
+	provider, err := s.GetProviderByType(providerType)
+	if err != nil {
+		return err
+	}
+	return s.testConnection(ctx, provider)
+}
+
+// TestStoredKey is like TestProvider but uses the user's key from keys instead
+// of the registered provider's key. Other settings (base URL, timeouts) are
+// taken from the registered provider of that type, if any. On success the key
+// is marked used.
+func (s *service) TestStoredKey(ctx context.Context, keys KeyStorageService, userID int32, providerType ProviderType) error {
+	apiKey, err := keys.GetKey(ctx, userID, providerType)
+	if err != nil {
+		return err
+	}
+
+	config := &ProviderConfig{Type: providerType}
+	if registered, err := s.GetProviderByType(providerType); err == nil {
+		if source, ok := registered.(configSource); ok {
+			copied := *source.providerConfig()
+			config = &copied
+		}
+	}
+	config.APIKey = apiKey
+
+	provider, err := newProviderFromConfig(config)
+	if err != nil {
+		return err
+	}
+	defer closeProvider(provider)
+
+	if err := s.testConnection(ctx, provider); err != nil {
+		return err
+	}
+	if err := keys.MarkKeyUsed(ctx, userID, providerType); err != nil {
+		return fmt.Errorf("failed to mark key used: %w", err)
+	}
+	return nil
+}
+
+// testConnection sends the connection test request and classifies the result.
+func (s *service) testConnection(ctx context.Context, provider Provider) error {
+	if !provider.IsConfigured(ctx) {
+		return ErrProviderNotConfigured
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.healthCheckTimeout)
+	defer cancel()
+
+	_, err := provider.Complete(ctx, connectionTestRequest)
+	providerType := provider.GetType()
+
+	var netErr net.Error
+	switch {
+	case err == nil, errors.Is(err, ErrEmptyResponse):
+		// An empty 1-token reply still proves the key was accepted
+		return nil
+	case errors.Is(err, ErrInvalidAPIKey), errors.Is(err, ErrProviderUnavailable):
+		return fmt.Errorf("%s connection test failed: %w", providerType, err)
+	case errors.As(err, &netErr), errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%s connection test failed: %w: %w", providerType, ErrProviderUnavailable, err)
+	default:
+		return fmt.Errorf("%s connection test failed: %w", providerType, err)
+	}
+}
+
+// configSource is implemented by providers built on BaseProvider.
+type configSource interface {
+	providerConfig() *ProviderConfig
+}
+
+// providerConfig returns the provider's configuration.
+func (b *BaseProvider) providerConfig() *ProviderConfig {
+	return b.Config
+}
+
+// newProviderFromConfig creates a provider of config.Type.
+func newProviderFromConfig(config *ProviderConfig) (Provider, error) {
+	switch config.Type {
+	case ProviderOpenAI:
+		return NewOpenAIProvider(config), nil
+	case ProviderOpenAICompatible:
+		return NewOpenAICompatibleProvider(config), nil
+	case ProviderGroq:
+		return NewGroqProvider(config), nil
+	case ProviderAnthropic:
+		return NewAnthropicProvider(config), nil
+	case ProviderGemini:
+		return NewGeminiProvider(config), nil
+	case ProviderOllama:
+		return NewOllamaProvider(config), nil
+	case ProviderCohere:
+		return NewCohereProvider(config), nil
+	case ProviderMock:
+		return NewMockEchoProvider(config), nil
+	default:
+		return nil, fmt.Errorf("unsupported provider type %q", config.Type)
+	}
+}
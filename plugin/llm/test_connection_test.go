@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newConnectionTestServer returns an OpenAI-style server that accepts only validKey.
+func newConnectionTestServer(t *testing.T, validKey string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") != "Bearer "+validKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"message":"Incorrect API key provided"}}`))
+			return
+		}
+		w.Write([]byte(`{"model":"gpt-4o-mini","choices":[{"index":0,"message":{"role":"assistant","content":"p"},"finish_reason":"length"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTestProvider(t *testing.T) {
+	server := newConnectionTestServer(t, "good-key")
+
+	// A closed server yields a network error
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	tests := []struct {
+		name    string
+		apiKey  string
+		baseURL string
+		wantErr error
+	}{
+		{"success", "good-key", server.URL, nil},
+		{"rejected key", "bad-key", server.URL, ErrInvalidAPIKey},
+		{"unreachable", "good-key", downURL, ErrProviderUnavailable},
+		{"no key", "", server.URL, ErrProviderNotConfigured},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewService()
+			svc.RegisterProvider(NewOpenAIProvider(&ProviderConfig{
+				Type:        ProviderOpenAI,
+				APIKey:      tt.apiKey,
+				BaseURL:     tt.baseURL,
+				RetryPolicy: &RetryPolicy{MaxAttempts: 1},
+			}))
+
+			err := svc.TestProvider(context.Background(), ProviderOpenAI)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Expected success, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTestProvider_NotRegistered(t *testing.T) {
+	if err := NewService().TestProvider(context.Background(), ProviderAnthropic); err == nil {
+		t.Error("Expected an error for an unregistered provider")
+	}
+}
+
+func TestTestStoredKey(t *testing.T) {
+	server := newConnectionTestServer(t, "sk-stored-key-123456")
+	ctx := context.Background()
+
+	keys, err := NewInMemoryKeyStorage("test-master-key-32-bytes-long!!")
+	if err != nil {
+		t.Fatalf("NewInMemoryKeyStorage() error: %v", err)
+	}
+	keys.StoreKey(ctx, 1, ProviderOpenAI, "sk-stored-key-123456")
+	keys.StoreKey(ctx, 2, ProviderOpenAI, "sk-revoked-key-123456")
+
+	// The registered provider has a different key; the base URL is reused
+	svc := NewService()
+	svc.RegisterProvider(NewOpenAIProvider(&ProviderConfig{
+		Type:    ProviderOpenAI,
+		APIKey:  "sk-server-key",
+		BaseURL: server.URL,
+	}))
+
+	if err := svc.TestStoredKey(ctx, keys, 1, ProviderOpenAI); err != nil {
+		t.Fatalf("TestStoredKey() error: %v", err)
+	}
+	if stored, _ := keys.GetStoredKey(ctx, 1, ProviderOpenAI); stored.UseCount != 1 || stored.LastUsedAt == nil {
+		t.Errorf("Expected the key to be marked used, got %+v", stored)
+	}
+
+	if err := svc.TestStoredKey(ctx, keys, 2, ProviderOpenAI); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected ErrInvalidAPIKey, got %v", err)
+	}
+	if stored, _ := keys.GetStoredKey(ctx, 2, ProviderOpenAI); stored.UseCount != 0 {
+		t.Errorf("Expected a rejected key not to be marked used, got %d uses", stored.UseCount)
+	}
+
+	if err := svc.TestStoredKey(ctx, keys, 3, ProviderOpenAI); err == nil {
+		t.Error("Expected an error for a user without a stored key")
+	}
+}
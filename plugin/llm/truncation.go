@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// TruncationStrategy controls how Complete shortens a request that would not
+// fit the model's context window.
+type TruncationStrategy string
+
+const (
+	// TruncationNone sends the request unchanged (the default).
+	TruncationNone TruncationStrategy = "none"
+
+	// TruncationTail drops the end of the content.
+	TruncationTail TruncationStrategy = "tail"
+
+	// TruncationMiddle keeps the beginning and end of the content and drops the middle.
+	TruncationMiddle TruncationStrategy = "middle"
+)
+
+// truncationMarker replaces the text removed by TruncationMiddle.
+const truncationMarker = "\n...\n"
+
+// contextWindow returns the known context window of model, or 0 if unknown.
+func contextWindow(model string) int {
+	if info, ok := LookupModelInfo(model); ok {
+		return info.ContextWindow
+	}
+	return 0
+}
+
+// truncateToContext applies req.TruncationStrategy when the estimated prompt
+// plus req.MaxTokens exceeds window. Only the longest user message is
+// shortened; the caller's request and messages are never modified. It returns
+// the request to send and whether it was truncated. If trimming that message
+// cannot make the request fit, it returns ErrContextTooLong. A window of 0
+// (unknown) disables truncation.
+func truncateToContext(req *CompletionRequest, window int) (*CompletionRequest, bool, error) {
+	if window <= 0 || req.TruncationStrategy == "" || req.TruncationStrategy == TruncationNone {
+		return req, false, nil
+	}
+
+	limit := window - req.MaxTokens
+	total := 0
+	target := -1
+	for i, m := range req.Messages {
+		total += estimateTokens(m.Content)
+		if m.Role == RoleUser && (target < 0 || len(m.Content) > len(req.Messages[target].Content)) {
+			target = i
+		}
+	}
+	if total <= limit {
+		return req, false, nil
+	}
+	if target < 0 {
+		return nil, false, fmt.Errorf("%w: no user message to truncate", ErrContextTooLong)
+	}
+
+	content := req.Messages[target].Content
+	keepTokens := estimateTokens(content) - (total - limit)
+	keepRunes := keepTokens * charsPerToken
+	if req.TruncationStrategy == TruncationMiddle {
+		keepRunes -= utf8.RuneCountInString(truncationMarker)
+	}
+	if keepRunes <= 0 {
+		return nil, false, fmt.Errorf("%w: estimated %d tokens exceeds %d even after truncation", ErrContextTooLong, total, limit)
+	}
+
+	runes := []rune(content)
+	var trimmed string
+	switch req.TruncationStrategy {
+	case TruncationTail:
+		trimmed = string(runes[:keepRunes])
+	case TruncationMiddle:
+		head := keepRunes / 2
+		trimmed = string(runes[:head]) + truncationMarker + string(runes[len(runes)-(keepRunes-head):])
+	default:
+		return nil, false, fmt.Errorf("unknown truncation strategy %q", req.TruncationStrategy)
+	}
+
+	out := *req
+	out.Messages = make([]Message, len(req.Messages))
+	copy(out.Messages, req.Messages)
+	out.Messages[target].Content = trimmed
+	return &out, true, nil
+}
@@ -0,0 +1,144 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func promptTokens(messages []Message) int {
+	total := 0
+	for _, m := range messages {
+		total += estimateTokens(m.Content)
+	}
+	return total
+}
+
+func TestTruncateToContext(t *testing.T) {
+	const window = 1000
+	content := strings.Repeat("a", 2000) + strings.Repeat("m", 2000) + strings.Repeat("z", 2000)
+
+	for _, strategy := range []TruncationStrategy{TruncationTail, TruncationMiddle} {
+		t.Run(string(strategy), func(t *testing.T) {
+			req := &CompletionRequest{
+				Messages: []Message{
+					{Role: RoleSystem, Content: "You are helpful."},
+					{Role: RoleUser, Content: content},
+				},
+				MaxTokens:          200,
+				TruncationStrategy: strategy,
+			}
+
+			out, truncated, err := truncateToContext(req, window)
+			if err != nil {
+				t.Fatalf("truncateToContext() error: %v", err)
+			}
+			if !truncated {
+				t.Fatal("Expected the request to be truncated")
+			}
+			if got := promptTokens(out.Messages) + out.MaxTokens; got > window {
+				t.Errorf("Expected request to fit %d tokens, got %d", window, got)
+			}
+			if req.Messages[1].Content != content {
+				t.Error("Caller's request must not be modified")
+			}
+
+			trimmed := out.Messages[1].Content
+			if !strings.HasPrefix(trimmed, "a") {
+				t.Errorf("Expected the beginning to be kept, got %q...", trimmed[:10])
+			}
+			switch strategy {
+			case TruncationTail:
+				if strings.Contains(trimmed, "z") {
+					t.Error("Tail truncation should drop the end")
+				}
+			case TruncationMiddle:
+				if !strings.HasSuffix(trimmed, "z") || strings.Contains(trimmed, "m") {
+					t.Error("Middle truncation should keep both ends and drop the middle")
+				}
+				if !strings.Contains(trimmed, truncationMarker) {
+					t.Error("Expected the truncation marker")
+				}
+			}
+		})
+	}
+}
+
+func TestTruncateToContext_NoChange(t *testing.T) {
+	long := &CompletionRequest{
+		Messages: []Message{{Role: RoleUser, Content: strings.Repeat("a", 8000)}},
+	}
+	short := &CompletionRequest{
+		Messages:           []Message{{Role: RoleUser, Content: "hello"}},
+		TruncationStrategy: TruncationTail,
+	}
+
+	tests := []struct {
+		name   string
+		req    *CompletionRequest
+		window int
+	}{
+		{"no strategy", long, 1000},
+		{"fits", short, 1000},
+		{"unknown window", &CompletionRequest{Messages: long.Messages, TruncationStrategy: TruncationTail}, 0},
+	}
+
+	for _, tt := range tests {
+		out, truncated, err := truncateToContext(tt.req, tt.window)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if truncated || out != tt.req {
+			t.Errorf("%s: expected request unchanged", tt.name)
+		}
+	}
+}
+
+func TestTruncateToContext_TooLong(t *testing.T) {
+	req := &CompletionRequest{
+		Messages: []Message{
+			{Role: RoleSystem, Content: strings.Repeat("s", 4000)},
+			{Role: RoleUser, Content: "hello"},
+		},
+		TruncationStrategy: TruncationTail,
+	}
+
+	if _, _, err := truncateToContext(req, 500); !errors.Is(err, ErrContextTooLong) {
+		t.Errorf("Expected ErrContextTooLong, got %v", err)
+	}
+}
+
+func TestOpenAIProviderCompleteTruncates(t *testing.T) {
+	var sent openAIChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"gpt-4","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(&ProviderConfig{Type: ProviderOpenAI, APIKey: "test-key", BaseURL: server.URL})
+
+	// gpt-4 has an 8192-token window
+	resp, err := provider.Complete(context.Background(), &CompletionRequest{
+		Model:              "gpt-4",
+		Messages:           []Message{{Role: RoleUser, Content: strings.Repeat("word ", 8000)}},
+		MaxTokens:          500,
+		TruncationStrategy: TruncationMiddle,
+	})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if !resp.Truncated {
+		t.Error("Expected Truncated to be set")
+	}
+	if got := estimateTokens(sent.Messages[0].Content.(string)) + 500; got > 8192 {
+		t.Errorf("Expected the sent prompt to fit the window, got %d tokens", got)
+	}
+}
@@ -0,0 +1,162 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// InstanceUserID is the user ID whose stored keys act as instance-level
+// defaults for users without a key of their own.
+const InstanceUserID int32 = 0
+
+// providerOverrideKey carries the provider set by WithProviderOverride.
+type providerOverrideKey struct{}
+
+// WithProviderOverride returns a context that makes Service calls use provider
+// instead of the active provider (e.g., one built from a user's own key).
+// Balancing does not apply to the override.
+func WithProviderOverride(ctx context.Context, provider Provider) context.Context {
+	return context.WithValue(ctx, providerOverrideKey{}, provider)
+}
+
+// providerOverride returns the provider set by WithProviderOverride, if any.
+func providerOverride(ctx context.Context) Provider {
+	provider, _ := ctx.Value(providerOverrideKey{}).(Provider)
+	return provider
+}
+
+// UserProviderResolver resolves the provider to use for a user: one built
+// from the user's own stored key, else from an instance-level key stored
+// under InstanceUserID, else none (the service's active provider applies).
+type UserProviderResolver struct {
+	service Service
+	keys    KeyStorageService
+
+	// providers caches the provider built for each user and type, rebuilt
+	// when the stored key changes
+	providers   map[userProviderKey]*userProvider
+	providersMu sync.Mutex
+}
+
+type userProviderKey struct {
+	userID       int32
+	providerType ProviderType
+}
+
+type userProvider struct {
+	apiKey   string
+	provider Provider
+}
+
+// NewUserProviderResolver creates a resolver that reads keys from keys. Other
+// provider settings (base URL, timeouts) are copied from the provider of the
+// same type registered with service, if any.
+func NewUserProviderResolver(service Service, keys KeyStorageService) *UserProviderResolver {
+	return &UserProviderResolver{
+		service:   service,
+		keys:      keys,
+		providers: make(map[userProviderKey]*userProvider),
+	}
+}
+
+// Resolve returns the provider for userID, or nil if neither the user nor the
+// instance has a stored key, meaning the service's active provider applies.
+// When several keys are stored, the active provider type is preferred, then
+// the service's fallback order.
+func (r *UserProviderResolver) Resolve(ctx context.Context, userID int32) (Provider, error) {
+	userIDs := []int32{userID}
+	if userID != InstanceUserID {
+		userIDs = append(userIDs, InstanceUserID)
+	}
+
+	for _, id := range userIDs {
+		stored, err := r.keys.ListKeysByProvider(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, providerType := range r.preferenceOrder() {
+			if _, ok := stored[providerType]; !ok {
+				continue
+			}
+			// An expired or undecryptable key falls through to the next candidate
+			apiKey, err := r.keys.GetKey(ctx, id, providerType)
+			if err != nil {
+				continue
+			}
+			return r.provider(id, providerType, apiKey)
+		}
+	}
+	return nil, nil
+}
+
+// preferenceOrder returns the active provider type followed by the fallback
+// order, honoring an order set with ConfigManager.SetFallbackOrder.
+func (r *UserProviderResolver) preferenceOrder() []ProviderType {
+	fallbackOrder := defaultFallbackOrder
+	if orderer, ok := r.service.(fallbackOrderer); ok {
+		fallbackOrder = orderer.getFallbackOrder()
+	}
+
+	order := make([]ProviderType, 0, len(fallbackOrder)+1)
+	if active := r.service.GetProvider(); active != nil {
+		order = append(order, active.GetType())
+	}
+	for _, providerType := range fallbackOrder {
+		if len(order) == 0 || providerType != order[0] {
+			order = append(order, providerType)
+		}
+	}
+	return order
+}
+
+// provider returns the cached provider for userID and providerType, building
+// a new one if apiKey changed.
+func (r *UserProviderResolver) provider(userID int32, providerType ProviderType, apiKey string) (Provider, error) {
+	key := userProviderKey{userID: userID, providerType: providerType}
+
+	r.providersMu.Lock()
+	defer r.providersMu.Unlock()
+
+	cached, ok := r.providers[key]
+	if ok && cached.apiKey == apiKey {
+		return cached.provider, nil
+	}
+
+	config := &ProviderConfig{Type: providerType}
+	if registered, err := r.service.GetProviderByType(providerType); err == nil {
+		if source, ok := registered.(configSource); ok {
+			copied := *source.providerConfig()
+			config = &copied
+		}
+	}
+	config.APIKey = apiKey
+
+	provider, err := newProviderFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		closeProvider(cached.provider)
+	}
+	r.providers[key] = &userProvider{apiKey: apiKey, provider: provider}
+	return provider, nil
+}
+
+// withUserProvider returns ctx overridden with the provider resolved for
+// userID by the configured ProviderResolver, or ctx unchanged if none applies.
+func (ts *TagService) withUserProvider(ctx context.Context, userID int32) (context.Context, error) {
+	if ts.config.ProviderResolver == nil {
+		return ctx, nil
+	}
+
+	provider, err := ts.config.ProviderResolver.Resolve(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve provider for user %d: %w", userID, err)
+	}
+	if provider == nil {
+		return ctx, nil
+	}
+	return WithProviderOverride(ctx, provider), nil
+}
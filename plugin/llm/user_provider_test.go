@@ -0,0 +1,144 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newUserProviderTestService returns a service whose active provider is an
+// Ollama server answering with ["ollama"], plus a keyless OpenAI provider
+// pointing at a server that answers with ["openai"] for any key, counting the
+// keys it saw.
+func newUserProviderTestService(t *testing.T) (Service, map[string]int) {
+	t.Helper()
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"llama3.2","message":{"role":"assistant","content":"[\"ollama\"]"},"done":true}`))
+	}))
+	t.Cleanup(ollama.Close)
+
+	seenKeys := make(map[string]int)
+	openai := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenKeys[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"gpt-4o-mini","choices":[{"index":0,"message":{"role":"assistant","content":"[\"openai\"]"},"finish_reason":"stop"}]}`))
+	}))
+	t.Cleanup(openai.Close)
+
+	svc := NewService()
+	svc.RegisterProvider(NewOllamaProvider(&ProviderConfig{Type: ProviderOllama, OllamaHost: ollama.URL}))
+	svc.RegisterProvider(NewOpenAIProvider(&ProviderConfig{Type: ProviderOpenAI, BaseURL: openai.URL}))
+	if err := svc.SetActiveProvider(ProviderOllama); err != nil {
+		t.Fatalf("SetActiveProvider() error: %v", err)
+	}
+	return svc, seenKeys
+}
+
+func TestTagService_UserProviderResolver(t *testing.T) {
+	ctx := context.Background()
+	svc, seenKeys := newUserProviderTestService(t)
+
+	keys, err := NewInMemoryKeyStorage("test-master-key-32-bytes-long!!")
+	if err != nil {
+		t.Fatalf("NewInMemoryKeyStorage() error: %v", err)
+	}
+	keys.StoreKey(ctx, 1, ProviderOpenAI, "sk-user-one-1234567890")
+
+	config := DefaultTagServiceConfig()
+	config.EnableAsync = false
+	config.ProviderResolver = NewUserProviderResolver(svc, keys)
+	ts := NewTagService(svc, config)
+
+	// User 1 uses their personal OpenAI key
+	result, err := ts.SuggestTags(ctx, 1, "memo from user one", nil)
+	if err != nil {
+		t.Fatalf("SuggestTags() error: %v", err)
+	}
+	if len(result.Tags) != 1 || result.Tags[0] != "openai" {
+		t.Errorf("Expected user 1 to get OpenAI tags, got %v", result.Tags)
+	}
+	if seenKeys["sk-user-one-1234567890"] != 1 {
+		t.Errorf("Expected one request with user 1's key, got %v", seenKeys)
+	}
+
+	// User 2 has no key and falls back to the instance Ollama provider
+	result, err = ts.SuggestTags(ctx, 2, "memo from user two", nil)
+	if err != nil {
+		t.Fatalf("SuggestTags() error: %v", err)
+	}
+	if len(result.Tags) != 1 || result.Tags[0] != "ollama" {
+		t.Errorf("Expected user 2 to get Ollama tags, got %v", result.Tags)
+	}
+	if len(seenKeys) != 1 {
+		t.Errorf("Expected no further OpenAI requests, got %v", seenKeys)
+	}
+}
+
+func TestUserProviderResolver_Resolve(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newUserProviderTestService(t)
+
+	keys, err := NewInMemoryKeyStorage("test-master-key-32-bytes-long!!")
+	if err != nil {
+		t.Fatalf("NewInMemoryKeyStorage() error: %v", err)
+	}
+	resolver := NewUserProviderResolver(svc, keys)
+
+	provider, err := resolver.Resolve(ctx, 1)
+	if err != nil || provider != nil {
+		t.Fatalf("Expected no provider without stored keys, got %v, %v", provider, err)
+	}
+
+	// An instance-level key applies to users without their own
+	keys.StoreKey(ctx, InstanceUserID, ProviderOpenAI, "sk-instance-1234567890")
+	instance, err := resolver.Resolve(ctx, 1)
+	if err != nil || instance == nil || instance.GetType() != ProviderOpenAI {
+		t.Fatalf("Expected the instance OpenAI provider, got %v, %v", instance, err)
+	}
+	if again, _ := resolver.Resolve(ctx, 2); again != instance {
+		t.Error("Expected the instance provider to be cached and shared")
+	}
+
+	// The user's own key wins over the instance key
+	keys.StoreKey(ctx, 1, ProviderOpenAI, "sk-user-one-1234567890")
+	own, err := resolver.Resolve(ctx, 1)
+	if err != nil || own == nil || own == instance {
+		t.Fatalf("Expected a provider built from user 1's key, got %v, %v", own, err)
+	}
+
+	// A changed key rebuilds the provider
+	keys.UpdateKey(ctx, 1, ProviderOpenAI, "sk-user-one-rotated-1234567890")
+	if rotated, _ := resolver.Resolve(ctx, 1); rotated == own {
+		t.Error("Expected a new provider after the key changed")
+	}
+}
+
+func TestUserProviderResolver_HonorsFallbackOrder(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newUserProviderTestService(t)
+
+	keys, err := NewInMemoryKeyStorage("test-master-key-32-bytes-long!!")
+	if err != nil {
+		t.Fatalf("NewInMemoryKeyStorage() error: %v", err)
+	}
+	keys.StoreKey(ctx, 1, ProviderOpenAI, "sk-user-one-1234567890")
+	keys.StoreKey(ctx, 1, ProviderAnthropic, "sk-ant-user-one-1234567890")
+	resolver := NewUserProviderResolver(svc, keys)
+
+	// The default order prefers OpenAI over Anthropic
+	provider, err := resolver.Resolve(ctx, 1)
+	if err != nil || provider == nil || provider.GetType() != ProviderOpenAI {
+		t.Fatalf("Expected OpenAI under the default order, got %v, %v", provider, err)
+	}
+
+	if err := NewConfigManager(svc).SetFallbackOrder([]ProviderType{ProviderAnthropic, ProviderOpenAI}); err != nil {
+		t.Fatalf("SetFallbackOrder() error: %v", err)
+	}
+	provider, err = resolver.Resolve(ctx, 1)
+	if err != nil || provider == nil || provider.GetType() != ProviderAnthropic {
+		t.Fatalf("Expected Anthropic under the configured order, got %v, %v", provider, err)
+	}
+}
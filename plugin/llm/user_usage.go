@@ -0,0 +1,41 @@
+package llm
+
+// UsageSummary is the token usage and estimated cost attributed to one user.
+// It has the same fields as the service-wide UsageStats.
+type UsageSummary = UsageStats
+
+// recordUserUsage adds the usage of a completed suggestion or summary to the
+// user's running totals. Cost is always estimated for per-user accounting.
+func (ts *TagService) recordUserUsage(userID int32, model string, usage *TokenUsage) {
+	if usage == nil {
+		return
+	}
+
+	cost := estimateCostUSD(model, usage)
+
+	ts.userUsageMu.Lock()
+	defer ts.userUsageMu.Unlock()
+
+	summary, exists := ts.userUsage[userID]
+	if !exists {
+		summary = &UsageSummary{}
+		ts.userUsage[userID] = summary
+	}
+	summary.Requests++
+	summary.PromptTokens += int64(usage.PromptTokens)
+	summary.CompletionTokens += int64(usage.CompletionTokens)
+	summary.TotalTokens += int64(usage.TotalTokens)
+	summary.EstimatedCostUSD += cost
+}
+
+// GetUserUsage returns the usage accumulated by a user's tag suggestions and
+// summaries. Cache hits cost nothing and are not counted.
+func (ts *TagService) GetUserUsage(userID int32) UsageSummary {
+	ts.userUsageMu.Lock()
+	defer ts.userUsageMu.Unlock()
+
+	if summary, exists := ts.userUsage[userID]; exists {
+		return *summary
+	}
+	return UsageSummary{}
+}
@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGetUserUsage_SeparateUsers(t *testing.T) {
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			return &SuggestTagsResponse{
+				Tags:  []string{"work"},
+				Model: "gpt-4o-mini",
+				Usage: &TokenUsage{PromptTokens: 100, CompletionTokens: 10, TotalTokens: 110},
+			}, nil
+		},
+	}
+	config := DefaultTagServiceConfig()
+	config.EnableAsync = false
+	ts := NewTagService(mock, config)
+	defer ts.Stop()
+
+	ctx := context.Background()
+	ts.SuggestTags(ctx, 1, "first memo", nil)
+	ts.SuggestTags(ctx, 1, "second memo", nil)
+	ts.SuggestTags(ctx, 2, "third memo", nil)
+
+	user1 := ts.GetUserUsage(1)
+	if user1.Requests != 2 || user1.PromptTokens != 200 || user1.CompletionTokens != 20 || user1.TotalTokens != 220 {
+		t.Errorf("Unexpected usage for user 1: %+v", user1)
+	}
+	if user1.EstimatedCostUSD <= 0 {
+		t.Errorf("Expected an estimated cost for user 1, got %f", user1.EstimatedCostUSD)
+	}
+
+	user2 := ts.GetUserUsage(2)
+	if user2.Requests != 1 || user2.TotalTokens != 110 {
+		t.Errorf("Unexpected usage for user 2: %+v", user2)
+	}
+
+	if unknown := ts.GetUserUsage(3); unknown != (UsageSummary{}) {
+		t.Errorf("Expected zero usage for a user without requests, got %+v", unknown)
+	}
+
+	// Cache hits are free
+	ts.SuggestTags(ctx, 2, "first memo", nil)
+	if got := ts.GetUserUsage(2); got.Requests != 1 {
+		t.Errorf("Expected cache hit not to add usage, got %+v", got)
+	}
+}
+
+func TestGetUserUsage_AsyncJobs(t *testing.T) {
+	mock := &mockLLMService{
+		suggestTagsFunc: func(ctx context.Context, req *SuggestTagsRequest) (*SuggestTagsResponse, error) {
+			return &SuggestTagsResponse{
+				Tags:  []string{"work"},
+				Usage: &TokenUsage{PromptTokens: 50, CompletionTokens: 5, TotalTokens: 55},
+			}, nil
+		},
+		summarizeFunc: func(ctx context.Context, req *SummarizeRequest) (*SummarizeResponse, error) {
+			return &SummarizeResponse{
+				Summary: "Short.",
+				Usage:   &TokenUsage{PromptTokens: 200, CompletionTokens: 40, TotalTokens: 240},
+			}, nil
+		},
+	}
+	ts := newTestSummarizeTagService(mock, 100)
+	defer ts.Stop()
+
+	tagDone := make(chan *TagJob, 1)
+	summaryDone := make(chan *SummarizeJob, 1)
+	ts.SetJobCallback(func(job *TagJob) { tagDone <- job })
+	ts.SetSummarizeJobCallback(func(job *SummarizeJob) { summaryDone <- job })
+
+	if _, err := ts.SuggestTagsAsync(7, 1, "async memo", nil); err != nil {
+		t.Fatalf("SuggestTagsAsync failed: %v", err)
+	}
+	if _, err := ts.SummarizeAsync(8, 2, "async memo", SummarizeOptions{}); err != nil {
+		t.Fatalf("SummarizeAsync failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-tagDone:
+		case <-summaryDone:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for jobs")
+		}
+	}
+
+	if got := ts.GetUserUsage(7); got.Requests != 1 || got.TotalTokens != 55 {
+		t.Errorf("Unexpected tag usage for user 7: %+v", got)
+	}
+	if got := ts.GetUserUsage(8); got.Requests != 1 || got.TotalTokens != 240 {
+		t.Errorf("Unexpected summary usage for user 8: %+v", got)
+	}
+}
+
+func TestDefaultSummarizeMapReduceUsage(t *testing.T) {
+	provider := &mockProvider{
+		defaultModel: "gpt-4",
+		completeResp: &CompletionResponse{
+			Content: "Chunk summary.",
+			Usage:   &TokenUsage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12},
+		},
+	}
+	base := NewBaseProvider(&ProviderConfig{SummarizeChunkTokens: 5000, SummarizeChunkOverlap: 100})
+
+	resp, err := base.DefaultSummarize(context.Background(), provider, &SummarizeRequest{Content: longContent(20000)})
+	if err != nil {
+		t.Fatalf("DefaultSummarize() error: %v", err)
+	}
+	// 5 chunks plus the reduce step
+	if resp.Usage == nil || resp.Usage.TotalTokens != 6*12 {
+		t.Errorf("Expected usage summed over all completions, got %+v", resp.Usage)
+	}
+}
@@ -0,0 +1,40 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Warmer is implemented by providers with a better way to prime themselves than
+// CheckHealth, such as loading a local model into memory.
+type Warmer interface {
+	// Warmup makes a minimal request so the first real request avoids cold-start costs.
+	Warmup(ctx context.Context) error
+}
+
+// Warmup primes the active provider (DNS, TLS, model load) with a single cheap
+// request: the provider's Warmup if it implements Warmer, otherwise CheckHealth.
+func (s *service) Warmup(ctx context.Context) error {
+	provider := s.GetProvider()
+	if provider == nil {
+		return ErrProviderNotConfigured
+	}
+
+	start := time.Now()
+	var err error
+	if warmer, ok := provider.(Warmer); ok {
+		err = warmer.Warmup(ctx)
+	} else {
+		err = provider.CheckHealth(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to warm up %s: %w", provider.GetType(), err)
+	}
+
+	slog.Info("LLM provider warmed up",
+		slog.String("provider", string(provider.GetType())),
+		slog.Duration("latency", time.Since(start)))
+	return nil
+}
@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	storepb "github.com/usememos/memos/proto/gen/store"
+)
+
+func TestServiceWarmup_Ollama(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/api/generate" {
+			t.Errorf("Expected path /api/generate, got %s", r.URL.Path)
+		}
+
+		var req ollamaGenerateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req.Model != "llama3.2" || req.KeepAlive != "30m" {
+			t.Errorf("Expected model llama3.2 with keep_alive 30m, got %+v", req)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model":"llama3.2","response":"","done":true}`))
+	}))
+	defer server.Close()
+
+	svc := NewService()
+	svc.RegisterProvider(NewOllamaProvider(&ProviderConfig{
+		Type:            ProviderOllama,
+		OllamaHost:      server.URL,
+		OllamaKeepAlive: "30m",
+	}))
+
+	if err := svc.Warmup(context.Background()); err != nil {
+		t.Fatalf("Warmup() error: %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected exactly 1 request, got %d", got)
+	}
+}
+
+func TestServiceWarmup_CheckHealth(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[{"id":"gpt-4o-mini"}]}`))
+	}))
+	defer server.Close()
+
+	svc := NewService()
+	svc.RegisterProvider(NewOpenAIProvider(&ProviderConfig{
+		Type:    ProviderOpenAI,
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+	}))
+
+	if err := svc.Warmup(context.Background()); err != nil {
+		t.Fatalf("Warmup() error: %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected exactly 1 request, got %d", got)
+	}
+}
+
+func TestServiceWarmup_Error(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"model 'llama3.2' not found, try pulling it first"}`))
+	}))
+	defer server.Close()
+
+	svc := NewService()
+	svc.RegisterProvider(NewOllamaProvider(&ProviderConfig{
+		Type:       ProviderOllama,
+		OllamaHost: server.URL,
+	}))
+
+	err := svc.Warmup(context.Background())
+	if !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound, got %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected exactly 1 request, got %d", got)
+	}
+}
+
+func TestServiceWarmup_NoProvider(t *testing.T) {
+	if err := NewService().Warmup(context.Background()); !errors.Is(err, ErrProviderNotConfigured) {
+		t.Errorf("Expected ErrProviderNotConfigured, got %v", err)
+	}
+}
+
+func TestConfigManager_WarmupOnLoad(t *testing.T) {
+	var warmups atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/generate" {
+			warmups.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"done":true}`))
+	}))
+	defer server.Close()
+
+	setting := &storepb.InstanceLLMSetting{
+		Provider:     storepb.InstanceLLMSetting_OLLAMA,
+		OllamaConfig: &storepb.LLMOllamaConfig{Host: server.URL},
+	}
+
+	// Disabled by default
+	if err := NewConfigManager(NewService()).LoadFromProto(context.Background(), setting); err != nil {
+		t.Fatalf("LoadFromProto failed: %v", err)
+	}
+	if got := warmups.Load(); got != 0 {
+		t.Errorf("Expected no warmup by default, got %d", got)
+	}
+
+	manager := NewConfigManager(NewService())
+	manager.SetWarmupOnLoad(true)
+	if err := manager.LoadFromProto(context.Background(), setting); err != nil {
+		t.Fatalf("LoadFromProto failed: %v", err)
+	}
+	if got := warmups.Load(); got != 1 {
+		t.Errorf("Expected 1 warmup request, got %d", got)
+	}
+}
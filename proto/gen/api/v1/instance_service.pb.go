@@ -152,6 +152,10 @@ const (
 	InstanceSetting_LLMSetting_GEMINI InstanceSetting_LLMSetting_LLMProvider = 3
 	// Local Ollama
 	InstanceSetting_LLMSetting_OLLAMA InstanceSetting_LLMSetting_LLMProvider = 4
+	// OpenAI-compatible endpoint (self-hosted or third-party)
+	InstanceSetting_LLMSetting_OPENAI_COMPATIBLE InstanceSetting_LLMSetting_LLMProvider = 5
+	// Groq (OpenAI-compatible, fast inference)
+	InstanceSetting_LLMSetting_GROQ InstanceSetting_LLMSetting_LLMProvider = 6
 )
 
 // Enum value maps for InstanceSetting_LLMSetting_LLMProvider.
@@ -162,6 +166,8 @@ var (
 		2: "ANTHROPIC",
 		3: "GEMINI",
 		4: "OLLAMA",
+		5: "OPENAI_COMPATIBLE",
+		6: "GROQ",
 	}
 	InstanceSetting_LLMSetting_LLMProvider_value = map[string]int32{
 		"LLM_PROVIDER_UNSPECIFIED": 0,
@@ -169,6 +175,8 @@ var (
 		"ANTHROPIC":                2,
 		"GEMINI":                   3,
 		"OLLAMA":                   4,
+		"OPENAI_COMPATIBLE":        5,
+		"GROQ":                     6,
 	}
 )
 
@@ -825,8 +833,12 @@ type InstanceSetting_LLMSetting struct {
 	EnableAutoSummary bool `protobuf:"varint,11,opt,name=enable_auto_summary,json=enableAutoSummary,proto3" json:"enable_auto_summary,omitempty"`
 	// enable_semantic_search enables vector-based semantic search.
 	EnableSemanticSearch bool `protobuf:"varint,12,opt,name=enable_semantic_search,json=enableSemanticSearch,proto3" json:"enable_semantic_search,omitempty"`
-	unknownFields        protoimpl.UnknownFields
-	sizeCache            protoimpl.SizeCache
+	// OpenAI-compatible endpoint configuration.
+	OpenaiCompatibleConfig *InstanceSetting_LLMOpenAIConfig `protobuf:"bytes,6,opt,name=openai_compatible_config,json=openaiCompatibleConfig,proto3" json:"openai_compatible_config,omitempty"`
+	// Groq configuration.
+	GroqConfig    *InstanceSetting_LLMOpenAIConfig `protobuf:"bytes,7,opt,name=groq_config,json=groqConfig,proto3" json:"groq_config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *InstanceSetting_LLMSetting) Reset() {
@@ -915,6 +927,20 @@ func (x *InstanceSetting_LLMSetting) GetEnableSemanticSearch() bool {
 	return false
 }
 
+func (x *InstanceSetting_LLMSetting) GetOpenaiCompatibleConfig() *InstanceSetting_LLMOpenAIConfig {
+	if x != nil {
+		return x.OpenaiCompatibleConfig
+	}
+	return nil
+}
+
+func (x *InstanceSetting_LLMSetting) GetGroqConfig() *InstanceSetting_LLMOpenAIConfig {
+	if x != nil {
+		return x.GroqConfig
+	}
+	return nil
+}
+
 // OpenAI-specific configuration.
 type InstanceSetting_LLMOpenAIConfig struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -926,8 +952,16 @@ type InstanceSetting_LLMOpenAIConfig struct {
 	DefaultModel string `protobuf:"bytes,3,opt,name=default_model,json=defaultModel,proto3" json:"default_model,omitempty"`
 	// Default model for embeddings (e.g., "text-embedding-3-small").
 	EmbeddingModel string `protobuf:"bytes,4,opt,name=embedding_model,json=embeddingModel,proto3" json:"embedding_model,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// Azure OpenAI deployment name. When set, requests use the Azure URL shape and api-key header.
+	AzureDeployment string `protobuf:"bytes,5,opt,name=azure_deployment,json=azureDeployment,proto3" json:"azure_deployment,omitempty"`
+	// Azure OpenAI API version (e.g., "2024-06-01").
+	AzureApiVersion string `protobuf:"bytes,6,opt,name=azure_api_version,json=azureApiVersion,proto3" json:"azure_api_version,omitempty"`
+	// OpenAI organization ID sent as the OpenAI-Organization header.
+	Organization string `protobuf:"bytes,7,opt,name=organization,proto3" json:"organization,omitempty"`
+	// OpenAI project ID sent as the OpenAI-Project header.
+	Project       string `protobuf:"bytes,8,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *InstanceSetting_LLMOpenAIConfig) Reset() {
@@ -988,6 +1022,34 @@ func (x *InstanceSetting_LLMOpenAIConfig) GetEmbeddingModel() string {
 	return ""
 }
 
+func (x *InstanceSetting_LLMOpenAIConfig) GetAzureDeployment() string {
+	if x != nil {
+		return x.AzureDeployment
+	}
+	return ""
+}
+
+func (x *InstanceSetting_LLMOpenAIConfig) GetAzureApiVersion() string {
+	if x != nil {
+		return x.AzureApiVersion
+	}
+	return ""
+}
+
+func (x *InstanceSetting_LLMOpenAIConfig) GetOrganization() string {
+	if x != nil {
+		return x.Organization
+	}
+	return ""
+}
+
+func (x *InstanceSetting_LLMOpenAIConfig) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
 // Anthropic-specific configuration.
 type InstanceSetting_LLMAnthropicConfig struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -1328,7 +1390,7 @@ const file_api_v1_instance_service_proto_rawDesc = "" +
 	"\x04demo\x18\x03 \x01(\bR\x04demo\x12!\n" +
 	"\finstance_url\x18\x06 \x01(\tR\vinstanceUrl\x12 \n" +
 	"\vinitialized\x18\a \x01(\bR\vinitialized\"\x1b\n" +
-	"\x19GetInstanceProfileRequest\"\xea\x18\n" +
+	"\x19GetInstanceProfileRequest\"\xd9\x1b\n" +
 	"\x0fInstanceSetting\x12\x17\n" +
 	"\x04name\x18\x01 \x01(\tB\x03\xe0A\bR\x04name\x12W\n" +
 	"\x0fgeneral_setting\x18\x02 \x01(\v2,.memos.api.v1.InstanceSetting.GeneralSettingH\x00R\x0egeneralSetting\x12W\n" +
@@ -1371,7 +1433,7 @@ const file_api_v1_instance_service_proto_rawDesc = "" +
 	"\x18display_with_update_time\x18\x02 \x01(\bR\x15displayWithUpdateTime\x120\n" +
 	"\x14content_length_limit\x18\x03 \x01(\x05R\x12contentLengthLimit\x127\n" +
 	"\x18enable_double_click_edit\x18\x04 \x01(\bR\x15enableDoubleClickEdit\x12\x1c\n" +
-	"\treactions\x18\a \x03(\tR\treactions\x1a\xad\x05\n" +
+	"\treactions\x18\a \x03(\tR\treactions\x1a\x87\a\n" +
 	"\n" +
 	"LLMSetting\x12P\n" +
 	"\bprovider\x18\x01 \x01(\x0e24.memos.api.v1.InstanceSetting.LLMSetting.LLMProviderR\bprovider\x12R\n" +
@@ -1382,7 +1444,10 @@ const file_api_v1_instance_service_proto_rawDesc = "" +
 	"\x13enable_auto_tagging\x18\n" +
 	" \x01(\bR\x11enableAutoTagging\x12.\n" +
 	"\x13enable_auto_summary\x18\v \x01(\bR\x11enableAutoSummary\x124\n" +
-	"\x16enable_semantic_search\x18\f \x01(\bR\x14enableSemanticSearch\"^\n" +
+	"\x16enable_semantic_search\x18\f \x01(\bR\x14enableSemanticSearch\x12g\n" +
+	"\x18openai_compatible_config\x18\x06 \x01(\v2-.memos.api.v1.InstanceSetting.LLMOpenAIConfigR\x16openaiCompatibleConfig\x12N\n" +
+	"\vgroq_config\x18\a \x01(\v2-.memos.api.v1.InstanceSetting.LLMOpenAIConfigR\n" +
+	"groqConfig\"\x7f\n" +
 	"\vLLMProvider\x12\x1c\n" +
 	"\x18LLM_PROVIDER_UNSPECIFIED\x10\x00\x12\n" +
 	"\n" +
@@ -1391,12 +1456,18 @@ const file_api_v1_instance_service_proto_rawDesc = "" +
 	"\n" +
 	"\x06GEMINI\x10\x03\x12\n" +
 	"\n" +
-	"\x06OLLAMA\x10\x04\x1a\x93\x01\n" +
+	"\x06OLLAMA\x10\x04\x12\x15\n" +
+	"\x11OPENAI_COMPATIBLE\x10\x05\x12\b\n" +
+	"\x04GROQ\x10\x06\x1a\xa8\x02\n" +
 	"\x0fLLMOpenAIConfig\x12\x17\n" +
 	"\aapi_key\x18\x01 \x01(\tR\x06apiKey\x12\x19\n" +
 	"\bbase_url\x18\x02 \x01(\tR\abaseUrl\x12#\n" +
 	"\rdefault_model\x18\x03 \x01(\tR\fdefaultModel\x12'\n" +
-	"\x0fembedding_model\x18\x04 \x01(\tR\x0eembeddingModel\x1am\n" +
+	"\x0fembedding_model\x18\x04 \x01(\tR\x0eembeddingModel\x12)\n" +
+	"\x10azure_deployment\x18\x05 \x01(\tR\x0fazureDeployment\x12*\n" +
+	"\x11azure_api_version\x18\x06 \x01(\tR\x0fazureApiVersion\x12\"\n" +
+	"\forganization\x18\a \x01(\tR\forganization\x12\x18\n" +
+	"\aproject\x18\b \x01(\tR\aproject\x1am\n" +
 	"\x12LLMAnthropicConfig\x12\x17\n" +
 	"\aapi_key\x18\x01 \x01(\tR\x06apiKey\x12\x19\n" +
 	"\bbase_url\x18\x02 \x01(\tR\abaseUrl\x12#\n" +
@@ -1479,17 +1550,19 @@ var file_api_v1_instance_service_proto_depIdxs = []int32{
 	13, // 11: memos.api.v1.InstanceSetting.LLMSetting.anthropic_config:type_name -> memos.api.v1.InstanceSetting.LLMAnthropicConfig
 	14, // 12: memos.api.v1.InstanceSetting.LLMSetting.gemini_config:type_name -> memos.api.v1.InstanceSetting.LLMGeminiConfig
 	15, // 13: memos.api.v1.InstanceSetting.LLMSetting.ollama_config:type_name -> memos.api.v1.InstanceSetting.LLMOllamaConfig
-	4,  // 14: memos.api.v1.InstanceService.GetInstanceProfile:input_type -> memos.api.v1.GetInstanceProfileRequest
-	6,  // 15: memos.api.v1.InstanceService.GetInstanceSetting:input_type -> memos.api.v1.GetInstanceSettingRequest
-	7,  // 16: memos.api.v1.InstanceService.UpdateInstanceSetting:input_type -> memos.api.v1.UpdateInstanceSettingRequest
-	3,  // 17: memos.api.v1.InstanceService.GetInstanceProfile:output_type -> memos.api.v1.InstanceProfile
-	5,  // 18: memos.api.v1.InstanceService.GetInstanceSetting:output_type -> memos.api.v1.InstanceSetting
-	5,  // 19: memos.api.v1.InstanceService.UpdateInstanceSetting:output_type -> memos.api.v1.InstanceSetting
-	17, // [17:20] is the sub-list for method output_type
-	14, // [14:17] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	12, // 14: memos.api.v1.InstanceSetting.LLMSetting.openai_compatible_config:type_name -> memos.api.v1.InstanceSetting.LLMOpenAIConfig
+	12, // 15: memos.api.v1.InstanceSetting.LLMSetting.groq_config:type_name -> memos.api.v1.InstanceSetting.LLMOpenAIConfig
+	4,  // 16: memos.api.v1.InstanceService.GetInstanceProfile:input_type -> memos.api.v1.GetInstanceProfileRequest
+	6,  // 17: memos.api.v1.InstanceService.GetInstanceSetting:input_type -> memos.api.v1.GetInstanceSettingRequest
+	7,  // 18: memos.api.v1.InstanceService.UpdateInstanceSetting:input_type -> memos.api.v1.UpdateInstanceSettingRequest
+	3,  // 19: memos.api.v1.InstanceService.GetInstanceProfile:output_type -> memos.api.v1.InstanceProfile
+	5,  // 20: memos.api.v1.InstanceService.GetInstanceSetting:output_type -> memos.api.v1.InstanceSetting
+	5,  // 21: memos.api.v1.InstanceService.UpdateInstanceSetting:output_type -> memos.api.v1.InstanceSetting
+	19, // [19:22] is the sub-list for method output_type
+	16, // [16:19] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_api_v1_instance_service_proto_init() }
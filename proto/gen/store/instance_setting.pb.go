@@ -152,6 +152,10 @@ const (
 	InstanceLLMSetting_GEMINI InstanceLLMSetting_LLMProvider = 3
 	// Local Ollama
 	InstanceLLMSetting_OLLAMA InstanceLLMSetting_LLMProvider = 4
+	// OpenAI-compatible endpoint
+	InstanceLLMSetting_OPENAI_COMPATIBLE InstanceLLMSetting_LLMProvider = 5
+	// Groq (OpenAI-compatible, fast inference)
+	InstanceLLMSetting_GROQ InstanceLLMSetting_LLMProvider = 6
 )
 
 // Enum value maps for InstanceLLMSetting_LLMProvider.
@@ -162,6 +166,8 @@ var (
 		2: "ANTHROPIC",
 		3: "GEMINI",
 		4: "OLLAMA",
+		5: "OPENAI_COMPATIBLE",
+		6: "GROQ",
 	}
 	InstanceLLMSetting_LLMProvider_value = map[string]int32{
 		"LLM_PROVIDER_UNSPECIFIED": 0,
@@ -169,6 +175,8 @@ var (
 		"ANTHROPIC":                2,
 		"GEMINI":                   3,
 		"OLLAMA":                   4,
+		"OPENAI_COMPATIBLE":        5,
+		"GROQ":                     6,
 	}
 )
 
@@ -819,8 +827,12 @@ type InstanceLLMSetting struct {
 	EnableAutoSummary bool `protobuf:"varint,11,opt,name=enable_auto_summary,json=enableAutoSummary,proto3" json:"enable_auto_summary,omitempty"`
 	// enable_semantic_search enables vector-based semantic search.
 	EnableSemanticSearch bool `protobuf:"varint,12,opt,name=enable_semantic_search,json=enableSemanticSearch,proto3" json:"enable_semantic_search,omitempty"`
-	unknownFields        protoimpl.UnknownFields
-	sizeCache            protoimpl.SizeCache
+	// OpenAI-compatible endpoint configuration (self-hosted or third-party).
+	OpenaiCompatibleConfig *LLMOpenAIConfig `protobuf:"bytes,6,opt,name=openai_compatible_config,json=openaiCompatibleConfig,proto3" json:"openai_compatible_config,omitempty"`
+	// Groq configuration.
+	GroqConfig    *LLMOpenAIConfig `protobuf:"bytes,7,opt,name=groq_config,json=groqConfig,proto3" json:"groq_config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *InstanceLLMSetting) Reset() {
@@ -909,6 +921,20 @@ func (x *InstanceLLMSetting) GetEnableSemanticSearch() bool {
 	return false
 }
 
+func (x *InstanceLLMSetting) GetOpenaiCompatibleConfig() *LLMOpenAIConfig {
+	if x != nil {
+		return x.OpenaiCompatibleConfig
+	}
+	return nil
+}
+
+func (x *InstanceLLMSetting) GetGroqConfig() *LLMOpenAIConfig {
+	if x != nil {
+		return x.GroqConfig
+	}
+	return nil
+}
+
 // LLMOpenAIConfig contains OpenAI-specific configuration.
 type LLMOpenAIConfig struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -920,8 +946,16 @@ type LLMOpenAIConfig struct {
 	DefaultModel string `protobuf:"bytes,3,opt,name=default_model,json=defaultModel,proto3" json:"default_model,omitempty"`
 	// Default model for embeddings (e.g., "text-embedding-3-small").
 	EmbeddingModel string `protobuf:"bytes,4,opt,name=embedding_model,json=embeddingModel,proto3" json:"embedding_model,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// Azure OpenAI deployment name. When set, requests use the Azure URL shape and api-key header.
+	AzureDeployment string `protobuf:"bytes,5,opt,name=azure_deployment,json=azureDeployment,proto3" json:"azure_deployment,omitempty"`
+	// Azure OpenAI API version (e.g., "2024-06-01").
+	AzureApiVersion string `protobuf:"bytes,6,opt,name=azure_api_version,json=azureApiVersion,proto3" json:"azure_api_version,omitempty"`
+	// OpenAI organization ID sent as the OpenAI-Organization header.
+	Organization string `protobuf:"bytes,7,opt,name=organization,proto3" json:"organization,omitempty"`
+	// OpenAI project ID sent as the OpenAI-Project header.
+	Project       string `protobuf:"bytes,8,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *LLMOpenAIConfig) Reset() {
@@ -982,6 +1016,34 @@ func (x *LLMOpenAIConfig) GetEmbeddingModel() string {
 	return ""
 }
 
+func (x *LLMOpenAIConfig) GetAzureDeployment() string {
+	if x != nil {
+		return x.AzureDeployment
+	}
+	return ""
+}
+
+func (x *LLMOpenAIConfig) GetAzureApiVersion() string {
+	if x != nil {
+		return x.AzureApiVersion
+	}
+	return ""
+}
+
+func (x *LLMOpenAIConfig) GetOrganization() string {
+	if x != nil {
+		return x.Organization
+	}
+	return ""
+}
+
+func (x *LLMOpenAIConfig) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
 // LLMAnthropicConfig contains Anthropic-specific configuration.
 type LLMAnthropicConfig struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -1218,7 +1280,7 @@ const file_store_instance_setting_proto_rawDesc = "" +
 	"\x18display_with_update_time\x18\x02 \x01(\bR\x15displayWithUpdateTime\x120\n" +
 	"\x14content_length_limit\x18\x03 \x01(\x05R\x12contentLengthLimit\x127\n" +
 	"\x18enable_double_click_edit\x18\x04 \x01(\bR\x15enableDoubleClickEdit\x12\x1c\n" +
-	"\treactions\x18\a \x03(\tR\treactions\"\xe8\x04\n" +
+	"\treactions\x18\a \x03(\tR\treactions\"\xa0\x06\n" +
 	"\x12InstanceLLMSetting\x12G\n" +
 	"\bprovider\x18\x01 \x01(\x0e2+.memos.store.InstanceLLMSetting.LLMProviderR\bprovider\x12A\n" +
 	"\ropenai_config\x18\x02 \x01(\v2\x1c.memos.store.LLMOpenAIConfigR\fopenaiConfig\x12J\n" +
@@ -1228,7 +1290,10 @@ const file_store_instance_setting_proto_rawDesc = "" +
 	"\x13enable_auto_tagging\x18\n" +
 	" \x01(\bR\x11enableAutoTagging\x12.\n" +
 	"\x13enable_auto_summary\x18\v \x01(\bR\x11enableAutoSummary\x124\n" +
-	"\x16enable_semantic_search\x18\f \x01(\bR\x14enableSemanticSearch\"^\n" +
+	"\x16enable_semantic_search\x18\f \x01(\bR\x14enableSemanticSearch\x12V\n" +
+	"\x18openai_compatible_config\x18\x06 \x01(\v2\x1c.memos.store.LLMOpenAIConfigR\x16openaiCompatibleConfig\x12=\n" +
+	"\vgroq_config\x18\a \x01(\v2\x1c.memos.store.LLMOpenAIConfigR\n" +
+	"groqConfig\"\x7f\n" +
 	"\vLLMProvider\x12\x1c\n" +
 	"\x18LLM_PROVIDER_UNSPECIFIED\x10\x00\x12\n" +
 	"\n" +
@@ -1237,12 +1302,18 @@ const file_store_instance_setting_proto_rawDesc = "" +
 	"\n" +
 	"\x06GEMINI\x10\x03\x12\n" +
 	"\n" +
-	"\x06OLLAMA\x10\x04\"\x93\x01\n" +
+	"\x06OLLAMA\x10\x04\x12\x15\n" +
+	"\x11OPENAI_COMPATIBLE\x10\x05\x12\b\n" +
+	"\x04GROQ\x10\x06\"\xa8\x02\n" +
 	"\x0fLLMOpenAIConfig\x12\x17\n" +
 	"\aapi_key\x18\x01 \x01(\tR\x06apiKey\x12\x19\n" +
 	"\bbase_url\x18\x02 \x01(\tR\abaseUrl\x12#\n" +
 	"\rdefault_model\x18\x03 \x01(\tR\fdefaultModel\x12'\n" +
-	"\x0fembedding_model\x18\x04 \x01(\tR\x0eembeddingModel\"m\n" +
+	"\x0fembedding_model\x18\x04 \x01(\tR\x0eembeddingModel\x12)\n" +
+	"\x10azure_deployment\x18\x05 \x01(\tR\x0fazureDeployment\x12*\n" +
+	"\x11azure_api_version\x18\x06 \x01(\tR\x0fazureApiVersion\x12\"\n" +
+	"\forganization\x18\a \x01(\tR\forganization\x12\x18\n" +
+	"\aproject\x18\b \x01(\tR\aproject\"m\n" +
 	"\x12LLMAnthropicConfig\x12\x17\n" +
 	"\aapi_key\x18\x01 \x01(\tR\x06apiKey\x12\x19\n" +
 	"\bbase_url\x18\x02 \x01(\tR\abaseUrl\x12#\n" +
@@ -1309,11 +1380,13 @@ var file_store_instance_setting_proto_depIdxs = []int32{
 	12, // 11: memos.store.InstanceLLMSetting.anthropic_config:type_name -> memos.store.LLMAnthropicConfig
 	13, // 12: memos.store.InstanceLLMSetting.gemini_config:type_name -> memos.store.LLMGeminiConfig
 	14, // 13: memos.store.InstanceLLMSetting.ollama_config:type_name -> memos.store.LLMOllamaConfig
-	14, // [14:14] is the sub-list for method output_type
-	14, // [14:14] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	11, // 14: memos.store.InstanceLLMSetting.openai_compatible_config:type_name -> memos.store.LLMOpenAIConfig
+	11, // 15: memos.store.InstanceLLMSetting.groq_config:type_name -> memos.store.LLMOpenAIConfig
+	16, // [16:16] is the sub-list for method output_type
+	16, // [16:16] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_store_instance_setting_proto_init() }
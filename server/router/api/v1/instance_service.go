@@ -123,11 +123,13 @@ func (s *APIV1Service) UpdateInstanceSetting(ctx context.Context, request *v1pb.
 // This prevents accidental loss of API keys when users save settings without re-entering them.
 func preserveExistingAPIKeys(newSetting, existingSetting *storepb.InstanceLLMSetting) {
 	// Preserve OpenAI API key
-	if newSetting.OpenaiConfig != nil && existingSetting.OpenaiConfig != nil {
-		if newSetting.OpenaiConfig.ApiKey == "" || newSetting.OpenaiConfig.ApiKey == maskedAPIKey {
-			newSetting.OpenaiConfig.ApiKey = existingSetting.OpenaiConfig.ApiKey
-		}
-	}
+	preserveOpenAIAPIKey(newSetting.OpenaiConfig, existingSetting.OpenaiConfig)
+
+	// Preserve OpenAI-compatible API key
+	preserveOpenAIAPIKey(newSetting.OpenaiCompatibleConfig, existingSetting.OpenaiCompatibleConfig)
+
+	// Preserve Groq API key
+	preserveOpenAIAPIKey(newSetting.GroqConfig, existingSetting.GroqConfig)
 
 	// Preserve Anthropic API key
 	if newSetting.AnthropicConfig != nil && existingSetting.AnthropicConfig != nil {
@@ -142,6 +144,18 @@ func preserveExistingAPIKeys(newSetting, existingSetting *storepb.InstanceLLMSet
 			newSetting.GeminiConfig.ApiKey = existingSetting.GeminiConfig.ApiKey
 		}
 	}
+
+	// Ollama config has no API key, so the incoming host/model settings are stored as submitted.
+}
+
+// preserveOpenAIAPIKey keeps the stored key of an OpenAI-shaped config when the incoming key is empty or masked.
+func preserveOpenAIAPIKey(newConfig, existingConfig *storepb.LLMOpenAIConfig) {
+	if newConfig == nil || existingConfig == nil {
+		return
+	}
+	if newConfig.ApiKey == "" || newConfig.ApiKey == maskedAPIKey {
+		newConfig.ApiKey = existingConfig.ApiKey
+	}
 }
 
 func convertInstanceSettingFromStore(setting *storepb.InstanceSetting) *v1pb.InstanceSetting {
@@ -332,16 +346,13 @@ func convertInstanceLLMSettingFromStore(setting *storepb.InstanceLLMSetting) *v1
 	}
 
 	// Convert OpenAI config with masked API key
-	if setting.OpenaiConfig != nil {
-		llmSetting.OpenaiConfig = &v1pb.InstanceSetting_LLMOpenAIConfig{
-			BaseUrl:        setting.OpenaiConfig.BaseUrl,
-			DefaultModel:   setting.OpenaiConfig.DefaultModel,
-			EmbeddingModel: setting.OpenaiConfig.EmbeddingModel,
-		}
-		if setting.OpenaiConfig.ApiKey != "" {
-			llmSetting.OpenaiConfig.ApiKey = maskedAPIKey
-		}
-	}
+	llmSetting.OpenaiConfig = convertLLMOpenAIConfigFromStore(setting.OpenaiConfig)
+
+	// Convert OpenAI-compatible config with masked API key
+	llmSetting.OpenaiCompatibleConfig = convertLLMOpenAIConfigFromStore(setting.OpenaiCompatibleConfig)
+
+	// Convert Groq config with masked API key
+	llmSetting.GroqConfig = convertLLMOpenAIConfigFromStore(setting.GroqConfig)
 
 	// Convert Anthropic config with masked API key
 	if setting.AnthropicConfig != nil {
@@ -389,14 +400,13 @@ func convertInstanceLLMSettingToStore(setting *v1pb.InstanceSetting_LLMSetting)
 	}
 
 	// Convert OpenAI config
-	if setting.OpenaiConfig != nil {
-		llmSetting.OpenaiConfig = &storepb.LLMOpenAIConfig{
-			ApiKey:         setting.OpenaiConfig.ApiKey,
-			BaseUrl:        setting.OpenaiConfig.BaseUrl,
-			DefaultModel:   setting.OpenaiConfig.DefaultModel,
-			EmbeddingModel: setting.OpenaiConfig.EmbeddingModel,
-		}
-	}
+	llmSetting.OpenaiConfig = convertLLMOpenAIConfigToStore(setting.OpenaiConfig)
+
+	// Convert OpenAI-compatible config
+	llmSetting.OpenaiCompatibleConfig = convertLLMOpenAIConfigToStore(setting.OpenaiCompatibleConfig)
+
+	// Convert Groq config
+	llmSetting.GroqConfig = convertLLMOpenAIConfigToStore(setting.GroqConfig)
 
 	// Convert Anthropic config
 	if setting.AnthropicConfig != nil {
@@ -427,6 +437,44 @@ func convertInstanceLLMSettingToStore(setting *v1pb.InstanceSetting_LLMSetting)
 	return llmSetting
 }
 
+// convertLLMOpenAIConfigFromStore converts an OpenAI-shaped config, masking the API key if set.
+func convertLLMOpenAIConfigFromStore(config *storepb.LLMOpenAIConfig) *v1pb.InstanceSetting_LLMOpenAIConfig {
+	if config == nil {
+		return nil
+	}
+
+	openaiConfig := &v1pb.InstanceSetting_LLMOpenAIConfig{
+		BaseUrl:         config.BaseUrl,
+		DefaultModel:    config.DefaultModel,
+		EmbeddingModel:  config.EmbeddingModel,
+		AzureDeployment: config.AzureDeployment,
+		AzureApiVersion: config.AzureApiVersion,
+		Organization:    config.Organization,
+		Project:         config.Project,
+	}
+	if config.ApiKey != "" {
+		openaiConfig.ApiKey = maskedAPIKey
+	}
+	return openaiConfig
+}
+
+func convertLLMOpenAIConfigToStore(config *v1pb.InstanceSetting_LLMOpenAIConfig) *storepb.LLMOpenAIConfig {
+	if config == nil {
+		return nil
+	}
+
+	return &storepb.LLMOpenAIConfig{
+		ApiKey:          config.ApiKey,
+		BaseUrl:         config.BaseUrl,
+		DefaultModel:    config.DefaultModel,
+		EmbeddingModel:  config.EmbeddingModel,
+		AzureDeployment: config.AzureDeployment,
+		AzureApiVersion: config.AzureApiVersion,
+		Organization:    config.Organization,
+		Project:         config.Project,
+	}
+}
+
 var (
 	ownerCache      *v1pb.User
 	ownerCacheMutex sync.RWMutex
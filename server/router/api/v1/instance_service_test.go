@@ -3,6 +3,7 @@ package v1
 import (
 	"testing"
 
+	v1pb "github.com/usememos/memos/proto/gen/api/v1"
 	storepb "github.com/usememos/memos/proto/gen/store"
 )
 
@@ -110,3 +111,167 @@ func TestPreserveExistingAPIKeys_NoExistingConfig(t *testing.T) {
 		t.Errorf("Expected API key to remain empty when no existing config, got %s", newSetting.OpenaiConfig.ApiKey)
 	}
 }
+
+func TestPreserveExistingAPIKeys_Gemini(t *testing.T) {
+	existing := &storepb.InstanceLLMSetting{
+		GeminiConfig: &storepb.LLMGeminiConfig{
+			ApiKey:       "AIza-existing-123",
+			DefaultModel: "gemini-1.5-flash",
+		},
+	}
+
+	// Test case 1: Empty API key should be preserved
+	newSetting := &storepb.InstanceLLMSetting{
+		GeminiConfig: &storepb.LLMGeminiConfig{
+			ApiKey:       "", // Empty - should preserve existing
+			DefaultModel: "gemini-1.5-pro",
+		},
+	}
+
+	preserveExistingAPIKeys(newSetting, existing)
+
+	if newSetting.GeminiConfig.ApiKey != "AIza-existing-123" {
+		t.Errorf("Expected Gemini API key to be preserved, got %s", newSetting.GeminiConfig.ApiKey)
+	}
+	if newSetting.GeminiConfig.DefaultModel != "gemini-1.5-pro" {
+		t.Errorf("Expected DefaultModel to be updated, got %s", newSetting.GeminiConfig.DefaultModel)
+	}
+
+	// Test case 2: Masked API key should be preserved
+	newSetting2 := &storepb.InstanceLLMSetting{
+		GeminiConfig: &storepb.LLMGeminiConfig{
+			ApiKey:       maskedAPIKey, // Masked - should preserve existing
+			DefaultModel: "gemini-1.5-flash",
+		},
+	}
+
+	preserveExistingAPIKeys(newSetting2, existing)
+
+	if newSetting2.GeminiConfig.ApiKey != "AIza-existing-123" {
+		t.Errorf("Expected masked Gemini API key to be preserved, got %s", newSetting2.GeminiConfig.ApiKey)
+	}
+
+	// Test case 3: New API key should NOT be preserved
+	newSetting3 := &storepb.InstanceLLMSetting{
+		GeminiConfig: &storepb.LLMGeminiConfig{
+			ApiKey:       "AIza-new-456", // New key - should NOT be overwritten
+			DefaultModel: "gemini-1.5-flash",
+		},
+	}
+
+	preserveExistingAPIKeys(newSetting3, existing)
+
+	if newSetting3.GeminiConfig.ApiKey != "AIza-new-456" {
+		t.Errorf("Expected new Gemini API key to be kept, got %s", newSetting3.GeminiConfig.ApiKey)
+	}
+}
+
+func TestPreserveExistingAPIKeys_Ollama(t *testing.T) {
+	existing := &storepb.InstanceLLMSetting{
+		OllamaConfig: &storepb.LLMOllamaConfig{
+			Host:         "http://localhost:11434",
+			DefaultModel: "llama3.2",
+		},
+		GeminiConfig: &storepb.LLMGeminiConfig{
+			ApiKey: "AIza-existing-123",
+		},
+	}
+
+	// Editing only the Ollama host must not clobber it or touch other providers
+	newSetting := &storepb.InstanceLLMSetting{
+		OllamaConfig: &storepb.LLMOllamaConfig{
+			Host:         "http://ollama.internal:11434",
+			DefaultModel: "llama3.2",
+		},
+		GeminiConfig: &storepb.LLMGeminiConfig{
+			ApiKey: maskedAPIKey,
+		},
+	}
+
+	preserveExistingAPIKeys(newSetting, existing)
+
+	if newSetting.OllamaConfig.Host != "http://ollama.internal:11434" {
+		t.Errorf("Expected Ollama host to be updated, got %s", newSetting.OllamaConfig.Host)
+	}
+	if newSetting.GeminiConfig.ApiKey != "AIza-existing-123" {
+		t.Errorf("Expected Gemini API key to be preserved, got %s", newSetting.GeminiConfig.ApiKey)
+	}
+}
+
+func TestPreserveExistingAPIKeys_OpenAICompatible(t *testing.T) {
+	existing := &storepb.InstanceLLMSetting{
+		OpenaiCompatibleConfig: &storepb.LLMOpenAIConfig{
+			ApiKey:  "sk-compatible-existing-123",
+			BaseUrl: "http://localhost:8000/v1",
+		},
+	}
+
+	newSetting := &storepb.InstanceLLMSetting{
+		OpenaiCompatibleConfig: &storepb.LLMOpenAIConfig{
+			ApiKey:  maskedAPIKey, // Masked - should preserve existing
+			BaseUrl: "http://localhost:9000/v1",
+		},
+	}
+
+	preserveExistingAPIKeys(newSetting, existing)
+
+	if newSetting.OpenaiCompatibleConfig.ApiKey != "sk-compatible-existing-123" {
+		t.Errorf("Expected OpenAI-compatible API key to be preserved, got %s", newSetting.OpenaiCompatibleConfig.ApiKey)
+	}
+	if newSetting.OpenaiCompatibleConfig.BaseUrl != "http://localhost:9000/v1" {
+		t.Errorf("Expected BaseUrl to be updated, got %s", newSetting.OpenaiCompatibleConfig.BaseUrl)
+	}
+}
+
+func TestPreserveExistingAPIKeys_Groq(t *testing.T) {
+	existing := &storepb.InstanceLLMSetting{
+		GroqConfig: &storepb.LLMOpenAIConfig{
+			ApiKey:       "gsk_existing123",
+			DefaultModel: "llama-3.1-8b-instant",
+		},
+	}
+
+	newSetting := &storepb.InstanceLLMSetting{
+		GroqConfig: &storepb.LLMOpenAIConfig{
+			ApiKey:       "", // Empty - should preserve existing
+			DefaultModel: "llama-3.3-70b-versatile",
+		},
+	}
+
+	preserveExistingAPIKeys(newSetting, existing)
+
+	if newSetting.GroqConfig.ApiKey != "gsk_existing123" {
+		t.Errorf("Expected Groq API key to be preserved, got %s", newSetting.GroqConfig.ApiKey)
+	}
+}
+
+func TestConvertInstanceLLMSetting_OpenAIAzure(t *testing.T) {
+	setting := &v1pb.InstanceSetting_LLMSetting{
+		Provider: v1pb.InstanceSetting_LLMSetting_OPENAI,
+		OpenaiConfig: &v1pb.InstanceSetting_LLMOpenAIConfig{
+			ApiKey:          "azure-key-123",
+			BaseUrl:         "https://example.openai.azure.com",
+			AzureDeployment: "gpt-4o-prod",
+			AzureApiVersion: "2024-06-01",
+		},
+	}
+
+	stored := convertInstanceLLMSettingToStore(setting)
+	if stored.OpenaiConfig.AzureDeployment != "gpt-4o-prod" {
+		t.Errorf("Expected AzureDeployment to be stored, got %s", stored.OpenaiConfig.AzureDeployment)
+	}
+	if stored.OpenaiConfig.AzureApiVersion != "2024-06-01" {
+		t.Errorf("Expected AzureApiVersion to be stored, got %s", stored.OpenaiConfig.AzureApiVersion)
+	}
+
+	converted := convertInstanceLLMSettingFromStore(stored)
+	if converted.OpenaiConfig.AzureDeployment != "gpt-4o-prod" {
+		t.Errorf("Expected AzureDeployment to round-trip, got %s", converted.OpenaiConfig.AzureDeployment)
+	}
+	if converted.OpenaiConfig.AzureApiVersion != "2024-06-01" {
+		t.Errorf("Expected AzureApiVersion to round-trip, got %s", converted.OpenaiConfig.AzureApiVersion)
+	}
+	if converted.OpenaiConfig.ApiKey != maskedAPIKey {
+		t.Errorf("Expected API key to be masked, got %s", converted.OpenaiConfig.ApiKey)
+	}
+}
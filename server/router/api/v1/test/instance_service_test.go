@@ -201,3 +201,137 @@ func TestGetInstanceSetting(t *testing.T) {
 		require.Contains(t, err.Error(), "invalid instance setting name")
 	})
 }
+
+func TestUpdateInstanceSetting_LLM(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("UpdateInstanceSetting - OpenAI-compatible config round trip", func(t *testing.T) {
+		ts := NewTestService(t)
+		defer ts.Cleanup()
+
+		hostUser, err := ts.CreateHostUser(ctx, "admin")
+		require.NoError(t, err)
+		userCtx := ts.CreateUserContext(ctx, hostUser.ID)
+
+		_, err = ts.Service.UpdateInstanceSetting(userCtx, &v1pb.UpdateInstanceSettingRequest{
+			Setting: &v1pb.InstanceSetting{
+				Name: "instance/settings/LLM",
+				Value: &v1pb.InstanceSetting_LlmSetting{
+					LlmSetting: &v1pb.InstanceSetting_LLMSetting{
+						Provider: v1pb.InstanceSetting_LLMSetting_OPENAI_COMPATIBLE,
+						OpenaiCompatibleConfig: &v1pb.InstanceSetting_LLMOpenAIConfig{
+							ApiKey:       "sk-compatible-123",
+							BaseUrl:      "http://localhost:8000/v1",
+							DefaultModel: "qwen2.5",
+						},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		resp, err := ts.Service.GetInstanceSetting(userCtx, &v1pb.GetInstanceSettingRequest{
+			Name: "instance/settings/LLM",
+		})
+		require.NoError(t, err)
+		llmSetting := resp.GetLlmSetting()
+		require.Equal(t, v1pb.InstanceSetting_LLMSetting_OPENAI_COMPATIBLE, llmSetting.Provider)
+		require.NotNil(t, llmSetting.OpenaiCompatibleConfig)
+		require.Equal(t, "***masked***", llmSetting.OpenaiCompatibleConfig.ApiKey)
+		require.Equal(t, "http://localhost:8000/v1", llmSetting.OpenaiCompatibleConfig.BaseUrl)
+		require.Equal(t, "qwen2.5", llmSetting.OpenaiCompatibleConfig.DefaultModel)
+
+		// Saving the masked setting back must keep the stored key.
+		_, err = ts.Service.UpdateInstanceSetting(userCtx, &v1pb.UpdateInstanceSettingRequest{Setting: resp})
+		require.NoError(t, err)
+
+		stored, err := ts.Store.GetInstanceLLMSetting(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "sk-compatible-123", stored.GetOpenaiCompatibleConfig().GetApiKey())
+		require.Equal(t, "http://localhost:8000/v1", stored.GetOpenaiCompatibleConfig().GetBaseUrl())
+	})
+
+	t.Run("UpdateInstanceSetting - OpenAI organization and project round trip", func(t *testing.T) {
+		ts := NewTestService(t)
+		defer ts.Cleanup()
+
+		hostUser, err := ts.CreateHostUser(ctx, "admin")
+		require.NoError(t, err)
+		userCtx := ts.CreateUserContext(ctx, hostUser.ID)
+
+		_, err = ts.Service.UpdateInstanceSetting(userCtx, &v1pb.UpdateInstanceSettingRequest{
+			Setting: &v1pb.InstanceSetting{
+				Name: "instance/settings/LLM",
+				Value: &v1pb.InstanceSetting_LlmSetting{
+					LlmSetting: &v1pb.InstanceSetting_LLMSetting{
+						Provider: v1pb.InstanceSetting_LLMSetting_OPENAI,
+						OpenaiConfig: &v1pb.InstanceSetting_LLMOpenAIConfig{
+							ApiKey:       "sk-openai-123",
+							Organization: "org-memos",
+							Project:      "proj_tagging",
+						},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		stored, err := ts.Store.GetInstanceLLMSetting(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "org-memos", stored.GetOpenaiConfig().GetOrganization())
+		require.Equal(t, "proj_tagging", stored.GetOpenaiConfig().GetProject())
+
+		resp, err := ts.Service.GetInstanceSetting(userCtx, &v1pb.GetInstanceSettingRequest{
+			Name: "instance/settings/LLM",
+		})
+		require.NoError(t, err)
+		openaiConfig := resp.GetLlmSetting().GetOpenaiConfig()
+		require.NotNil(t, openaiConfig)
+		require.Equal(t, "org-memos", openaiConfig.Organization)
+		require.Equal(t, "proj_tagging", openaiConfig.Project)
+	})
+}
+
+func TestUpdateInstanceSetting_LLMGroq(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTestService(t)
+	defer ts.Cleanup()
+
+	hostUser, err := ts.CreateHostUser(ctx, "admin")
+	require.NoError(t, err)
+	userCtx := ts.CreateUserContext(ctx, hostUser.ID)
+
+	_, err = ts.Service.UpdateInstanceSetting(userCtx, &v1pb.UpdateInstanceSettingRequest{
+		Setting: &v1pb.InstanceSetting{
+			Name: "instance/settings/LLM",
+			Value: &v1pb.InstanceSetting_LlmSetting{
+				LlmSetting: &v1pb.InstanceSetting_LLMSetting{
+					Provider: v1pb.InstanceSetting_LLMSetting_GROQ,
+					GroqConfig: &v1pb.InstanceSetting_LLMOpenAIConfig{
+						ApiKey:       "gsk_test1234567890abcdefghij",
+						DefaultModel: "llama-3.3-70b-versatile",
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	resp, err := ts.Service.GetInstanceSetting(userCtx, &v1pb.GetInstanceSettingRequest{
+		Name: "instance/settings/LLM",
+	})
+	require.NoError(t, err)
+	llmSetting := resp.GetLlmSetting()
+	require.Equal(t, v1pb.InstanceSetting_LLMSetting_GROQ, llmSetting.Provider)
+	require.NotNil(t, llmSetting.GroqConfig)
+	require.Equal(t, "***masked***", llmSetting.GroqConfig.ApiKey)
+	require.Equal(t, "llama-3.3-70b-versatile", llmSetting.GroqConfig.DefaultModel)
+
+	// Saving the masked setting back must keep the stored key.
+	_, err = ts.Service.UpdateInstanceSetting(userCtx, &v1pb.UpdateInstanceSettingRequest{Setting: resp})
+	require.NoError(t, err)
+
+	stored, err := ts.Store.GetInstanceLLMSetting(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "gsk_test1234567890abcdefghij", stored.GetGroqConfig().GetApiKey())
+}
@@ -0,0 +1,13 @@
+CREATE TABLE `llm_tag_job` (
+  `id` VARCHAR(64) NOT NULL PRIMARY KEY,
+  `memo_id` INT NOT NULL,
+  `user_id` INT NOT NULL,
+  `content` TEXT NOT NULL,
+  `existing_tags` TEXT NOT NULL,
+  `status` VARCHAR(32) NOT NULL,
+  `result` TEXT NOT NULL,
+  `error` TEXT NOT NULL,
+  `attempts` INT NOT NULL DEFAULT 0,
+  `created_ts` BIGINT NOT NULL,
+  `completed_ts` BIGINT
+);
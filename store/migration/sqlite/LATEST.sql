@@ -106,3 +106,18 @@ CREATE TABLE reaction (
   reaction_type TEXT NOT NULL,
   UNIQUE(creator_id, content_id, reaction_type)
 );
+
+-- llm_tag_job
+CREATE TABLE llm_tag_job (
+  id TEXT NOT NULL PRIMARY KEY,
+  memo_id INTEGER NOT NULL,
+  user_id INTEGER NOT NULL,
+  content TEXT NOT NULL,
+  existing_tags TEXT NOT NULL DEFAULT '[]',
+  status TEXT NOT NULL,
+  result TEXT NOT NULL DEFAULT '',
+  error TEXT NOT NULL DEFAULT '',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  created_ts BIGINT NOT NULL,
+  completed_ts BIGINT
+);
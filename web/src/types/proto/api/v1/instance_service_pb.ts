@@ -16,7 +16,7 @@ import type { Message } from "@bufbuild/protobuf";
  * Describes the file api/v1/instance_service.proto.
  */
 export const file_api_v1_instance_service: GenFile = /*@__PURE__*/
-  fileDesc("Ch1hcGkvdjEvaW5zdGFuY2Vfc2VydmljZS5wcm90bxIMbWVtb3MuYXBpLnYxIlsKD0luc3RhbmNlUHJvZmlsZRIPCgd2ZXJzaW9uGAIgASgJEgwKBGRlbW8YAyABKAgSFAoMaW5zdGFuY2VfdXJsGAYgASgJEhMKC2luaXRpYWxpemVkGAcgASgIIhsKGUdldEluc3RhbmNlUHJvZmlsZVJlcXVlc3Qi7hIKD0luc3RhbmNlU2V0dGluZxIRCgRuYW1lGAEgASgJQgPgQQgSRwoPZ2VuZXJhbF9zZXR0aW5nGAIgASgLMiwubWVtb3MuYXBpLnYxLkluc3RhbmNlU2V0dGluZy5HZW5lcmFsU2V0dGluZ0gAEkcKD3N0b3JhZ2Vfc2V0dGluZxgDIAEoCzIsLm1lbW9zLmFwaS52MS5JbnN0YW5jZVNldHRpbmcuU3RvcmFnZVNldHRpbmdIABJQChRtZW1vX3JlbGF0ZWRfc2V0dGluZxgEIAEoCzIwLm1lbW9zLmFwaS52MS5JbnN0YW5jZVNldHRpbmcuTWVtb1JlbGF0ZWRTZXR0aW5nSAASPwoLbGxtX3NldHRpbmcYBSABKAsyKC5tZW1vcy5hcGkudjEuSW5zdGFuY2VTZXR0aW5nLkxMTVNldHRpbmdIABqHAwoOR2VuZXJhbFNldHRpbmcSIgoaZGlzYWxsb3dfdXNlcl9yZWdpc3RyYXRpb24YAiABKAgSHgoWZGlzYWxsb3dfcGFzc3dvcmRfYXV0aBgDIAEoCBIZChFhZGRpdGlvbmFsX3NjcmlwdBgEIAEoCRIYChBhZGRpdGlvbmFsX3N0eWxlGAUgASgJElIKDmN1c3RvbV9wcm9maWxlGAYgASgLMjoubWVtb3MuYXBpLnYxLkluc3RhbmNlU2V0dGluZy5HZW5lcmFsU2V0dGluZy5DdXN0b21Qcm9maWxlEh0KFXdlZWtfc3RhcnRfZGF5X29mZnNldBgHIAEoBRIgChhkaXNhbGxvd19jaGFuZ2VfdXNlcm5hbWUYCCABKAgSIAoYZGlzYWxsb3dfY2hhbmdlX25pY2tuYW1lGAkgASgIGkUKDUN1c3RvbVByb2ZpbGUSDQoFdGl0bGUYASABKAkSEwoLZGVzY3JpcHRpb24YAiABKAkSEAoIbG9nb191cmwYAyABKAkaugMKDlN0b3JhZ2VTZXR0aW5nEk4KDHN0b3JhZ2VfdHlwZRgBIAEoDjI4Lm1lbW9zLmFwaS52MS5JbnN0YW5jZVNldHRpbmcuU3RvcmFnZVNldHRpbmcuU3RvcmFnZVR5cGUSGQoRZmlsZXBhdGhfdGVtcGxhdGUYAiABKAkSHAoUdXBsb2FkX3NpemVfbGltaXRfbWIYAyABKAMSSAoJczNfY29uZmlnGAQgASgLMjUubWVtb3MuYXBpLnYxLkluc3RhbmNlU2V0dGluZy5TdG9yYWdlU2V0dGluZy5TM0NvbmZpZxqGAQoIUzNDb25maWcSFQoNYWNjZXNzX2tleV9pZBgBIAEoCRIZChFhY2Nlc3Nfa2V5X3NlY3JldBgCIAEoCRIQCghlbmRwb2ludBgDIAEoCRIOCgZyZWdpb24YBCABKAkSDgoGYnVja2V0GAUgASgJEhYKDnVzZV9wYXRoX3N0eWxlGAYgASgIIkwKC1N0b3JhZ2VUeXBlEhwKGFNUT1JBR0VfVFlQRV9VTlNQRUNJRklFRBAAEgwKCERBVEFCQVNFEAESCQoFTE9DQUwQAhIGCgJTMxADGq0BChJNZW1vUmVsYXRlZFNldHRpbmcSIgoaZGlzYWxsb3dfcHVibGljX3Zpc2liaWxpdHkYASABKAgSIAoYZGlzcGxheV93aXRoX3VwZGF0ZV90aW1lGAIgASgIEhwKFGNvbnRlbnRfbGVuZ3RoX2xpbWl0GAMgASgFEiAKGGVuYWJsZV9kb3VibGVfY2xpY2tfZWRpdBgEIAEoCBIRCglyZWFjdGlvbnMYByADKAkarAQKCkxMTVNldHRpbmcSRgoIcHJvdmlkZXIYASABKA4yNC5tZW1vcy5hcGkudjEuSW5zdGFuY2VTZXR0aW5nLkxMTVNldHRpbmcuTExNUHJvdmlkZXISRAoNb3BlbmFpX2NvbmZpZxgCIAEoCzItLm1lbW9zLmFwaS52MS5JbnN0YW5jZVNldHRpbmcuTExNT3BlbkFJQ29uZmlnEkoKEGFudGhyb3BpY19jb25maWcYAyABKAsyMC5tZW1vcy5hcGkudjEuSW5zdGFuY2VTZXR0aW5nLkxMTUFudGhyb3BpY0NvbmZpZxJECg1nZW1pbmlfY29uZmlnGAQgASgLMi0ubWVtb3MuYXBpLnYxLkluc3RhbmNlU2V0dGluZy5MTE1HZW1pbmlDb25maWcSRAoNb2xsYW1hX2NvbmZpZxgFIAEoCzItLm1lbW9zLmFwaS52MS5JbnN0YW5jZVNldHRpbmcuTExNT2xsYW1hQ29uZmlnEhsKE2VuYWJsZV9hdXRvX3RhZ2dpbmcYCiABKAgSGwoTZW5hYmxlX2F1dG9fc3VtbWFyeRgLIAEoCBIeChZlbmFibGVfc2VtYW50aWNfc2VhcmNoGAwgASgIIl4KC0xMTVByb3ZpZGVyEhwKGExMTV9QUk9WSURFUl9VTlNQRUNJRklFRBAAEgoKBk9QRU5BSRABEg0KCUFOVEhST1BJQxACEgoKBkdFTUlOSRADEgoKBk9MTEFNQRAEGmQKD0xMTU9wZW5BSUNvbmZpZxIPCgdhcGlfa2V5GAEgASgJEhAKCGJhc2VfdXJsGAIgASgJEhUKDWRlZmF1bHRfbW9kZWwYAyABKAkSFwoPZW1iZWRkaW5nX21vZGVsGAQgASgJGk4KEkxMTUFudGhyb3BpY0NvbmZpZxIPCgdhcGlfa2V5GAEgASgJEhAKCGJhc2VfdXJsGAIgASgJEhUKDWRlZmF1bHRfbW9kZWwYAyABKAkaOQoPTExNR2VtaW5pQ29uZmlnEg8KB2FwaV9rZXkYASABKAkSFQoNZGVmYXVsdF9tb2RlbBgCIAEoCRpPCg9MTE1PbGxhbWFDb25maWcSDAoEaG9zdBgBIAEoCRIVCg1kZWZhdWx0X21vZGVsGAIgASgJEhcKD2VtYmVkZGluZ19tb2RlbBgDIAEoCSJPCgNLZXkSEwoPS0VZX1VOU1BFQ0lGSUVEEAASCwoHR0VORVJBTBABEgsKB1NUT1JBR0UQAhIQCgxNRU1PX1JFTEFURUQQAxIHCgNMTE0QBDph6kFeChxtZW1vcy5hcGkudjEvSW5zdGFuY2VTZXR0aW5nEhtpbnN0YW5jZS9zZXR0aW5ncy97c2V0dGluZ30qEGluc3RhbmNlU2V0dGluZ3MyD2luc3RhbmNlU2V0dGluZ0IHCgV2YWx1ZSJPChlHZXRJbnN0YW5jZVNldHRpbmdSZXF1ZXN0EjIKBG5hbWUYASABKAlCJOBBAvpBHgocbWVtb3MuYXBpLnYxL0luc3RhbmNlU2V0dGluZyKJAQocVXBkYXRlSW5zdGFuY2VTZXR0aW5nUmVxdWVzdBIzCgdzZXR0aW5nGAEgASgLMh0ubWVtb3MuYXBpLnYxLkluc3RhbmNlU2V0dGluZ0ID4EECEjQKC3VwZGF0ZV9tYXNrGAIgASgLMhouZ29vZ2xlLnByb3RvYnVmLkZpZWxkTWFza0ID4EEBMtsDCg9JbnN0YW5jZVNlcnZpY2USfgoSR2V0SW5zdGFuY2VQcm9maWxlEicubWVtb3MuYXBpLnYxLkdldEluc3RhbmNlUHJvZmlsZVJlcXVlc3QaHS5tZW1vcy5hcGkudjEuSW5zdGFuY2VQcm9maWxlIiCC0+STAhoSGC9hcGkvdjEvaW5zdGFuY2UvcHJvZmlsZRKPAQoSR2V0SW5zdGFuY2VTZXR0aW5nEicubWVtb3MuYXBpLnYxLkdldEluc3RhbmNlU2V0dGluZ1JlcXVlc3QaHS5tZW1vcy5hcGkudjEuSW5zdGFuY2VTZXR0aW5nIjHaQQRuYW1lgtPkkwIkEiIvYXBpL3YxL3tuYW1lPWluc3RhbmNlL3NldHRpbmdzLyp9ErUBChVVcGRhdGVJbnN0YW5jZVNldHRpbmcSKi5tZW1vcy5hcGkudjEuVXBkYXRlSW5zdGFuY2VTZXR0aW5nUmVxdWVzdBodLm1lbW9zLmFwaS52MS5JbnN0YW5jZVNldHRpbmciUdpBE3NldHRpbmcsdXBkYXRlX21hc2uC0+STAjU6B3NldHRpbmcyKi9hcGkvdjEve3NldHRpbmcubmFtZT1pbnN0YW5jZS9zZXR0aW5ncy8qfUKsAQoQY29tLm1lbW9zLmFwaS52MUIUSW5zdGFuY2VTZXJ2aWNlUHJvdG9QAVowZ2l0aHViLmNvbS91c2VtZW1vcy9tZW1vcy9wcm90by9nZW4vYXBpL3YxO2FwaXYxogIDTUFYqgIMTWVtb3MuQXBpLlYxygIMTWVtb3NcQXBpXFYx4gIYTWVtb3NcQXBpXFYxXEdQQk1ldGFkYXRh6gIOTWVtb3M6OkFwaTo6VjFiBnByb3RvMw", [file_google_api_annotations, file_google_api_client, file_google_api_field_behavior, file_google_api_resource, file_google_protobuf_field_mask]);
+  fileDesc("Ch1hcGkvdjEvaW5zdGFuY2Vfc2VydmljZS5wcm90bxIMbWVtb3MuYXBpLnYxIlsKD0luc3RhbmNlUHJvZmlsZRIPCgd2ZXJzaW9uGAIgASgJEgwKBGRlbW8YAyABKAgSFAoMaW5zdGFuY2VfdXJsGAYgASgJEhMKC2luaXRpYWxpemVkGAcgASgIIhsKGUdldEluc3RhbmNlUHJvZmlsZVJlcXVlc3QigRUKD0luc3RhbmNlU2V0dGluZxIRCgRuYW1lGAEgASgJQgPgQQgSRwoPZ2VuZXJhbF9zZXR0aW5nGAIgASgLMiwubWVtb3MuYXBpLnYxLkluc3RhbmNlU2V0dGluZy5HZW5lcmFsU2V0dGluZ0gAEkcKD3N0b3JhZ2Vfc2V0dGluZxgDIAEoCzIsLm1lbW9zLmFwaS52MS5JbnN0YW5jZVNldHRpbmcuU3RvcmFnZVNldHRpbmdIABJQChRtZW1vX3JlbGF0ZWRfc2V0dGluZxgEIAEoCzIwLm1lbW9zLmFwaS52MS5JbnN0YW5jZVNldHRpbmcuTWVtb1JlbGF0ZWRTZXR0aW5nSAASPwoLbGxtX3NldHRpbmcYBSABKAsyKC5tZW1vcy5hcGkudjEuSW5zdGFuY2VTZXR0aW5nLkxMTVNldHRpbmdIABqHAwoOR2VuZXJhbFNldHRpbmcSIgoaZGlzYWxsb3dfdXNlcl9yZWdpc3RyYXRpb24YAiABKAgSHgoWZGlzYWxsb3dfcGFzc3dvcmRfYXV0aBgDIAEoCBIZChFhZGRpdGlvbmFsX3NjcmlwdBgEIAEoCRIYChBhZGRpdGlvbmFsX3N0eWxlGAUgASgJElIKDmN1c3RvbV9wcm9maWxlGAYgASgLMjoubWVtb3MuYXBpLnYxLkluc3RhbmNlU2V0dGluZy5HZW5lcmFsU2V0dGluZy5DdXN0b21Qcm9maWxlEh0KFXdlZWtfc3RhcnRfZGF5X29mZnNldBgHIAEoBRIgChhkaXNhbGxvd19jaGFuZ2VfdXNlcm5hbWUYCCABKAgSIAoYZGlzYWxsb3dfY2hhbmdlX25pY2tuYW1lGAkgASgIGkUKDUN1c3RvbVByb2ZpbGUSDQoFdGl0bGUYASABKAkSEwoLZGVzY3JpcHRpb24YAiABKAkSEAoIbG9nb191cmwYAyABKAkaugMKDlN0b3JhZ2VTZXR0aW5nEk4KDHN0b3JhZ2VfdHlwZRgBIAEoDjI4Lm1lbW9zLmFwaS52MS5JbnN0YW5jZVNldHRpbmcuU3RvcmFnZVNldHRpbmcuU3RvcmFnZVR5cGUSGQoRZmlsZXBhdGhfdGVtcGxhdGUYAiABKAkSHAoUdXBsb2FkX3NpemVfbGltaXRfbWIYAyABKAMSSAoJczNfY29uZmlnGAQgASgLMjUubWVtb3MuYXBpLnYxLkluc3RhbmNlU2V0dGluZy5TdG9yYWdlU2V0dGluZy5TM0NvbmZpZxqGAQoIUzNDb25maWcSFQoNYWNjZXNzX2tleV9pZBgBIAEoCRIZChFhY2Nlc3Nfa2V5X3NlY3JldBgCIAEoCRIQCghlbmRwb2ludBgDIAEoCRIOCgZyZWdpb24YBCABKAkSDgoGYnVja2V0GAUgASgJEhYKDnVzZV9wYXRoX3N0eWxlGAYgASgIIkwKC1N0b3JhZ2VUeXBlEhwKGFNUT1JBR0VfVFlQRV9VTlNQRUNJRklFRBAAEgwKCERBVEFCQVNFEAESCQoFTE9DQUwQAhIGCgJTMxADGq0BChJNZW1vUmVsYXRlZFNldHRpbmcSIgoaZGlzYWxsb3dfcHVibGljX3Zpc2liaWxpdHkYASABKAgSIAoYZGlzcGxheV93aXRoX3VwZGF0ZV90aW1lGAIgASgIEhwKFGNvbnRlbnRfbGVuZ3RoX2xpbWl0GAMgASgFEiAKGGVuYWJsZV9kb3VibGVfY2xpY2tfZWRpdBgEIAEoCBIRCglyZWFjdGlvbnMYByADKAka4gUKCkxMTVNldHRpbmcSRgoIcHJvdmlkZXIYASABKA4yNC5tZW1vcy5hcGkudjEuSW5zdGFuY2VTZXR0aW5nLkxMTVNldHRpbmcuTExNUHJvdmlkZXISRAoNb3BlbmFpX2NvbmZpZxgCIAEoCzItLm1lbW9zLmFwaS52MS5JbnN0YW5jZVNldHRpbmcuTExNT3BlbkFJQ29uZmlnEkoKEGFudGhyb3BpY19jb25maWcYAyABKAsyMC5tZW1vcy5hcGkudjEuSW5zdGFuY2VTZXR0aW5nLkxMTUFudGhyb3BpY0NvbmZpZxJECg1nZW1pbmlfY29uZmlnGAQgASgLMi0ubWVtb3MuYXBpLnYxLkluc3RhbmNlU2V0dGluZy5MTE1HZW1pbmlDb25maWcSRAoNb2xsYW1hX2NvbmZpZxgFIAEoCzItLm1lbW9zLmFwaS52MS5JbnN0YW5jZVNldHRpbmcuTExNT2xsYW1hQ29uZmlnEhsKE2VuYWJsZV9hdXRvX3RhZ2dpbmcYCiABKAgSGwoTZW5hYmxlX2F1dG9fc3VtbWFyeRgLIAEoCBIeChZlbmFibGVfc2VtYW50aWNfc2VhcmNoGAwgASgIEk8KGG9wZW5haV9jb21wYXRpYmxlX2NvbmZpZxgGIAEoCzItLm1lbW9zLmFwaS52MS5JbnN0YW5jZVNldHRpbmcuTExNT3BlbkFJQ29uZmlnEkIKC2dyb3FfY29uZmlnGAcgASgLMi0ubWVtb3MuYXBpLnYxLkluc3RhbmNlU2V0dGluZy5MTE1PcGVuQUlDb25maWcifwoLTExNUHJvdmlkZXISHAoYTExNX1BST1ZJREVSX1VOU1BFQ0lGSUVEEAASCgoGT1BFTkFJEAESDQoJQU5USFJPUElDEAISCgoGR0VNSU5JEAMSCgoGT0xMQU1BEAQSFQoRT1BFTkFJX0NPTVBBVElCTEUQBRIICgRHUk9REAYawAEKD0xMTU9wZW5BSUNvbmZpZxIPCgdhcGlfa2V5GAEgASgJEhAKCGJhc2VfdXJsGAIgASgJEhUKDWRlZmF1bHRfbW9kZWwYAyABKAkSFwoPZW1iZWRkaW5nX21vZGVsGAQgASgJEhgKEGF6dXJlX2RlcGxveW1lbnQYBSABKAkSGQoRYXp1cmVfYXBpX3ZlcnNpb24YBiABKAkSFAoMb3JnYW5pemF0aW9uGAcgASgJEg8KB3Byb2plY3QYCCABKAkaTgoSTExNQW50aHJvcGljQ29uZmlnEg8KB2FwaV9rZXkYASABKAkSEAoIYmFzZV91cmwYAiABKAkSFQoNZGVmYXVsdF9tb2RlbBgDIAEoCRo5Cg9MTE1HZW1pbmlDb25maWcSDwoHYXBpX2tleRgBIAEoCRIVCg1kZWZhdWx0X21vZGVsGAIgASgJGk8KD0xMTU9sbGFtYUNvbmZpZxIMCgRob3N0GAEgASgJEhUKDWRlZmF1bHRfbW9kZWwYAiABKAkSFwoPZW1iZWRkaW5nX21vZGVsGAMgASgJIk8KA0tleRITCg9LRVlfVU5TUEVDSUZJRUQQABILCgdHRU5FUkFMEAESCwoHU1RPUkFHRRACEhAKDE1FTU9fUkVMQVRFRBADEgcKA0xMTRAEOmHqQV4KHG1lbW9zLmFwaS52MS9JbnN0YW5jZVNldHRpbmcSG2luc3RhbmNlL3NldHRpbmdzL3tzZXR0aW5nfSoQaW5zdGFuY2VTZXR0aW5nczIPaW5zdGFuY2VTZXR0aW5nQgcKBXZhbHVlIk8KGUdldEluc3RhbmNlU2V0dGluZ1JlcXVlc3QSMgoEbmFtZRgBIAEoCUIk4EEC+kEeChxtZW1vcy5hcGkudjEvSW5zdGFuY2VTZXR0aW5nIokBChxVcGRhdGVJbnN0YW5jZVNldHRpbmdSZXF1ZXN0EjMKB3NldHRpbmcYASABKAsyHS5tZW1vcy5hcGkudjEuSW5zdGFuY2VTZXR0aW5nQgPgQQISNAoLdXBkYXRlX21hc2sYAiABKAsyGi5nb29nbGUucHJvdG9idWYuRmllbGRNYXNrQgPgQQEy2wMKD0luc3RhbmNlU2VydmljZRJ+ChJHZXRJbnN0YW5jZVByb2ZpbGUSJy5tZW1vcy5hcGkudjEuR2V0SW5zdGFuY2VQcm9maWxlUmVxdWVzdBodLm1lbW9zLmFwaS52MS5JbnN0YW5jZVByb2ZpbGUiIILT5JMCGhIYL2FwaS92MS9pbnN0YW5jZS9wcm9maWxlEo8BChJHZXRJbnN0YW5jZVNldHRpbmcSJy5tZW1vcy5hcGkudjEuR2V0SW5zdGFuY2VTZXR0aW5nUmVxdWVzdBodLm1lbW9zLmFwaS52MS5JbnN0YW5jZVNldHRpbmciMdpBBG5hbWWC0+STAiQSIi9hcGkvdjEve25hbWU9aW5zdGFuY2Uvc2V0dGluZ3MvKn0StQEKFVVwZGF0ZUluc3RhbmNlU2V0dGluZxIqLm1lbW9zLmFwaS52MS5VcGRhdGVJbnN0YW5jZVNldHRpbmdSZXF1ZXN0Gh0ubWVtb3MuYXBpLnYxLkluc3RhbmNlU2V0dGluZyJR2kETc2V0dGluZyx1cGRhdGVfbWFza4LT5JMCNToHc2V0dGluZzIqL2FwaS92MS97c2V0dGluZy5uYW1lPWluc3RhbmNlL3NldHRpbmdzLyp9QqwBChBjb20ubWVtb3MuYXBpLnYxQhRJbnN0YW5jZVNlcnZpY2VQcm90b1ABWjBnaXRodWIuY29tL3VzZW1lbW9zL21lbW9zL3Byb3RvL2dlbi9hcGkvdjE7YXBpdjGiAgNNQViqAgxNZW1vcy5BcGkuVjHKAgxNZW1vc1xBcGlcVjHiAhhNZW1vc1xBcGlcVjFcR1BCTWV0YWRhdGHqAg5NZW1vczo6QXBpOjpWMWIGcHJvdG8z", [file_google_api_annotations, file_google_api_client, file_google_api_field_behavior, file_google_api_resource, file_google_protobuf_field_mask]);
 
 /**
  * Instance profile message containing basic instance information.
@@ -467,6 +467,20 @@ export type InstanceSetting_LLMSetting = Message<"memos.api.v1.InstanceSetting.L
    * @generated from field: bool enable_semantic_search = 12;
    */
   enableSemanticSearch: boolean;
+
+  /**
+   * OpenAI-compatible endpoint configuration.
+   *
+   * @generated from field: memos.api.v1.InstanceSetting.LLMOpenAIConfig openai_compatible_config = 6;
+   */
+  openaiCompatibleConfig?: InstanceSetting_LLMOpenAIConfig;
+
+  /**
+   * Groq configuration.
+   *
+   * @generated from field: memos.api.v1.InstanceSetting.LLMOpenAIConfig groq_config = 7;
+   */
+  groqConfig?: InstanceSetting_LLMOpenAIConfig;
 };
 
 /**
@@ -514,6 +528,20 @@ export enum InstanceSetting_LLMSetting_LLMProvider {
    * @generated from enum value: OLLAMA = 4;
    */
   OLLAMA = 4,
+
+  /**
+   * OpenAI-compatible endpoint (self-hosted or third-party)
+   *
+   * @generated from enum value: OPENAI_COMPATIBLE = 5;
+   */
+  OPENAI_COMPATIBLE = 5,
+
+  /**
+   * Groq (OpenAI-compatible, fast inference)
+   *
+   * @generated from enum value: GROQ = 6;
+   */
+  GROQ = 6,
 }
 
 /**
@@ -555,6 +583,34 @@ export type InstanceSetting_LLMOpenAIConfig = Message<"memos.api.v1.InstanceSett
    * @generated from field: string embedding_model = 4;
    */
   embeddingModel: string;
+
+  /**
+   * Azure OpenAI deployment name. When set, requests use the Azure URL shape and api-key header.
+   *
+   * @generated from field: string azure_deployment = 5;
+   */
+  azureDeployment: string;
+
+  /**
+   * Azure OpenAI API version (e.g., "2024-06-01").
+   *
+   * @generated from field: string azure_api_version = 6;
+   */
+  azureApiVersion: string;
+
+  /**
+   * OpenAI organization ID sent as the OpenAI-Organization header.
+   *
+   * @generated from field: string organization = 7;
+   */
+  organization: string;
+
+  /**
+   * OpenAI project ID sent as the OpenAI-Project header.
+   *
+   * @generated from field: string project = 8;
+   */
+  project: string;
 };
 
 /**